@@ -33,6 +33,7 @@ import (
 func init() {
 	var shouldStreamLogs bool
 	var shouldStreamEvents bool
+	var rollbackOnFailure bool
 	var deploymentID string
 	var deployCmd = &cobra.Command{
 		Use:   "deploy <csar_path>",
@@ -49,46 +50,13 @@ func init() {
 			if err != nil {
 				httputil.ErrExit(err)
 			}
-			absPath, err := filepath.Abs(args[0])
+			csarZip, err := readCSAR(args[0])
 			if err != nil {
-				return err
+				httputil.ErrExit(err)
 			}
-			fileInfo, err := os.Stat(absPath)
+			location, err := submitCSAR(csarZip, client, deploymentID, rollbackOnFailure)
 			if err != nil {
-				return err
-			}
-			var location = ""
-			if !fileInfo.IsDir() {
-				file, err := os.Open(absPath)
-				if err != nil {
-					httputil.ErrExit(err)
-				}
-
-				defer file.Close()
-
-				buff, err := ioutil.ReadAll(file)
-				if err != nil {
-					httputil.ErrExit(err)
-				}
-				fileType := http.DetectContentType(buff)
-				if fileType == "application/zip" {
-					location, err = submitCSAR(buff, client, deploymentID)
-					if err != nil {
-						httputil.ErrExit(err)
-					}
-				}
-			}
-
-			if location == "" {
-				csarZip, err := ziputil.ZipPath(absPath)
-				if err != nil {
-					httputil.ErrExit(err)
-				}
-				location, err = submitCSAR(csarZip, client, deploymentID)
-
-				if err != nil {
-					httputil.ErrExit(err)
-				}
+				httputil.ErrExit(err)
 			}
 			taskID := path.Base(location)
 			if deploymentID == "" {
@@ -107,19 +75,48 @@ func init() {
 	}
 	deployCmd.PersistentFlags().BoolVarP(&shouldStreamLogs, "stream-logs", "l", false, "Stream logs after deploying the CSAR. In this mode logs can't be filtered, to use this feature see the \"log\" command.")
 	deployCmd.PersistentFlags().BoolVarP(&shouldStreamEvents, "stream-events", "e", false, "Stream events after deploying the CSAR.")
+	deployCmd.PersistentFlags().BoolVarP(&rollbackOnFailure, "rollback-on-failure", "", false, "Remove resources created by the install workflow if it fails.")
 	// Do not impose a max id length as it doesn't have a concrete impact for now
 	//deployCmd.PersistentFlags().StringVarP(&deploymentID, "id", "", "", fmt.Sprintf("Specify a id for this deployment. This id should not already exists, should respect the following format: %q and should be less than %d characters long", rest.YorcDeploymentIDPattern, rest.YorcDeploymentIDMaxLength))
-	deployCmd.PersistentFlags().StringVarP(&deploymentID, "id", "", "", fmt.Sprintf("Specify a id for this deployment. This id should not already exists, should respect the following format: %q", rest.YorcDeploymentIDPattern))
+	deployCmd.PersistentFlags().StringVarP(&deploymentID, "id", "", "", fmt.Sprintf("Specify a id for this deployment. If a deployment with this id already exists it is updated (see the \"update\" command). This id should respect the following format: %q", rest.YorcDeploymentIDPattern))
 	DeploymentsCmd.AddCommand(deployCmd)
 }
 
-func submitCSAR(csarZip []byte, client *httputil.YorcClient, deploymentID string) (string, error) {
+// readCSAR returns the content of the CSAR pointed by csarPath
+//
+// If csarPath points to a zip archive it is returned as it, otherwise the file or directory is zipped.
+func readCSAR(csarPath string) ([]byte, error) {
+	absPath, err := filepath.Abs(csarPath)
+	if err != nil {
+		return nil, err
+	}
+	fileInfo, err := os.Stat(absPath)
+	if err != nil {
+		return nil, err
+	}
+	if !fileInfo.IsDir() {
+		buff, err := ioutil.ReadFile(absPath)
+		if err != nil {
+			return nil, err
+		}
+		if http.DetectContentType(buff) == "application/zip" {
+			return buff, nil
+		}
+	}
+	return ziputil.ZipPath(absPath)
+}
+
+func submitCSAR(csarZip []byte, client *httputil.YorcClient, deploymentID string, rollbackOnFailure bool) (string, error) {
 	var request *http.Request
 	var err error
+	query := ""
+	if rollbackOnFailure {
+		query = "?rollbackOnFailure"
+	}
 	if deploymentID != "" {
-		request, err = client.NewRequest(http.MethodPut, path.Join("/deployments", deploymentID), bytes.NewReader(csarZip))
+		request, err = client.NewRequest(http.MethodPut, path.Join("/deployments", deploymentID)+query, bytes.NewReader(csarZip))
 	} else {
-		request, err = client.NewRequest(http.MethodPost, "/deployments", bytes.NewReader(csarZip))
+		request, err = client.NewRequest(http.MethodPost, "/deployments"+query, bytes.NewReader(csarZip))
 	}
 	if err != nil {
 		return "", err
@@ -0,0 +1,177 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ystia/yorc/commands/httputil"
+	"github.com/ystia/yorc/rest"
+	"github.com/ystia/yorc/tasks"
+)
+
+func init() {
+	var reconcile bool
+	var shouldStreamLogs bool
+	var shouldStreamEvents bool
+	var driftCmd = &cobra.Command{
+		Use:   "drift <id>",
+		Short: "Detect infrastructure drifts of a deployed application",
+		Long: `Check if the infrastructure resources of the deployment <id> were modified or deleted outside of Yorc.
+	Resources managed by Terraform are compared to their state stored in Consul.
+	With the --reconcile flag drifted resources are brought back to their expected state.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.Errorf("Expecting a deployment id (got %d parameters)", len(args))
+			}
+			if shouldStreamLogs && shouldStreamEvents {
+				return errors.Errorf("You can't provide stream-events and stream-logs flags at same time")
+			}
+			client, err := httputil.GetClient()
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			deploymentID := args[0]
+
+			report, err := detectDrift(client, deploymentID)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			printDriftReport(report, !NoColor)
+
+			if !reconcile || !report.Drifted {
+				return nil
+			}
+			taskID, err := submitReconcile(client, deploymentID)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			fmt.Printf("Drift reconciliation submitted. Deployment Id: %s\t(Reconcile Task Id: %s)\n", deploymentID, taskID)
+			if shouldStreamLogs {
+				StreamsLogs(client, deploymentID, !NoColor, false, false)
+			} else if shouldStreamEvents {
+				StreamsEvents(client, deploymentID, !NoColor, false, false)
+			}
+			return nil
+		},
+	}
+	driftCmd.PersistentFlags().BoolVarP(&reconcile, "reconcile", "r", false, "Bring back drifted resources to their expected state")
+	driftCmd.PersistentFlags().BoolVarP(&shouldStreamLogs, "stream-logs", "l", false, "Stream logs after submitting the reconciliation. In this mode logs can't be filtered, to use this feature see the \"log\" command.")
+	driftCmd.PersistentFlags().BoolVarP(&shouldStreamEvents, "stream-events", "e", false, "Stream events after submitting the reconciliation.")
+	DeploymentsCmd.AddCommand(driftCmd)
+}
+
+// detectDrift submits a drift query and waits for its result
+func detectDrift(client *httputil.YorcClient, deploymentID string) (*rest.DriftReport, error) {
+	response, err := client.Post(path.Join("/drift", deploymentID), "application/json", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, httputil.YorcAPIDefaultErrorMsg)
+	}
+	response.Body.Close()
+	httputil.HandleHTTPStatusCode(response, deploymentID, "deployment", http.StatusAccepted)
+	location := response.Header.Get("Location")
+	if location == "" {
+		return nil, errors.New("No \"Location\" header returned in Yorc response")
+	}
+
+	for {
+		task, err := getQueryTask(client, location)
+		if err != nil {
+			return nil, err
+		}
+		switch task.Status {
+		case tasks.DONE.String():
+			report := &rest.DriftReport{}
+			if err = json.Unmarshal(task.ResultSet, report); err != nil {
+				return nil, errors.Wrap(err, "Failed to read the drift report")
+			}
+			return report, nil
+		case tasks.FAILED.String(), tasks.CANCELED.String():
+			return nil, errors.Errorf("Drift detection task %s ended with status %s, check the deployment logs for details", task.ID, task.Status)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+func getQueryTask(client *httputil.YorcClient, location string) (*rest.Task, error) {
+	request, err := client.NewRequest(http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Add("Accept", "application/json")
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, errors.Wrap(err, httputil.YorcAPIDefaultErrorMsg)
+	}
+	defer response.Body.Close()
+	httputil.HandleHTTPStatusCode(response, path.Base(location), "task", http.StatusOK)
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	task := &rest.Task{}
+	if err = json.Unmarshal(body, task); err != nil {
+		return nil, errors.Wrap(err, "Failed to read the drift detection task")
+	}
+	return task, nil
+}
+
+func submitReconcile(client *httputil.YorcClient, deploymentID string) (string, error) {
+	response, err := client.Post(path.Join("/deployments", deploymentID, "reconcile"), "application/json", nil)
+	if err != nil {
+		return "", errors.Wrap(err, httputil.YorcAPIDefaultErrorMsg)
+	}
+	response.Body.Close()
+	httputil.HandleHTTPStatusCode(response, deploymentID, "deployment", http.StatusCreated)
+	location := response.Header.Get("Location")
+	if location == "" {
+		return "", errors.New("No \"Location\" header returned in Yorc response")
+	}
+	return path.Base(location), nil
+}
+
+func printDriftReport(report *rest.DriftReport, colorize bool) {
+	if colorize {
+		defer color.Unset()
+	}
+	driftedLabel := "drifted"
+	if colorize {
+		driftedLabel = color.New(color.FgHiYellow, color.Bold).SprintFunc()(driftedLabel)
+	}
+	for _, node := range report.Nodes {
+		if len(node.Resources) == 0 {
+			fmt.Printf("Node %s: no drift\n", node.NodeName)
+			continue
+		}
+		fmt.Printf("Node %s: %s\n", node.NodeName, driftedLabel)
+		for _, res := range node.Resources {
+			fmt.Printf("  %s: %s\n", res.Resource, res.Kind)
+		}
+	}
+	if !report.Drifted {
+		fmt.Printf("No drift detected on deployment %s (%d node(s) checked)\n", report.DeploymentID, len(report.Nodes))
+		return
+	}
+	fmt.Printf("Drifts detected on deployment %s\n", report.DeploymentID)
+}
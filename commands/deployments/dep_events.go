@@ -34,6 +34,8 @@ import (
 func init() {
 	var fromBeginning bool
 	var noStream bool
+	var follow bool
+	var filter events.Filter
 	var eventCmd = &cobra.Command{
 		Use:     "events [<DeploymentId>]",
 		Short:   "Stream events for a deployment or all deployments",
@@ -56,17 +58,56 @@ func init() {
 			}
 			colorize := !NoColor
 
-			StreamsEvents(client, deploymentID, colorize, fromBeginning, noStream)
+			if follow {
+				if noStream {
+					return errors.New("--follow and --no-stream flags are mutually exclusive")
+				}
+				FollowEvents(client, deploymentID, colorize, fromBeginning, filter)
+				return nil
+			}
+			streamEvents(client, deploymentID, colorize, fromBeginning, noStream, filterQueryParameters(filter))
 			return nil
 		},
 	}
 	eventCmd.PersistentFlags().BoolVarP(&fromBeginning, "from-beginning", "b", false, "Show events from the beginning of deployments")
 	eventCmd.PersistentFlags().BoolVarP(&noStream, "no-stream", "n", false, "Show events then exit. Do not stream events. It implies --from-beginning")
+	eventCmd.PersistentFlags().BoolVarP(&follow, "follow", "f", false, "Follow events as they are pushed by Yorc using a Server-Sent Events stream")
+	eventCmd.PersistentFlags().StringSliceVar(&filter.Nodes, "node", nil, "Show only events related to the given nodes (comma-separated or repeated flag)")
+	eventCmd.PersistentFlags().StringSliceVar(&filter.Tasks, "task", nil, "Show only events related to the given tasks (comma-separated or repeated flag)")
 	DeploymentsCmd.AddCommand(eventCmd)
 }
 
 // StreamsEvents allows to stream events
 func StreamsEvents(client *httputil.YorcClient, deploymentID string, colorize, fromBeginning, stop bool) {
+	streamEvents(client, deploymentID, colorize, fromBeginning, stop, "")
+}
+
+// FollowEvents allows to follow events pushed by Yorc as a Server-Sent Events stream
+func FollowEvents(client *httputil.YorcClient, deploymentID string, colorize, fromBeginning bool, filter events.Filter) {
+	if colorize {
+		defer color.Unset()
+	}
+	var lastIdx uint64
+	if !fromBeginning {
+		var ok bool
+		lastIdx, ok = getLatestIndex(client, deploymentID, "events")
+		if ok {
+			fmt.Println("Streaming new events...")
+		} else {
+			fmt.Fprint(os.Stderr, "Failed to get latest events index from Yorc, events will appear from the beginning.")
+		}
+	}
+	followStream(client, deploymentID, "events", lastIdx, filter, func(data []byte) {
+		var event events.StatusUpdate
+		err := json.Unmarshal(data, &event)
+		if err != nil {
+			httputil.ErrExit(err)
+		}
+		printEvent(event, colorize)
+	})
+}
+
+func streamEvents(client *httputil.YorcClient, deploymentID string, colorize, fromBeginning, stop bool, filtersParam string) {
 	if colorize {
 		defer color.Unset()
 	}
@@ -98,9 +139,9 @@ func StreamsEvents(client *httputil.YorcClient, deploymentID string, colorize, f
 	}
 	for {
 		if deploymentID != "" {
-			request, err = client.NewRequest("GET", fmt.Sprintf("/deployments/%s/events?index=%d", deploymentID, lastIdx), nil)
+			request, err = client.NewRequest("GET", fmt.Sprintf("/deployments/%s/events?index=%d%s", deploymentID, lastIdx, filtersParam), nil)
 		} else {
-			request, err = client.NewRequest("GET", fmt.Sprintf("/events?index=%d", lastIdx), nil)
+			request, err = client.NewRequest("GET", fmt.Sprintf("/events?index=%d%s", lastIdx, filtersParam), nil)
 		}
 		if err != nil {
 			httputil.ErrExit(err)
@@ -128,32 +169,7 @@ func StreamsEvents(client *httputil.YorcClient, deploymentID string, colorize, f
 		}
 		lastIdx = evts.LastIndex
 		for _, event := range evts.Events {
-			ts := event.Timestamp
-			if colorize {
-				ts = color.CyanString("%s", event.Timestamp)
-			}
-			evType, err := events.StatusUpdateTypeString(event.Type)
-			if err != nil {
-				if colorize {
-					fmt.Printf("%s: ", color.MagentaString("Warning"))
-				} else {
-					fmt.Print("Warning: ")
-				}
-				fmt.Printf("Unknown event type: %q\n", event.Type)
-			}
-			switch evType {
-			case events.InstanceStatusChangeType:
-				fmt.Printf("%s:\t Deployment: %s\t Node: %s\t Instance: %s\t State: %s\n", ts, event.DeploymentID, event.Node, event.Instance, event.Status)
-			case events.DeploymentStatusChangeType:
-				fmt.Printf("%s:\t Deployment: %s\t Deployment Status: %s\n", ts, event.DeploymentID, event.Status)
-			case events.CustomCommandStatusChangeType:
-				fmt.Printf("%s:\t Deployment: %s\t Task %q (custom command)\t Status: %s\n", ts, event.DeploymentID, event.TaskID, event.Status)
-			case events.ScalingStatusChangeType:
-				fmt.Printf("%s:\t Deployment: %s\t Task %q (scaling)\t Status: %s\n", ts, event.DeploymentID, event.TaskID, event.Status)
-			case events.WorkflowStatusChangeType:
-				fmt.Printf("%s:\t Deployment: %s\t Task %q (workflow)\t Status: %s\n", ts, event.DeploymentID, event.TaskID, event.Status)
-			}
-
+			printEvent(event, colorize)
 		}
 
 		response.Body.Close()
@@ -163,3 +179,31 @@ func StreamsEvents(client *httputil.YorcClient, deploymentID string, colorize, f
 		}
 	}
 }
+
+func printEvent(event events.StatusUpdate, colorize bool) {
+	ts := event.Timestamp
+	if colorize {
+		ts = color.CyanString("%s", event.Timestamp)
+	}
+	evType, err := events.StatusUpdateTypeString(event.Type)
+	if err != nil {
+		if colorize {
+			fmt.Printf("%s: ", color.MagentaString("Warning"))
+		} else {
+			fmt.Print("Warning: ")
+		}
+		fmt.Printf("Unknown event type: %q\n", event.Type)
+	}
+	switch evType {
+	case events.InstanceStatusChangeType:
+		fmt.Printf("%s:\t Deployment: %s\t Node: %s\t Instance: %s\t State: %s\n", ts, event.DeploymentID, event.Node, event.Instance, event.Status)
+	case events.DeploymentStatusChangeType:
+		fmt.Printf("%s:\t Deployment: %s\t Deployment Status: %s\n", ts, event.DeploymentID, event.Status)
+	case events.CustomCommandStatusChangeType:
+		fmt.Printf("%s:\t Deployment: %s\t Task %q (custom command)\t Status: %s\n", ts, event.DeploymentID, event.TaskID, event.Status)
+	case events.ScalingStatusChangeType:
+		fmt.Printf("%s:\t Deployment: %s\t Task %q (scaling)\t Status: %s\n", ts, event.DeploymentID, event.TaskID, event.Status)
+	case events.WorkflowStatusChangeType:
+		fmt.Printf("%s:\t Deployment: %s\t Task %q (workflow)\t Status: %s\n", ts, event.DeploymentID, event.TaskID, event.Status)
+	}
+}
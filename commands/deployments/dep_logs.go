@@ -34,6 +34,8 @@ import (
 func init() {
 	var fromBeginning bool
 	var noStream bool
+	var follow bool
+	var filter events.Filter
 	var logCmd = &cobra.Command{
 		Use:     "logs [<DeploymentId>]",
 		Short:   "Stream logs for a deployment or all deployments",
@@ -56,17 +58,53 @@ func init() {
 			}
 			colorize := !NoColor
 
-			StreamsLogs(client, deploymentID, colorize, fromBeginning, noStream)
+			if follow {
+				if noStream {
+					return errors.New("--follow and --no-stream flags are mutually exclusive")
+				}
+				FollowLogs(client, deploymentID, colorize, fromBeginning, filter)
+				return nil
+			}
+			streamLogs(client, deploymentID, colorize, fromBeginning, noStream, filterQueryParameters(filter))
 			return nil
 		},
 	}
 	logCmd.PersistentFlags().BoolVarP(&fromBeginning, "from-beginning", "b", false, "Show logs from the beginning of deployments")
 	logCmd.PersistentFlags().BoolVarP(&noStream, "no-stream", "n", false, "Show logs then exit. Do not stream logs. It implies --from-beginning")
+	logCmd.PersistentFlags().BoolVarP(&follow, "follow", "f", false, "Follow logs as they are pushed by Yorc using a Server-Sent Events stream")
+	logCmd.PersistentFlags().StringSliceVar(&filter.Nodes, "node", nil, "Show only logs related to the given nodes (comma-separated or repeated flag)")
+	logCmd.PersistentFlags().StringSliceVar(&filter.Tasks, "task", nil, "Show only logs related to the given tasks (comma-separated or repeated flag)")
+	logCmd.PersistentFlags().StringSliceVar(&filter.Workflows, "workflow", nil, "Show only logs related to the given workflows (comma-separated or repeated flag)")
+	logCmd.PersistentFlags().StringSliceVar(&filter.Levels, "level", nil, "Show only logs with the given levels: INFO, DEBUG, WARN or ERROR (comma-separated or repeated flag)")
 	DeploymentsCmd.AddCommand(logCmd)
 }
 
 // StreamsLogs allows to stream logs
 func StreamsLogs(client *httputil.YorcClient, deploymentID string, colorize, fromBeginning, stop bool) {
+	streamLogs(client, deploymentID, colorize, fromBeginning, stop, "")
+}
+
+// FollowLogs allows to follow logs pushed by Yorc as a Server-Sent Events stream
+func FollowLogs(client *httputil.YorcClient, deploymentID string, colorize, fromBeginning bool, filter events.Filter) {
+	if colorize {
+		defer color.Unset()
+	}
+	var lastIdx uint64
+	if !fromBeginning {
+		var ok bool
+		lastIdx, ok = getLatestIndex(client, deploymentID, "logs")
+		if ok {
+			fmt.Println("Streaming new logs...")
+		} else {
+			fmt.Fprint(os.Stderr, "Failed to get latest log index from Yorc, logs will appear from the beginning.")
+		}
+	}
+	followStream(client, deploymentID, "logs", lastIdx, filter, func(data []byte) {
+		printLog(data, colorize)
+	})
+}
+
+func streamLogs(client *httputil.YorcClient, deploymentID string, colorize, fromBeginning, stop bool, filtersParam string) {
 	if colorize {
 		defer color.Unset()
 	}
@@ -97,7 +135,6 @@ func StreamsLogs(client *httputil.YorcClient, deploymentID string, colorize, fro
 			fmt.Fprint(os.Stderr, "Failed to get latest log index from Yorc, logs will appear from the beginning.")
 		}
 	}
-	var filtersParam string
 	for {
 		if deploymentID != "" {
 			request, err = client.NewRequest("GET", fmt.Sprintf("/deployments/%s/logs?index=%d%s", deploymentID, lastIdx, filtersParam), nil)
@@ -129,11 +166,7 @@ func StreamsLogs(client *httputil.YorcClient, deploymentID string, colorize, fro
 
 		lastIdx = logs.LastIndex
 		for _, log := range logs.Logs {
-			if colorize {
-				fmt.Printf("%s\n", color.CyanString("%s", format(log)))
-			} else {
-				fmt.Printf("%s\n", format(log))
-			}
+			printLog(log, colorize)
 		}
 
 		response.Body.Close()
@@ -144,6 +177,14 @@ func StreamsLogs(client *httputil.YorcClient, deploymentID string, colorize, fro
 	}
 }
 
+func printLog(log json.RawMessage, colorize bool) {
+	if colorize {
+		fmt.Printf("%s\n", color.CyanString("%s", format(log)))
+	} else {
+		fmt.Printf("%s\n", format(log))
+	}
+}
+
 func format(log json.RawMessage) string {
 	var data map[string]interface{}
 	err := json.Unmarshal(log, &data)
@@ -32,18 +32,23 @@ func init() {
 	var shouldStreamLogs bool
 	var shouldStreamEvents bool
 	var nodeName string
+	var groupName string
 	var instancesDelta int32
 	var scaleCmd = &cobra.Command{
 		Use:   "scale <id>",
-		Short: "Scale a node",
-		Long:  `Scale a given node of a deployment <id> by adding or removing the specified number of instances.`,
+		Short: "Scale a node or a group of nodes",
+		Long: `Scale a given node of a deployment <id> by adding or removing the specified number of instances.
+When a group is given instead of a node, the same number of instances is added to or removed from each member of the group.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) != 1 {
 				return errors.Errorf("Expecting a deployment id (got %d parameters)", len(args))
 			}
 
-			if nodeName == "" {
-				return errors.New("Missing mandatory \"node\" flag")
+			if nodeName == "" && groupName == "" {
+				return errors.New("Missing mandatory \"node\" or \"group\" flag")
+			}
+			if nodeName != "" && groupName != "" {
+				return errors.New("You can't provide node and group flags at same time")
 			}
 
 			if instancesDelta == 0 {
@@ -56,7 +61,12 @@ func init() {
 			}
 			deploymentID := args[0]
 
-			location, err := postScalingRequest(client, deploymentID, nodeName, instancesDelta)
+			var location string
+			if groupName != "" {
+				location, err = postScalingRequest(client, deploymentID, path.Join("groups", groupName, "scale"), "deployment/group", groupName, instancesDelta)
+			} else {
+				location, err = postScalingRequest(client, deploymentID, path.Join("scale", nodeName), "deployment/node", nodeName, instancesDelta)
+			}
 			if err != nil {
 				return err
 			}
@@ -73,14 +83,15 @@ func init() {
 		},
 	}
 	scaleCmd.PersistentFlags().StringVarP(&nodeName, "node", "n", "", "The name of the node that should be scaled.")
+	scaleCmd.PersistentFlags().StringVarP(&groupName, "group", "g", "", "The name of the group whose members should be scaled. Can't be used with the node flag.")
 	scaleCmd.PersistentFlags().Int32VarP(&instancesDelta, "delta", "d", 0, "The non-zero number of instance to add (if > 0) or remove (if < 0).")
 	scaleCmd.PersistentFlags().BoolVarP(&shouldStreamLogs, "stream-logs", "l", false, "Stream logs after issuing the scaling request. In this mode logs can't be filtered, to use this feature see the \"log\" command.")
 	scaleCmd.PersistentFlags().BoolVarP(&shouldStreamEvents, "stream-events", "e", false, "Stream events after  issuing the scaling request.")
 	DeploymentsCmd.AddCommand(scaleCmd)
 }
 
-func postScalingRequest(client *httputil.YorcClient, deploymentID, nodeName string, instancesDelta int32) (string, error) {
-	request, err := client.NewRequest("POST", path.Join("/deployments", deploymentID, "scale", nodeName), nil)
+func postScalingRequest(client *httputil.YorcClient, deploymentID, scalingPath, resourceType, resourceName string, instancesDelta int32) (string, error) {
+	request, err := client.NewRequest("POST", path.Join("/deployments", deploymentID, scalingPath), nil)
 	if err != nil {
 		httputil.ErrExit(errors.Wrap(err, httputil.YorcAPIDefaultErrorMsg))
 	}
@@ -98,8 +109,8 @@ func postScalingRequest(client *httputil.YorcClient, deploymentID, nodeName stri
 		httputil.ErrExit(errors.Wrap(err, httputil.YorcAPIDefaultErrorMsg))
 	}
 
-	ids := deploymentID + "/" + nodeName
-	httputil.HandleHTTPStatusCode(response, ids, "deployment/node", http.StatusAccepted)
+	ids := deploymentID + "/" + resourceName
+	httputil.HandleHTTPStatusCode(response, ids, resourceType, http.StatusAccepted)
 	location := response.Header.Get("Location")
 	if location == "" {
 		return "", errors.New("No \"Location\" header returned in Yorc response")
@@ -0,0 +1,115 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ystia/yorc/commands/httputil"
+	"github.com/ystia/yorc/rest"
+)
+
+func init() {
+	var shouldStreamLogs bool
+	var shouldStreamEvents bool
+	var updateCmd = &cobra.Command{
+		Use:   "update <id> <csar_path>",
+		Short: "Update a deployed application",
+		Long: `Update the deployment <id> with a modified version of its CSAR pointed by <csar_path>
+	The new topology is compared to the deployed one: new nodes are installed, removed nodes are uninstalled
+	and modified nodes are configured again. Existing instances and their attributes are kept.
+	<csar_path> follows the same rules than for the "deploy" command.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return errors.Errorf("Expecting a deployment id and a path to a file or directory (got %d parameters)", len(args))
+			}
+			client, err := httputil.GetClient()
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			deploymentID := args[0]
+
+			// PUT on an unknown id creates a new deployment, ensure that we are updating an existing one
+			response, err := client.Get(path.Join("/deployments", deploymentID))
+			if err != nil {
+				httputil.ErrExit(errors.Wrap(err, httputil.YorcAPIDefaultErrorMsg))
+			}
+			response.Body.Close()
+			httputil.HandleHTTPStatusCode(response, deploymentID, "deployment", http.StatusOK)
+
+			csarZip, err := readCSAR(args[1])
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			location, update, err := submitCSARUpdate(client, deploymentID, csarZip)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			fmt.Printf("Deployment update submitted. Deployment Id: %s\t(Update Task Id: %s)\n", deploymentID, path.Base(location))
+			fmt.Printf("  Added nodes: %s\n", strings.Join(update.AddedNodes, ", "))
+			fmt.Printf("  Removed nodes: %s\n", strings.Join(update.RemovedNodes, ", "))
+			fmt.Printf("  Modified nodes: %s\n", strings.Join(update.ModifiedNodes, ", "))
+			if shouldStreamLogs && !shouldStreamEvents {
+				StreamsLogs(client, deploymentID, !NoColor, false, false)
+			} else if !shouldStreamLogs && shouldStreamEvents {
+				StreamsEvents(client, deploymentID, !NoColor, false, false)
+			} else if shouldStreamLogs && shouldStreamEvents {
+				return errors.Errorf("You can't provide stream-events and stream-logs flags at same time")
+			}
+			return nil
+		},
+	}
+	updateCmd.PersistentFlags().BoolVarP(&shouldStreamLogs, "stream-logs", "l", false, "Stream logs after submitting the update. In this mode logs can't be filtered, to use this feature see the \"log\" command.")
+	updateCmd.PersistentFlags().BoolVarP(&shouldStreamEvents, "stream-events", "e", false, "Stream events after submitting the update.")
+	DeploymentsCmd.AddCommand(updateCmd)
+}
+
+func submitCSARUpdate(client *httputil.YorcClient, deploymentID string, csarZip []byte) (string, *rest.DeploymentUpdate, error) {
+	request, err := client.NewRequest(http.MethodPut, path.Join("/deployments", deploymentID), bytes.NewReader(csarZip))
+	if err != nil {
+		return "", nil, err
+	}
+	request.Header.Add("Content-Type", "application/zip")
+	request.Header.Add("Accept", "application/json")
+	response, err := client.Do(request)
+	if err != nil {
+		return "", nil, err
+	}
+	defer response.Body.Close()
+	httputil.HandleHTTPStatusCode(response, deploymentID, "deployment", http.StatusCreated)
+	location := response.Header.Get("Location")
+	if location == "" {
+		return "", nil, errors.New("No \"Location\" header returned in Yorc response")
+	}
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return "", nil, err
+	}
+	update := &rest.DeploymentUpdate{}
+	err = json.Unmarshal(body, update)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "Failed to read the deployment update description")
+	}
+	return location, update, nil
+}
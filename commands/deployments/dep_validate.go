@@ -0,0 +1,107 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ystia/yorc/commands/httputil"
+	"github.com/ystia/yorc/rest"
+)
+
+func init() {
+	var validateCmd = &cobra.Command{
+		Use:   "validate <csar_path>",
+		Short: "Validate a CSAR without deploying it",
+		Long: `Check a CSAR pointed by <csar_path> as it would be checked by the "deploy" command but without creating a deployment.
+	Errors and warnings are reported with the definition file and line they relate to.
+	The command exits with a non-zero status if errors are found.
+	<csar_path> follows the same rules than for the "deploy" command.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.Errorf("Expecting a path to a file or directory (got %d parameters)", len(args))
+			}
+			client, err := httputil.GetClient()
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			csarZip, err := readCSAR(args[0])
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			report, err := submitCSARValidation(client, csarZip)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+
+			colorize := !NoColor
+			if colorize {
+				defer color.Unset()
+			}
+			errLevel, warnLevel := "Error", "Warning"
+			if colorize {
+				errLevel = color.New(color.FgHiRed, color.Bold).SprintFunc()(errLevel)
+				warnLevel = color.New(color.FgHiYellow, color.Bold).SprintFunc()(warnLevel)
+			}
+			for _, m := range report.Errors {
+				fmt.Printf("%s: %s\n", errLevel, m)
+			}
+			for _, m := range report.Warnings {
+				fmt.Printf("%s: %s\n", warnLevel, m)
+			}
+			if !report.Valid {
+				fmt.Printf("CSAR is invalid: %d error(s), %d warning(s)\n", len(report.Errors), len(report.Warnings))
+				os.Exit(1)
+			}
+			fmt.Printf("CSAR is valid (%d warning(s))\n", len(report.Warnings))
+			return nil
+		},
+	}
+	DeploymentsCmd.AddCommand(validateCmd)
+}
+
+func submitCSARValidation(client *httputil.YorcClient, csarZip []byte) (*rest.ValidationReport, error) {
+	request, err := client.NewRequest(http.MethodPost, "/validations", bytes.NewReader(csarZip))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Add("Content-Type", "application/zip")
+	request.Header.Add("Accept", "application/json")
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	httputil.HandleHTTPStatusCode(response, "", "validation", http.StatusOK)
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	report := &rest.ValidationReport{}
+	err = json.Unmarshal(body, report)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to read the validation report")
+	}
+	return report, nil
+}
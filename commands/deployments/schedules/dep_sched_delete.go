@@ -0,0 +1,56 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedules
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ystia/yorc/commands/httputil"
+)
+
+func init() {
+	schedulesCmd.AddCommand(deleteScheduleCmd)
+}
+
+var deleteScheduleCmd = &cobra.Command{
+	Use:   "delete <DeploymentId> <ScheduleId>",
+	Short: "Delete a schedule",
+	Long: `Delete a schedule and its history.
+	Tasks already registered by this schedule are not affected.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			return errors.Errorf("Expecting a deployment id and a schedule id (got %d parameters)", len(args))
+		}
+		client, err := httputil.GetClient()
+		if err != nil {
+			httputil.ErrExit(err)
+		}
+
+		request, err := client.NewRequest("DELETE", "/deployments/"+args[0]+"/schedules/"+args[1], nil)
+		if err != nil {
+			httputil.ErrExit(err)
+		}
+		response, err := client.Do(request)
+		defer response.Body.Close()
+		if err != nil {
+			httputil.ErrExit(err)
+		}
+		httputil.HandleHTTPStatusCode(response, args[0]+"/"+args[1], "deployment/schedule", http.StatusOK)
+		return nil
+	},
+}
@@ -0,0 +1,72 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedules
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ystia/yorc/commands/httputil"
+	"github.com/ystia/yorc/helper/tabutil"
+	"github.com/ystia/yorc/rest"
+)
+
+func init() {
+	schedulesCmd.AddCommand(scheduleInfoCmd)
+}
+
+var scheduleInfoCmd = &cobra.Command{
+	Use:   "info <DeploymentId> <ScheduleId>",
+	Short: "Get the details of a schedule",
+	Long:  `Display the definition of a schedule and the history of the tasks it registered.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			return errors.Errorf("Expecting a deployment id and a schedule id (got %d parameters)", len(args))
+		}
+		client, err := httputil.GetClient()
+		if err != nil {
+			httputil.ErrExit(err)
+		}
+
+		var schedule rest.Schedule
+		err = httputil.GetJSONEntityFromAtomGetRequest(client, rest.AtomLink{Href: "/deployments/" + args[0] + "/schedules/" + args[1]}, &schedule)
+		if err != nil {
+			httputil.ErrExit(err)
+		}
+
+		fmt.Println("Schedule:", schedule.ID)
+		fmt.Println("Cron:    ", schedule.Cron)
+		fmt.Println("Task:    ", scheduledTaskString(schedule.ScheduleRequest))
+		if schedule.ContinueOnError {
+			fmt.Println("Continue on error")
+		}
+		for name, value := range schedule.Inputs {
+			fmt.Printf("Input:    %s=%s\n", name, value)
+		}
+		fmt.Println("Next run:", formatNextRun(schedule.NextRun))
+
+		historyTable := tabutil.NewTable()
+		historyTable.AddHeaders("Date", "Task Id", "Error")
+		for _, entry := range schedule.History {
+			historyTable.AddRow(entry.Date.Local().Format(time.RFC3339), entry.TaskID, entry.Error)
+		}
+		fmt.Println("History:")
+		fmt.Println(historyTable.Render())
+		return nil
+	},
+}
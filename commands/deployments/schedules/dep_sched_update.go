@@ -0,0 +1,68 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedules
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ystia/yorc/commands/httputil"
+)
+
+func init() {
+	var flags scheduleFlags
+	var updateCmd = &cobra.Command{
+		Use:   "update <DeploymentId> <ScheduleId>",
+		Short: "Update a schedule",
+		Long: `Replace the definition of a schedule. The history of the schedule is kept.
+    Either a workflow name or a node name and a custom command name are required.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return errors.Errorf("Expecting a deployment id and a schedule id (got %d parameters)", len(args))
+			}
+			scheduleRequest, err := flags.request()
+			if err != nil {
+				return err
+			}
+			client, err := httputil.GetClient()
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			body, err := json.Marshal(scheduleRequest)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+
+			request, err := client.NewRequest("PUT", "/deployments/"+args[0]+"/schedules/"+args[1], bytes.NewBuffer(body))
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			request.Header.Add("Content-Type", "application/json")
+			response, err := client.Do(request)
+			defer response.Body.Close()
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			httputil.HandleHTTPStatusCode(response, args[0]+"/"+args[1], "deployment/schedule", http.StatusOK)
+			return nil
+		},
+	}
+	flags.addFlags(updateCmd)
+	schedulesCmd.AddCommand(updateCmd)
+}
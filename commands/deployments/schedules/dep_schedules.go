@@ -0,0 +1,164 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedules
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ystia/yorc/commands/deployments"
+	"github.com/ystia/yorc/commands/httputil"
+	"github.com/ystia/yorc/helper/tabutil"
+	"github.com/ystia/yorc/rest"
+	"github.com/ystia/yorc/tasks"
+)
+
+var schedulesCmd = &cobra.Command{
+	Use:   "schedules <DeploymentId>",
+	Short: "List and manage schedules of a deployment",
+	Long: `Display info about the schedules of a given deployment.
+    Schedules periodically run custom workflows or custom commands according to a cron expression.
+    It prints the schedules ID, cron expression, task and next run.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return errors.Errorf("Expecting a deployment id (got %d parameters)", len(args))
+		}
+		client, err := httputil.GetClient()
+		if err != nil {
+			httputil.ErrExit(err)
+		}
+		request, err := client.NewRequest("GET", "/deployments/"+args[0]+"/schedules", nil)
+		if err != nil {
+			httputil.ErrExit(err)
+		}
+		request.Header.Add("Accept", "application/json")
+		response, err := client.Do(request)
+		defer response.Body.Close()
+		if err != nil {
+			httputil.ErrExit(err)
+		}
+		httputil.HandleHTTPStatusCode(response, args[0], "schedule", http.StatusOK)
+
+		var schedulesCol rest.SchedulesCollection
+		body, err := ioutil.ReadAll(response.Body)
+		if err != nil {
+			httputil.ErrExit(err)
+		}
+		err = json.Unmarshal(body, &schedulesCol)
+		if err != nil {
+			httputil.ErrExit(err)
+		}
+
+		schedulesTable := tabutil.NewTable()
+		schedulesTable.AddHeaders("Id", "Cron", "Task", "Next Run", "Last Task")
+		for _, scheduleLink := range schedulesCol.Schedules {
+			if scheduleLink.Rel != rest.LinkRelSchedule {
+				continue
+			}
+			var schedule rest.Schedule
+			err = httputil.GetJSONEntityFromAtomGetRequest(client, scheduleLink, &schedule)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			var lastTask string
+			if len(schedule.History) > 0 {
+				lastEntry := schedule.History[len(schedule.History)-1]
+				lastTask = lastEntry.TaskID
+				if lastEntry.Error != "" {
+					lastTask = "error: " + lastEntry.Error
+				}
+			}
+			schedulesTable.AddRow(path.Base(scheduleLink.Href), schedule.Cron, scheduledTaskString(schedule.ScheduleRequest), formatNextRun(schedule.NextRun), lastTask)
+		}
+		fmt.Println("Schedules:")
+		fmt.Println(schedulesTable.Render())
+		return nil
+	},
+}
+
+func init() {
+	deployments.DeploymentsCmd.AddCommand(schedulesCmd)
+}
+
+// scheduleFlags holds the command-line flags describing a schedule
+type scheduleFlags struct {
+	cron            string
+	workflowName    string
+	continueOnError bool
+	nodeName        string
+	customCName     string
+	inputs          []string
+}
+
+func (f *scheduleFlags) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&f.cron, "cron", "s", "", "The cron expression of the schedule (like \"0 2 * * *\" or \"@daily\")")
+	cmd.Flags().StringVarP(&f.workflowName, "workflow-name", "w", "", "The name of the workflow to run (exclusive with custom commands flags)")
+	cmd.Flags().BoolVarP(&f.continueOnError, "continue-on-error", "", false, "By default if an error occurs in a step of the workflow then other running steps are cancelled and the workflow is stopped. This flag allows to continue to the next steps even if an error occurs.")
+	cmd.Flags().StringVarP(&f.nodeName, "node", "n", "", "The node name of the custom command to run (use with flags c and i)")
+	cmd.Flags().StringVarP(&f.customCName, "custom", "c", "", "The custom command name to run (use with flags n and i)")
+	cmd.Flags().StringSliceVarP(&f.inputs, "inputsMap", "i", make([]string, 0), "The inputs of the custom command as name=value pairs (use with flags c and n)")
+}
+
+func (f *scheduleFlags) request() (rest.ScheduleRequest, error) {
+	request := rest.ScheduleRequest{Cron: f.cron}
+	if f.cron == "" {
+		return request, errors.New("A cron expression is required")
+	}
+	switch {
+	case f.workflowName != "" && f.customCName == "":
+		request.TaskType = tasks.CustomWorkflow.String()
+		request.WorkflowName = f.workflowName
+		request.ContinueOnError = f.continueOnError
+	case f.workflowName == "" && f.customCName != "" && f.nodeName != "":
+		request.TaskType = tasks.CustomCommand.String()
+		request.NodeName = f.nodeName
+		request.CustomCommandName = f.customCName
+		request.Inputs = make(map[string]string)
+		for _, arg := range f.inputs {
+			for _, split := range strings.Split(arg, ",") {
+				tmp := strings.SplitN(split, "=", 2)
+				if len(tmp) != 2 {
+					return request, errors.Errorf("Invalid input %q, expecting name=value", split)
+				}
+				request.Inputs[tmp[0]] = tmp[1]
+			}
+		}
+	default:
+		return request, errors.New("You need to provide either a workflow name or a node name and a custom command name")
+	}
+	return request, nil
+}
+
+func scheduledTaskString(request rest.ScheduleRequest) string {
+	if strings.EqualFold(request.TaskType, tasks.CustomCommand.String()) {
+		return fmt.Sprintf("%s %s.%s", request.TaskType, request.NodeName, request.CustomCommandName)
+	}
+	return fmt.Sprintf("%s %s", request.TaskType, request.WorkflowName)
+}
+
+func formatNextRun(nextRun time.Time) string {
+	if nextRun.IsZero() {
+		return "never"
+	}
+	return nextRun.Local().Format(time.RFC3339)
+}
@@ -0,0 +1,115 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ystia/yorc/commands/httputil"
+	"github.com/ystia/yorc/events"
+	"github.com/ystia/yorc/rest"
+)
+
+// Delay before reconnecting to a Server-Sent Events stream closed by the server
+const followReconnectDelay = 2 * time.Second
+
+// getLatestIndex returns the latest index of the given stream kind ("events" or "logs").
+//
+// ok is false if Yorc did not return this index.
+func getLatestIndex(client *httputil.YorcClient, deploymentID, kind string) (idx uint64, ok bool) {
+	var response *http.Response
+	var err error
+	if deploymentID != "" {
+		response, err = client.Head("/deployments/" + deploymentID + "/" + kind)
+		httputil.HandleHTTPStatusCode(response, deploymentID, "deployment", http.StatusOK)
+	} else {
+		response, err = client.Head("/" + kind)
+	}
+	if err != nil {
+		httputil.ErrExit(err)
+	}
+	idxHd := response.Header.Get(rest.YorcIndexHeader)
+	if idxHd == "" {
+		return 0, false
+	}
+	idx, err = strconv.ParseUint(idxHd, 10, 64)
+	if err != nil {
+		httputil.ErrExit(err)
+	}
+	return idx, true
+}
+
+// filterQueryParameters returns the query string matching a filter, starting with a '&'.
+func filterQueryParameters(filter events.Filter) string {
+	values := url.Values{}
+	for param, criterion := range map[string][]string{"node": filter.Nodes, "task": filter.Tasks, "workflow": filter.Workflows, "level": filter.Levels} {
+		for _, v := range criterion {
+			values.Add(param, v)
+		}
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	return "&" + values.Encode()
+}
+
+// followStream reads the given stream kind ("events" or "logs") from Yorc as Server-Sent Events
+// and calls fn for each received entry.
+//
+// It never returns, when the connection is closed it is resumed from the last received event.
+func followStream(client *httputil.YorcClient, deploymentID, kind string, lastIdx uint64, filter events.Filter, fn func(data []byte)) {
+	var lastEventID string
+	for {
+		var path string
+		if deploymentID != "" {
+			path = fmt.Sprintf("/deployments/%s/%s?index=%d%s", deploymentID, kind, lastIdx, filterQueryParameters(filter))
+		} else {
+			path = fmt.Sprintf("/%s?index=%d%s", kind, lastIdx, filterQueryParameters(filter))
+		}
+		request, err := client.NewRequest("GET", path, nil)
+		if err != nil {
+			httputil.ErrExit(err)
+		}
+		request.Header.Add("Accept", "text/event-stream")
+		if lastEventID != "" {
+			request.Header.Add("Last-Event-ID", lastEventID)
+		}
+		response, err := client.Do(request)
+		if err != nil {
+			httputil.ErrExit(err)
+		}
+		if deploymentID != "" {
+			httputil.HandleHTTPStatusCode(response, deploymentID, "deployment", http.StatusOK)
+		} else {
+			httputil.HandleHTTPStatusCode(response, "", kind, http.StatusOK)
+		}
+
+		err = httputil.ReadServerSentEvents(response.Body, func(evt httputil.ServerSentEvent) error {
+			lastEventID = evt.ID
+			fn(evt.Data)
+			return nil
+		})
+		response.Body.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Connection to Yorc lost (%v), reconnecting...\n", err)
+		}
+		time.Sleep(followReconnectDelay)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 
 	"github.com/fatih/color"
@@ -118,10 +119,18 @@ func displayStepTables(client *httputil.YorcClient, args []string) {
 	}
 	fmt.Println("Steps:")
 	tasksTable := tabutil.NewTable()
-	tasksTable.AddHeaders("Name", "Status")
+	tasksTable.AddHeaders("Name", "Status", "Attempts", "Skipped On Resume")
 	errs := make([]error, 0)
 	for _, step := range steps {
-		tasksTable.AddRow(step.Name, getColoredTaskStepStatus(colorize, step.Status))
+		attempts := ""
+		if step.Attempts > 0 {
+			attempts = strconv.Itoa(step.Attempts)
+		}
+		skippedOnResume := ""
+		if step.SkippedOnResume {
+			skippedOnResume = "yes"
+		}
+		tasksTable.AddRow(step.Name, getColoredTaskStepStatus(colorize, step.Status), attempts, skippedOnResume)
 	}
 	fmt.Println(tasksTable.Render())
 	if len(errs) > 0 {
@@ -143,6 +152,8 @@ func getColoredTaskStepStatus(colorize bool, status string) string {
 		return color.New(color.FgHiYellow, color.Bold).SprintFunc()(status)
 	case strings.ToLower(status) == "done":
 		return color.New(color.FgHiGreen, color.Bold).SprintFunc()(status)
+	case strings.ToLower(status) == "skipped":
+		return color.New(color.FgHiBlue, color.Bold).SprintFunc()(status)
 	default:
 		return status
 	}
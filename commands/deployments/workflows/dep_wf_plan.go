@@ -0,0 +1,122 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflows
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ystia/yorc/commands/httputil"
+	"github.com/ystia/yorc/tasks"
+)
+
+func init() {
+	var workflowName string
+	var taskID string
+	var wfPlanCmd = &cobra.Command{
+		Use:   "plan <id>",
+		Short: "Show the execution plan of a given TOSCA workflow without running it.",
+		Long: `Show the execution plan of a given TOSCA workflow without running it.
+	Steps are grouped by execution levels, steps of a level only depend on steps of previous levels.
+	For each step the operations it would call and their executors are displayed as well as the reason why a step would be skipped.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.Errorf("Expecting an id (got %d parameters)", len(args))
+			}
+			client, err := httputil.GetClient()
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			if workflowName == "" {
+				return errors.New("Missing mandatory \"workflow-name\" parameter")
+			}
+			url := fmt.Sprintf("/deployments/%s/workflows/%s/plan", args[0], workflowName)
+			if taskID != "" {
+				url = url + "?taskID=" + taskID
+			}
+			request, err := client.NewRequest("GET", url, nil)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			request.Header.Add("Accept", "application/json")
+			response, err := client.Do(request)
+			defer response.Body.Close()
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			ids := args[0] + "/" + workflowName
+			httputil.HandleHTTPStatusCode(response, ids, "deployment/workflow", http.StatusOK)
+
+			var plan tasks.WorkflowPlan
+			body, err := ioutil.ReadAll(response.Body)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			err = json.Unmarshal(body, &plan)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			fmt.Printf("Execution plan of workflow %s:\n", workflowName)
+			for i, level := range plan.Levels {
+				fmt.Printf("  Level %d:\n", i)
+				for _, step := range level.Steps {
+					fmt.Printf("    Step %s:\n", step.Name)
+					if step.Target != "" {
+						fmt.Println("      Target:", step.Target)
+					}
+					if step.Skipped {
+						fmt.Println("      Skipped:", step.SkipReason)
+					}
+					fmt.Println("      Activities:")
+					for _, activity := range step.Activities {
+						fmt.Printf("        - %s: %s\n", activity.Type, activity.Value)
+						details := make([]string, 0)
+						if activity.NodeType != "" {
+							details = append(details, "node type: "+activity.NodeType)
+						}
+						if activity.ImplementationArtifact != "" {
+							details = append(details, "implementation artifact: "+activity.ImplementationArtifact)
+						}
+						if activity.ExecutorOrigin != "" {
+							details = append(details, fmt.Sprintf("executor: %s (%s)", activity.ExecutorOrigin, activity.ExecutorMatch))
+						}
+						if len(details) > 0 {
+							fmt.Println("          " + strings.Join(details, ", "))
+						}
+						if activity.Error != "" {
+							fmt.Println("          Error:", activity.Error)
+						}
+					}
+				}
+			}
+			if len(plan.SkippedSteps) > 0 {
+				fmt.Println("  Skipped steps:")
+				for _, stepName := range plan.SkippedSteps {
+					fmt.Println("    -", stepName)
+				}
+			}
+			return nil
+		},
+	}
+	wfPlanCmd.PersistentFlags().StringVarP(&workflowName, "workflow-name", "w", "", "The workflows name")
+	wfPlanCmd.PersistentFlags().StringVarP(&taskID, "task-id", "t", "", "Compute the plan in the context of the given task (for instance to preview a task resume)")
+	workflowsCmd.AddCommand(wfPlanCmd)
+}
@@ -67,7 +67,7 @@ func init() {
 			}
 
 			hostsTable := tabutil.NewTable()
-			hostsTable.AddHeaders("Name", "Connection", "Status", "Message", "Labels")
+			hostsTable.AddHeaders("Name", "Connection", "Status", "Message", "Labels", "Allocated To", "Since", "Expires")
 			var labelsList string
 			for k, v := range host.Labels {
 				if labelsList != "" {
@@ -76,7 +76,8 @@ func init() {
 				labelsList += fmt.Sprintf("%s:%s", k, v)
 			}
 
-			hostsTable.AddRow(host.Name, host.Connection.String(), getColoredHostStatus(colorize, host.Status.String()), host.Message, labelsList)
+			allocatedTo, since, expires := getHostLeaseColumns(host.Lease)
+			hostsTable.AddRow(host.Name, host.Connection.String(), getColoredHostStatus(colorize, host.Status.String()), host.Message, labelsList, allocatedTo, since, expires)
 
 			if colorize {
 				defer color.Unset()
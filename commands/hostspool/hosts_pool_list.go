@@ -22,11 +22,13 @@ import (
 	"net/http"
 
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/ystia/yorc/commands/httputil"
 	"github.com/ystia/yorc/helper/tabutil"
+	"github.com/ystia/yorc/prov/hostspool"
 	"github.com/ystia/yorc/rest"
 )
 
@@ -69,7 +71,7 @@ func init() {
 			}
 
 			hostsTable := tabutil.NewTable()
-			hostsTable.AddHeaders("Name", "Connection", "Status", "Message", "Labels")
+			hostsTable.AddHeaders("Name", "Connection", "Status", "Message", "Labels", "Allocated To", "Since", "Expires")
 			for _, hostLink := range hostsColl.Hosts {
 				if hostLink.Rel == rest.LinkRelHost {
 					var host rest.Host
@@ -87,7 +89,8 @@ func init() {
 						labelsList += fmt.Sprintf("%s:%s", k, v)
 					}
 
-					hostsTable.AddRow(host.Name, host.Connection.String(), getColoredHostStatus(colorize, host.Status.String()), host.Message, labelsList)
+					allocatedTo, since, expires := getHostLeaseColumns(host.Lease)
+					hostsTable.AddRow(host.Name, host.Connection.String(), getColoredHostStatus(colorize, host.Status.String()), host.Message, labelsList, allocatedTo, since, expires)
 				}
 			}
 			if colorize {
@@ -115,3 +118,15 @@ func getColoredHostStatus(colorize bool, status string) string {
 		return color.New(color.FgHiRed, color.Bold).SprintFunc()(status)
 	}
 }
+
+func getHostLeaseColumns(lease *hostspool.Lease) (string, string, string) {
+	if lease == nil {
+		return "", "", ""
+	}
+	expires := "never"
+	if lease.ExpirationDate != nil {
+		expires = lease.ExpirationDate.Format(time.RFC3339)
+	}
+	allocatedTo := fmt.Sprintf("%s/%s-%s", lease.DeploymentID, lease.NodeName, lease.InstanceID)
+	return allocatedTo, lease.AllocationDate.Format(time.RFC3339), expires
+}
@@ -0,0 +1,81 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputil
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// maxServerSentEventSize is the maximum size of a single line of a Server-Sent Events stream.
+// Yorc logs content may be up to 512Kb.
+const maxServerSentEventSize = 1024 * 1024
+
+// ServerSentEvent is an event received on a Server-Sent Events stream
+type ServerSentEvent struct {
+	// ID is the last event ID known for this stream
+	ID    string
+	Event string
+	Data  []byte
+}
+
+// ReadServerSentEvents reads a Server-Sent Events stream and calls fn for each received event.
+//
+// It returns when the stream ends, when reading fails or when fn returns an error.
+// Reaching the end of the stream is not considered as an error.
+func ReadServerSentEvents(r io.Reader, fn func(evt ServerSentEvent) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxServerSentEventSize)
+	var lastID, event string
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			// Blank line: dispatch the event
+			if len(data) > 0 {
+				if event == "" {
+					event = "message"
+				}
+				err := fn(ServerSentEvent{ID: lastID, Event: event, Data: []byte(strings.Join(data, "\n"))})
+				if err != nil {
+					return err
+				}
+			}
+			event = ""
+			data = nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			// Comment such as keep-alive
+			continue
+		}
+		field, value := line, ""
+		if i := strings.Index(line, ":"); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch field {
+		case "id":
+			lastID = value
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	return errors.Wrap(scanner.Err(), "failed to read events stream")
+}
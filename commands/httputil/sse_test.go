@@ -0,0 +1,54 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputil
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadServerSentEvents(t *testing.T) {
+	t.Parallel()
+	stream := ": keep-alive\n\n" +
+		"event: log\ndata: {\"content\":\"first\"}\n\n" +
+		"id: 12\nevent: log\ndata: {\"content\":\"second\"}\n\n" +
+		": keep-alive\n\n" +
+		"data: line1\ndata:line2\n\n" +
+		"id: 15\nevent: status\n"
+
+	var got []ServerSentEvent
+	err := ReadServerSentEvents(strings.NewReader(stream), func(evt ServerSentEvent) error {
+		got = append(got, evt)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []ServerSentEvent{
+		{ID: "", Event: "log", Data: []byte(`{"content":"first"}`)},
+		{ID: "12", Event: "log", Data: []byte(`{"content":"second"}`)},
+		{ID: "12", Event: "message", Data: []byte("line1\nline2")},
+	}, got)
+
+	var count int
+	err = ReadServerSentEvents(strings.NewReader(stream), func(evt ServerSentEvent) error {
+		count++
+		return errors.New("stop")
+	})
+	assert.EqualError(t, err, "stop")
+	assert.Equal(t, 1, count)
+}
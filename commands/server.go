@@ -19,6 +19,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/spf13/cast"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/ystia/yorc/config"
@@ -48,6 +49,7 @@ var ansibleConfiguration = map[string]interface{}{
 	"ansible.connection_retries":         5,
 	"ansible.operation_remote_base_dir":  ".yorc",
 	"ansible.keep_operation_remote_path": config.DefaultKeepOperationRemotePath,
+	"ansible.instances_parallelism":      config.DefaultAnsibleInstancesParallelism,
 }
 
 var consulConfiguration = map[string]interface{}{
@@ -63,6 +65,19 @@ var consulConfiguration = map[string]interface{}{
 	"consul.publisher_max_routines": config.DefaultConsulPubMaxRoutines,
 }
 
+var wfStepRetryConfiguration = map[string]interface{}{
+	"wf_step_retry.max_attempts":     config.DefaultWfStepRetryMaxAttempts,
+	"wf_step_retry.delay":            config.DefaultWfStepRetryDelay,
+	"wf_step_retry.max_delay":        config.DefaultWfStepRetryMaxDelay,
+	"wf_step_retry.backoff":          config.DefaultWfStepRetryBackoff,
+	"wf_step_retry.retryable_errors": []string{},
+}
+
+var tasksDispatcherConfiguration = map[string]interface{}{
+	"tasks_dispatcher.max_running_tasks_per_deployment":     config.DefaultTasksDispatcherMaxRunningTasksPerDeployment,
+	"tasks_dispatcher.max_running_tasks_per_infrastructure": config.DefaultTasksDispatcherMaxRunningTasksPerInfrastructure,
+}
+
 var cfgFile string
 
 var resolvedServerExtraParams []*serverExtraParams
@@ -191,6 +206,14 @@ func setConfig() {
 	serverCmd.PersistentFlags().Duration("graceful_shutdown_timeout", config.DefaultServerGracefulShutdownTimeout, "Timeout to  wait for a graceful shutdown of the Yorc server. After this delay the server immediately exits.")
 	serverCmd.PersistentFlags().StringP("resources_prefix", "x", "", "Prefix created resources (like Computes and so on)")
 	serverCmd.PersistentFlags().Duration("wf_step_graceful_termination_timeout", config.DefaultWfStepGracefulTerminationTimeout, "Timeout to wait for a graceful termination of a workflow step during concurrent workflow step failure. After this delay the step is set on error.")
+	serverCmd.PersistentFlags().Duration("wf_step_operation_timeout", 0, "Default timeout of an operation called by a workflow step, it may be overridden by the timeout of the operation implementation. After this delay the operation is canceled and the step is set on error. Zero means no timeout.")
+	serverCmd.PersistentFlags().Int("wf_step_retry_max_attempts", config.DefaultWfStepRetryMaxAttempts, "Default maximum number of attempts of an operation called by a workflow step. Setting it to 1 disables retries.")
+	serverCmd.PersistentFlags().Duration("wf_step_retry_delay", config.DefaultWfStepRetryDelay, "Default delay between two attempts of an operation called by a workflow step.")
+	serverCmd.PersistentFlags().Duration("wf_step_retry_max_delay", config.DefaultWfStepRetryMaxDelay, "Default maximum delay between two attempts of an operation called by a workflow step when using an exponential backoff.")
+	serverCmd.PersistentFlags().String("wf_step_retry_backoff", config.DefaultWfStepRetryBackoff, "Default backoff strategy between two attempts of an operation called by a workflow step. Either \"fixed\" or \"exponential\".")
+	serverCmd.PersistentFlags().StringSlice("wf_step_retry_retryable_errors", []string{}, "Default classes of errors (\"all\", \"timeout\", \"network\" or a regular expression matching the error message) that trigger a retry of an operation called by a workflow step. If empty all errors are retried.")
+	serverCmd.PersistentFlags().Int("tasks_dispatcher_max_running_tasks_per_deployment", config.DefaultTasksDispatcherMaxRunningTasksPerDeployment, "Maximum number of tasks running concurrently for a given deployment across all the Yorc servers of the cluster. 0 means no limit.")
+	serverCmd.PersistentFlags().Int("tasks_dispatcher_max_running_tasks_per_infrastructure", config.DefaultTasksDispatcherMaxRunningTasksPerInfrastructure, "Maximum number of tasks running concurrently on a given infrastructure across all the Yorc servers of the cluster. 0 means no limit.")
 
 	// Flags definition for Yorc HTTP REST API
 	serverCmd.PersistentFlags().Int("http_port", config.DefaultHTTPPort, "Port number for the Yorc HTTP REST API. If omitted or set to '0' then the default port number is used, any positive integer will be used as it, and finally any negative value will let use a random port.")
@@ -215,6 +238,7 @@ func setConfig() {
 	serverCmd.PersistentFlags().Bool("ansible_debug", false, "Prints massive debug information from Ansible")
 	serverCmd.PersistentFlags().Int("ansible_connection_retries", 5, "Number of retries in case of Ansible SSH connection failure")
 	serverCmd.PersistentFlags().String("operation_remote_base_dir", ".yorc", "Name of the temporary directory used by Ansible on the nodes")
+	serverCmd.PersistentFlags().Int("ansible_instances_parallelism", config.DefaultAnsibleInstancesParallelism, "Number of instances of a node on which a per-instance operation is run concurrently")
 	serverCmd.PersistentFlags().Bool("keep_operation_remote_path", config.DefaultKeepOperationRemotePath, "Define wether the path created to store artifacts on the nodes will be removed at the end of workflow executions.")
 
 	//Bind Consul persistent flags
@@ -229,6 +253,17 @@ func setConfig() {
 	viper.BindPFlag("server_graceful_shutdown_timeout", serverCmd.PersistentFlags().Lookup("graceful_shutdown_timeout"))
 	viper.BindPFlag("resources_prefix", serverCmd.PersistentFlags().Lookup("resources_prefix"))
 	viper.BindPFlag("wf_step_graceful_termination_timeout", serverCmd.PersistentFlags().Lookup("wf_step_graceful_termination_timeout"))
+	viper.BindPFlag("wf_step_operation_timeout", serverCmd.PersistentFlags().Lookup("wf_step_operation_timeout"))
+
+	//Bind workflow steps retry persistent flags
+	for key := range wfStepRetryConfiguration {
+		viper.BindPFlag(key, serverCmd.PersistentFlags().Lookup(toFlatKey(key)))
+	}
+
+	//Bind tasks dispatcher persistent flags
+	for key := range tasksDispatcherConfiguration {
+		viper.BindPFlag(key, serverCmd.PersistentFlags().Lookup(toFlatKey(key)))
+	}
 
 	//Bind Flags Yorc HTTP REST API
 	viper.BindPFlag("http_port", serverCmd.PersistentFlags().Lookup("http_port"))
@@ -260,6 +295,17 @@ func setConfig() {
 	}
 
 	viper.BindEnv("wf_step_graceful_termination_timeout")
+	viper.BindEnv("wf_step_operation_timeout")
+
+	//Bind workflow steps retry environment variables flags
+	for key := range wfStepRetryConfiguration {
+		viper.BindEnv(key, toEnvVar(key))
+	}
+
+	//Bind tasks dispatcher environment variables flags
+	for key := range tasksDispatcherConfiguration {
+		viper.BindEnv(key, toEnvVar(key))
+	}
 
 	//Bind Ansible environment variables flags
 	for key := range ansibleConfiguration {
@@ -281,6 +327,16 @@ func setConfig() {
 		viper.SetDefault(key, value)
 	}
 
+	// Workflow steps retry configuration default settings
+	for key, value := range wfStepRetryConfiguration {
+		viper.SetDefault(key, value)
+	}
+
+	// Tasks dispatcher configuration default settings
+	for key, value := range tasksDispatcherConfiguration {
+		viper.SetDefault(key, value)
+	}
+
 	// Ansible configuration default settings
 	for key, value := range ansibleConfiguration {
 		viper.SetDefault(key, value)
@@ -300,6 +356,7 @@ func getConfig() config.Configuration {
 	configuration.Ansible.ConnectionRetries = viper.GetInt("ansible.connection_retries")
 	configuration.Ansible.OperationRemoteBaseDir = viper.GetString("ansible.operation_remote_base_dir")
 	configuration.Ansible.KeepOperationRemotePath = viper.GetBool("ansible.keep_operation_remote_path")
+	configuration.Ansible.InstancesParallelism = viper.GetInt("ansible.instances_parallelism")
 	configuration.WorkingDirectory = viper.GetString("working_directory")
 	configuration.PluginsDirectory = viper.GetString("plugins_directory")
 	configuration.WorkersNumber = viper.GetInt("workers_number")
@@ -320,6 +377,18 @@ func getConfig() config.Configuration {
 	configuration.Consul.SSLVerify = viper.GetBool("consul.ssl_verify")
 	configuration.ServerGracefulShutdownTimeout = viper.GetDuration("server_graceful_shutdown_timeout")
 	configuration.WfStepGracefulTerminationTimeout = viper.GetDuration("wf_step_graceful_termination_timeout")
+	configuration.WfStepOperationTimeout = viper.GetDuration("wf_step_operation_timeout")
+	configuration.WfStepRetry.MaxAttempts = viper.GetInt("wf_step_retry.max_attempts")
+	configuration.WfStepRetry.Delay = viper.GetDuration("wf_step_retry.delay")
+	configuration.WfStepRetry.MaxDelay = viper.GetDuration("wf_step_retry.max_delay")
+	configuration.WfStepRetry.Backoff = viper.GetString("wf_step_retry.backoff")
+	configuration.WfStepRetry.RetryableErrors = viper.GetStringSlice("wf_step_retry.retryable_errors")
+	configuration.TasksDispatcher.MaxRunningTasksPerDeployment = viper.GetInt("tasks_dispatcher.max_running_tasks_per_deployment")
+	configuration.TasksDispatcher.MaxRunningTasksPerInfrastructure = viper.GetInt("tasks_dispatcher.max_running_tasks_per_infrastructure")
+	configuration.TasksDispatcher.Priorities = make(map[string]int)
+	for taskType, priority := range viper.GetStringMap("tasks_dispatcher.priorities") {
+		configuration.TasksDispatcher.Priorities[strings.ToLower(taskType)] = cast.ToInt(priority)
+	}
 	configuration.Infrastructures = make(map[string]config.DynamicMap)
 	configuration.Vault = make(config.DynamicMap)
 
@@ -163,7 +163,8 @@ func TestConfigFile(t *testing.T) {
 				DebugExec:               true,
 				ConnectionRetries:       10,
 				OperationRemoteBaseDir:  "test_base_dir",
-				KeepOperationRemotePath: true},
+				KeepOperationRemotePath: true,
+				InstancesParallelism:    config.DefaultAnsibleInstancesParallelism},
 			ConsulConfig: config.Consul{
 				Token:          "testToken",
 				Datacenter:     "testDC",
@@ -183,6 +184,7 @@ func TestConfigFile(t *testing.T) {
 				ConnectionRetries:       11,
 				OperationRemoteBaseDir:  "test_base_dir2",
 				KeepOperationRemotePath: true,
+				InstancesParallelism:    4,
 			},
 			ConsulConfig: config.Consul{
 				Token:          "testToken2",
@@ -221,6 +223,7 @@ func TestAnsibleDefaultValues(t *testing.T) {
 		ConnectionRetries:       5,
 		OperationRemoteBaseDir:  ".yorc",
 		KeepOperationRemotePath: false,
+		InstancesParallelism:    config.DefaultAnsibleInstancesParallelism,
 	}
 
 	testResetConfig()
@@ -263,6 +266,7 @@ func TestAnsibleEnvVariables(t *testing.T) {
 		ConnectionRetries:       12,
 		OperationRemoteBaseDir:  "testEnvBaseDir",
 		KeepOperationRemotePath: true,
+		InstancesParallelism:    6,
 	}
 
 	// Set Ansible configuration environment ariables
@@ -271,6 +275,7 @@ func TestAnsibleEnvVariables(t *testing.T) {
 	os.Setenv("YORC_ANSIBLE_CONNECTION_RETRIES", strconv.Itoa(expectedAnsibleConfig.ConnectionRetries))
 	os.Setenv("YORC_OPERATION_REMOTE_BASE_DIR", expectedAnsibleConfig.OperationRemoteBaseDir)
 	os.Setenv("YORC_KEEP_OPERATION_REMOTE_PATH", strconv.FormatBool(expectedAnsibleConfig.KeepOperationRemotePath))
+	os.Setenv("YORC_ANSIBLE_INSTANCES_PARALLELISM", strconv.Itoa(expectedAnsibleConfig.InstancesParallelism))
 
 	testResetConfig()
 	setConfig()
@@ -285,6 +290,7 @@ func TestAnsibleEnvVariables(t *testing.T) {
 	os.Unsetenv("YORC_ANSIBLE_CONNECTION_RETRIES")
 	os.Unsetenv("YORC_OPERATION_REMOTE_BASE_DIR")
 	os.Unsetenv("YORC_KEEP_OPERATION_REMOTE_PATH")
+	os.Unsetenv("YORC_ANSIBLE_INSTANCES_PARALLELISM")
 }
 
 // Tests Consul configuration using environment variables
@@ -344,14 +350,16 @@ func TestAnsiblePersistentFlags(t *testing.T) {
 		ConnectionRetries:       15,
 		OperationRemoteBaseDir:  "testPFlagBaseDir",
 		KeepOperationRemotePath: true,
+		InstancesParallelism:    8,
 	}
 
 	ansiblePFlagConfiguration := map[string]string{
-		"ansible_use_openssh":        strconv.FormatBool(expectedAnsibleConfig.UseOpenSSH),
-		"ansible_debug":              strconv.FormatBool(expectedAnsibleConfig.DebugExec),
-		"ansible_connection_retries": strconv.Itoa(expectedAnsibleConfig.ConnectionRetries),
-		"operation_remote_base_dir":  expectedAnsibleConfig.OperationRemoteBaseDir,
-		"keep_operation_remote_path": strconv.FormatBool(expectedAnsibleConfig.KeepOperationRemotePath),
+		"ansible_use_openssh":           strconv.FormatBool(expectedAnsibleConfig.UseOpenSSH),
+		"ansible_debug":                 strconv.FormatBool(expectedAnsibleConfig.DebugExec),
+		"ansible_connection_retries":    strconv.Itoa(expectedAnsibleConfig.ConnectionRetries),
+		"operation_remote_base_dir":     expectedAnsibleConfig.OperationRemoteBaseDir,
+		"keep_operation_remote_path":    strconv.FormatBool(expectedAnsibleConfig.KeepOperationRemotePath),
+		"ansible_instances_parallelism": strconv.Itoa(expectedAnsibleConfig.InstancesParallelism),
 	}
 
 	testResetConfig()
@@ -22,6 +22,7 @@ import (
 	"text/template"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cast"
 )
 
@@ -45,12 +46,33 @@ const DefaultPluginDir = "plugins"
 // DefaultServerGracefulShutdownTimeout is the default timeout for a graceful shutdown of a Yorc server before exiting
 const DefaultServerGracefulShutdownTimeout = 5 * time.Minute
 
-//DefaultKeepOperationRemotePath is set to true by default in order to remove path created to store operation artifacts on nodes.
+// DefaultKeepOperationRemotePath is set to true by default in order to remove path created to store operation artifacts on nodes.
 const DefaultKeepOperationRemotePath = false
 
+// DefaultAnsibleInstancesParallelism is the default number of instances of a node on which a per-instance operation is run concurrently
+const DefaultAnsibleInstancesParallelism = 1
+
 // DefaultWfStepGracefulTerminationTimeout is the default timeout for a graceful termination of a workflow step during concurrent workflow step failure
 const DefaultWfStepGracefulTerminationTimeout = 2 * time.Minute
 
+// DefaultWfStepRetryMaxAttempts is the default maximum number of attempts of a workflow step operation (1 means no retry)
+const DefaultWfStepRetryMaxAttempts = 1
+
+// DefaultWfStepRetryDelay is the default delay between two attempts of a workflow step operation
+const DefaultWfStepRetryDelay = 10 * time.Second
+
+// DefaultWfStepRetryMaxDelay is the default maximum delay between two attempts of a workflow step operation when using an exponential backoff
+const DefaultWfStepRetryMaxDelay = 5 * time.Minute
+
+// DefaultWfStepRetryBackoff is the default backoff strategy between two attempts of a workflow step operation
+const DefaultWfStepRetryBackoff = "fixed"
+
+// DefaultTasksDispatcherMaxRunningTasksPerDeployment is the default maximum number of tasks running concurrently for a given deployment (0 means no limit)
+const DefaultTasksDispatcherMaxRunningTasksPerDeployment = 0
+
+// DefaultTasksDispatcherMaxRunningTasksPerInfrastructure is the default maximum number of tasks running concurrently on a given infrastructure (0 means no limit)
+const DefaultTasksDispatcherMaxRunningTasksPerInfrastructure = 0
+
 // Configuration holds config information filled by Cobra and Viper (see commands package for more information)
 type Configuration struct {
 	Ansible                          Ansible
@@ -68,6 +90,12 @@ type Configuration struct {
 	Infrastructures                  map[string]DynamicMap
 	Vault                            DynamicMap
 	WfStepGracefulTerminationTimeout time.Duration
+	// WfStepOperationTimeout is the default timeout of an operation called by a workflow step (0 means no timeout)
+	//
+	// It is overridden by the timeout defined in the TOSCA implementation of an operation.
+	WfStepOperationTimeout time.Duration
+	WfStepRetry            WfStepRetry
+	TasksDispatcher        TasksDispatcher
 }
 
 // Ansible configuration
@@ -77,6 +105,31 @@ type Ansible struct {
 	ConnectionRetries       int
 	OperationRemoteBaseDir  string
 	KeepOperationRemotePath bool
+	// InstancesParallelism is the number of instances of a node on which a per-instance operation is run concurrently
+	//
+	// It may be overridden for some nodes by a yorc.policies.InstancesParallelism policy.
+	InstancesParallelism int
+}
+
+// WfStepRetry holds the default retry policy applied to operations called by workflow steps
+//
+// This policy may be overridden at the step level in TOSCA workflows.
+type WfStepRetry struct {
+	MaxAttempts     int
+	Delay           time.Duration
+	MaxDelay        time.Duration
+	Backoff         string
+	RetryableErrors []string
+}
+
+// TasksDispatcher holds the configuration of the tasks dispatcher
+//
+// Concurrency limits are enforced across all the Yorc servers of a cluster, so they should be identical on all of them.
+type TasksDispatcher struct {
+	// Priorities overrides the default priority of tasks by task type (lower cased task type name, like "deploy" or "customcommand")
+	Priorities                       map[string]int
+	MaxRunningTasksPerDeployment     int
+	MaxRunningTasksPerInfrastructure int
 }
 
 // Consul configuration
@@ -183,6 +236,39 @@ func (dm DynamicMap) GetDuration(name string) time.Duration {
 	return cast.ToDuration(dm.Get(name))
 }
 
+// GetNamedConfig returns a named sub-configuration defined under the given key of this map.
+//
+// The result contains the parameters defined at the root of this map, except the given key, overridden by the
+// parameters of the named sub-configuration. An empty name refers to the root parameters only.
+func (dm DynamicMap) GetNamedConfig(key, name string) (DynamicMap, error) {
+	result := make(DynamicMap)
+	for _, k := range dm.Keys() {
+		if k != key {
+			result.Set(k, dm[k])
+		}
+	}
+	if name == "" {
+		return result, nil
+	}
+
+	namedConfigs, err := cast.ToStringMapE(dm[key])
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %q configuration", key)
+	}
+	namedConfig, ok := namedConfigs[name]
+	if !ok {
+		return nil, errors.Errorf("no configuration named %q found under %q", name, key)
+	}
+	params, err := cast.ToStringMapE(namedConfig)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid configuration %q under %q", name, key)
+	}
+	for k, v := range params {
+		result.Set(k, v)
+	}
+	return result, nil
+}
+
 // DefaultConfigTemplateResolver is the default resolver for configuration templates
 var DefaultConfigTemplateResolver TemplateResolver = &configTemplateResolver{}
 
@@ -194,3 +194,39 @@ func TestDynamicMap_Keys(t *testing.T) {
 		})
 	}
 }
+
+func TestDynamicMap_GetNamedConfig(t *testing.T) {
+	t.Parallel()
+	dm := DynamicMap{
+		"url":  "1.2.3.4",
+		"port": 22,
+		"locations": map[string]interface{}{
+			"other": map[string]interface{}{"url": "5.6.7.8"},
+			"bad":   "not a map",
+		},
+	}
+	tests := []struct {
+		name    string
+		config  string
+		want    DynamicMap
+		wantErr bool
+	}{
+		{name: "TestRoot", config: "", want: DynamicMap{"url": "1.2.3.4", "port": 22}},
+		{name: "TestNamed", config: "other", want: DynamicMap{"url": "5.6.7.8", "port": 22}},
+		{name: "TestUnknown", config: "unknown", wantErr: true},
+		{name: "TestInvalid", config: "bad", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := dm.GetNamedConfig("locations", tt.config)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+	_, err := DynamicMap{"locations": "not a map"}.GetNamedConfig("locations", "other")
+	assert.Error(t, err)
+}
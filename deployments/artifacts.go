@@ -41,7 +41,7 @@ func GetArtifactsForType(kv *api.KV, deploymentID, typeName string) (map[string]
 	} else {
 		artifacts = make(map[string]string)
 	}
-	artifactsPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/types", typeName, "artifacts")
+	artifactsPath := path.Join(deploymentKVPrefix(deploymentID), "topology/types", typeName, "artifacts")
 
 	err = updateArtifactsFromPath(kv, artifacts, artifactsPath)
 	return artifacts, errors.Wrapf(err, "Failed to get artifacts for type: %q", typeName)
@@ -60,7 +60,7 @@ func GetArtifactsForNode(kv *api.KV, deploymentID, nodeName string) (map[string]
 	if err != nil {
 		return nil, err
 	}
-	artifactsPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/nodes", nodeName, "artifacts")
+	artifactsPath := path.Join(deploymentKVPrefix(deploymentID), "topology/nodes", nodeName, "artifacts")
 
 	err = updateArtifactsFromPath(kv, artifacts, artifactsPath)
 	return artifacts, errors.Wrapf(err, "Failed to get artifacts for node: %q", nodeName)
@@ -98,7 +98,7 @@ func updateArtifactsFromPath(kv *api.KV, artifacts map[string]string, artifactsP
 // GetArtifactTypeExtensions returns the extensions defined in this artifact type.
 // If the artifact doesn't define any extension then a nil slice is returned
 func GetArtifactTypeExtensions(kv *api.KV, deploymentID, artifactType string) ([]string, error) {
-	kvp, _, err := kv.Get(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/types", artifactType, "file_ext"), nil)
+	kvp, _, err := kv.Get(path.Join(deploymentKVPrefix(deploymentID), "topology/types", artifactType, "file_ext"), nil)
 	if err != nil {
 		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
 	}
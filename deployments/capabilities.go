@@ -42,7 +42,7 @@ func TypeHasCapability(kv *api.KV, deploymentID, typeName, capabilityTypeName st
 // GetCapabilitiesOfType returns names of all capabilities in a given type hierarchy that derives from a given capability type
 func GetCapabilitiesOfType(kv *api.KV, deploymentID, typeName, capabilityTypeName string) ([]string, error) {
 	capabilities := make([]string, 0)
-	typePath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "types", typeName)
+	typePath := path.Join(deploymentKVPrefix(deploymentID), "topology", "types", typeName)
 	capabilitiesKeys, _, err := kv.Keys(typePath+"/capabilities/", "/", nil)
 	if err != nil {
 		return capabilities, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
@@ -108,7 +108,7 @@ func GetCapabilityProperty(kv *api.KV, deploymentID, nodeName, capabilityName, p
 		}
 	}
 
-	capPropPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/nodes", nodeName, "capabilities", capabilityName, "properties", propertyName)
+	capPropPath := path.Join(deploymentKVPrefix(deploymentID), "topology/nodes", nodeName, "capabilities", capabilityName, "properties", propertyName)
 	found, result, err := getValueAssignmentWithDataType(kv, deploymentID, capPropPath, nodeName, "", "", propDataType, nestedKeys...)
 	if err != nil || found {
 		// If there is an error or property was found
@@ -212,7 +212,7 @@ func GetInstanceCapabilityAttribute(kv *api.KV, deploymentID, nodeName, instance
 	}
 
 	// First look at instance scoped attributes
-	capAttrPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/instances", nodeName, instanceName, "capabilities", capabilityName, "attributes", attributeName)
+	capAttrPath := path.Join(deploymentKVPrefix(deploymentID), "topology/instances", nodeName, instanceName, "capabilities", capabilityName, "attributes", attributeName)
 	found, result, err := getValueAssignmentWithDataType(kv, deploymentID, capAttrPath, nodeName, instanceName, "", attrDataType, nestedKeys...)
 	if err != nil || found {
 		// If there is an error or attribute was found
@@ -220,7 +220,7 @@ func GetInstanceCapabilityAttribute(kv *api.KV, deploymentID, nodeName, instance
 	}
 
 	// Then look at global node level
-	nodeCapPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/nodes", nodeName, "capabilities", capabilityName, "attributes", attributeName)
+	nodeCapPath := path.Join(deploymentKVPrefix(deploymentID), "topology/nodes", nodeName, "capabilities", capabilityName, "attributes", attributeName)
 	found, result, err = getValueAssignmentWithDataType(kv, deploymentID, nodeCapPath, nodeName, instanceName, "", attrDataType, nestedKeys...)
 	if err != nil || found {
 		// If there is an error or attribute was found
@@ -267,7 +267,7 @@ func SetInstanceCapabilityAttribute(deploymentID, nodeName, instanceName, capabi
 
 // SetInstanceCapabilityAttributeComplex sets an instance capability attribute that may be a literal or a complex data type
 func SetInstanceCapabilityAttributeComplex(deploymentID, nodeName, instanceName, capabilityName, attributeName string, attributeValue interface{}) error {
-	attrPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/instances", nodeName, instanceName, "capabilities", capabilityName, "attributes", attributeName)
+	attrPath := path.Join(deploymentKVPrefix(deploymentID), "topology/instances", nodeName, instanceName, "capabilities", capabilityName, "attributes", attributeName)
 	_, errGrp, store := consulutil.WithContext(context.Background())
 	storeComplexType(store, attrPath, attributeValue)
 	return errGrp.Wait()
@@ -292,7 +292,7 @@ func SetCapabilityAttributeComplexForAllInstances(kv *api.KV, deploymentID, node
 	}
 	_, errGrp, store := consulutil.WithContext(context.Background())
 	for _, instanceName := range ids {
-		attrPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/instances", nodeName, instanceName, "capabilities", capabilityName, "attributes", attributeName)
+		attrPath := path.Join(deploymentKVPrefix(deploymentID), "topology/instances", nodeName, instanceName, "capabilities", capabilityName, "attributes", attributeName)
 		storeComplexType(store, attrPath, attributeValue)
 	}
 	return errGrp.Wait()
@@ -316,7 +316,7 @@ func GetNodeCapabilityType(kv *api.KV, deploymentID, nodeName, capabilityName st
 // It may return an empty string if the capability is not found in the type hierarchy
 func GetNodeTypeCapabilityType(kv *api.KV, deploymentID, nodeType, capabilityName string) (string, error) {
 
-	kvp, _, err := kv.Get(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/types", nodeType, "capabilities", capabilityName, "type"), nil)
+	kvp, _, err := kv.Get(path.Join(deploymentKVPrefix(deploymentID), "topology/types", nodeType, "capabilities", capabilityName, "type"), nil)
 	if err != nil {
 		return "", errors.Wrap(err, consulutil.ConsulGenericErrMsg)
 	}
@@ -337,7 +337,7 @@ func GetNodeTypeCapabilityType(kv *api.KV, deploymentID, nodeType, capabilityNam
 //
 // It explores the type hierarchy (derived_from) to found the given capability.
 func GetNodeTypeCapabilityProperty(kv *api.KV, deploymentID, nodeType, capabilityName, propertyName, propDataType string) (bool, string, error) {
-	capPropPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/types", nodeType, "capabilities", capabilityName, "properties", propertyName)
+	capPropPath := path.Join(deploymentKVPrefix(deploymentID), "topology/types", nodeType, "capabilities", capabilityName, "properties", propertyName)
 	found, result, err := getValueAssignmentWithDataType(kv, deploymentID, capPropPath, "", "", "", propDataType)
 	if err != nil || found {
 		return found, result, errors.Wrapf(err, "Failed to get property %q for capability %q on node type %q", propertyName, capabilityName, nodeType)
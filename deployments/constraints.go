@@ -0,0 +1,584 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/tosca"
+)
+
+// A constraintViolation is a value of the topology template that doesn't satisfy its constraints
+type constraintViolation struct {
+	// templatePath is the path of the value in the topology template (ex: node_templates, Compute, properties, num_cpus)
+	templatePath []string
+	err          error
+}
+
+// constraintViolations is an error reporting all the constraint violations of a topology template
+type constraintViolations []constraintViolation
+
+func (cv constraintViolations) Error() string {
+	msgs := make([]string, len(cv))
+	for i := range cv {
+		msgs[i] = cv[i].err.Error()
+	}
+	return "constraints violations: " + strings.Join(msgs, "; ")
+}
+
+// A valueDefinition holds the parts of a property, attribute or input definition used to check constraints
+type valueDefinition struct {
+	dataType               string
+	entrySchema            string
+	constraints            []tosca.ConstraintClause
+	entrySchemaConstraints []tosca.ConstraintClause
+}
+
+// hasConstraints returns false if it is sure that no constraint applies on values of this definition
+func (d valueDefinition) hasConstraints() bool {
+	return len(d.constraints) > 0 || len(d.entrySchemaConstraints) > 0 ||
+		(d.dataType != "" && !tosca.IsBuiltinType(d.dataType)) ||
+		(d.entrySchema != "" && !tosca.IsBuiltinType(d.entrySchema))
+}
+
+// isComplex returns true if values of this definition may be lists or maps
+func (d valueDefinition) isComplex() bool {
+	return d.dataType == "list" || d.dataType == "map" || (d.dataType != "" && !tosca.IsBuiltinType(d.dataType))
+}
+
+// readConstraints reads the constraint clauses stored under the given Consul key
+func readConstraints(kv *api.KV, key string) ([]tosca.ConstraintClause, error) {
+	kvp, _, err := kv.Get(key, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp == nil || len(kvp.Value) == 0 {
+		return nil, nil
+	}
+	var constraints []tosca.ConstraintClause
+	err = yaml.Unmarshal(kvp.Value, &constraints)
+	return constraints, errors.Wrapf(err, "failed to parse constraints stored under %q", key)
+}
+
+// readValueDefinition reads the definition of a property, attribute or input stored under the given Consul prefix
+func readValueDefinition(kv *api.KV, defPath string) (valueDefinition, error) {
+	def := valueDefinition{}
+	kvp, _, err := kv.Get(path.Join(defPath, "type"), nil)
+	if err != nil {
+		return def, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp != nil {
+		def.dataType = string(kvp.Value)
+	}
+	kvp, _, err = kv.Get(path.Join(defPath, "entry_schema"), nil)
+	if err != nil {
+		return def, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp != nil {
+		def.entrySchema = string(kvp.Value)
+	}
+	def.constraints, err = readConstraints(kv, path.Join(defPath, "constraints"))
+	if err != nil {
+		return def, err
+	}
+	def.entrySchemaConstraints, err = readConstraints(kv, path.Join(defPath, "entry_schema_constraints"))
+	return def, err
+}
+
+// getTypeValueDefinitionPath returns the Consul prefix of the definition of a property or attribute in a type hierarchy.
+//
+// The definition of the most derived type is returned. An empty string is returned if there is no such definition.
+func getTypeValueDefinitionPath(kv *api.KV, deploymentID, typeName, name string, isProperty bool) (string, error) {
+	defType := "properties"
+	if !isProperty {
+		defType = "attributes"
+	}
+	for typeName != "" && !tosca.IsBuiltinType(typeName) {
+		defPath := path.Join(deploymentKVPrefix(deploymentID), "topology/types", typeName, defType, name)
+		kvp, _, err := kv.Get(path.Join(defPath, "name"), nil)
+		if err != nil {
+			return "", errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+		}
+		if kvp != nil {
+			return defPath, nil
+		}
+		typeName, err = GetParentType(kv, deploymentID, typeName)
+		if err != nil {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// parseConstrainedValue converts a value read from Consul into a value that could be checked against constraints.
+//
+// Lists and maps are returned by Consul accessors as JSON documents.
+func parseConstrainedValue(def valueDefinition, value string) interface{} {
+	if def.isComplex() && (strings.HasPrefix(value, "[") || strings.HasPrefix(value, "{")) {
+		if v, err := decodeJSONValue([]byte(value)); err == nil {
+			return v
+		}
+	}
+	return value
+}
+
+// normalizeConstrainedValue converts any Go value into lists, maps and scalars like the ones returned by parseConstrainedValue
+func normalizeConstrainedValue(value interface{}) (interface{}, error) {
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to check constraints of value %v", value)
+	}
+	v, err := decodeJSONValue(b)
+	return v, errors.Wrapf(err, "failed to check constraints of value %v", value)
+}
+
+// decodeJSONValue decodes a JSON document keeping numbers as they are written to check them against constraints
+func decodeJSONValue(b []byte) (interface{}, error) {
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	err := d.Decode(&v)
+	return v, err
+}
+
+// parseConstrainedValueIfString parses values given as strings like values read from Consul
+func parseConstrainedValueIfString(def valueDefinition, value interface{}) interface{} {
+	if s, ok := value.(string); ok {
+		return parseConstrainedValue(def, s)
+	}
+	return value
+}
+
+func formatConstrainedValue(value interface{}) string {
+	switch value.(type) {
+	case []interface{}, map[string]interface{}:
+		b, err := json.Marshal(value)
+		if err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprintf("%q", fmt.Sprint(value))
+}
+
+// checkConstraintClauses checks that a value satisfies the given constraints.
+//
+// Length constraints applied on lists and maps check their number of entries, other constraints
+// are not applicable on lists and maps.
+// location describes the checked value in error messages (ex: node "Compute" property "num_cpus")
+func checkConstraintClauses(constraints []tosca.ConstraintClause, location string, value interface{}) error {
+	for _, c := range constraints {
+		var ok bool
+		var err error
+		switch v := value.(type) {
+		case []interface{}:
+			if !c.IsLengthConstraint() {
+				continue
+			}
+			ok, err = c.EvaluateLength(len(v))
+		case map[string]interface{}:
+			if !c.IsLengthConstraint() {
+				continue
+			}
+			ok, err = c.EvaluateLength(len(v))
+		default:
+			ok, err = c.Evaluate(fmt.Sprint(v))
+		}
+		if err != nil {
+			return errors.Wrapf(err, "%s: failed to check constraint %q", location, c.String())
+		}
+		if !ok {
+			return errors.Errorf("%s: value %s does not satisfy constraint %q", location, formatConstrainedValue(value), c.String())
+		}
+	}
+	return nil
+}
+
+// checkValueConstraints checks that a value satisfies the constraints of its definition, the constraints of its
+// entry schema if it is a list or a map and the constraints of its data type.
+func checkValueConstraints(kv *api.KV, deploymentID string, def valueDefinition, location string, value interface{}) error {
+	if err := checkConstraintClauses(def.constraints, location, value); err != nil {
+		return err
+	}
+	if def.dataType == "list" || def.dataType == "map" {
+		entryDef := valueDefinition{dataType: def.entrySchema, constraints: def.entrySchemaConstraints}
+		switch v := value.(type) {
+		case []interface{}:
+			for i, entry := range v {
+				if err := checkValueConstraints(kv, deploymentID, entryDef, fmt.Sprintf("%s[%d]", location, i), entry); err != nil {
+					return err
+				}
+			}
+		case map[string]interface{}:
+			for k, entry := range v {
+				if err := checkValueConstraints(kv, deploymentID, entryDef, fmt.Sprintf("%s[%s]", location, k), entry); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return checkDataTypeConstraints(kv, deploymentID, def.dataType, location, value)
+}
+
+// checkDataTypeConstraints checks that a value satisfies the constraints of a data type and of its parents.
+//
+// If the value is a map, the constraints of the data type properties are checked too.
+func checkDataTypeConstraints(kv *api.KV, deploymentID, dataType, location string, value interface{}) error {
+	checkedProps := make(map[string]struct{})
+	for dataType != "" && !tosca.IsBuiltinType(dataType) {
+		typePath := path.Join(deploymentKVPrefix(deploymentID), "topology/types", dataType)
+		constraints, err := readConstraints(kv, path.Join(typePath, "constraints"))
+		if err != nil {
+			return err
+		}
+		if err = checkConstraintClauses(constraints, location, value); err != nil {
+			return err
+		}
+		if m, ok := value.(map[string]interface{}); ok {
+			props, err := GetTypeProperties(kv, deploymentID, dataType, false)
+			if err != nil {
+				return err
+			}
+			for _, prop := range props {
+				if _, checked := checkedProps[prop]; checked {
+					continue
+				}
+				checkedProps[prop] = struct{}{}
+				propValue, ok := m[prop]
+				if !ok {
+					continue
+				}
+				propDef, err := readValueDefinition(kv, path.Join(typePath, "properties", prop))
+				if err != nil {
+					return err
+				}
+				if err = checkValueConstraints(kv, deploymentID, propDef, location+"."+prop, propValue); err != nil {
+					return err
+				}
+			}
+		}
+		dataType, err = GetParentType(kv, deploymentID, dataType)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isResolvableAtStoreTime returns false if the given TOSCA function depends on values only known at runtime
+func isResolvableAtStoreTime(function string) (bool, error) {
+	va := &tosca.ValueAssignment{}
+	if err := yaml.Unmarshal([]byte(function), va); err != nil {
+		return false, errors.Wrapf(err, "Failed to parse TOSCA function %q", function)
+	}
+	fn := va.GetFunction()
+	if fn == nil {
+		return false, nil
+	}
+	return len(fn.GetFunctionsByOperator(tosca.GetAttributeOperator)) == 0 &&
+		len(fn.GetFunctionsByOperator(tosca.GetOperationOutputOperator)) == 0, nil
+}
+
+// checkStoredValueConstraints checks that a value assignment stored in Consul satisfies the constraints of its definition.
+//
+// Functions are resolved in the context of the given node if they don't depend on runtime values, otherwise they are not checked.
+func checkStoredValueConstraints(kv *api.KV, deploymentID, nodeName string, def valueDefinition, location, value string, isFunction bool) error {
+	if isFunction {
+		resolvable, err := isResolvableAtStoreTime(value)
+		if err != nil || !resolvable {
+			return err
+		}
+		value, err = resolveValueAssignmentAsString(kv, deploymentID, nodeName, "", "", value)
+		if err != nil {
+			// The function will be resolved again at runtime and will fail with a proper error
+			log.Debugf("Deployment %q: skipping constraints check on %s as its value can't be resolved: %v", deploymentID, location, err)
+			return nil
+		}
+	}
+	return checkValueConstraints(kv, deploymentID, def, location, parseConstrainedValue(def, value))
+}
+
+// checkTopologyConstraints checks that the values of inputs, node templates properties and node templates capabilities
+// properties satisfy their constraints.
+//
+// Values depending on attributes or operations outputs are only known at runtime and are not checked.
+func checkTopologyConstraints(kv *api.KV, deploymentID string) error {
+	var violations constraintViolations
+	topologyPath := path.Join(deploymentKVPrefix(deploymentID), "topology")
+
+	inputs, _, err := kv.Keys(path.Join(topologyPath, "inputs")+"/", "/", nil)
+	if err != nil {
+		return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	for _, inputPath := range inputs {
+		inputName := path.Base(inputPath)
+		def, err := readValueDefinition(kv, inputPath)
+		if err != nil {
+			return err
+		}
+		if !def.hasConstraints() {
+			continue
+		}
+		found, value, isFunction, err := getValueAssignmentWithoutResolve(kv, deploymentID, path.Join(inputPath, "value"), "")
+		if err != nil {
+			return err
+		}
+		if !found {
+			found, value, isFunction, err = getValueAssignmentWithoutResolve(kv, deploymentID, path.Join(inputPath, "default"), "")
+			if err != nil {
+				return err
+			}
+		}
+		if !found {
+			continue
+		}
+		err = checkStoredValueConstraints(kv, deploymentID, "", def, fmt.Sprintf("input %q", inputName), value, isFunction)
+		if err != nil {
+			violations = append(violations, constraintViolation{[]string{"inputs", inputName}, err})
+		}
+	}
+
+	nodes, err := GetNodes(kv, deploymentID)
+	if err != nil {
+		return err
+	}
+	for _, nodeName := range nodes {
+		nodeViolations, err := checkNodeConstraints(kv, deploymentID, nodeName)
+		if err != nil {
+			return err
+		}
+		violations = append(violations, nodeViolations...)
+	}
+	if len(violations) > 0 {
+		return violations
+	}
+	return nil
+}
+
+// checkNodeConstraints checks that the properties and capabilities properties of a node template satisfy their constraints
+func checkNodeConstraints(kv *api.KV, deploymentID, nodeName string) (constraintViolations, error) {
+	var violations constraintViolations
+	nodeType, err := GetNodeType(kv, deploymentID, nodeName)
+	if err != nil {
+		return nil, err
+	}
+	nodePath := path.Join(deploymentKVPrefix(deploymentID), "topology/nodes", nodeName)
+	props, err := GetTypeProperties(kv, deploymentID, nodeType, true)
+	if err != nil {
+		return nil, err
+	}
+	checkedProps := make(map[string]struct{})
+	for _, prop := range props {
+		if _, checked := checkedProps[prop]; checked {
+			continue
+		}
+		checkedProps[prop] = struct{}{}
+		defPath, err := getTypeValueDefinitionPath(kv, deploymentID, nodeType, prop, true)
+		if err != nil {
+			return nil, err
+		}
+		if defPath == "" {
+			continue
+		}
+		def, err := readValueDefinition(kv, defPath)
+		if err != nil {
+			return nil, err
+		}
+		if !def.hasConstraints() {
+			continue
+		}
+		found, value, isFunction, err := getValueAssignmentWithoutResolve(kv, deploymentID, path.Join(nodePath, "properties", prop), "")
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			found, value, isFunction, err = getTypeDefaultProperty(kv, deploymentID, nodeType, prop)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if !found {
+			continue
+		}
+		err = checkStoredValueConstraints(kv, deploymentID, nodeName, def, fmt.Sprintf("node %q property %q", nodeName, prop), value, isFunction)
+		if err != nil {
+			violations = append(violations, constraintViolation{[]string{"node_templates", nodeName, "properties", prop}, err})
+		}
+	}
+
+	capabilities, _, err := kv.Keys(path.Join(nodePath, "capabilities")+"/", "/", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	for _, capPath := range capabilities {
+		capName := path.Base(capPath)
+		capType, err := GetNodeCapabilityType(kv, deploymentID, nodeName, capName)
+		if err != nil {
+			return nil, err
+		}
+		if capType == "" {
+			continue
+		}
+		capProps, _, err := kv.Keys(path.Join(capPath, "properties")+"/", "/", nil)
+		if err != nil {
+			return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+		}
+		for _, capPropPath := range capProps {
+			prop := path.Base(capPropPath)
+			defPath, err := getTypeValueDefinitionPath(kv, deploymentID, capType, prop, true)
+			if err != nil {
+				return nil, err
+			}
+			if defPath == "" {
+				continue
+			}
+			def, err := readValueDefinition(kv, defPath)
+			if err != nil {
+				return nil, err
+			}
+			if !def.hasConstraints() {
+				continue
+			}
+			found, value, isFunction, err := getValueAssignmentWithoutResolve(kv, deploymentID, capPropPath, "")
+			if err != nil || !found {
+				if err != nil {
+					return nil, err
+				}
+				continue
+			}
+			err = checkStoredValueConstraints(kv, deploymentID, nodeName, def, fmt.Sprintf("node %q capability %q property %q", nodeName, capName, prop), value, isFunction)
+			if err != nil {
+				violations = append(violations, constraintViolation{[]string{"node_templates", nodeName, "capabilities", capName, "properties", prop}, err})
+			}
+		}
+	}
+	return violations, nil
+}
+
+// attributeConstraintsCache caches by deployment and node type the definitions having constraints of attributes.
+//
+// A definition of an attribute or of a property with the same name is mapped to its name. As types definitions of a
+// deployment only change when it is updated, the cache of a deployment is cleared when its definition is stored.
+var attributeConstraintsCache = struct {
+	sync.RWMutex
+	deployments map[string]map[string]map[string][]valueDefinition
+}{deployments: make(map[string]map[string]map[string][]valueDefinition)}
+
+// ClearAttributeConstraintsCache forgets the cached attributes constraints of a deployment
+func ClearAttributeConstraintsCache(deploymentID string) {
+	attributeConstraintsCache.Lock()
+	defer attributeConstraintsCache.Unlock()
+	delete(attributeConstraintsCache.deployments, deploymentID)
+}
+
+// getConstrainedAttributesDefinitions returns the definitions having constraints of the attributes of a node type
+func getConstrainedAttributesDefinitions(kv *api.KV, deploymentID, nodeType string) (map[string][]valueDefinition, error) {
+	attributeConstraintsCache.RLock()
+	defs, ok := attributeConstraintsCache.deployments[deploymentID][nodeType]
+	attributeConstraintsCache.RUnlock()
+	if ok {
+		return defs, nil
+	}
+
+	defs = make(map[string][]valueDefinition)
+	for _, isProperty := range []bool{false, true} {
+		var names []string
+		var err error
+		if isProperty {
+			names, err = GetTypeProperties(kv, deploymentID, nodeType, true)
+		} else {
+			names, err = GetTypeAttributes(kv, deploymentID, nodeType, true)
+		}
+		if err != nil {
+			return nil, err
+		}
+		seen := make(map[string]bool, len(names))
+		for _, name := range names {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			defPath, err := getTypeValueDefinitionPath(kv, deploymentID, nodeType, name, isProperty)
+			if err != nil {
+				return nil, err
+			}
+			if defPath == "" {
+				continue
+			}
+			def, err := readValueDefinition(kv, defPath)
+			if err != nil {
+				return nil, err
+			}
+			if def.hasConstraints() {
+				defs[name] = append(defs[name], def)
+			}
+		}
+	}
+
+	attributeConstraintsCache.Lock()
+	defer attributeConstraintsCache.Unlock()
+	if attributeConstraintsCache.deployments[deploymentID] == nil {
+		attributeConstraintsCache.deployments[deploymentID] = make(map[string]map[string][]valueDefinition)
+	}
+	attributeConstraintsCache.deployments[deploymentID][nodeType] = defs
+	return defs, nil
+}
+
+// checkAttributeConstraints checks that a value of a node attribute satisfies the constraints of its definition.
+//
+// As TOSCA reflects properties as attributes, the constraints of a property with the same name are checked too.
+// Nothing is checked if the node definition doesn't exist anymore.
+func checkAttributeConstraints(kv *api.KV, deploymentID, nodeName, attributeName string, value interface{}) error {
+	kvp, _, err := kv.Get(path.Join(deploymentKVPrefix(deploymentID), "topology/nodes", nodeName, "type"), nil)
+	if err != nil {
+		return errors.Wrapf(err, "Can't get type for node %q", nodeName)
+	}
+	if kvp == nil || len(kvp.Value) == 0 {
+		return nil
+	}
+	constrainedAttributes, err := getConstrainedAttributesDefinitions(kv, deploymentID, string(kvp.Value))
+	if err != nil {
+		return err
+	}
+	defs := constrainedAttributes[attributeName]
+	if len(defs) == 0 {
+		return nil
+	}
+	normalized, err := normalizeConstrainedValue(value)
+	if err != nil {
+		return err
+	}
+	for _, def := range defs {
+		err = checkValueConstraints(kv, deploymentID, def, fmt.Sprintf("node %q attribute %q", nodeName, attributeName), parseConstrainedValueIfString(def, normalized))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/ystia/yorc/testutil"
+	"github.com/ystia/yorc/tosca"
+)
+
+func TestCheckConstraintClauses(t *testing.T) {
+	t.Parallel()
+	parseConstraints := func(t *testing.T, s string) []tosca.ConstraintClause {
+		var constraints []tosca.ConstraintClause
+		require.NoError(t, yaml.Unmarshal([]byte(s), &constraints))
+		return constraints
+	}
+	tests := []struct {
+		name        string
+		constraints string
+		value       interface{}
+		wantErr     string
+	}{
+		{"ScalarOK", "[ in_range: [1, 10] ]", "5", ""},
+		{"ScalarKO", "[ in_range: [1, 10] ]", "50", `prop: value "50" does not satisfy constraint "in_range: [1 10]"`},
+		{"ScalarUnitOK", "[ greater_or_equal: 512 MB ]", "1 GB", ""},
+		{"ListLengthOK", "[ min_length: 1, max_length: 2 ]", []interface{}{"a", "b"}, ""},
+		{"ListLengthKO", "[ max_length: 1 ]", []interface{}{"a", "b"}, `prop: value ["a","b"] does not satisfy constraint "max_length: 1"`},
+		{"MapLengthKO", "[ length: 2 ]", map[string]interface{}{"a": "b"}, `prop: value {"a":"b"} does not satisfy constraint "length: 2"`},
+		{"ListSkipScalarConstraints", "[ equal: 2 ]", []interface{}{"a", "b"}, ""},
+		{"PatternKO", "[ pattern: '[a-z]+' ]", "abc1", `prop: value "abc1" does not satisfy constraint "pattern: [a-z]+"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkConstraintClauses(parseConstraints(t, tt.constraints), "prop", tt.value)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tt.wantErr, err.Error())
+		})
+	}
+}
+
+func testConstraints(t *testing.T, kv *api.KV) {
+	t.Parallel()
+	deploymentID := testutil.BuildDeploymentID(t)
+	err := StoreDeploymentDefinition(context.Background(), kv, deploymentID, "testdata/constraints.yaml")
+	require.NoError(t, err)
+
+	err = SetInstanceAttributeComplex(deploymentID, "Web", "0", "status_codes", []int{200, 404})
+	require.NoError(t, err)
+	err = SetInstanceAttributeComplex(deploymentID, "Web", "0", "status_codes", []int{200, 700})
+	require.Error(t, err)
+	assert.Equal(t, `node "Web" attribute "status_codes[1]": value "700" does not satisfy constraint "in_range: [100 599]"`, err.Error())
+
+	// Properties are reflected as attributes
+	err = SetInstanceAttribute(deploymentID, "Web", "0", "name", "WEB")
+	require.Error(t, err)
+	err = SetAttributeComplexForAllInstances(kv, deploymentID, "Web", "tags", []string{"front", "backend"})
+	require.Error(t, err)
+	assert.Equal(t, `node "Web" attribute "tags[1]": value "backend" does not satisfy constraint "max_length: 5"`, err.Error())
+	err = SetInstanceAttributeComplex(deploymentID, "Web", "0", "ports", map[string]interface{}{"https": map[string]interface{}{"target": 443}})
+	require.NoError(t, err)
+
+	// Constrained definitions are cached by node type and nothing is checked for nodes that don't exist anymore
+	nodeType, err := GetNodeType(kv, deploymentID, "Web")
+	require.NoError(t, err)
+	attributeConstraintsCache.RLock()
+	defs, cached := attributeConstraintsCache.deployments[deploymentID][nodeType]
+	attributeConstraintsCache.RUnlock()
+	require.True(t, cached)
+	assert.Len(t, defs["status_codes"], 1)
+	assert.NotContains(t, defs, "tosca_name")
+	err = SetInstanceAttribute(deploymentID, "Removed", "0", "name", "WEB")
+	require.NoError(t, err)
+
+	ClearAttributeConstraintsCache(deploymentID)
+	attributeConstraintsCache.RLock()
+	_, cached = attributeConstraintsCache.deployments[deploymentID]
+	attributeConstraintsCache.RUnlock()
+	require.False(t, cached)
+}
+
+func testConstraintsInvalid(t *testing.T, kv *api.KV) {
+	t.Parallel()
+	deploymentID := testutil.BuildDeploymentID(t)
+	err := StoreDeploymentDefinition(context.Background(), kv, deploymentID, "testdata/constraints_invalid.yaml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `input "web_port": value "80" does not satisfy constraint "in_range: [1024 65535]"`)
+	assert.Contains(t, err.Error(), `node "Web" property "name": value "ws" does not satisfy constraint "min_length: 3"`)
+	assert.Contains(t, err.Error(), `node "Web" property "ports[http].target": value "70000" does not satisfy constraint "in_range: [1 65535]"`)
+	assert.Contains(t, err.Error(), `node "Web" property "mem_size": value "256 MB" does not satisfy constraint "greater_or_equal: 512 MB"`)
+
+	report, err := ValidateDeploymentDefinition(context.Background(), kv, "testdata/constraints_invalid.yaml")
+	require.NoError(t, err)
+	require.False(t, report.Valid)
+	lines := make(map[int]bool)
+	for _, msg := range report.Errors {
+		lines[msg.Line] = true
+	}
+	assert.Equal(t, map[int]bool{41: true, 51: true, 52: true, 53: true}, lines)
+}
@@ -75,5 +75,35 @@ func TestRunConsulDeploymentsPackageTests(t *testing.T) {
 		t.Run("testImportTopologyTemplate", func(t *testing.T) {
 			testImportTopologyTemplate(t, kv)
 		})
+		t.Run("testRollbackOnFailure", func(t *testing.T) {
+			testRollbackOnFailure(t, kv)
+		})
+		t.Run("testUpdateDeploymentDefinition", func(t *testing.T) {
+			testUpdateDeploymentDefinition(t, kv)
+		})
+		t.Run("testValidateDeploymentDefinition", func(t *testing.T) {
+			testValidateDeploymentDefinition(t, kv)
+		})
+		t.Run("testPolicies", func(t *testing.T) {
+			testPolicies(t, kv)
+		})
+		t.Run("testPoliciesInvalidTarget", func(t *testing.T) {
+			testPoliciesInvalidTarget(t, kv)
+		})
+		t.Run("testGroups", func(t *testing.T) {
+			testGroups(t, kv)
+		})
+		t.Run("testGroupsInvalidMember", func(t *testing.T) {
+			testGroupsInvalidMember(t, kv)
+		})
+		t.Run("testConstraints", func(t *testing.T) {
+			testConstraints(t, kv)
+		})
+		t.Run("testConstraintsInvalid", func(t *testing.T) {
+			testConstraintsInvalid(t, kv)
+		})
+		t.Run("testSubstitutions", func(t *testing.T) {
+			testSubstitutions(t, kv)
+		})
 	})
 }
@@ -47,7 +47,7 @@ func getTypePropertyOrAttributeDataType(kv *api.KV, deploymentID, typeName, prop
 	if !isProp {
 		tType = "attributes"
 	}
-	propertyDefinitionPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/types", typeName, tType, propertyName)
+	propertyDefinitionPath := path.Join(deploymentKVPrefix(deploymentID), "topology/types", typeName, tType, propertyName)
 	kvp, _, err := kv.Get(path.Join(propertyDefinitionPath, "type"), nil)
 	if err != nil {
 		return "", errors.Wrap(err, consulutil.ConsulGenericErrMsg)
@@ -119,7 +119,7 @@ func GetTopologyOutputType(kv *api.KV, deploymentID, outputName string) (string,
 }
 
 func getTopologyInputOrOutputType(kv *api.KV, deploymentID, parameterName, parameterType string) (string, error) {
-	kvp, _, err := kv.Get(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", parameterType, parameterName, "type"), nil)
+	kvp, _, err := kv.Get(path.Join(deploymentKVPrefix(deploymentID), "topology", parameterType, parameterName, "type"), nil)
 	if err != nil {
 		return "", errors.Wrap(err, consulutil.ConsulGenericErrMsg)
 	}
@@ -128,7 +128,7 @@ func getTopologyInputOrOutputType(kv *api.KV, deploymentID, parameterName, param
 	}
 	iType := string(kvp.Value)
 	if iType == "list" || iType == "map" {
-		kvp, _, err := kv.Get(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", parameterType, parameterName, "entry_schema"), nil)
+		kvp, _, err := kv.Get(path.Join(deploymentKVPrefix(deploymentID), "topology", parameterType, parameterName, "entry_schema"), nil)
 		if err != nil {
 			return "", errors.Wrap(err, consulutil.ConsulGenericErrMsg)
 		}
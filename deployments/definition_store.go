@@ -55,43 +55,76 @@ var reg = registry.GetRegistry()
 // StoreDeploymentDefinition takes a defPath and parse it as a tosca.Topology then it store it in consul under
 // consulutil.DeploymentKVPrefix/deploymentID
 func StoreDeploymentDefinition(ctx context.Context, kv *api.KV, deploymentID string, defPath string) error {
-	topology := tosca.Topology{}
-	definition, err := os.Open(defPath)
+	defer ClearAttributeConstraintsCache(deploymentID)
+	topology, err := readTopologyDefinition(defPath)
 	if err != nil {
-		return errors.Wrapf(err, "Failed to open definition file %q", defPath)
+		return err
 	}
-	defBytes, err := ioutil.ReadAll(definition)
+
+	err = storeDeployment(ctx, topology, deploymentID, filepath.Dir(defPath), true)
 	if err != nil {
-		return errors.Wrapf(err, "Failed to open definition file %q", defPath)
+		return errors.Wrapf(err, "Failed to store TOSCA Definition for deployment with id %q, (file path %q)", deploymentID, defPath)
 	}
-
-	err = yaml.Unmarshal(defBytes, &topology)
+	err = registerImplementationTypes(ctx, kv, deploymentID)
+	if err != nil {
+		return err
+	}
+	err = checkGroupsMembers(kv, deploymentID)
 	if err != nil {
-		return errors.Wrapf(err, "Failed to unmarshal yaml definition for file %q", defPath)
+		return errors.Wrapf(err, "Failed to store TOSCA Definition for deployment with id %q, (file path %q)", deploymentID, defPath)
 	}
-
-	err = storeDeployment(ctx, topology, deploymentID, filepath.Dir(defPath))
+	err = checkPoliciesTargets(kv, deploymentID)
 	if err != nil {
 		return errors.Wrapf(err, "Failed to store TOSCA Definition for deployment with id %q, (file path %q)", deploymentID, defPath)
 	}
-	err = registerImplementationTypes(ctx, kv, deploymentID)
+	err = checkTopologyConstraints(kv, deploymentID)
 	if err != nil {
-		return err
+		return errors.Wrapf(err, "Failed to store TOSCA Definition for deployment with id %q, (file path %q)", deploymentID, defPath)
 	}
 
 	return enhanceNodes(ctx, kv, deploymentID)
 }
 
+// readTopologyDefinition parses the given defPath as a tosca.Topology
+func readTopologyDefinition(defPath string) (tosca.Topology, error) {
+	topology := tosca.Topology{}
+	definition, err := os.Open(defPath)
+	if err != nil {
+		return topology, errors.Wrapf(err, "Failed to open definition file %q", defPath)
+	}
+	defer definition.Close()
+	defBytes, err := ioutil.ReadAll(definition)
+	if err != nil {
+		return topology, errors.Wrapf(err, "Failed to open definition file %q", defPath)
+	}
+
+	err = yaml.Unmarshal(defBytes, &topology)
+	if err != nil {
+		return topology, errors.Wrapf(err, "Failed to unmarshal yaml definition for file %q", defPath)
+	}
+	return topology, nil
+}
+
 // storeDeployment stores a whole deployment.
-func storeDeployment(ctx context.Context, topology tosca.Topology, deploymentID, rootDefPath string) error {
+//
+// Node templates substituted by imported topologies are expanded before being stored.
+// The deployment status is reset to INITIAL only if resetStatus is true.
+func storeDeployment(ctx context.Context, topology tosca.Topology, deploymentID, rootDefPath string, resetStatus bool) error {
+	substitutions, err := expandSubstitutions(&topology, rootDefPath)
+	if err != nil {
+		return err
+	}
 	errCtx, errGroup, consulStore := consulutil.WithContext(ctx)
 	errCtx = context.WithValue(errCtx, errGrpKey, errGroup)
 	errCtx = context.WithValue(errCtx, consulStoreKey, consulStore)
-	consulStore.StoreConsulKeyAsString(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "status"), fmt.Sprint(INITIAL))
+	if resetStatus {
+		consulStore.StoreConsulKeyAsString(path.Join(deploymentKVPrefix(deploymentID), "status"), fmt.Sprint(INITIAL))
+	}
 
 	errGroup.Go(func() error {
-		return storeTopology(errCtx, topology, deploymentID, path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology"), "", "", rootDefPath)
+		return storeTopology(errCtx, topology, deploymentID, path.Join(deploymentKVPrefix(deploymentID), "topology"), "", "", rootDefPath)
 	})
+	storeSubstitutions(errCtx, substitutions, path.Join(deploymentKVPrefix(deploymentID), "topology"))
 
 	return errGroup.Wait()
 }
@@ -121,6 +154,12 @@ func storeTopology(ctx context.Context, topology tosca.Topology, deploymentID, t
 		storeInputs(ctx, topology, topologyPrefix)
 		storeOutputs(ctx, topology, topologyPrefix)
 		storeNodes(ctx, topology, topologyPrefix, importPath, rootDefPath)
+		if err := storeGroups(ctx, topology, topologyPrefix); err != nil {
+			return err
+		}
+		if err := storePolicies(ctx, topology, topologyPrefix); err != nil {
+			return err
+		}
 	}
 
 	if err := storeTypes(ctx, topology, topologyPrefix, importPath); err != nil {
@@ -131,6 +170,8 @@ func storeTopology(ctx context.Context, topology tosca.Topology, deploymentID, t
 	}
 	storeCapabilityTypes(ctx, topology, topologyPrefix)
 	storeArtifactTypes(ctx, topology, topologyPrefix)
+	storePolicyTypes(ctx, topology, topologyPrefix)
+	storeGroupTypes(ctx, topology, topologyPrefix)
 
 	// Detect potential cycles in inline workflows
 	if err := checkNestedWorkflows(topology); err != nil {
@@ -138,7 +179,7 @@ func storeTopology(ctx context.Context, topology tosca.Topology, deploymentID, t
 	}
 
 	if isRootTopologyTemplate {
-		storeWorkflows(ctx, topology, deploymentID)
+		return storeWorkflows(ctx, topology, deploymentID)
 	}
 	return nil
 }
@@ -192,6 +233,7 @@ func storeDataTypes(ctx context.Context, topology tosca.Topology, topologyPrefix
 		dtPrefix := path.Join(dataTypesPrefix, dataTypeName)
 		storeCommonType(consulStore, dataType.Type, dtPrefix)
 		consulStore.StoreConsulKeyAsString(path.Join(dtPrefix, "name"), dataTypeName)
+		storeConstraints(consulStore, path.Join(dtPrefix, "constraints"), dataType.Constraints)
 		for propName, propDefinition := range dataType.Properties {
 			storePropertyDefinition(ctx, path.Join(dtPrefix, "properties", propName), propName, propDefinition)
 		}
@@ -204,48 +246,59 @@ func storeDataTypes(ctx context.Context, topology tosca.Topology, topologyPrefix
 func storeImports(ctx context.Context, topology tosca.Topology, deploymentID, topologyPrefix, importPath, rootDefPath string) error {
 	errGroup := ctx.Value(errGrpKey).(*errgroup.Group)
 	for i, element := range topology.Imports {
+		importedTopology, importedPath, isInternal, err := readImportedTopology(element, importPath, rootDefPath)
+		if err != nil {
+			return err
+		}
+		importPrefix := path.Join("imports", importPath, strconv.Itoa(i))
+		if isInternal {
+			importPrefix = path.Join("imports", strconv.Itoa(i))
+		}
+		errGroup.Go(func() error {
+			return storeTopology(ctx, importedTopology, deploymentID, topologyPrefix, importPrefix, importedPath, rootDefPath)
+		})
+	}
+	return nil
+}
 
-		importURI := strings.Trim(element.File, " \t")
-		importPrefix := strconv.Itoa(i)
-		importedTopology := tosca.Topology{}
-
-		if strings.HasPrefix(importURI, "<") && strings.HasSuffix(importURI, ">") {
-			// Internal import
-			importURI = strings.Trim(importURI, "<>")
-			var defBytes []byte
-			var err error
-			if defBytes, err = reg.GetToscaDefinition(importURI); err != nil {
-				return errors.Errorf("Failed to import internal definition %s: %v", importURI, err)
-			}
-			if err = yaml.Unmarshal(defBytes, &importedTopology); err != nil {
-				return errors.Errorf("Failed to parse internal definition %s: %v", importURI, err)
-			}
-			errGroup.Go(func() error {
-				return storeTopology(ctx, importedTopology, deploymentID, topologyPrefix, path.Join("imports", importPrefix), "", rootDefPath)
-			})
-		} else {
-			uploadFile := filepath.Join(rootDefPath, filepath.FromSlash(importPath), filepath.FromSlash(importURI))
-
-			definition, err := os.Open(uploadFile)
-			if err != nil {
-				return errors.Errorf("Failed to parse internal definition %s: %v", importURI, err)
-			}
+// readImportedTopology parses the topology imported by the given import definition.
+//
+// It also returns the import path of the imported topology (empty for internal imports) and
+// whether or not it is an internal definition.
+func readImportedTopology(element tosca.ImportDefinition, importPath, rootDefPath string) (tosca.Topology, string, bool, error) {
+	importURI := strings.Trim(element.File, " \t")
+	importedTopology := tosca.Topology{}
+
+	if strings.HasPrefix(importURI, "<") && strings.HasSuffix(importURI, ">") {
+		// Internal import
+		importURI = strings.Trim(importURI, "<>")
+		var defBytes []byte
+		var err error
+		if defBytes, err = reg.GetToscaDefinition(importURI); err != nil {
+			return importedTopology, "", true, errors.Errorf("Failed to import internal definition %s: %v", importURI, err)
+		}
+		if err = yaml.Unmarshal(defBytes, &importedTopology); err != nil {
+			return importedTopology, "", true, errors.Errorf("Failed to parse internal definition %s: %v", importURI, err)
+		}
+		return importedTopology, "", true, nil
+	}
+	uploadFile := filepath.Join(rootDefPath, filepath.FromSlash(importPath), filepath.FromSlash(importURI))
 
-			defBytes, err := ioutil.ReadAll(definition)
-			if err != nil {
-				return errors.Errorf("Failed to parse internal definition %s: %v", importURI, err)
-			}
+	definition, err := os.Open(uploadFile)
+	if err != nil {
+		return importedTopology, "", false, errors.Errorf("Failed to parse internal definition %s: %v", importURI, err)
+	}
+	defer definition.Close()
 
-			if err = yaml.Unmarshal(defBytes, &importedTopology); err != nil {
-				return errors.Errorf("Failed to parse internal definition %s: %v", importURI, err)
-			}
+	defBytes, err := ioutil.ReadAll(definition)
+	if err != nil {
+		return importedTopology, "", false, errors.Errorf("Failed to parse internal definition %s: %v", importURI, err)
+	}
 
-			errGroup.Go(func() error {
-				return storeTopology(ctx, importedTopology, deploymentID, topologyPrefix, path.Join("imports", importPath, importPrefix), path.Dir(path.Join(importPath, importURI)), rootDefPath)
-			})
-		}
+	if err = yaml.Unmarshal(defBytes, &importedTopology); err != nil {
+		return importedTopology, "", false, errors.Errorf("Failed to parse internal definition %s: %v", importURI, err)
 	}
-	return nil
+	return importedTopology, path.Dir(path.Join(importPath, importURI)), false, nil
 }
 
 // storeOutputs stores topology outputs
@@ -288,6 +341,8 @@ func storeInputs(ctx context.Context, topology tosca.Topology, topologyPrefix st
 		consulStore.StoreConsulKeyAsString(path.Join(inputPrefix, "status"), input.Status)
 		consulStore.StoreConsulKeyAsString(path.Join(inputPrefix, "type"), input.Type)
 		consulStore.StoreConsulKeyAsString(path.Join(inputPrefix, "entry_schema"), input.EntrySchema.Type)
+		storeConstraints(consulStore, path.Join(inputPrefix, "constraints"), input.Constraints)
+		storeConstraints(consulStore, path.Join(inputPrefix, "entry_schema_constraints"), input.EntrySchema.Constraints)
 		storeValueAssignment(consulStore, path.Join(inputPrefix, "value"), input.Value)
 	}
 }
@@ -366,6 +421,8 @@ func storePropertyDefinition(ctx context.Context, propPrefix, propName string, p
 	consulStore.StoreConsulKeyAsString(propPrefix+"/description", propDefinition.Description)
 	consulStore.StoreConsulKeyAsString(propPrefix+"/type", propDefinition.Type)
 	consulStore.StoreConsulKeyAsString(propPrefix+"/entry_schema", propDefinition.EntrySchema.Type)
+	storeConstraints(consulStore, propPrefix+"/constraints", propDefinition.Constraints)
+	storeConstraints(consulStore, propPrefix+"/entry_schema_constraints", propDefinition.EntrySchema.Constraints)
 	storeValueAssignment(consulStore, propPrefix+"/default", propDefinition.Default)
 	if propDefinition.Required == nil {
 		// Required by default
@@ -382,10 +439,25 @@ func storeAttributeDefinition(ctx context.Context, attrPrefix, attrName string,
 	consulStore.StoreConsulKeyAsString(attrPrefix+"/description", attrDefinition.Description)
 	consulStore.StoreConsulKeyAsString(attrPrefix+"/type", attrDefinition.Type)
 	consulStore.StoreConsulKeyAsString(attrPrefix+"/entry_schema", attrDefinition.EntrySchema.Type)
+	storeConstraints(consulStore, attrPrefix+"/entry_schema_constraints", attrDefinition.EntrySchema.Constraints)
 	storeValueAssignment(consulStore, attrPrefix+"/default", attrDefinition.Default)
 	consulStore.StoreConsulKeyAsString(attrPrefix+"/status", attrDefinition.Status)
 }
 
+// storeConstraints stores a list of constraint clauses as a yaml document
+func storeConstraints(consulStore consulutil.ConsulStore, constraintsPath string, constraints []tosca.ConstraintClause) {
+	if len(constraints) == 0 {
+		return
+	}
+	c, err := yaml.Marshal(constraints)
+	if err != nil {
+		// Constraints are built from a yaml document so this should never happen
+		log.Printf("Warning: failed to store constraints %v under %q: %v", constraints, constraintsPath, err)
+		return
+	}
+	consulStore.StoreConsulKeyAsString(constraintsPath, string(c))
+}
+
 func storeComplexType(consulStore consulutil.ConsulStore, valuePath string, value interface{}) {
 	v := reflect.ValueOf(value)
 	switch v.Kind() {
@@ -558,6 +630,9 @@ func storeTypes(ctx context.Context, topology tosca.Topology, topologyPrefix, im
 					}
 					consulStore.StoreConsulKeyAsString(operationPrefix+"/implementation/operation_host", strings.ToUpper(operationDef.Implementation.OperationHost))
 				}
+				if err := storeOperationTimeout(consulStore, operationPrefix, operationDef.Implementation.Timeout); err != nil {
+					return err
+				}
 			}
 		}
 
@@ -676,6 +751,9 @@ func storeRelationshipTypes(ctx context.Context, topology tosca.Topology, topolo
 					}
 					consulStore.StoreConsulKeyAsString(operationPrefix+"/implementation/operation_host", strings.ToUpper(operationDef.Implementation.OperationHost))
 				}
+				if err := storeOperationTimeout(consulStore, operationPrefix, operationDef.Implementation.Timeout); err != nil {
+					return err
+				}
 			}
 		}
 
@@ -735,13 +813,112 @@ func storeArtifactTypes(ctx context.Context, topology tosca.Topology, topologyPr
 	}
 }
 
+// storePolicyTypes stores topology policy types
+func storePolicyTypes(ctx context.Context, topology tosca.Topology, topologyPrefix string) {
+	consulStore := ctx.Value(consulStoreKey).(consulutil.ConsulStore)
+	for policyTypeName, policyType := range topology.PolicyTypes {
+		policyTypePrefix := path.Join(topologyPrefix, "types", policyTypeName)
+		storeCommonType(consulStore, policyType.Type, policyTypePrefix)
+		consulStore.StoreConsulKeyAsString(policyTypePrefix+"/name", policyTypeName)
+		propertiesPrefix := policyTypePrefix + "/properties"
+		for propName, propDefinition := range policyType.Properties {
+			propPrefix := propertiesPrefix + "/" + propName
+			storePropertyDefinition(ctx, propPrefix, propName, propDefinition)
+		}
+		consulStore.StoreConsulKeyAsString(policyTypePrefix+"/targets", strings.Join(policyType.Targets, ","))
+	}
+}
+
+// storePolicies stores topology policies
+func storePolicies(ctx context.Context, topology tosca.Topology, topologyPrefix string) error {
+	consulStore := ctx.Value(consulStoreKey).(consulutil.ConsulStore)
+	policiesPrefix := path.Join(topologyPrefix, "policies")
+	for policyName, policy := range topology.TopologyTemplate.Policies {
+		if policy.Type == "" {
+			return errors.Errorf("Missing mandatory type for policy %q", policyName)
+		}
+		for _, target := range policy.Targets {
+			_, isNode := topology.TopologyTemplate.NodeTemplates[target]
+			_, isGroup := topology.TopologyTemplate.Groups[target]
+			if !isNode && !isGroup {
+				return errors.Errorf("Unknown target %q for policy %q: targets should be node templates or groups of the topology", target, policyName)
+			}
+		}
+		policyPrefix := path.Join(policiesPrefix, policyName)
+		consulStore.StoreConsulKeyAsString(policyPrefix+"/name", policyName)
+		consulStore.StoreConsulKeyAsString(policyPrefix+"/type", policy.Type)
+		consulStore.StoreConsulKeyAsString(policyPrefix+"/description", policy.Description)
+		for metaName, metaValue := range policy.Metadata {
+			consulStore.StoreConsulKeyAsString(path.Join(policyPrefix, "metadata", metaName), metaValue)
+		}
+		propertiesPrefix := policyPrefix + "/properties"
+		for propName, propValue := range policy.Properties {
+			storeValueAssignment(consulStore, propertiesPrefix+"/"+url.QueryEscape(propName), propValue)
+		}
+		consulStore.StoreConsulKeyAsString(policyPrefix+"/targets", strings.Join(policy.Targets, ","))
+	}
+	return nil
+}
+
+// storeGroupTypes stores topology group types
+func storeGroupTypes(ctx context.Context, topology tosca.Topology, topologyPrefix string) {
+	consulStore := ctx.Value(consulStoreKey).(consulutil.ConsulStore)
+	for groupTypeName, groupType := range topology.GroupTypes {
+		groupTypePrefix := path.Join(topologyPrefix, "types", groupTypeName)
+		storeCommonType(consulStore, groupType.Type, groupTypePrefix)
+		consulStore.StoreConsulKeyAsString(groupTypePrefix+"/name", groupTypeName)
+		propertiesPrefix := groupTypePrefix + "/properties"
+		for propName, propDefinition := range groupType.Properties {
+			propPrefix := propertiesPrefix + "/" + propName
+			storePropertyDefinition(ctx, propPrefix, propName, propDefinition)
+		}
+		consulStore.StoreConsulKeyAsString(groupTypePrefix+"/members", strings.Join(groupType.Members, ","))
+	}
+}
+
+// storeGroups stores topology groups
+func storeGroups(ctx context.Context, topology tosca.Topology, topologyPrefix string) error {
+	consulStore := ctx.Value(consulStoreKey).(consulutil.ConsulStore)
+	groupsPrefix := path.Join(topologyPrefix, "groups")
+	for groupName, group := range topology.TopologyTemplate.Groups {
+		if group.Type == "" {
+			return errors.Errorf("Missing mandatory type for group %q", groupName)
+		}
+		if _, ok := topology.TopologyTemplate.NodeTemplates[groupName]; ok {
+			return errors.Errorf("Group %q has the same name than a node template of the topology", groupName)
+		}
+		for _, member := range group.Members {
+			if _, ok := topology.TopologyTemplate.NodeTemplates[member]; !ok {
+				return errors.Errorf("Unknown member %q for group %q: members should be node templates of the topology", member, groupName)
+			}
+		}
+		groupPrefix := path.Join(groupsPrefix, groupName)
+		consulStore.StoreConsulKeyAsString(groupPrefix+"/name", groupName)
+		consulStore.StoreConsulKeyAsString(groupPrefix+"/type", group.Type)
+		consulStore.StoreConsulKeyAsString(groupPrefix+"/description", group.Description)
+		for metaName, metaValue := range group.Metadata {
+			consulStore.StoreConsulKeyAsString(path.Join(groupPrefix, "metadata", metaName), metaValue)
+		}
+		propertiesPrefix := groupPrefix + "/properties"
+		for propName, propValue := range group.Properties {
+			storeValueAssignment(consulStore, propertiesPrefix+"/"+url.QueryEscape(propName), propValue)
+		}
+		consulStore.StoreConsulKeyAsString(groupPrefix+"/members", strings.Join(group.Members, ","))
+	}
+	return nil
+}
+
 // storeWorkflows stores topology workflows
-func storeWorkflows(ctx context.Context, topology tosca.Topology, deploymentID string) {
+func storeWorkflows(ctx context.Context, topology tosca.Topology, deploymentID string) error {
 	consulStore := ctx.Value(consulStoreKey).(consulutil.ConsulStore)
-	workflowsPrefix := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "workflows")
+	workflowsPrefix := path.Join(deploymentKVPrefix(deploymentID), "workflows")
 	for wfName, workflow := range topology.TopologyTemplate.Workflows {
 		workflowPrefix := workflowsPrefix + "/" + url.QueryEscape(wfName)
-		for stepName, step := range workflow.Steps {
+		steps, err := expandGroupsSteps(wfName, workflow.Steps, topology.TopologyTemplate.Groups)
+		if err != nil {
+			return err
+		}
+		for stepName, step := range steps {
 			stepPrefix := workflowPrefix + "/steps/" + url.QueryEscape(stepName)
 			if step.Target != "" {
 				consulStore.StoreConsulKeyAsString(stepPrefix+"/target", step.Target)
@@ -752,6 +929,16 @@ func storeWorkflows(ctx context.Context, topology tosca.Topology, deploymentID s
 			if step.OperationHost != "" {
 				consulStore.StoreConsulKeyAsString(stepPrefix+"/operation_host", strings.ToUpper(step.OperationHost))
 			}
+			if step.Retry != nil {
+				storeWorkflowStepRetry(consulStore, stepPrefix+"/retry", step.Retry)
+			}
+			if len(step.Filter) > 0 {
+				filter, err := yaml.Marshal(step.Filter)
+				if err != nil {
+					return errors.Wrapf(err, "failed to store filter of step %q in workflow %q", stepName, wfName)
+				}
+				consulStore.StoreConsulKeyAsString(stepPrefix+"/filter", string(filter))
+			}
 			activitiesPrefix := stepPrefix + "/activities"
 			for actIndex, activity := range step.Activities {
 				activityPrefix := activitiesPrefix + "/" + strconv.Itoa(actIndex)
@@ -775,8 +962,32 @@ func storeWorkflows(ctx context.Context, topology tosca.Topology, deploymentID s
 				// store in consul a prefix for the next step to be executed ; this prefix is stepPrefix/next/onSuccess_value
 				consulStore.StoreConsulKeyAsString(fmt.Sprintf("%s/next/%s", stepPrefix, url.QueryEscape(next)), "")
 			}
+			for _, onFailure := range step.OnFailure {
+				// store in consul a prefix for the step to be executed on failure ; this prefix is stepPrefix/on_failure/onFailure_value
+				consulStore.StoreConsulKeyAsString(fmt.Sprintf("%s/on_failure/%s", stepPrefix, url.QueryEscape(onFailure)), "")
+			}
 		}
 	}
+	return nil
+}
+
+// storeWorkflowStepRetry stores the retry policy of a workflow step
+func storeWorkflowStepRetry(consulStore consulutil.ConsulStore, retryPrefix string, retry *tosca.Retry) {
+	if retry.MaxAttempts != 0 {
+		consulStore.StoreConsulKeyAsString(retryPrefix+"/max_attempts", strconv.Itoa(retry.MaxAttempts))
+	}
+	if retry.Delay != "" {
+		consulStore.StoreConsulKeyAsString(retryPrefix+"/delay", retry.Delay)
+	}
+	if retry.MaxDelay != "" {
+		consulStore.StoreConsulKeyAsString(retryPrefix+"/max_delay", retry.MaxDelay)
+	}
+	if retry.Backoff != "" {
+		consulStore.StoreConsulKeyAsString(retryPrefix+"/backoff", strings.ToLower(retry.Backoff))
+	}
+	for i, retryableError := range retry.RetryableErrors {
+		consulStore.StoreConsulKeyAsString(path.Join(retryPrefix, "retryable_errors", strconv.Itoa(i)), retryableError)
+	}
 }
 
 // checkNestedWorkflows detect potential cycle in all nested workflows
@@ -815,13 +1026,19 @@ func createInstancesForNode(ctx context.Context, kv *api.KV, deploymentID, nodeN
 	if err != nil {
 		return err
 	}
-	createNodeInstances(consulStore, kv, nbInstances, deploymentID, nodeName)
+	err = createNodeInstances(consulStore, kv, nbInstances, deploymentID, nodeName)
+	if err != nil {
+		return err
+	}
 	ip, networkNodeName, err := checkFloattingIP(kv, deploymentID, nodeName)
 	if err != nil {
 		return err
 	}
 	if ip {
-		createNodeInstances(consulStore, kv, nbInstances, deploymentID, networkNodeName)
+		err = createNodeInstances(consulStore, kv, nbInstances, deploymentID, networkNodeName)
+		if err != nil {
+			return err
+		}
 	}
 
 	bs, bsNames, err := checkBlockStorage(kv, deploymentID, nodeName)
@@ -831,7 +1048,10 @@ func createInstancesForNode(ctx context.Context, kv *api.KV, deploymentID, nodeN
 
 	if bs {
 		for _, name := range bsNames {
-			createNodeInstances(consulStore, kv, nbInstances, deploymentID, name)
+			err = createNodeInstances(consulStore, kv, nbInstances, deploymentID, name)
+			if err != nil {
+				return err
+			}
 		}
 
 	}
@@ -868,7 +1088,7 @@ func registerImplementationTypes(ctx context.Context, kv *api.KV, deploymentID s
 				if check != "" {
 					return errors.Errorf("Duplicate implementation artifact file extension %q found in artifact %q and %q", ext, check, t)
 				}
-				extPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", implementationArtifactsExtensionsPath, ext)
+				extPath := path.Join(deploymentKVPrefix(deploymentID), "topology", implementationArtifactsExtensionsPath, ext)
 				_, err = kv.Put(&api.KVPair{Key: extPath, Value: []byte(t)}, nil)
 				if err != nil {
 					return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
@@ -941,7 +1161,7 @@ func enhanceNodes(ctx context.Context, kv *api.KV, deploymentID string) error {
 func fixGetOperationOutputForHost(ctx context.Context, kv *api.KV, deploymentID, nodeName string) error {
 	nodeType, err := GetNodeType(kv, deploymentID, nodeName)
 	if nodeType != "" && err == nil {
-		interfacesPrefix := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "types", nodeType, "interfaces")
+		interfacesPrefix := path.Join(deploymentKVPrefix(deploymentID), "topology", "types", nodeType, "interfaces")
 		interfacesNamesPaths, _, err := kv.Keys(interfacesPrefix+"/", "/", nil)
 		if err != nil {
 			return err
@@ -968,7 +1188,7 @@ func fixGetOperationOutputForHost(ctx context.Context, kv *api.KV, deploymentID,
 						return errors.New("Fail to get the hostedOn to fix the output")
 					}
 					if hostedNodeType, err := GetNodeType(kv, deploymentID, hostedOn); hostedNodeType != "" && err == nil {
-						consulutil.StoreConsulKeyAsString(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "types", hostedNodeType, "interfaces", path.Base(interfaceNamePath), path.Base(operationPath), "outputs", "SELF", path.Base(outputNamePath), "expression"), "get_operation_output: [SELF,"+path.Base(interfaceNamePath)+","+path.Base(operationPath)+","+path.Base(outputNamePath)+"]")
+						consulutil.StoreConsulKeyAsString(path.Join(deploymentKVPrefix(deploymentID), "topology", "types", hostedNodeType, "interfaces", path.Base(interfaceNamePath), path.Base(operationPath), "outputs", "SELF", path.Base(outputNamePath), "expression"), "get_operation_output: [SELF,"+path.Base(interfaceNamePath)+","+path.Base(operationPath)+","+path.Base(outputNamePath)+"]")
 					}
 				}
 			}
@@ -984,7 +1204,7 @@ func fixGetOperationOutputForHost(ctx context.Context, kv *api.KV, deploymentID,
 // This function help us to fix the get_operation_output when it on a relationship, to tell to the SOURCE or TARGET to store the exported value in consul
 // Ex: To get an variable from a past operation or a future operation
 func fixGetOperationOutputForRelationship(ctx context.Context, kv *api.KV, deploymentID, nodeName string) error {
-	reqPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "nodes", nodeName, "requirements")
+	reqPath := path.Join(deploymentKVPrefix(deploymentID), "topology", "nodes", nodeName, "requirements")
 	reqName, _, err := kv.Keys(reqPath+"/", "/", nil)
 	if err != nil {
 		return err
@@ -994,7 +1214,7 @@ func fixGetOperationOutputForRelationship(ctx context.Context, kv *api.KV, deplo
 		if err != nil {
 			return err
 		}
-		relationshipPrefix := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "types", relationshipType, "interfaces")
+		relationshipPrefix := path.Join(deploymentKVPrefix(deploymentID), "topology", "types", relationshipType, "interfaces")
 		interfaceNamesPaths, _, err := kv.Keys(relationshipPrefix+"/", "/", nil)
 		if err != nil {
 			return err
@@ -1028,7 +1248,7 @@ func fixGetOperationOutputForRelationship(ctx context.Context, kv *api.KV, deplo
 							}
 							nodeType, _ = GetNodeType(kv, deploymentID, targetNode)
 						}
-						consulutil.StoreConsulKeyAsString(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "types", nodeType, "interfaces", path.Base(interfaceNamePath), path.Base(operationNamePath), "outputs", "SELF", path.Base(outputNamePath), "expression"), "get_operation_output: [SELF,"+path.Base(interfaceNamePath)+","+path.Base(operationNamePath)+","+path.Base(outputNamePath)+"]")
+						consulutil.StoreConsulKeyAsString(path.Join(deploymentKVPrefix(deploymentID), "topology", "types", nodeType, "interfaces", path.Base(interfaceNamePath), path.Base(operationNamePath), "outputs", "SELF", path.Base(outputNamePath), "expression"), "get_operation_output: [SELF,"+path.Base(interfaceNamePath)+","+path.Base(operationNamePath)+","+path.Base(outputNamePath)+"]")
 					}
 				}
 			}
@@ -1099,7 +1319,7 @@ func fixAlienBlockStorages(ctx context.Context, kv *api.KV, deploymentID, nodeNa
 			ctxStore, errgroup, consulStore := consulutil.WithContext(ctx)
 			ctxStore = context.WithValue(ctxStore, consulStoreKey, consulStore)
 
-			storeRequirementAssignment(ctxStore, req, path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/nodes", computeNodeName, "requirements", fmt.Sprint(newReqID)), "local_storage")
+			storeRequirementAssignment(ctxStore, req, path.Join(deploymentKVPrefix(deploymentID), "topology/nodes", computeNodeName, "requirements", fmt.Sprint(newReqID)), "local_storage")
 
 			err = errgroup.Wait()
 			if err != nil {
@@ -1115,9 +1335,19 @@ func fixAlienBlockStorages(ctx context.Context, kv *api.KV, deploymentID, nodeNa
 /**
 This function create a given number of floating IP instances
 */
-func createNodeInstances(consulStore consulutil.ConsulStore, kv *api.KV, numberInstances uint32, deploymentID, nodeName string) {
+func createNodeInstances(consulStore consulutil.ConsulStore, kv *api.KV, numberInstances uint32, deploymentID, nodeName string) error {
 
-	nodePath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "nodes", nodeName)
+	nodePath := path.Join(deploymentKVPrefix(deploymentID), "topology", "nodes", nodeName)
+
+	// When a deployment is updated instances of nodes that already exist are kept as is
+	existingInstances, err := GetNodeInstancesIds(kv, deploymentID, nodeName)
+	if err != nil {
+		return err
+	}
+	if len(existingInstances) > 0 {
+		consulStore.StoreConsulKeyAsString(path.Join(nodePath, "nbInstances"), strconv.Itoa(len(existingInstances)))
+		return nil
+	}
 
 	consulStore.StoreConsulKeyAsString(path.Join(nodePath, "nbInstances"), strconv.FormatUint(uint64(numberInstances), 10))
 
@@ -1125,6 +1355,7 @@ func createNodeInstances(consulStore consulutil.ConsulStore, kv *api.KV, numberI
 		instanceName := strconv.FormatUint(uint64(i), 10)
 		createNodeInstance(kv, consulStore, deploymentID, nodeName, instanceName)
 	}
+	return nil
 }
 
 /**
@@ -1194,7 +1425,10 @@ func createMissingBlockStorageForNode(consulStore consulutil.ConsulStore, kv *ap
 	}
 
 	for _, name := range bsName {
-		createNodeInstances(consulStore, kv, nbInstances, deploymentID, name)
+		err = createNodeInstances(consulStore, kv, nbInstances, deploymentID, name)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -1231,6 +1465,17 @@ func checkBlockStorage(kv *api.KV, deploymentID, nodeName string) (bool, []strin
 	return true, bsName, nil
 }
 
+// storeOperationTimeout stores the timeout of an operation implementation if any
+func storeOperationTimeout(consulStore consulutil.ConsulStore, operationPrefix string, timeout int) error {
+	if timeout < 0 {
+		return errors.Errorf("Invalid negative timeout %d for operation %q", timeout, path.Base(operationPrefix))
+	}
+	if timeout > 0 {
+		consulStore.StoreConsulKeyAsString(operationPrefix+"/implementation/timeout", strconv.Itoa(timeout))
+	}
+	return nil
+}
+
 func checkOperationHost(operationHost string, isRelationshipType bool) error {
 	operationHostUpper := strings.ToUpper(operationHost)
 	if isRelationshipType {
@@ -17,6 +17,7 @@ package deployments
 import (
 	"fmt"
 	"path"
+	"strconv"
 	"strings"
 
 	"github.com/hashicorp/consul/api"
@@ -24,6 +25,16 @@ import (
 	"github.com/ystia/yorc/helper/consulutil"
 )
 
+// deploymentKVPrefix returns the prefix in Consul KV store of a given deployment
+//
+// Throwaway deployments used to validate definitions are stored under consulutil.ValidationsKVPrefix.
+func deploymentKVPrefix(deploymentID string) string {
+	if IsValidationDeploymentID(deploymentID) {
+		return path.Join(consulutil.ValidationsKVPrefix, deploymentID)
+	}
+	return path.Join(consulutil.DeploymentKVPrefix, deploymentID)
+}
+
 type deploymentNotFound struct {
 	deploymentID string
 }
@@ -67,7 +78,7 @@ func DeploymentStatusFromString(status string, ignoreCase bool) (DeploymentStatu
 //  	}
 //  }
 func GetDeploymentStatus(kv *api.KV, deploymentID string) (DeploymentStatus, error) {
-	kvp, _, err := kv.Get(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "status"), nil)
+	kvp, _, err := kv.Get(path.Join(deploymentKVPrefix(deploymentID), "status"), nil)
 	if err != nil {
 		return INITIAL, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
 	}
@@ -79,7 +90,7 @@ func GetDeploymentStatus(kv *api.KV, deploymentID string) (DeploymentStatus, err
 
 //GetDeploymentTemplateName only return the name of the template used during the deployment
 func GetDeploymentTemplateName(kv *api.KV, deploymentID string) (string, error) {
-	kvp, _, err := kv.Get(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "name"), nil)
+	kvp, _, err := kv.Get(path.Join(deploymentKVPrefix(deploymentID), "topology", "name"), nil)
 	if err != nil {
 		return "", errors.Wrap(err, consulutil.ConsulGenericErrMsg)
 	}
@@ -99,3 +110,26 @@ func DoesDeploymentExists(kv *api.KV, deploymentID string) (bool, error) {
 	}
 	return true, nil
 }
+
+// SetRollbackOnFailure sets if resources created by the install workflow of a given deployment
+// should be removed if this workflow fails
+func SetRollbackOnFailure(kv *api.KV, deploymentID string, rollbackOnFailure bool) error {
+	_, err := kv.Put(&api.KVPair{Key: path.Join(deploymentKVPrefix(deploymentID), "rollback_on_failure"), Value: []byte(strconv.FormatBool(rollbackOnFailure))}, nil)
+	return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+}
+
+// IsRollbackOnFailureEnabled checks if resources created by the install workflow of a given deployment
+// should be removed if this workflow fails
+//
+// Rollback is disabled by default.
+func IsRollbackOnFailureEnabled(kv *api.KV, deploymentID string) (bool, error) {
+	kvp, _, err := kv.Get(path.Join(deploymentKVPrefix(deploymentID), "rollback_on_failure"), nil)
+	if err != nil {
+		return false, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp == nil || len(kvp.Value) == 0 {
+		return false, nil
+	}
+	rollbackOnFailure, err := strconv.ParseBool(string(kvp.Value))
+	return rollbackOnFailure, errors.Wrapf(err, "invalid rollback on failure value for deployment %q", deploymentID)
+}
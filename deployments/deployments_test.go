@@ -15,11 +15,23 @@
 package deployments
 
 import (
+	"path"
+	"strings"
 	"testing"
 
+	"github.com/hashicorp/consul/api"
 	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/helper/consulutil"
 )
 
+func TestDeploymentKVPrefix(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, path.Join(consulutil.DeploymentKVPrefix, "myDeployment"), deploymentKVPrefix("myDeployment"))
+	throwawayID := newThrowawayDeploymentID()
+	require.Equal(t, path.Join(consulutil.ValidationsKVPrefix, throwawayID), deploymentKVPrefix(throwawayID))
+}
+
 func TestDeploymentStatusFromString(t *testing.T) {
 	t.Parallel()
 	status, err := DeploymentStatusFromString("initial", true)
@@ -55,6 +67,10 @@ func TestDeploymentStatusFromString(t *testing.T) {
 	require.Nil(t, err)
 	require.Equal(t, UNDEPLOYMENT_FAILED, status)
 
+	status, err = DeploymentStatusFromString("rolled_back", true)
+	require.Nil(t, err)
+	require.Equal(t, ROLLED_BACK, status)
+
 	_, err = DeploymentStatusFromString("startOfDepStatusConst", false)
 	require.NotNil(t, err)
 
@@ -65,3 +81,27 @@ func TestDeploymentStatusFromString(t *testing.T) {
 	require.NotNil(t, err)
 
 }
+
+func testRollbackOnFailure(t *testing.T, kv *api.KV) {
+	t.Parallel()
+	deploymentID := strings.Replace(t.Name(), "/", "_", -1)
+
+	rollback, err := IsRollbackOnFailureEnabled(kv, deploymentID)
+	require.NoError(t, err)
+	require.False(t, rollback, "rollback on failure should be disabled by default")
+
+	require.NoError(t, SetRollbackOnFailure(kv, deploymentID, true))
+	rollback, err = IsRollbackOnFailureEnabled(kv, deploymentID)
+	require.NoError(t, err)
+	require.True(t, rollback)
+
+	require.NoError(t, SetRollbackOnFailure(kv, deploymentID, false))
+	rollback, err = IsRollbackOnFailureEnabled(kv, deploymentID)
+	require.NoError(t, err)
+	require.False(t, rollback)
+
+	_, err = kv.Put(&api.KVPair{Key: path.Join(consulutil.DeploymentKVPrefix, deploymentID, "rollback_on_failure"), Value: []byte("maybe")}, nil)
+	require.NoError(t, err)
+	_, err = IsRollbackOnFailureEnabled(kv, deploymentID)
+	require.Error(t, err)
+}
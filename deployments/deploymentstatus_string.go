@@ -18,9 +18,9 @@ package deployments
 
 import "strconv"
 
-const _DeploymentStatus_name = "startOfDepStatusConstINITIALDEPLOYMENT_IN_PROGRESSDEPLOYEDUNDEPLOYMENT_IN_PROGRESSUNDEPLOYEDDEPLOYMENT_FAILEDUNDEPLOYMENT_FAILEDSCALING_IN_PROGRESSendOfDepStatusConst"
+const _DeploymentStatus_name = "startOfDepStatusConstINITIALDEPLOYMENT_IN_PROGRESSDEPLOYEDUNDEPLOYMENT_IN_PROGRESSUNDEPLOYEDDEPLOYMENT_FAILEDUNDEPLOYMENT_FAILEDSCALING_IN_PROGRESSROLLED_BACKUPDATE_IN_PROGRESSUPDATE_FAILEDROLLBACK_FAILEDendOfDepStatusConst"
 
-var _DeploymentStatus_index = [...]uint8{0, 21, 28, 50, 58, 82, 92, 109, 128, 147, 166}
+var _DeploymentStatus_index = [...]uint8{0, 21, 28, 50, 58, 82, 92, 109, 128, 147, 158, 176, 189, 204, 223}
 
 func (i DeploymentStatus) String() string {
 	if i < 0 || i >= DeploymentStatus(len(_DeploymentStatus_index)-1) {
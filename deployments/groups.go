@@ -0,0 +1,124 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"strings"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/helper/collections"
+)
+
+// GetGroups returns the names of the groups defined in the topology of a given deployment
+func GetGroups(kv *api.KV, deploymentID string) ([]string, error) {
+	return groupsKind.getTemplates(kv, deploymentID)
+}
+
+// DoesGroupExist checks if a given group exists in the topology of a given deployment
+func DoesGroupExist(kv *api.KV, deploymentID, groupName string) (bool, error) {
+	return groupsKind.doesTemplateExist(kv, deploymentID, groupName)
+}
+
+// GetGroupType returns the type of a given group
+func GetGroupType(kv *api.KV, deploymentID, groupName string) (string, error) {
+	return groupsKind.getTemplateType(kv, deploymentID, groupName)
+}
+
+// GetGroupDescription returns the description of a given group
+func GetGroupDescription(kv *api.KV, deploymentID, groupName string) (string, error) {
+	return groupsKind.getTemplateDescription(kv, deploymentID, groupName)
+}
+
+// GetGroupMembers returns the names of the nodes members of a given group
+func GetGroupMembers(kv *api.KV, deploymentID, groupName string) ([]string, error) {
+	return groupsKind.getTemplateNodes(kv, deploymentID, groupName)
+}
+
+// GetGroupProperty retrieves the value for a given property in a given group
+//
+// It returns true if a value is found false otherwise as first return parameter.
+// If the property is not found in the group then the type hierarchy is explored to find a default value.
+func GetGroupProperty(kv *api.KV, deploymentID, groupName, propertyName string, nestedKeys ...string) (bool, string, error) {
+	return groupsKind.getTemplateProperty(kv, deploymentID, groupName, propertyName, nestedKeys...)
+}
+
+// GetGroupsForNode returns the names of the groups a given node is member of
+func GetGroupsForNode(kv *api.KV, deploymentID, nodeName string) ([]string, error) {
+	groups, err := GetGroups(kv, deploymentID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0)
+	for _, group := range groups {
+		members, err := GetGroupMembers(kv, deploymentID, group)
+		if err != nil {
+			return nil, err
+		}
+		if collections.ContainsString(members, nodeName) {
+			result = append(result, group)
+		}
+	}
+	return result, nil
+}
+
+// getGroupTypeMembers returns the node types allowed as members of a given group type.
+//
+// If members are not defined in the given type then its type hierarchy is explored.
+// An empty result means that there is no restriction on members.
+func getGroupTypeMembers(kv *api.KV, deploymentID, groupTypeName string) ([]string, error) {
+	return groupsKind.getTypeAllowedNodesTypes(kv, deploymentID, groupTypeName)
+}
+
+// checkGroupsMembers checks that groups types exist and that the members of each group
+// are allowed by its group type
+func checkGroupsMembers(kv *api.KV, deploymentID string) error {
+	groups, err := GetGroups(kv, deploymentID)
+	if err != nil {
+		return err
+	}
+	for _, group := range groups {
+		groupType, err := GetGroupType(kv, deploymentID, group)
+		if err != nil {
+			return err
+		}
+		allowedTypes, err := getGroupTypeMembers(kv, deploymentID, groupType)
+		if err != nil {
+			return errors.Wrapf(err, "Invalid type for group %q", group)
+		}
+		if len(allowedTypes) == 0 {
+			continue
+		}
+		members, err := GetGroupMembers(kv, deploymentID, group)
+		if err != nil {
+			return err
+		}
+	membersLoop:
+		for _, member := range members {
+			for _, allowedType := range allowedTypes {
+				derived, err := IsNodeDerivedFrom(kv, deploymentID, member, strings.TrimSpace(allowedType))
+				if err != nil {
+					return err
+				}
+				if derived {
+					continue membersLoop
+				}
+			}
+			return errors.Errorf("Node %q can't be a member of group %q: group type %q only allows members of types %v", member, group, groupType, allowedTypes)
+		}
+	}
+	return nil
+}
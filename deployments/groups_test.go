@@ -0,0 +1,93 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/testutil"
+)
+
+func testGroups(t *testing.T, kv *api.KV) {
+	t.Parallel()
+	deploymentID := testutil.BuildDeploymentID(t)
+	err := StoreDeploymentDefinition(context.Background(), kv, deploymentID, "testdata/groups.yaml")
+	require.NoError(t, err)
+
+	groups, err := GetGroups(kv, deploymentID)
+	require.NoError(t, err)
+	sort.Strings(groups)
+	assert.Equal(t, []string{"others", "replicas"}, groups)
+
+	exist, err := DoesGroupExist(kv, deploymentID, "replicas")
+	require.NoError(t, err)
+	assert.True(t, exist)
+	exist, err = DoesGroupExist(kv, deploymentID, "Compute1")
+	require.NoError(t, err)
+	assert.False(t, exist)
+
+	groupType, err := GetGroupType(kv, deploymentID, "replicas")
+	require.NoError(t, err)
+	assert.Equal(t, "yorc.tests.groups.Replicas", groupType)
+
+	description, err := GetGroupDescription(kv, deploymentID, "replicas")
+	require.NoError(t, err)
+	assert.Equal(t, "All database hosts", description)
+
+	members, err := GetGroupMembers(kv, deploymentID, "replicas")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Compute1", "Compute2"}, members)
+
+	found, value, err := GetGroupProperty(kv, deploymentID, "replicas", "quorum")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "2", value)
+
+	groups, err = GetGroupsForNode(kv, deploymentID, "Compute2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"replicas"}, groups)
+
+	policies, err := GetPoliciesForNode(kv, deploymentID, "Compute1", "tosca.policies.Placement")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"spread"}, policies)
+	policies, err = GetPoliciesForNode(kv, deploymentID, "Compute3", "")
+	require.NoError(t, err)
+	assert.Len(t, policies, 0)
+
+	wf, err := ReadWorkflow(kv, deploymentID, "install")
+	require.NoError(t, err)
+	require.Len(t, wf.Steps, 3)
+	require.Contains(t, wf.Steps, "replicas_install_Compute1")
+	require.Contains(t, wf.Steps, "replicas_install_Compute2")
+	assert.Equal(t, "Compute1", wf.Steps["replicas_install_Compute1"].Target)
+	assert.Equal(t, "Compute2", wf.Steps["replicas_install_Compute2"].Target)
+	next := wf.Steps["Compute3_install"].OnSuccess
+	sort.Strings(next)
+	assert.Equal(t, []string{"replicas_install_Compute1", "replicas_install_Compute2"}, next)
+}
+
+func testGroupsInvalidMember(t *testing.T, kv *api.KV) {
+	t.Parallel()
+	deploymentID := testutil.BuildDeploymentID(t)
+	err := StoreDeploymentDefinition(context.Background(), kv, deploymentID, "testdata/groups_invalid_member.yaml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `Node "Soft" can't be a member of group "replicas"`)
+}
@@ -19,7 +19,6 @@ import (
 
 	"github.com/hashicorp/consul/api"
 	"github.com/pkg/errors"
-	"github.com/ystia/yorc/helper/consulutil"
 )
 
 // GetInputValue tries to retrieve the value of the given input name.
@@ -29,11 +28,11 @@ import (
 func GetInputValue(kv *api.KV, deploymentID, inputName string, nestedKeys ...string) (string, error) {
 	dataType, err := GetTopologyInputType(kv, deploymentID, inputName)
 
-	found, result, err := getValueAssignmentWithDataType(kv, deploymentID, path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/inputs", inputName, "value"), "", "", "", dataType, nestedKeys...)
+	found, result, err := getValueAssignmentWithDataType(kv, deploymentID, path.Join(deploymentKVPrefix(deploymentID), "topology/inputs", inputName, "value"), "", "", "", dataType, nestedKeys...)
 	if err != nil || found {
 		return result, errors.Wrapf(err, "Failed to get input %q value", inputName)
 	}
-	_, result, err = getValueAssignmentWithDataType(kv, deploymentID, path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/inputs", inputName, "default"), "", "", "", dataType, nestedKeys...)
+	_, result, err = getValueAssignmentWithDataType(kv, deploymentID, path.Join(deploymentKVPrefix(deploymentID), "topology/inputs", inputName, "default"), "", "", "", dataType, nestedKeys...)
 
 	return result, errors.Wrapf(err, "Failed to get input %q value", inputName)
 }
@@ -27,7 +27,7 @@ import (
 
 // SetInstanceStateString stores the state of a given node instance and publishes a status change event
 func SetInstanceStateString(kv *api.KV, deploymentID, nodeName, instanceName, state string) error {
-	_, err := kv.Put(&api.KVPair{Key: path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/instances", nodeName, instanceName, "attributes/state"), Value: []byte(state)}, nil)
+	_, err := kv.Put(&api.KVPair{Key: path.Join(deploymentKVPrefix(deploymentID), "topology/instances", nodeName, instanceName, "attributes/state"), Value: []byte(state)}, nil)
 	if err != nil {
 		return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
 	}
@@ -42,7 +42,7 @@ func SetInstanceState(kv *api.KV, deploymentID, nodeName, instanceName string, s
 
 // GetInstanceState retrieves the state of a given node instance
 func GetInstanceState(kv *api.KV, deploymentID, nodeName, instanceName string) (tosca.NodeState, error) {
-	kvp, _, err := kv.Get(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/instances", nodeName, instanceName, "attributes/state"), nil)
+	kvp, _, err := kv.Get(path.Join(deploymentKVPrefix(deploymentID), "topology/instances", nodeName, instanceName, "attributes/state"), nil)
 	if err != nil {
 		return tosca.NodeStateError, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
 	}
@@ -58,7 +58,7 @@ func GetInstanceState(kv *api.KV, deploymentID, nodeName, instanceName string) (
 
 // DeleteInstance deletes the given instance of the given node from the Consul store
 func DeleteInstance(kv *api.KV, deploymentID, nodeName, instanceName string) error {
-	_, err := kv.DeleteTree(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/instances", nodeName, instanceName), nil)
+	_, err := kv.DeleteTree(path.Join(deploymentKVPrefix(deploymentID), "topology/instances", nodeName, instanceName), nil)
 	return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
 }
 
@@ -68,7 +68,17 @@ func DeleteInstance(kv *api.KV, deploymentID, nodeName, instanceName string) err
 // If the attribute is not found in the node then the type hierarchy is explored to find a default value.
 // If the attribute is still not found then it will explore the HostedOn hierarchy.
 // If still not found then it will check node properties as the spec states "TOSCA orchestrators will automatically reflect (i.e., make available) any property defined on an entity making it available as an attribute of the entity with the same name as the property."
+// Attributes mapped by a substituted node are retrieved on the node instance they are mapped to.
 func GetInstanceAttribute(kv *api.KV, deploymentID, nodeName, instanceName, attributeName string, nestedKeys ...string) (bool, string, error) {
+	// Attributes of substituted nodes are mapped to attributes of the nodes in which they are expanded
+	isMapped, targetNode, targetInstance, targetAttribute, err := getSubstitutionAttributeInstance(kv, deploymentID, nodeName, instanceName, attributeName)
+	if err != nil {
+		return false, "", err
+	}
+	if isMapped {
+		return GetInstanceAttribute(kv, deploymentID, targetNode, targetInstance, targetAttribute, nestedKeys...)
+	}
+
 	nodeType, err := GetNodeType(kv, deploymentID, nodeName)
 	if err != nil {
 		return false, "", err
@@ -87,7 +97,7 @@ func GetInstanceAttribute(kv *api.KV, deploymentID, nodeName, instanceName, attr
 	}
 
 	// First look at instance-scoped attributes
-	found, result, err := getValueAssignmentWithDataType(kv, deploymentID, path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/instances", nodeName, instanceName, "attributes", attributeName), nodeName, instanceName, "", attrDataType, nestedKeys...)
+	found, result, err := getValueAssignmentWithDataType(kv, deploymentID, path.Join(deploymentKVPrefix(deploymentID), "topology/instances", nodeName, instanceName, "attributes", attributeName), nodeName, instanceName, "", attrDataType, nestedKeys...)
 	if err != nil {
 		return false, "", errors.Wrapf(err, "Failed to get attribute %q for node %q (instance %q)", attributeName, nodeName, instanceName)
 	}
@@ -96,7 +106,7 @@ func GetInstanceAttribute(kv *api.KV, deploymentID, nodeName, instanceName, attr
 	}
 
 	// Then look at global node level (not instance-scoped)
-	found, result, err = getValueAssignmentWithDataType(kv, deploymentID, path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/nodes", nodeName, "attributes", attributeName), nodeName, instanceName, "", attrDataType, nestedKeys...)
+	found, result, err = getValueAssignmentWithDataType(kv, deploymentID, path.Join(deploymentKVPrefix(deploymentID), "topology/nodes", nodeName, "attributes", attributeName), nodeName, instanceName, "", attrDataType, nestedKeys...)
 	if err != nil {
 		return false, "", errors.Wrapf(err, "Failed to get attribute %q for node %q", attributeName, nodeName, instanceName)
 	}
@@ -136,14 +146,21 @@ func GetInstanceAttribute(kv *api.KV, deploymentID, nodeName, instanceName, attr
 }
 
 // SetInstanceAttribute sets an instance attribute
+//
+// An error is returned if the value doesn't satisfy the constraints of the attribute definition.
 func SetInstanceAttribute(deploymentID, nodeName, instanceName, attributeName, attributeValue string) error {
 	return SetInstanceAttributeComplex(deploymentID, nodeName, instanceName, attributeName, attributeValue)
 
 }
 
 // SetInstanceAttributeComplex sets an instance attribute that may be a literal or a complex data type
+//
+// An error is returned if the value doesn't satisfy the constraints of the attribute definition.
 func SetInstanceAttributeComplex(deploymentID, nodeName, instanceName, attributeName string, attributeValue interface{}) error {
-	attrPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/instances", nodeName, instanceName, "attributes", attributeName)
+	if err := checkAttributeConstraints(consulutil.GetKV(), deploymentID, nodeName, attributeName, attributeValue); err != nil {
+		return err
+	}
+	attrPath := path.Join(deploymentKVPrefix(deploymentID), "topology/instances", nodeName, instanceName, "attributes", attributeName)
 	_, errGrp, store := consulutil.WithContext(context.Background())
 	storeComplexType(store, attrPath, attributeValue)
 	return errGrp.Wait()
@@ -162,13 +179,16 @@ func SetAttributeForAllInstances(kv *api.KV, deploymentID, nodeName, attributeNa
 // It does the same thing than iterating over instances ids and calling SetInstanceAttributeComplex but use
 // a consulutil.ConsulStore to do it in parallel. We can expect better performances with a large number of instances
 func SetAttributeComplexForAllInstances(kv *api.KV, deploymentID, nodeName, attributeName string, attributeValue interface{}) error {
+	if err := checkAttributeConstraints(kv, deploymentID, nodeName, attributeName, attributeValue); err != nil {
+		return err
+	}
 	ids, err := GetNodeInstancesIds(kv, deploymentID, nodeName)
 	if err != nil {
 		return err
 	}
 	_, errGrp, store := consulutil.WithContext(context.Background())
 	for _, instanceName := range ids {
-		storeComplexType(store, path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/instances", nodeName, instanceName, "attributes", attributeName), attributeValue)
+		storeComplexType(store, path.Join(deploymentKVPrefix(deploymentID), "topology/instances", nodeName, instanceName, "attributes", attributeName), attributeValue)
 	}
 	return errGrp.Wait()
 }
@@ -122,7 +122,7 @@ func getScalablePropertyForNode(kv *api.KV, deploymentID, nodeName, propertyName
 
 // GetNbInstancesForNode retrieves the number of instances for a given node nodeName in deployment deploymentID.
 func GetNbInstancesForNode(kv *api.KV, deploymentID, nodeName string) (uint32, error) {
-	instancesPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "instances", nodeName)
+	instancesPath := path.Join(deploymentKVPrefix(deploymentID), "topology", "instances", nodeName)
 	keys, _, err := kv.Keys(instancesPath+"/", "/", nil)
 	if err != nil {
 		return 0, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
@@ -135,7 +135,7 @@ func GetNbInstancesForNode(kv *api.KV, deploymentID, nodeName string) (uint32, e
 // It may be an empty array if the given node is not HostedOn a scalable node.
 func GetNodeInstancesIds(kv *api.KV, deploymentID, nodeName string) ([]string, error) {
 	names := make([]string, 0)
-	instancesPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/instances", nodeName)
+	instancesPath := path.Join(deploymentKVPrefix(deploymentID), "topology/instances", nodeName)
 	instances, _, err := kv.Keys(instancesPath+"/", "/", nil)
 	if err != nil {
 		return names, errors.Wrap(err, "Consul communication error")
@@ -151,7 +151,7 @@ func GetNodeInstancesIds(kv *api.KV, deploymentID, nodeName string) ([]string, e
 //
 // If there is no HostedOn relationship for this node then it returns an empty string
 func GetHostedOnNode(kv *api.KV, deploymentID, nodeName string) (string, error) {
-	nodePath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "nodes", nodeName)
+	nodePath := path.Join(deploymentKVPrefix(deploymentID), "topology", "nodes", nodeName)
 	// So we have to traverse the hosted on relationships...
 	// Lets inspect the requirements to found hosted on relationships
 	reqKVPs, _, err := kv.Keys(path.Join(nodePath, "requirements")+"/", "/", nil)
@@ -242,7 +242,7 @@ func GetNodeProperty(kv *api.KV, deploymentID, nodeName, propertyName string, ne
 			return false, "", err
 		}
 	}
-	nodePath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "nodes", nodeName)
+	nodePath := path.Join(deploymentKVPrefix(deploymentID), "topology", "nodes", nodeName)
 
 	found, result, err := getValueAssignmentWithDataType(kv, deploymentID, path.Join(nodePath, "properties", propertyName), nodeName, "", "", propDataType, nestedKeys...)
 	if err != nil {
@@ -312,7 +312,7 @@ func GetNodeProperty(kv *api.KV, deploymentID, nodeName, propertyName string, ne
 // SetNodeProperty sets a node property
 func SetNodeProperty(kv *api.KV, deploymentID, nodeName, propertyName, propertyValue string) error {
 	kvp := &api.KVPair{
-		Key:   path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "nodes", nodeName, "properties", propertyName),
+		Key:   path.Join(deploymentKVPrefix(deploymentID), "topology", "nodes", nodeName, "properties", propertyName),
 		Value: []byte(propertyValue),
 	}
 	_, err := kv.Put(kvp, nil)
@@ -354,7 +354,7 @@ func SetNodeInstanceAttribute(kv *api.KV, deploymentID, nodeName, instanceName,
 // GetNodes returns the names of the different nodes for a given deployment.
 func GetNodes(kv *api.KV, deploymentID string) ([]string, error) {
 	names := make([]string, 0)
-	nodesPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/nodes")
+	nodesPath := path.Join(deploymentKVPrefix(deploymentID), "topology/nodes")
 	nodes, _, err := kv.Keys(nodesPath+"/", "/", nil)
 	if err != nil {
 		return names, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
@@ -367,7 +367,7 @@ func GetNodes(kv *api.KV, deploymentID string) ([]string, error) {
 
 // GetNodeType returns the type of a given node identified by its name
 func GetNodeType(kv *api.KV, deploymentID, nodeName string) (string, error) {
-	kvp, _, err := kv.Get(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/nodes", nodeName, "type"), nil)
+	kvp, _, err := kv.Get(path.Join(deploymentKVPrefix(deploymentID), "topology/nodes", nodeName, "type"), nil)
 	if err != nil {
 		return "", errors.Wrapf(err, "Can't get type for node %q", nodeName)
 	}
@@ -400,7 +400,7 @@ func GetNodeAttributesNames(kv *api.KV, deploymentID, nodeName string) ([]string
 	if err != nil {
 		return nil, err
 	}
-	nodeInstancesPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "instances", nodeName)
+	nodeInstancesPath := path.Join(deploymentKVPrefix(deploymentID), "topology", "instances", nodeName)
 	for _, instance := range instances {
 		err = storeSubKeysInSet(kv, path.Join(nodeInstancesPath, instance, "attributes"), attributesSet)
 		if err != nil {
@@ -409,11 +409,20 @@ func GetNodeAttributesNames(kv *api.KV, deploymentID, nodeName string) ([]string
 	}
 
 	// Look at not instance-scoped attribute
-	err = storeSubKeysInSet(kv, path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "nodes", nodeName, "attributes"), attributesSet)
+	err = storeSubKeysInSet(kv, path.Join(deploymentKVPrefix(deploymentID), "topology", "nodes", nodeName, "attributes"), attributesSet)
 	if err != nil {
 		return nil, err
 	}
 
+	// Look at attributes mapped by a substituted node
+	mappedAttrs, err := getSubstitutionAttributes(kv, deploymentID, nodeName)
+	if err != nil {
+		return nil, err
+	}
+	for _, attr := range mappedAttrs {
+		attributesSet[attr] = struct{}{}
+	}
+
 	attributesList := make([]string, len(attributesSet))
 	i := 0
 	for attr := range attributesSet {
@@ -443,7 +452,7 @@ func GetTypeAttributesNames(kv *api.KV, deploymentID, typeName string) ([]string
 			attributesSet[attr] = struct{}{}
 		}
 	}
-	err = storeSubKeysInSet(kv, path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "types", typeName, "attributes"), attributesSet)
+	err = storeSubKeysInSet(kv, path.Join(deploymentKVPrefix(deploymentID), "topology", "types", typeName, "attributes"), attributesSet)
 	if err != nil {
 		return nil, err
 	}
@@ -622,7 +631,7 @@ func CreateNewNodeStackInstances(kv *api.KV, deploymentID, nodeName string, inst
 // createNodeInstance creates required elements for a new node
 func createNodeInstance(kv *api.KV, consulStore consulutil.ConsulStore, deploymentID, nodeName, instanceName string) {
 
-	instancePath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "instances", nodeName)
+	instancePath := path.Join(deploymentKVPrefix(deploymentID), "topology", "instances", nodeName)
 
 	consulStore.StoreConsulKeyAsString(path.Join(instancePath, instanceName, "attributes/state"), tosca.NodeStateInitial.String())
 	consulStore.StoreConsulKeyAsString(path.Join(instancePath, instanceName, "attributes/tosca_name"), nodeName)
@@ -633,7 +642,7 @@ func createNodeInstance(kv *api.KV, consulStore consulutil.ConsulStore, deployme
 
 // DoesNodeExist checks if a given node exist in a deployment
 func DoesNodeExist(kv *api.KV, deploymentID, nodeName string) (bool, error) {
-	kvp, _, err := kv.Get(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/nodes", nodeName, "name"), nil)
+	kvp, _, err := kv.Get(path.Join(deploymentKVPrefix(deploymentID), "topology/nodes", nodeName, "name"), nil)
 	if err != nil {
 		return false, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
 	}
@@ -20,6 +20,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	yaml "gopkg.in/yaml.v2"
 
@@ -121,8 +122,8 @@ func getOperationAndInterfacePath(deploymentID, nodeType, operationName string)
 		op = strings.TrimPrefix(op, "tosca.interfaces.relationship.")
 	}
 	opPaths := strings.Split(op, ".")
-	operationPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/types", nodeType, "interfaces", path.Join(opPaths...))
-	interfacePath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/types", nodeType, "interfaces", opPaths[0])
+	operationPath := path.Join(deploymentKVPrefix(deploymentID), "topology/types", nodeType, "interfaces", path.Join(opPaths...))
+	interfacePath := path.Join(deploymentKVPrefix(deploymentID), "topology/types", nodeType, "interfaces", opPaths[0])
 
 	return operationPath, interfacePath
 
@@ -246,10 +247,29 @@ func GetOperationImplementationRepository(kv *api.KV, deploymentID, nodeType, op
 	return string(kvp.Value), nil
 }
 
+// GetOperationImplementationTimeout returns the timeout defined in the implementation of an operation of a given type
+//
+// 0 is returned if no timeout is defined.
+func GetOperationImplementationTimeout(kv *api.KV, deploymentID, typeName, operationName string) (time.Duration, error) {
+	operationPath := getOperationPath(deploymentID, typeName, operationName)
+	kvp, _, err := kv.Get(path.Join(operationPath, "implementation/timeout"), nil)
+	if err != nil {
+		return 0, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp == nil || len(kvp.Value) == 0 {
+		return 0, nil
+	}
+	timeout, err := strconv.Atoi(string(kvp.Value))
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid timeout %q for operation %q of type %q", string(kvp.Value), operationName, typeName)
+	}
+	return time.Duration(timeout) * time.Second, nil
+}
+
 // GetOperationOutputForNode return a map with in index the instance number and in value the result of the output
 // The "params" parameter is necessary to pass the path of the output
 func GetOperationOutputForNode(kv *api.KV, deploymentID, nodeName, instanceName, interfaceName, operationName, outputName string) (string, error) {
-	instancesPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/instances", nodeName)
+	instancesPath := path.Join(deploymentKVPrefix(deploymentID), "topology/instances", nodeName)
 
 	output, _, err := kv.Get(filepath.Join(instancesPath, instanceName, "outputs", strings.ToLower(interfaceName), strings.ToLower(operationName), outputName), nil)
 	if err != nil {
@@ -274,7 +294,7 @@ func GetOperationOutputForNode(kv *api.KV, deploymentID, nodeName, instanceName,
 // GetOperationOutputForRelationship retrieves an operation output for a relationship
 // The returned value may be empty if the operation output could not be retrieved
 func GetOperationOutputForRelationship(kv *api.KV, deploymentID, nodeName, instanceName, requirementIndex, interfaceName, operationName, outputName string) (string, error) {
-	result, _, err := kv.Get(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/relationship_instances", nodeName, requirementIndex, instanceName, "outputs", strings.ToLower(path.Join(interfaceName, operationName)), outputName), nil)
+	result, _, err := kv.Get(path.Join(deploymentKVPrefix(deploymentID), "topology/relationship_instances", nodeName, requirementIndex, instanceName, "outputs", strings.ToLower(path.Join(interfaceName, operationName)), outputName), nil)
 	if err != nil {
 		return "", err
 	}
@@ -304,7 +324,7 @@ func getOperationOutputForRequirements(kv *api.KV, deploymentID, nodeName, insta
 // If the extension is unknown then an empty string is returned
 func GetImplementationArtifactForExtension(kv *api.KV, deploymentID, extension string) (string, error) {
 	extension = strings.ToLower(extension)
-	kvp, _, err := kv.Get(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", implementationArtifactsExtensionsPath, extension), nil)
+	kvp, _, err := kv.Get(path.Join(deploymentKVPrefix(deploymentID), "topology", implementationArtifactsExtensionsPath, extension), nil)
 	if err != nil {
 		return "", errors.Wrap(err, consulutil.ConsulGenericErrMsg)
 	}
@@ -396,10 +416,14 @@ func getParentOperation(kv *api.KV, deploymentID string, operation prov.Operatio
 // An OperationInputResult represents a result of retrieving an operation input
 //
 // As in case of attributes it may have different values based on the instance name this struct contains the necessary information to identify the result context
+//
+// IsArtifact is true if the value is the path of an artifact relative to the deployment root returned by a get_artifact function,
+// executors should replace it by the path of the artifact on the host where the operation runs.
 type OperationInputResult struct {
 	NodeName     string
 	InstanceName string
 	Value        string
+	IsArtifact   bool
 }
 
 // GetOperationInput retrieves the value of an input for a given operation
@@ -439,7 +463,7 @@ func GetOperationInput(kv *api.KV, deploymentID, nodeName string, operation prov
 				return nil, err
 			}
 			for _, ins := range instances {
-				results = append(results, OperationInputResult{ctxNodeName, ins, res})
+				results = append(results, OperationInputResult{NodeName: ctxNodeName, InstanceName: ins, Value: res})
 			}
 			return results, nil
 		}
@@ -479,7 +503,7 @@ func GetOperationInput(kv *api.KV, deploymentID, nodeName string, operation prov
 			if err != nil {
 				return nil, err
 			}
-			results = append(results, OperationInputResult{ctxNodeName, ins, res})
+			results = append(results, OperationInputResult{NodeName: ctxNodeName, InstanceName: ins, Value: res, IsArtifact: f.Operator == tosca.GetArtifactOperator})
 		}
 		return results, nil
 
@@ -535,7 +559,7 @@ func GetOperationInputPropertyDefinitionDefault(kv *api.KV, deploymentID, nodeNa
 			return nil, err
 		}
 		for _, ins := range instances {
-			results = append(results, OperationInputResult{nodeName, ins, res})
+			results = append(results, OperationInputResult{NodeName: nodeName, InstanceName: ins, Value: res})
 		}
 		return results, nil
 	}
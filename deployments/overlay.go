@@ -0,0 +1,123 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// overlayLeftoversFileName is the name of the file listing files of a previous overlay that are kept
+// in the overlay of a deployment until they are pruned.
+const overlayLeftoversFileName = "overlay-leftovers"
+
+// ReplaceOverlay replaces the overlay directory of a deployment by the newOverlay directory.
+//
+// Files of the previous overlay that are not part of the new one are kept in the overlay as nodes
+// removed by an update may still reference them. They are recorded so they can be removed by PruneOverlay
+// once those nodes are uninstalled.
+func ReplaceOverlay(workingDir, deploymentID, newOverlay string) error {
+	deploymentPath := filepath.Join(workingDir, "deployments", deploymentID)
+	overlayPath := filepath.Join(deploymentPath, "overlay")
+	previousOverlayPath := filepath.Join(deploymentPath, "overlay-previous")
+	if err := os.RemoveAll(previousOverlayPath); err != nil {
+		return errors.Wrapf(err, "failed to replace overlay of deployment %q", deploymentID)
+	}
+	if err := os.Rename(overlayPath, previousOverlayPath); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to replace overlay of deployment %q", deploymentID)
+	}
+	if err := os.Rename(newOverlay, overlayPath); err != nil {
+		return errors.Wrapf(err, "failed to replace overlay of deployment %q", deploymentID)
+	}
+
+	var leftovers []string
+	err := filepath.Walk(previousOverlayPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == previousOverlayPath {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel(previousOverlayPath, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(overlayPath, relPath)
+		if _, err = os.Lstat(target); err == nil || !os.IsNotExist(err) {
+			return err
+		}
+		if err = os.MkdirAll(filepath.Dir(target), 0775); err != nil {
+			return err
+		}
+		leftovers = append(leftovers, relPath)
+		return os.Rename(path, target)
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to keep files of the previous overlay of deployment %q", deploymentID)
+	}
+	if err = os.RemoveAll(previousOverlayPath); err != nil {
+		return errors.Wrapf(err, "failed to remove previous overlay of deployment %q", deploymentID)
+	}
+
+	leftoversFile := filepath.Join(deploymentPath, overlayLeftoversFileName)
+	if len(leftovers) == 0 {
+		err = os.Remove(leftoversFile)
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "failed to update overlay of deployment %q", deploymentID)
+		}
+		return nil
+	}
+	err = ioutil.WriteFile(leftoversFile, []byte(strings.Join(leftovers, "\n")+"\n"), 0664)
+	return errors.Wrapf(err, "failed to record files kept in the overlay of deployment %q", deploymentID)
+}
+
+// PruneOverlay removes from the overlay of a deployment the files kept by ReplaceOverlay
+func PruneOverlay(workingDir, deploymentID string) error {
+	deploymentPath := filepath.Join(workingDir, "deployments", deploymentID)
+	leftoversFile := filepath.Join(deploymentPath, overlayLeftoversFileName)
+	f, err := os.Open(leftoversFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to prune overlay of deployment %q", deploymentID)
+	}
+	defer f.Close()
+	overlayPath := filepath.Join(deploymentPath, "overlay")
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		relPath := strings.TrimSpace(scanner.Text())
+		if relPath == "" {
+			continue
+		}
+		err = os.Remove(filepath.Join(overlayPath, relPath))
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "failed to prune overlay of deployment %q", deploymentID)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return errors.Wrapf(err, "failed to prune overlay of deployment %q", deploymentID)
+	}
+	err = os.Remove(leftoversFile)
+	return errors.Wrapf(err, "failed to prune overlay of deployment %q", deploymentID)
+}
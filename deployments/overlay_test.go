@@ -0,0 +1,72 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeOverlayFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0775))
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0664))
+}
+
+func TestReplaceAndPruneOverlay(t *testing.T) {
+	workingDir, err := ioutil.TempDir("", "yorc-overlay")
+	require.NoError(t, err)
+	defer os.RemoveAll(workingDir)
+
+	overlayPath := filepath.Join(workingDir, "deployments", "d1", "overlay")
+	writeOverlayFile(t, filepath.Join(overlayPath, "topology.yaml"), "old")
+	writeOverlayFile(t, filepath.Join(overlayPath, "scripts", "kept.sh"), "old")
+	writeOverlayFile(t, filepath.Join(overlayPath, "scripts", "removed.sh"), "old")
+
+	newOverlay := filepath.Join(workingDir, "deployments", "d1", "update", "overlay")
+	writeOverlayFile(t, filepath.Join(newOverlay, "renamed.yaml"), "new")
+	writeOverlayFile(t, filepath.Join(newOverlay, "scripts", "kept.sh"), "new")
+
+	require.NoError(t, ReplaceOverlay(workingDir, "d1", newOverlay))
+
+	content, err := ioutil.ReadFile(filepath.Join(overlayPath, "scripts", "kept.sh"))
+	require.NoError(t, err)
+	require.Equal(t, "new", string(content))
+	_, err = os.Stat(filepath.Join(overlayPath, "renamed.yaml"))
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(overlayPath, "topology.yaml"))
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(overlayPath, "scripts", "removed.sh"))
+	require.NoError(t, err)
+	_, err = os.Stat(newOverlay)
+	require.True(t, os.IsNotExist(err))
+
+	require.NoError(t, PruneOverlay(workingDir, "d1"))
+	_, err = os.Stat(filepath.Join(overlayPath, "renamed.yaml"))
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(overlayPath, "scripts", "kept.sh"))
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(overlayPath, "topology.yaml"))
+	require.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(overlayPath, "scripts", "removed.sh"))
+	require.True(t, os.IsNotExist(err))
+
+	// Pruning twice is a no-op
+	require.NoError(t, PruneOverlay(workingDir, "d1"))
+}
@@ -0,0 +1,192 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"strings"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/helper/collections"
+)
+
+// GetPolicies returns the names of the policies defined in the topology of a given deployment
+func GetPolicies(kv *api.KV, deploymentID string) ([]string, error) {
+	return policiesKind.getTemplates(kv, deploymentID)
+}
+
+// DoesPolicyExist checks if a given policy exists in the topology of a given deployment
+func DoesPolicyExist(kv *api.KV, deploymentID, policyName string) (bool, error) {
+	return policiesKind.doesTemplateExist(kv, deploymentID, policyName)
+}
+
+// GetPolicyType returns the type of a given policy
+func GetPolicyType(kv *api.KV, deploymentID, policyName string) (string, error) {
+	return policiesKind.getTemplateType(kv, deploymentID, policyName)
+}
+
+// GetPolicyDescription returns the description of a given policy
+func GetPolicyDescription(kv *api.KV, deploymentID, policyName string) (string, error) {
+	return policiesKind.getTemplateDescription(kv, deploymentID, policyName)
+}
+
+// GetPolicyTargets returns the names of the nodes targeted by a given policy
+func GetPolicyTargets(kv *api.KV, deploymentID, policyName string) ([]string, error) {
+	return policiesKind.getTemplateNodes(kv, deploymentID, policyName)
+}
+
+// GetPolicyPropertiesNames returns the names of the properties defined for a given policy
+// either in the policy itself or in its type hierarchy
+func GetPolicyPropertiesNames(kv *api.KV, deploymentID, policyName string) ([]string, error) {
+	return policiesKind.getTemplatePropertiesNames(kv, deploymentID, policyName)
+}
+
+// GetPolicyProperty retrieves the value for a given property in a given policy
+//
+// It returns true if a value is found false otherwise as first return parameter.
+// If the property is not found in the policy then the type hierarchy is explored to find a default value.
+func GetPolicyProperty(kv *api.KV, deploymentID, policyName, propertyName string, nestedKeys ...string) (bool, string, error) {
+	return policiesKind.getTemplateProperty(kv, deploymentID, policyName, propertyName, nestedKeys...)
+}
+
+// GetPoliciesForType returns the names of the policies of a given deployment having a type
+// equal to or derived from the given policy type
+func GetPoliciesForType(kv *api.KV, deploymentID, policyTypeName string) ([]string, error) {
+	policies, err := GetPolicies(kv, deploymentID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0)
+	for _, policy := range policies {
+		policyType, err := GetPolicyType(kv, deploymentID, policy)
+		if err != nil {
+			return nil, err
+		}
+		derived, err := IsTypeDerivedFrom(kv, deploymentID, policyType, policyTypeName)
+		if err != nil {
+			return nil, err
+		}
+		if derived {
+			result = append(result, policy)
+		}
+	}
+	return result, nil
+}
+
+// GetPoliciesForNode returns the names of the policies targeting a given node either directly
+// or through a group the node is member of.
+//
+// If policyTypeName is not empty only policies having a type equal to or derived from it are returned.
+// Policies without targets are not returned.
+func GetPoliciesForNode(kv *api.KV, deploymentID, nodeName, policyTypeName string) ([]string, error) {
+	var policies []string
+	var err error
+	if policyTypeName != "" {
+		policies, err = GetPoliciesForType(kv, deploymentID, policyTypeName)
+	} else {
+		policies, err = GetPolicies(kv, deploymentID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	groups, err := GetGroupsForNode(kv, deploymentID, nodeName)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0)
+	for _, policy := range policies {
+		targets, err := GetPolicyTargets(kv, deploymentID, policy)
+		if err != nil {
+			return nil, err
+		}
+		for _, target := range targets {
+			if target == nodeName || collections.ContainsString(groups, target) {
+				result = append(result, policy)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// getPolicyTypeTargets returns the node or group types allowed as targets of a given policy type.
+//
+// If targets are not defined in the given type then its type hierarchy is explored.
+// An empty result means that there is no restriction on targets.
+func getPolicyTypeTargets(kv *api.KV, deploymentID, policyTypeName string) ([]string, error) {
+	return policiesKind.getTypeAllowedNodesTypes(kv, deploymentID, policyTypeName)
+}
+
+// checkPoliciesTargets checks that policies types exist and that the targets of each policy
+// are allowed by its policy type.
+//
+// Groups targets are checked against their group type while nodes targets are checked against their node type.
+func checkPoliciesTargets(kv *api.KV, deploymentID string) error {
+	policies, err := GetPolicies(kv, deploymentID)
+	if err != nil {
+		return err
+	}
+	for _, policy := range policies {
+		policyType, err := GetPolicyType(kv, deploymentID, policy)
+		if err != nil {
+			return err
+		}
+		allowedTypes, err := getPolicyTypeTargets(kv, deploymentID, policyType)
+		if err != nil {
+			return errors.Wrapf(err, "Invalid type for policy %q", policy)
+		}
+		if len(allowedTypes) == 0 {
+			continue
+		}
+		targets, err := GetPolicyTargets(kv, deploymentID, policy)
+		if err != nil {
+			return err
+		}
+	targetsLoop:
+		for _, target := range targets {
+			isGroup, err := DoesGroupExist(kv, deploymentID, target)
+			if err != nil {
+				return err
+			}
+			var groupType string
+			if isGroup {
+				groupType, err = GetGroupType(kv, deploymentID, target)
+				if err != nil {
+					return err
+				}
+			}
+			for _, allowedType := range allowedTypes {
+				var derived bool
+				if isGroup {
+					derived, err = IsTypeDerivedFrom(kv, deploymentID, groupType, strings.TrimSpace(allowedType))
+				} else {
+					derived, err = IsNodeDerivedFrom(kv, deploymentID, target, strings.TrimSpace(allowedType))
+				}
+				if err != nil {
+					return err
+				}
+				if derived {
+					continue targetsLoop
+				}
+			}
+			if isGroup {
+				return errors.Errorf("Group %q can't be a target of policy %q: policy type %q only allows targets of types %v", target, policy, policyType, allowedTypes)
+			}
+			return errors.Errorf("Node %q can't be a target of policy %q: policy type %q only allows targets of types %v", target, policy, policyType, allowedTypes)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/testutil"
+)
+
+func testPolicies(t *testing.T, kv *api.KV) {
+	t.Parallel()
+	deploymentID := testutil.BuildDeploymentID(t)
+	err := StoreDeploymentDefinition(context.Background(), kv, deploymentID, "testdata/policies.yaml")
+	require.NoError(t, err)
+
+	policies, err := GetPolicies(kv, deploymentID)
+	require.NoError(t, err)
+	sort.Strings(policies)
+	assert.Equal(t, []string{"parallelism", "placement", "untargeted"}, policies)
+
+	exist, err := DoesPolicyExist(kv, deploymentID, "parallelism")
+	require.NoError(t, err)
+	assert.True(t, exist)
+	exist, err = DoesPolicyExist(kv, deploymentID, "unknown")
+	require.NoError(t, err)
+	assert.False(t, exist)
+
+	policyType, err := GetPolicyType(kv, deploymentID, "parallelism")
+	require.NoError(t, err)
+	assert.Equal(t, "yorc.tests.policies.ChildParallelism", policyType)
+
+	description, err := GetPolicyDescription(kv, deploymentID, "parallelism")
+	require.NoError(t, err)
+	assert.Equal(t, "Limit operations parallelism", description)
+
+	targets, err := GetPolicyTargets(kv, deploymentID, "parallelism")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Compute", "Compute2"}, targets)
+	targets, err = GetPolicyTargets(kv, deploymentID, "untargeted")
+	require.NoError(t, err)
+	assert.Len(t, targets, 0)
+
+	props, err := GetPolicyPropertiesNames(kv, deploymentID, "parallelism")
+	require.NoError(t, err)
+	sort.Strings(props)
+	assert.Equal(t, []string{"label", "max"}, props)
+
+	found, value, err := GetPolicyProperty(kv, deploymentID, "parallelism", "max")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "3", value)
+	found, value, err = GetPolicyProperty(kv, deploymentID, "untargeted", "max")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "1", value)
+	found, _, err = GetPolicyProperty(kv, deploymentID, "untargeted", "label")
+	require.NoError(t, err)
+	assert.False(t, found)
+	found, value, err = GetPolicyProperty(kv, deploymentID, "placement", "zone")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "zone-a", value)
+
+	policies, err = GetPoliciesForType(kv, deploymentID, "yorc.tests.policies.Parallelism")
+	require.NoError(t, err)
+	sort.Strings(policies)
+	assert.Equal(t, []string{"parallelism", "untargeted"}, policies)
+
+	policies, err = GetPoliciesForNode(kv, deploymentID, "Compute2", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"parallelism"}, policies)
+	policies, err = GetPoliciesForNode(kv, deploymentID, "Soft", "tosca.policies.Placement")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"placement"}, policies)
+	policies, err = GetPoliciesForNode(kv, deploymentID, "Soft", "yorc.tests.policies.Parallelism")
+	require.NoError(t, err)
+	assert.Len(t, policies, 0)
+}
+
+func testPoliciesInvalidTarget(t *testing.T, kv *api.KV) {
+	t.Parallel()
+	deploymentID := testutil.BuildDeploymentID(t)
+	err := StoreDeploymentDefinition(context.Background(), kv, deploymentID, "testdata/policies_invalid_target.yaml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `Node "Soft" can't be a target of policy "parallelism"`)
+}
@@ -46,7 +46,7 @@ func GetRelationshipPropertyFromRequirement(kv *api.KV, deploymentID, nodeName,
 			}
 		}
 	}
-	reqPrefix := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/nodes", nodeName, "requirements", requirementIndex)
+	reqPrefix := path.Join(deploymentKVPrefix(deploymentID), "topology/nodes", nodeName, "requirements", requirementIndex)
 
 	found, result, err := getValueAssignmentWithDataType(kv, deploymentID, path.Join(reqPrefix, "properties", propertyName), nodeName, "", requirementIndex, propDataType, nestedKeys...)
 	if err != nil {
@@ -129,7 +129,7 @@ func GetRelationshipAttributeFromRequirement(kv *api.KV, deploymentID, nodeName,
 	}
 
 	// First look at instance scoped attributes
-	capAttrPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/relationship_instances", nodeName, requirementIndex, instanceName, "attributes", attributeName)
+	capAttrPath := path.Join(deploymentKVPrefix(deploymentID), "topology/relationship_instances", nodeName, requirementIndex, instanceName, "attributes", attributeName)
 	found, result, err := getValueAssignmentWithDataType(kv, deploymentID, capAttrPath, nodeName, instanceName, requirementIndex, attrDataType, nestedKeys...)
 	if err != nil || found {
 		// If there is an error or attribute was found
@@ -159,7 +159,7 @@ func SetInstanceRelationshipAttribute(deploymentID, nodeName, instanceName, requ
 
 // SetInstanceRelationshipAttributeComplex sets an instance relationship attribute that may be a literal or a complex data type
 func SetInstanceRelationshipAttributeComplex(deploymentID, nodeName, instanceName, requirementIndex, attributeName string, attributeValue interface{}) error {
-	attrPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/relationship_instances", nodeName, requirementIndex, instanceName, "attributes", attributeName)
+	attrPath := path.Join(deploymentKVPrefix(deploymentID), "topology/relationship_instances", nodeName, requirementIndex, instanceName, "attributes", attributeName)
 	_, errGrp, store := consulutil.WithContext(context.Background())
 	storeComplexType(store, attrPath, attributeValue)
 	return errGrp.Wait()
@@ -184,7 +184,7 @@ func SetRelationshipAttributeComplexForAllInstances(kv *api.KV, deploymentID, no
 	}
 	_, errGrp, store := consulutil.WithContext(context.Background())
 	for _, instanceName := range ids {
-		attrPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/relationship_instances", nodeName, requirementIndex, instanceName, "attributes", attributeName)
+		attrPath := path.Join(deploymentKVPrefix(deploymentID), "topology/relationship_instances", nodeName, requirementIndex, instanceName, "attributes", attributeName)
 		storeComplexType(store, attrPath, attributeValue)
 	}
 	return errGrp.Wait()
@@ -192,7 +192,7 @@ func SetRelationshipAttributeComplexForAllInstances(kv *api.KV, deploymentID, no
 
 // This function create an instance of each relationship and reference who is the target and the instanceID of this one
 func createRelationshipInstances(consulStore consulutil.ConsulStore, kv *api.KV, deploymentID, nodeName string) error {
-	relInstancePath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/relationship_instances")
+	relInstancePath := path.Join(deploymentKVPrefix(deploymentID), "topology/relationship_instances")
 	reqKeys, err := GetRequirementsIndexes(kv, deploymentID, nodeName)
 	nodeInstanceIds, err := GetNodeInstancesIds(kv, deploymentID, nodeName)
 	if err != nil {
@@ -231,7 +231,7 @@ func createRelationshipInstances(consulStore consulutil.ConsulStore, kv *api.KV,
 }
 
 func addOrRemoveInstanceFromTargetRelationship(kv *api.KV, deploymentID, nodeName, instanceName string, add bool) error {
-	relInstancePath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/relationship_instances")
+	relInstancePath := path.Join(deploymentKVPrefix(deploymentID), "topology/relationship_instances")
 	relInstKVPairs, _, err := kv.List(relInstancePath, nil)
 	if err != nil {
 		return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
@@ -276,7 +276,7 @@ func addOrRemoveInstanceFromTargetRelationship(kv *api.KV, deploymentID, nodeNam
 
 // DeleteRelationshipInstance deletes the instance from relationship instances stored in consul
 func DeleteRelationshipInstance(kv *api.KV, deploymentID, nodeName, instanceName string) error {
-	relInstancePath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/relationship_instances")
+	relInstancePath := path.Join(deploymentKVPrefix(deploymentID), "topology/relationship_instances")
 	nodeRelInstancePath := path.Join(relInstancePath, nodeName)
 	reqIndices, _, err := kv.Keys(nodeRelInstancePath+"/", "/", nil)
 	if err != nil {
@@ -28,7 +28,7 @@ const DockerHubURL string = "https://hub.docker.com/"
 
 // GetRepositoryURLFromName allow you to retrieve the url of a repo from is name
 func GetRepositoryURLFromName(kv *api.KV, deploymentID, repoName string) (url string, err error) {
-	repositoriesPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "repositories")
+	repositoriesPath := path.Join(deploymentKVPrefix(deploymentID), "topology", "repositories")
 	res, _, err := kv.Get(path.Join(repositoriesPath, repoName, "url"), nil)
 	if err != nil {
 		err = errors.Wrap(err, consulutil.ConsulGenericErrMsg)
@@ -46,7 +46,7 @@ func GetRepositoryURLFromName(kv *api.KV, deploymentID, repoName string) (url st
 
 // GetRepositoryTokenTypeFromName retrieves the token_type of credential for a given repoName
 func GetRepositoryTokenTypeFromName(kv *api.KV, deploymentID, repoName string) (string, error) {
-	repositoriesPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "repositories")
+	repositoriesPath := path.Join(deploymentKVPrefix(deploymentID), "topology", "repositories")
 	res, _, err := kv.Get(path.Join(repositoriesPath, repoName, "credentials", "token_type"), nil)
 	if err != nil {
 		return "", errors.Wrap(err, "An error has occurred when trying to get repository token_type")
@@ -61,7 +61,7 @@ func GetRepositoryTokenTypeFromName(kv *api.KV, deploymentID, repoName string) (
 
 // GetRepositoryTokenUserFromName This function get the credentials (user/token) for a given repoName
 func GetRepositoryTokenUserFromName(kv *api.KV, deploymentID, repoName string) (token string, user string, err error) {
-	repositoriesPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "repositories")
+	repositoriesPath := path.Join(deploymentKVPrefix(deploymentID), "topology", "repositories")
 	res, _, err := kv.Get(path.Join(repositoriesPath, repoName, "credentials", "token"), nil)
 	if err != nil {
 		err = errors.Wrap(err, "An error has occurred when trying to get repository token")
@@ -26,7 +26,7 @@ import (
 
 // GetRequirementKeyByNameForNode returns path to requirement which name match with defined requirementName for a given node name
 func GetRequirementKeyByNameForNode(kv *api.KV, deploymentID, nodeName, requirementName string) (string, error) {
-	nodePath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "nodes", nodeName)
+	nodePath := path.Join(deploymentKVPrefix(deploymentID), "topology", "nodes", nodeName)
 	reqKVPs, _, err := kv.Keys(path.Join(nodePath, "requirements")+"/", "/", nil)
 	if err != nil {
 		return "", errors.Wrap(err, consulutil.ConsulGenericErrMsg)
@@ -51,7 +51,7 @@ func GetRequirementKeyByNameForNode(kv *api.KV, deploymentID, nodeName, requirem
 //
 // The returned slice may be empty if there is no matching requirements.
 func GetRequirementsKeysByTypeForNode(kv *api.KV, deploymentID, nodeName, requirementType string) ([]string, error) {
-	nodePath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "nodes", nodeName)
+	nodePath := path.Join(deploymentKVPrefix(deploymentID), "topology", "nodes", nodeName)
 	reqKVPs, _, err := kv.Keys(path.Join(nodePath, "requirements")+"/", "/", nil)
 	reqKeys := make([]string, 0)
 	if err != nil {
@@ -95,7 +95,7 @@ func GetRequirementIndexFromRequirementKey(requirementKey string) string {
 
 // GetRequirementsIndexes returns the list of requirements indexes for a given node
 func GetRequirementsIndexes(kv *api.KV, deploymentID, nodeName string) ([]string, error) {
-	reqPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "nodes", nodeName, "requirements")
+	reqPath := path.Join(deploymentKVPrefix(deploymentID), "topology", "nodes", nodeName, "requirements")
 	reqKVPs, _, err := kv.Keys(reqPath+"/", "/", nil)
 	if err != nil {
 		return nil, errors.Wrapf(err, consulutil.ConsulGenericErrMsg)
@@ -108,7 +108,7 @@ func GetRequirementsIndexes(kv *api.KV, deploymentID, nodeName string) ([]string
 
 // GetNbRequirementsForNode returns the number of requirements declared for the given node
 func GetNbRequirementsForNode(kv *api.KV, deploymentID, nodeName string) (int, error) {
-	nodePath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "nodes", nodeName)
+	nodePath := path.Join(deploymentKVPrefix(deploymentID), "topology", "nodes", nodeName)
 	reqKVPs, _, err := kv.Keys(path.Join(nodePath, "requirements")+"/", "/", nil)
 	if err != nil {
 		return 0, errors.Wrapf(err, "Failed to retrieve requirements for node %q", nodeName)
@@ -120,7 +120,7 @@ func GetNbRequirementsForNode(kv *api.KV, deploymentID, nodeName string) (int, e
 //
 // If there is no relationship defined for this requirement then an empty string is returned.
 func GetRelationshipForRequirement(kv *api.KV, deploymentID, nodeName, requirementIndex string) (string, error) {
-	kvp, _, err := kv.Get(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/nodes", nodeName, "requirements", requirementIndex, "relationship"), nil)
+	kvp, _, err := kv.Get(path.Join(deploymentKVPrefix(deploymentID), "topology/nodes", nodeName, "requirements", requirementIndex, "relationship"), nil)
 	// TODO: explicit naming of the relationship is optional and there is alternative way to retrieve it furthermore it can refer to a relationship_template_name instead of a relationship_type_name
 	if err != nil || kvp == nil || len(kvp.Value) == 0 {
 		return "", errors.Wrap(err, consulutil.ConsulGenericErrMsg)
@@ -132,7 +132,7 @@ func GetRelationshipForRequirement(kv *api.KV, deploymentID, nodeName, requireme
 //
 // If there is no capability defined for this requirement then an empty string is returned.
 func GetCapabilityForRequirement(kv *api.KV, deploymentID, nodeName, requirementIndex string) (string, error) {
-	kvp, _, err := kv.Get(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/nodes", nodeName, "requirements", requirementIndex, "capability"), nil)
+	kvp, _, err := kv.Get(path.Join(deploymentKVPrefix(deploymentID), "topology/nodes", nodeName, "requirements", requirementIndex, "capability"), nil)
 	if err != nil || kvp == nil || len(kvp.Value) == 0 {
 		return "", errors.Wrap(err, consulutil.ConsulGenericErrMsg)
 	}
@@ -143,7 +143,7 @@ func GetCapabilityForRequirement(kv *api.KV, deploymentID, nodeName, requirement
 //
 // If there is no node defined for this requirement then an empty string is returned.
 func GetTargetNodeForRequirement(kv *api.KV, deploymentID, nodeName, requirementIndex string) (string, error) {
-	kvp, _, err := kv.Get(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/nodes", nodeName, "requirements", requirementIndex, "node"), nil)
+	kvp, _, err := kv.Get(path.Join(deploymentKVPrefix(deploymentID), "topology/nodes", nodeName, "requirements", requirementIndex, "node"), nil)
 	// TODO: explicit naming of the node is optional and there is alternative way to retrieve it furthermore it can refer to a node_template_name instead of a node_type_name
 	if err != nil || kvp == nil || len(kvp.Value) == 0 {
 		return "", errors.Wrap(err, consulutil.ConsulGenericErrMsg)
@@ -155,7 +155,7 @@ func GetTargetNodeForRequirement(kv *api.KV, deploymentID, nodeName, requirement
 //
 // If there is no node defined for this requirement then an empty string is returned.
 func GetTargetNodeForRequirementByName(kv *api.KV, deploymentID, nodeName, requirementName string) (string, error) {
-	reqPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/nodes", nodeName, "requirements")
+	reqPath := path.Join(deploymentKVPrefix(deploymentID), "topology/nodes", nodeName, "requirements")
 	kvp, _, err := kv.Keys(reqPath+"/", "/", nil)
 	// TODO: explicit naming of the node is optional and there is alternative way to retrieve it furthermore it can refer to a node_template_name instead of a node_type_name
 	if err != nil || kvp == nil {
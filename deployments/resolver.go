@@ -15,6 +15,7 @@
 package deployments
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
@@ -34,6 +35,9 @@ const funcKeywordSOURCE string = "SOURCE"
 const funcKeywordTARGET string = "TARGET"
 const funcKeywordREQTARGET string = "REQ_TARGET"
 
+// artifactLocationLocalFile is the get_artifact location letting the orchestrator choose where the artifact is provided
+const artifactLocationLocalFile string = "LOCAL_FILE"
+
 // functionResolver is used to resolve TOSCA functions
 type functionResolver struct {
 	kv               *api.KV
@@ -80,28 +84,23 @@ func (fr *functionResolver) resolveFunction(fn *tosca.Function) (string, error)
 	}
 	operands := make([]string, len(fn.Operands))
 	for i, op := range fn.Operands {
-		if op.IsLiteral() {
-			var err error
-			s := op.String()
-			if isQuoted(s) {
-				s, err = strconv.Unquote(s)
-				if err != nil {
-					return "", errors.Wrapf(err, "failed to unquote literal operand of function %v", fn)
-				}
-			}
-			operands[i] = s
-		} else {
-			subFn := op.(*tosca.Function)
-			r, err := fr.resolveFunction(subFn)
-			if err != nil {
-				return "", err
-			}
-			operands[i] = r
+		r, err := fr.resolveOperand(fn, op)
+		if err != nil {
+			return "", err
 		}
+		operands[i] = r
 	}
 	switch fn.Operator {
 	case tosca.ConcatOperator:
 		return strings.Join(operands, ""), nil
+	case tosca.JoinOperator:
+		return fr.resolveJoin(operands)
+	case tosca.TokenOperator:
+		return fr.resolveToken(operands)
+	case tosca.GetNodesOfTypeOperator:
+		return fr.resolveGetNodesOfType(operands)
+	case tosca.GetArtifactOperator:
+		return fr.resolveGetArtifact(operands)
 	case tosca.GetInputOperator:
 		return fr.resolveGetInput(operands)
 	case tosca.GetOperationOutputOperator:
@@ -114,6 +113,175 @@ func (fr *functionResolver) resolveFunction(fn *tosca.Function) (string, error)
 	return "", errors.Errorf("Unsupported function %q", string(fn.Operator))
 }
 
+// resolveOperand resolves an operand of a function
+//
+// Lists operands are resolved as JSON lists like other complex values.
+func (fr *functionResolver) resolveOperand(fn *tosca.Function, op tosca.Operand) (string, error) {
+	switch v := op.(type) {
+	case *tosca.Function:
+		return fr.resolveFunction(v)
+	case tosca.ListOperand:
+		values := make([]string, len(v))
+		for i, subOp := range v {
+			r, err := fr.resolveOperand(fn, subOp)
+			if err != nil {
+				return "", err
+			}
+			values[i] = r
+		}
+		b, err := json.Marshal(values)
+		return string(b), errors.Wrapf(err, "failed to resolve list operand of function %v", fn)
+	}
+	s := op.String()
+	if isQuoted(s) {
+		var err error
+		s, err = strconv.Unquote(s)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to unquote literal operand of function %v", fn)
+		}
+	}
+	return s, nil
+}
+
+func (fr *functionResolver) resolveJoin(operands []string) (string, error) {
+	if len(operands) < 1 || len(operands) > 2 {
+		return "", errors.Errorf("expecting a list of values and an optional delimiter as parameters of a join function")
+	}
+	v, err := decodeJSONValue([]byte(operands[0]))
+	values, ok := v.([]interface{})
+	if err != nil || !ok {
+		return "", errors.Errorf("expecting a list as first parameter of a join function, got %q", operands[0])
+	}
+	var delimiter string
+	if len(operands) == 2 {
+		delimiter = operands[1]
+	}
+	strValues := make([]string, len(values))
+	for i, value := range values {
+		switch value.(type) {
+		case []interface{}, map[string]interface{}:
+			b, err := json.Marshal(value)
+			if err != nil {
+				return "", errors.Wrapf(err, "failed to join value %v", value)
+			}
+			strValues[i] = string(b)
+		default:
+			strValues[i] = fmt.Sprint(value)
+		}
+	}
+	return strings.Join(strValues, delimiter), nil
+}
+
+func (fr *functionResolver) resolveToken(operands []string) (string, error) {
+	if len(operands) != 3 {
+		return "", errors.Errorf("expecting exactly three parameters for a token function")
+	}
+	index, err := strconv.Atoi(operands[2])
+	if err != nil || index < 0 {
+		return "", errors.Errorf("expecting a positive integer as substring index of a token function, got %q", operands[2])
+	}
+	// Consecutive separators delimit empty substrings
+	var tokens []string
+	var start int
+	for i, r := range operands[0] {
+		if strings.ContainsRune(operands[1], r) {
+			tokens = append(tokens, operands[0][start:i])
+			start = i + len(string(r))
+		}
+	}
+	tokens = append(tokens, operands[0][start:])
+	if index >= len(tokens) {
+		return "", errors.Errorf(`Can't resolve "token: [%s]" %q has only %d substrings`, strings.Join(operands, ", "), operands[0], len(tokens))
+	}
+	return tokens[index], nil
+}
+
+func (fr *functionResolver) resolveGetNodesOfType(operands []string) (string, error) {
+	if len(operands) != 1 {
+		return "", errors.Errorf("expecting exactly one parameter for a get_nodes_of_type function")
+	}
+	nodes, err := GetNodes(fr.kv, fr.deploymentID)
+	if err != nil {
+		return "", err
+	}
+	result := make([]string, 0)
+	for _, node := range nodes {
+		isOfType, err := IsNodeDerivedFrom(fr.kv, fr.deploymentID, node, operands[0])
+		if err != nil {
+			return "", err
+		}
+		if isOfType {
+			result = append(result, node)
+		}
+	}
+	b, err := json.Marshal(result)
+	return string(b), errors.Wrap(err, "failed to resolve get_nodes_of_type function")
+}
+
+// resolveGetArtifact resolves a get_artifact function into the path of the artifact relative to the deployment root
+//
+// Executors are responsible for providing the artifact file on the host where the operation runs.
+func (fr *functionResolver) resolveGetArtifact(operands []string) (string, error) {
+	funcString := fmt.Sprintf("get_artifact: [%s]", strings.Join(operands, ", "))
+	if len(operands) < 2 || len(operands) > 4 {
+		return "", errors.Errorf("expecting between two and four parameters for a get_artifact function (%s)", funcString)
+	}
+	if len(operands) > 2 && operands[2] != "" && operands[2] != artifactLocationLocalFile {
+		return "", errors.Errorf(`Can't resolve %q only the %s location is supported`, funcString, artifactLocationLocalFile)
+	}
+	artifacts, err := fr.getArtifactsForEntity(funcString, operands[0])
+	if err != nil {
+		return "", err
+	}
+	artifact, ok := artifacts[operands[1]]
+	if !ok {
+		return "", errors.Errorf("Can't resolve expression %q artifact not found", funcString)
+	}
+	return artifact, nil
+}
+
+func (fr *functionResolver) getArtifactsForEntity(funcString, entity string) (map[string]string, error) {
+	switch entity {
+	case funcKeywordSELF, funcKeywordSOURCE:
+		artifacts, err := GetArtifactsForNode(fr.kv, fr.deploymentID, fr.nodeName)
+		if err != nil || entity == funcKeywordSOURCE || fr.requirementIndex == "" {
+			return artifacts, err
+		}
+		// Artifacts of the relationship type override those of the source node
+		relType, err := GetRelationshipForRequirement(fr.kv, fr.deploymentID, fr.nodeName, fr.requirementIndex)
+		if err != nil {
+			return nil, err
+		}
+		relArtifacts, err := GetArtifactsForType(fr.kv, fr.deploymentID, relType)
+		if err != nil {
+			return nil, err
+		}
+		for artName, art := range relArtifacts {
+			artifacts[artName] = art
+		}
+		return artifacts, nil
+	case funcKeywordTARGET:
+		if fr.requirementIndex == "" {
+			return nil, errors.Errorf(`Can't resolve %q %s keyword is supported only in the context of a relationship`, funcString, entity)
+		}
+		targetNode, err := GetTargetNodeForRequirement(fr.kv, fr.deploymentID, fr.nodeName, fr.requirementIndex)
+		if err != nil {
+			return nil, err
+		}
+		return GetArtifactsForNode(fr.kv, fr.deploymentID, targetNode)
+	case funcKeywordHOST:
+		host, err := GetHostedOnNode(fr.kv, fr.deploymentID, fr.nodeName)
+		if err != nil {
+			return nil, err
+		}
+		if host == "" {
+			return nil, errors.Errorf(`Can't resolve %q node %q is not hosted on another node`, funcString, fr.nodeName)
+		}
+		return GetArtifactsForNode(fr.kv, fr.deploymentID, host)
+	}
+	return GetArtifactsForNode(fr.kv, fr.deploymentID, entity)
+}
+
 func (fr *functionResolver) resolveGetInput(operands []string) (string, error) {
 	if len(operands) < 1 {
 		return "", errors.Errorf("expecting at least one parameter for a get_input function")
@@ -41,6 +41,9 @@ func testResolver(t *testing.T, kv *api.KV) {
 	t.Run("deployments/resolver/testResolveComplex", func(t *testing.T) {
 		testResolveComplex(t, kv)
 	})
+	t.Run("deployments/resolver/testResolveFunctions", func(t *testing.T) {
+		testResolveFunctions(t, kv)
+	})
 
 }
 
@@ -154,3 +157,63 @@ func testResolveComplex(t *testing.T, kv *api.KV) {
 		})
 	}
 }
+
+func testResolveFunctions(t *testing.T, kv *api.KV) {
+	deploymentID := testutil.BuildDeploymentID(t)
+	err := StoreDeploymentDefinition(context.Background(), kv, deploymentID, "testdata/functions.yaml")
+	require.NoError(t, err)
+
+	tests := []struct {
+		property string
+		want     string
+	}{
+		{"login", "admin@example.org"},
+		{"hosts", "ComputeA,ComputeB"},
+		{"network", "10.0.0.0"},
+		{"config_path", "config/app.cfg"},
+	}
+	for _, tt := range tests {
+		found, value, err := GetNodeProperty(kv, deploymentID, "Soft", tt.property)
+		require.NoError(t, err, "property %q", tt.property)
+		require.True(t, found, "property %q", tt.property)
+		require.Equal(t, tt.want, value, "property %q", tt.property)
+	}
+
+	r := resolver(kv, deploymentID).context(withNodeName("Soft"))
+	_, err = r.resolveFunction(generateToscaValueAssignmentFromString(t, `{ get_artifact: [ SELF, missing ] }`).GetFunction())
+	require.Error(t, err)
+	_, err = r.resolveFunction(generateToscaValueAssignmentFromString(t, `{ get_artifact: [ SELF, config, /opt/config ] }`).GetFunction())
+	require.Error(t, err)
+	result, err := r.resolveFunction(generateToscaValueAssignmentFromString(t, `{ get_nodes_of_type: yorc.tests.functions.Soft }`).GetFunction())
+	require.NoError(t, err)
+	require.Equal(t, `["Soft"]`, result)
+}
+
+func TestResolveJoinAndToken(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		function string
+		want     string
+		wantErr  bool
+	}{
+		{"Join", `{ join: [ [a, b, c], ", " ] }`, "a, b, c", false},
+		{"JoinNoDelimiter", `{ join: [ [a, b, c] ] }`, "abc", false},
+		{"JoinNested", `{ join: [ [a, { concat: [b, c] }], "-" ] }`, "a-bc", false},
+		{"Token", `{ token: [ "a:b:c", ":", 1 ] }`, "b", false},
+		{"TokenSeveralSeparators", `{ token: [ "a:b/c", ":/", 2 ] }`, "c", false},
+		{"TokenEmptySubstring", `{ token: [ "a::c", ":", 1 ] }`, "", false},
+		{"TokenOutOfRange", `{ token: [ "a:b:c", ":", 3 ] }`, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := resolver(nil, "").resolveFunction(generateToscaValueAssignmentFromString(t, tt.function).GetFunction())
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, result)
+		})
+	}
+}
@@ -37,6 +37,14 @@ const (
 	UNDEPLOYMENT_FAILED
 	// SCALING_IN_PROGRESS instances are currently added or removed to the deployment
 	SCALING_IN_PROGRESS
+	// ROLLED_BACK deployment encountered an error and resources created before this error were removed
+	ROLLED_BACK
+	// UPDATE_IN_PROGRESS deployment is currently updated with a new topology
+	UPDATE_IN_PROGRESS
+	// UPDATE_FAILED deployment update encountered an error
+	UPDATE_FAILED
+	// ROLLBACK_FAILED deployment encountered an error and resources created before this error could not be all removed
+	ROLLBACK_FAILED
 
 	endOfDepStatusConst // Do not remove this line and define new const before it. It is used to get const value from string
 )
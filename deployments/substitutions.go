@@ -0,0 +1,852 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/helper/collections"
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/tosca"
+)
+
+// A substitutableTopology is an imported topology which topology template declares substitution mappings
+type substitutableTopology struct {
+	topology   tosca.Topology
+	importPath string
+}
+
+// A substitution is the expansion of an abstract node template by the node templates of a substitutable topology
+type substitution struct {
+	nodeName     string
+	nodeType     string
+	topology     tosca.Topology
+	nodes        map[string]string
+	capabilities map[string][2]string
+	requirements map[string][2]string
+	attributes   map[string][2]string
+}
+
+// expandSubstitutions replaces in the given topology each node template which type is substituted by an
+// imported topology by the node templates of this topology.
+//
+// Expanded node templates are named <abstract node name>_<node template name>. The abstract node template is kept
+// but its requirements are moved to the node templates they are mapped to and the requirements targeting it are
+// redirected to the node templates exposing the mapped capabilities. Workflows steps are expanded accordingly.
+// Substituted node templates of substitutable topologies are themselves expanded.
+func expandSubstitutions(topology *tosca.Topology, rootDefPath string) ([]*substitution, error) {
+	if len(topology.TopologyTemplate.NodeTemplates) == 0 {
+		return nil, nil
+	}
+	nodeTypes := make(map[string]tosca.NodeType)
+	substitutables := make(map[string]substitutableTopology)
+	err := loadSubstitutableTopologies(*topology, "", rootDefPath, nodeTypes, substitutables, make(map[string]bool))
+	if err != nil {
+		return nil, err
+	}
+	if len(substitutables) == 0 {
+		return nil, nil
+	}
+
+	tt := &topology.TopologyTemplate
+	// substituted types of the abstract nodes in which a node is expanded, used to detect cycles
+	parents := make(map[string][]string)
+	var queue []string
+	for _, nodeName := range sortedNodeTemplatesNames(tt.NodeTemplates) {
+		if _, ok := substitutables[tt.NodeTemplates[nodeName].Type]; ok {
+			queue = append(queue, nodeName)
+		}
+	}
+	var substitutions []*substitution
+	for i := 0; i < len(queue); i++ {
+		nodeName := queue[i]
+		nodeType := tt.NodeTemplates[nodeName].Type
+		if collections.ContainsString(parents[nodeName], nodeType) {
+			return nil, errors.Errorf("can't substitute node %q: type %q is substituted by a topology in which it is used", nodeName, nodeType)
+		}
+		sub, err := expandSubstitutedNode(tt, nodeName, substitutables[nodeType], nodeTypes)
+		if err != nil {
+			return nil, err
+		}
+		substitutions = append(substitutions, sub)
+		for _, innerName := range sortedStringsMapValues(sub.nodes) {
+			if _, ok := substitutables[tt.NodeTemplates[innerName].Type]; ok {
+				parents[innerName] = append(append([]string{}, parents[nodeName]...), nodeType)
+				queue = append(queue, innerName)
+			}
+		}
+	}
+
+	// Inner abstract nodes are expanded after the outer ones so redirected requirements are redirected again if needed
+	for _, sub := range substitutions {
+		if err = sub.redirectRequirements(tt, nodeTypes); err != nil {
+			return nil, err
+		}
+	}
+	for _, sub := range substitutions {
+		for wfName, wf := range tt.Workflows {
+			wf.Steps, err = sub.expandWorkflowSteps(wfName, wf.Steps)
+			if err != nil {
+				return nil, err
+			}
+			tt.Workflows[wfName] = wf
+		}
+	}
+	return substitutions, nil
+}
+
+// loadSubstitutableTopologies walks through the imports of a topology to collect node types and topologies
+// declaring substitution mappings
+func loadSubstitutableTopologies(topology tosca.Topology, importPath, rootDefPath string, nodeTypes map[string]tosca.NodeType, substitutables map[string]substitutableTopology, visited map[string]bool) error {
+	for typeName, nodeType := range topology.NodeTypes {
+		nodeTypes[typeName] = nodeType
+	}
+	for _, element := range topology.Imports {
+		importKey := path.Join(importPath, element.File)
+		if visited[importKey] {
+			continue
+		}
+		visited[importKey] = true
+		importedTopology, importedPath, _, err := readImportedTopology(element, importPath, rootDefPath)
+		if err != nil {
+			return err
+		}
+		if sm := importedTopology.TopologyTemplate.SubstitutionMappings; sm != nil && sm.NodeType != "" {
+			if _, ok := substitutables[sm.NodeType]; ok {
+				return errors.Errorf("node type %q is substituted by several imported topologies", sm.NodeType)
+			}
+			substitutables[sm.NodeType] = substitutableTopology{topology: importedTopology, importPath: importedPath}
+		}
+		err = loadSubstitutableTopologies(importedTopology, importedPath, rootDefPath, nodeTypes, substitutables, visited)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expandSubstitutedNode adds to the topology template the node templates of the topology substituting the given node
+func expandSubstitutedNode(tt *tosca.TopologyTemplate, nodeName string, st substitutableTopology, nodeTypes map[string]tosca.NodeType) (*substitution, error) {
+	node := tt.NodeTemplates[nodeName]
+	inner := st.topology.TopologyTemplate
+	mappings := inner.SubstitutionMappings
+	sub := &substitution{
+		nodeName:     nodeName,
+		nodeType:     node.Type,
+		topology:     st.topology,
+		nodes:        make(map[string]string, len(inner.NodeTemplates)),
+		capabilities: make(map[string][2]string),
+		requirements: make(map[string][2]string),
+		attributes:   make(map[string][2]string),
+	}
+	for innerName := range inner.NodeTemplates {
+		expandedName := nodeName + "_" + innerName
+		if _, exists := tt.NodeTemplates[expandedName]; exists {
+			return nil, errors.Errorf("can't substitute node %q: a node named %q already exists", nodeName, expandedName)
+		}
+		sub.nodes[innerName] = expandedName
+	}
+	self := map[string]string{"SELF": nodeName}
+	var err error
+
+	// Values of the inputs of the substituting topology
+	inputs := make(map[string]*tosca.ValueAssignment)
+	for inputName, input := range inner.Inputs {
+		if input.Value != nil {
+			inputs[inputName] = input.Value
+		} else if input.Default != nil {
+			inputs[inputName] = input.Default
+		}
+	}
+	mappedProperties := make(map[string]map[string]*tosca.ValueAssignment)
+	for propName, target := range mappings.Properties {
+		value := node.Properties[propName]
+		if value == nil {
+			value = getNodeTypePropertyDefault(nodeTypes, node.Type, propName)
+		}
+		value, err = rewriteValueAssignment(value, self, nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "can't map property %q of node %q", propName, nodeName)
+		}
+		switch len(target) {
+		case 1:
+			if _, ok := inner.Inputs[target[0]]; !ok {
+				return nil, errors.Errorf("property %q of node %q is mapped to unknown input %q", propName, nodeName, target[0])
+			}
+			if value != nil {
+				inputs[target[0]] = value
+			}
+		case 2:
+			if _, ok := sub.nodes[target[0]]; !ok {
+				return nil, errors.Errorf("property %q of node %q is mapped to unknown node %q", propName, nodeName, target[0])
+			}
+			if value != nil {
+				if mappedProperties[target[0]] == nil {
+					mappedProperties[target[0]] = make(map[string]*tosca.ValueAssignment)
+				}
+				mappedProperties[target[0]][target[1]] = value
+			}
+		default:
+			return nil, errors.Errorf("invalid mapping %v for property %q of node type %q", target, propName, node.Type)
+		}
+	}
+	for _, m := range []struct {
+		kind     string
+		mappings map[string][]string
+		result   map[string][2]string
+	}{
+		{"capability", mappings.Capabilities, sub.capabilities},
+		{"requirement", mappings.Requirements, sub.requirements},
+		{"attribute", mappings.Attributes, sub.attributes},
+	} {
+		for name, target := range m.mappings {
+			if len(target) != 2 {
+				return nil, errors.Errorf("invalid mapping %v for %s %q of node type %q", target, m.kind, name, node.Type)
+			}
+			expandedName, ok := sub.nodes[target[0]]
+			if !ok {
+				return nil, errors.Errorf("%s %q of node type %q is mapped to unknown node %q", m.kind, name, node.Type, target[0])
+			}
+			m.result[name] = [2]string{expandedName, target[1]}
+		}
+	}
+
+	for innerName, innerNode := range inner.NodeTemplates {
+		expanded, err := expandNodeTemplate(innerNode, sub.nodes, inputs, st.importPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "can't substitute node %q: failed to expand node %q", nodeName, innerName)
+		}
+		for propName, value := range mappedProperties[innerName] {
+			expanded.Properties[propName] = value
+		}
+		tt.NodeTemplates[sub.nodes[innerName]] = expanded
+	}
+
+	// Capabilities properties and attributes of the abstract node override the ones of the mapped capabilities
+	for capName, capability := range node.Capabilities {
+		target, ok := sub.capabilities[capName]
+		if !ok {
+			continue
+		}
+		targetNode := tt.NodeTemplates[target[0]]
+		targetCap := targetNode.Capabilities[target[1]]
+		if targetCap.Properties == nil {
+			targetCap.Properties = make(map[string]*tosca.ValueAssignment)
+		}
+		if targetCap.Attributes == nil {
+			targetCap.Attributes = make(map[string]*tosca.ValueAssignment)
+		}
+		for propName, value := range capability.Properties {
+			if targetCap.Properties[propName], err = rewriteValueAssignment(value, self, nil); err != nil {
+				return nil, errors.Wrapf(err, "can't map capability %q of node %q", capName, nodeName)
+			}
+		}
+		for attrName, value := range capability.Attributes {
+			if targetCap.Attributes[attrName], err = rewriteValueAssignment(value, self, nil); err != nil {
+				return nil, errors.Wrapf(err, "can't map capability %q of node %q", capName, nodeName)
+			}
+		}
+		targetNode.Capabilities[target[1]] = targetCap
+	}
+
+	// Requirements of the abstract node are moved to the node templates they are mapped to
+	for _, reqMap := range node.Requirements {
+		for reqName, req := range reqMap {
+			target, ok := sub.requirements[reqName]
+			if !ok {
+				return nil, errors.Errorf("requirement %q of node %q is not mapped by the substitution mappings of type %q", reqName, nodeName, node.Type)
+			}
+			targetNode := tt.NodeTemplates[target[0]]
+			targetNode.Requirements = assignRequirement(targetNode.Requirements, target[1], req)
+			tt.NodeTemplates[target[0]] = targetNode
+		}
+	}
+	node.Requirements = nil
+	tt.NodeTemplates[nodeName] = node
+	return sub, nil
+}
+
+// expandNodeTemplate returns a copy of a node template of a substituting topology where references to other node
+// templates are renamed and inputs are replaced by their values
+func expandNodeTemplate(node tosca.NodeTemplate, nodes map[string]string, inputs map[string]*tosca.ValueAssignment, importPath string) (tosca.NodeTemplate, error) {
+	var err error
+	expanded := tosca.NodeTemplate{
+		Type:         node.Type,
+		Description:  node.Description,
+		Directives:   node.Directives,
+		Properties:   make(map[string]*tosca.ValueAssignment, len(node.Properties)),
+		Attributes:   make(map[string]*tosca.ValueAssignment, len(node.Attributes)),
+		Capabilities: make(map[string]tosca.CapabilityAssignment, len(node.Capabilities)),
+		Artifacts:    make(tosca.ArtifactDefMap, len(node.Artifacts)),
+	}
+	for propName, value := range node.Properties {
+		if expanded.Properties[propName], err = rewriteValueAssignment(value, nodes, inputs); err != nil {
+			return expanded, errors.Wrapf(err, "property %q", propName)
+		}
+	}
+	for attrName, value := range node.Attributes {
+		if expanded.Attributes[attrName], err = rewriteValueAssignment(value, nodes, inputs); err != nil {
+			return expanded, errors.Wrapf(err, "attribute %q", attrName)
+		}
+	}
+	for capName, capability := range node.Capabilities {
+		expandedCap := tosca.CapabilityAssignment{
+			Properties: make(map[string]*tosca.ValueAssignment, len(capability.Properties)),
+			Attributes: make(map[string]*tosca.ValueAssignment, len(capability.Attributes)),
+		}
+		for propName, value := range capability.Properties {
+			if expandedCap.Properties[propName], err = rewriteValueAssignment(value, nodes, inputs); err != nil {
+				return expanded, errors.Wrapf(err, "property %q of capability %q", propName, capName)
+			}
+		}
+		for attrName, value := range capability.Attributes {
+			if expandedCap.Attributes[attrName], err = rewriteValueAssignment(value, nodes, inputs); err != nil {
+				return expanded, errors.Wrapf(err, "attribute %q of capability %q", attrName, capName)
+			}
+		}
+		expanded.Capabilities[capName] = expandedCap
+	}
+	for _, reqMap := range node.Requirements {
+		expandedMap := make(tosca.RequirementAssignmentMap, len(reqMap))
+		for reqName, req := range reqMap {
+			if target, ok := nodes[req.Node]; ok {
+				req.Node = target
+			}
+			props := make(map[string]*tosca.ValueAssignment, len(req.RelationshipProps))
+			for propName, value := range req.RelationshipProps {
+				if props[propName], err = rewriteValueAssignment(value, nodes, inputs); err != nil {
+					return expanded, errors.Wrapf(err, "property %q of requirement %q", propName, reqName)
+				}
+			}
+			req.RelationshipProps = props
+			expandedMap[reqName] = req
+		}
+		expanded.Requirements = append(expanded.Requirements, expandedMap)
+	}
+	for artName, artifact := range node.Artifacts {
+		// Artifacts paths are relative to the substituting topology
+		artifact.File = path.Join(importPath, artifact.File)
+		expanded.Artifacts[artName] = artifact
+	}
+	return expanded, nil
+}
+
+// assignRequirement sets the given requirement assignment on the first requirement with this name that doesn't
+// target a node yet or adds it to the requirements list
+func assignRequirement(requirements []tosca.RequirementAssignmentMap, reqName string, req tosca.RequirementAssignment) []tosca.RequirementAssignmentMap {
+	for _, reqMap := range requirements {
+		if existing, ok := reqMap[reqName]; ok && existing.Node == "" {
+			if req.Capability == "" {
+				req.Capability = existing.Capability
+			}
+			if req.Relationship == "" {
+				req.Relationship = existing.Relationship
+			}
+			reqMap[reqName] = req
+			return requirements
+		}
+	}
+	return append(requirements, tosca.RequirementAssignmentMap{reqName: req})
+}
+
+// rewriteValueAssignment returns a copy of a value assignment where node names used in TOSCA functions are renamed
+// and get_input functions are replaced by the given inputs values.
+//
+// If inputs is nil, get_input functions are kept as is.
+func rewriteValueAssignment(va *tosca.ValueAssignment, nodes map[string]string, inputs map[string]*tosca.ValueAssignment) (*tosca.ValueAssignment, error) {
+	if va == nil || va.GetFunction() == nil {
+		return va, nil
+	}
+	f := va.GetFunction()
+	if f.Operator == tosca.GetInputOperator && inputs != nil {
+		return getSubstitutionInputValue(f, inputs)
+	}
+	rewritten, err := rewriteFunction(f, nodes, inputs)
+	if err != nil {
+		return nil, err
+	}
+	return &tosca.ValueAssignment{Type: tosca.ValueAssignmentFunction, Value: rewritten}, nil
+}
+
+func rewriteFunction(f *tosca.Function, nodes map[string]string, inputs map[string]*tosca.ValueAssignment) (*tosca.Function, error) {
+	result := &tosca.Function{Operator: f.Operator, Operands: make([]tosca.Operand, len(f.Operands))}
+	for i, op := range f.Operands {
+		var err error
+		if result.Operands[i], err = rewriteOperand(op, nodes, inputs); err != nil {
+			return nil, err
+		}
+	}
+	switch f.Operator {
+	case tosca.GetPropertyOperator, tosca.GetAttributeOperator, tosca.GetOperationOutputOperator, tosca.GetArtifactOperator:
+		if len(result.Operands) > 0 && result.Operands[0].IsLiteral() {
+			if target, ok := nodes[string(result.Operands[0].(tosca.LiteralOperand))]; ok {
+				result.Operands[0] = tosca.LiteralOperand(target)
+			}
+		}
+	}
+	return result, nil
+}
+
+func rewriteOperand(op tosca.Operand, nodes map[string]string, inputs map[string]*tosca.ValueAssignment) (tosca.Operand, error) {
+	switch v := op.(type) {
+	case *tosca.Function:
+		if v.Operator != tosca.GetInputOperator || inputs == nil {
+			return rewriteFunction(v, nodes, inputs)
+		}
+		value, err := getSubstitutionInputValue(v, inputs)
+		if err != nil {
+			return nil, err
+		}
+		switch value.Type {
+		case tosca.ValueAssignmentFunction:
+			return value.GetFunction(), nil
+		case tosca.ValueAssignmentLiteral:
+			return tosca.LiteralOperand(fmt.Sprint(value.Value)), nil
+		default:
+			return nil, errors.Errorf("%s value of input %q can't be used in function %q", value.Type, v.Operands[0], v)
+		}
+	case tosca.ListOperand:
+		result := make(tosca.ListOperand, len(v))
+		for i, subOp := range v {
+			var err error
+			if result[i], err = rewriteOperand(subOp, nodes, inputs); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+	return op, nil
+}
+
+func getSubstitutionInputValue(f *tosca.Function, inputs map[string]*tosca.ValueAssignment) (*tosca.ValueAssignment, error) {
+	if len(f.Operands) != 1 || !f.Operands[0].IsLiteral() {
+		return nil, errors.Errorf("unsupported function %q in a substituting topology", f)
+	}
+	inputName := string(f.Operands[0].(tosca.LiteralOperand))
+	value, ok := inputs[inputName]
+	if !ok {
+		return nil, errors.Errorf("input %q has no value", inputName)
+	}
+	return value, nil
+}
+
+// getNodeTypePropertyDefault returns the default value of a property in a node type hierarchy or nil if there is none
+func getNodeTypePropertyDefault(nodeTypes map[string]tosca.NodeType, typeName, propName string) *tosca.ValueAssignment {
+	for typeName != "" {
+		nodeType, ok := nodeTypes[typeName]
+		if !ok {
+			return nil
+		}
+		if propDef, ok := nodeType.Properties[propName]; ok {
+			return propDef.Default
+		}
+		typeName = nodeType.DerivedFrom
+	}
+	return nil
+}
+
+// getNodeTypeCapabilityType returns the type of a capability in a node type hierarchy
+func getNodeTypeCapabilityType(nodeTypes map[string]tosca.NodeType, typeName, capName string) string {
+	for typeName != "" {
+		nodeType, ok := nodeTypes[typeName]
+		if !ok {
+			return ""
+		}
+		if capDef, ok := nodeType.Capabilities[capName]; ok {
+			return capDef.Type
+		}
+		typeName = nodeType.DerivedFrom
+	}
+	return ""
+}
+
+// redirectRequirements makes requirements targeting the abstract node target the node templates exposing
+// its mapped capabilities
+func (s *substitution) redirectRequirements(tt *tosca.TopologyTemplate, nodeTypes map[string]tosca.NodeType) error {
+	for _, nodeName := range sortedNodeTemplatesNames(tt.NodeTemplates) {
+		node := tt.NodeTemplates[nodeName]
+		for _, reqMap := range node.Requirements {
+			for reqName, req := range reqMap {
+				if req.Node != s.nodeName {
+					continue
+				}
+				capName, err := s.findCapabilityMapping(req.Capability, nodeTypes)
+				if err != nil {
+					return errors.Wrapf(err, "can't redirect requirement %q of node %q", reqName, nodeName)
+				}
+				target := s.capabilities[capName]
+				req.Node = target[0]
+				if req.Capability == capName {
+					req.Capability = target[1]
+				}
+				reqMap[reqName] = req
+			}
+		}
+	}
+	return nil
+}
+
+// findCapabilityMapping returns the name of the mapped capability matching a requirement capability which may be
+// either a capability name or a capability type
+func (s *substitution) findCapabilityMapping(capability string, nodeTypes map[string]tosca.NodeType) (string, error) {
+	if _, ok := s.capabilities[capability]; ok {
+		return capability, nil
+	}
+	var candidates []string
+	for capName := range s.capabilities {
+		if capability == "" || getNodeTypeCapabilityType(nodeTypes, s.nodeType, capName) == capability {
+			candidates = append(candidates, capName)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return "", errors.Errorf("no capability of substituted node %q matches %q", s.nodeName, capability)
+	case 1:
+		return candidates[0], nil
+	default:
+		sort.Strings(candidates)
+		return "", errors.Errorf("several capabilities of substituted node %q match %q: %v", s.nodeName, capability, candidates)
+	}
+}
+
+// expandWorkflowSteps inserts the steps operating the expanded node templates before the steps targeting
+// the abstract node.
+//
+// Steps of the abstract node keep only their set_state and inline activities so the abstract node state reflects
+// the one of its substituting topology. Relationship operations of its requirements are moved to the node templates
+// the requirements are mapped to.
+func (s *substitution) expandWorkflowSteps(wfName string, steps map[string]tosca.Step) (map[string]tosca.Step, error) {
+	result := make(map[string]tosca.Step, len(steps))
+	block := make(map[string]bool)
+	for stepName, step := range steps {
+		if step.Target == s.nodeName {
+			if step.TargetRelationShip == "" {
+				block[stepName] = true
+			} else {
+				target, ok := s.requirements[step.TargetRelationShip]
+				if !ok {
+					return nil, errors.Errorf("step %q of workflow %q targets requirement %q of substituted node %q that is not mapped", stepName, wfName, step.TargetRelationShip, s.nodeName)
+				}
+				step.Target, step.TargetRelationShip = target[0], target[1]
+			}
+		}
+		result[stepName] = step
+	}
+	if len(block) == 0 {
+		return result, nil
+	}
+
+	subSteps, err := s.workflowSteps(wfName)
+	if err != nil {
+		return nil, err
+	}
+	if len(subSteps) > 0 {
+		for stepName := range subSteps {
+			if _, exists := result[stepName]; exists {
+				return nil, errors.Errorf("can't expand workflow %q for substituted node %q: a step named %q already exists", wfName, s.nodeName, stepName)
+			}
+		}
+		blockEntries := stepsWithoutPredecessors(result, block)
+		subEntries := stepsWithoutPredecessors(subSteps, nil)
+		for stepName, step := range result {
+			if block[stepName] {
+				continue
+			}
+			step.OnSuccess = replaceLinks(step.OnSuccess, blockEntries, subEntries)
+			step.OnFailure = replaceLinks(step.OnFailure, blockEntries, subEntries)
+			result[stepName] = step
+		}
+		for stepName, step := range subSteps {
+			if len(step.OnSuccess) == 0 {
+				step.OnSuccess = blockEntries
+			}
+			result[stepName] = step
+		}
+	}
+
+	for stepName := range block {
+		step := result[stepName]
+		activities := make([]tosca.Activity, 0, len(step.Activities))
+		for _, activity := range step.Activities {
+			if activity.SetState != "" || activity.Inline != "" {
+				activities = append(activities, activity)
+			}
+		}
+		if len(activities) == 0 {
+			removeStep(result, stepName)
+			continue
+		}
+		step.Activities = activities
+		result[stepName] = step
+	}
+	return result, nil
+}
+
+// workflowSteps returns the steps operating the expanded node templates in the given workflow.
+//
+// Steps of the workflow with the same name in the substituting topology are used if any and are named
+// <abstract node name>_<step name>. Otherwise install and uninstall workflows steps are generated.
+func (s *substitution) workflowSteps(wfName string) (map[string]tosca.Step, error) {
+	wf, ok := s.topology.TopologyTemplate.Workflows[wfName]
+	if !ok {
+		return s.generateWorkflowSteps(wfName), nil
+	}
+	steps := make(map[string]tosca.Step, len(wf.Steps))
+	rename := func(links []string) []string {
+		if links == nil {
+			return nil
+		}
+		result := make([]string, len(links))
+		for i, link := range links {
+			result[i] = s.nodeName + "_" + link
+		}
+		return result
+	}
+	for stepName, step := range wf.Steps {
+		if step.Target != "" {
+			target, ok := s.nodes[step.Target]
+			if !ok {
+				return nil, errors.Errorf("step %q of workflow %q of the topology substituting node %q targets unknown node %q", stepName, wfName, s.nodeName, step.Target)
+			}
+			step.Target = target
+		}
+		step.OnSuccess = rename(step.OnSuccess)
+		step.OnFailure = rename(step.OnFailure)
+		steps[s.nodeName+"_"+stepName] = step
+	}
+	return steps, nil
+}
+
+// generateWorkflowSteps generates one step per expanded node for install and uninstall workflows.
+//
+// A node is installed after the nodes it depends on and uninstalled before them.
+func (s *substitution) generateWorkflowSteps(wfName string) map[string]tosca.Step {
+	if wfName != "install" && wfName != "uninstall" {
+		return nil
+	}
+	innerNodes := s.topology.TopologyTemplate.NodeTemplates
+	steps := make(map[string]tosca.Step, len(s.nodes))
+	for innerName, nodeName := range s.nodes {
+		var activities []tosca.Activity
+		if _, err := reg.GetDelegateExecutor(innerNodes[innerName].Type); err == nil {
+			activities = []tosca.Activity{{Delegate: wfName}}
+		} else if wfName == "install" {
+			activities = []tosca.Activity{
+				{SetState: "creating"},
+				{CallOperation: "tosca.interfaces.node.lifecycle.Standard.create"},
+				{SetState: "created"},
+				{SetState: "configuring"},
+				{CallOperation: "tosca.interfaces.node.lifecycle.Standard.configure"},
+				{SetState: "configured"},
+				{SetState: "starting"},
+				{CallOperation: "tosca.interfaces.node.lifecycle.Standard.start"},
+				{SetState: "started"},
+			}
+		} else {
+			activities = []tosca.Activity{
+				{SetState: "stopping"},
+				{CallOperation: "tosca.interfaces.node.lifecycle.Standard.stop"},
+				{SetState: "stopped"},
+				{SetState: "deleting"},
+				{CallOperation: "tosca.interfaces.node.lifecycle.Standard.delete"},
+				{SetState: "deleted"},
+			}
+		}
+		steps[nodeName+"_"+wfName] = tosca.Step{Target: nodeName, Activities: activities}
+	}
+	for innerName, innerNode := range innerNodes {
+		for _, reqMap := range innerNode.Requirements {
+			for _, req := range reqMap {
+				target, ok := s.nodes[req.Node]
+				if !ok || req.Node == innerName {
+					continue
+				}
+				from, to := target+"_"+wfName, s.nodes[innerName]+"_"+wfName
+				if wfName == "uninstall" {
+					from, to = to, from
+				}
+				step := steps[from]
+				if !collections.ContainsString(step.OnSuccess, to) {
+					step.OnSuccess = append(step.OnSuccess, to)
+					sort.Strings(step.OnSuccess)
+				}
+				steps[from] = step
+			}
+		}
+	}
+	return steps
+}
+
+// stepsWithoutPredecessors returns the sorted names of the steps of a set that are not linked from another step
+// of this set. A nil set means all steps.
+func stepsWithoutPredecessors(steps map[string]tosca.Step, set map[string]bool) []string {
+	hasPredecessor := make(map[string]bool)
+	for stepName, step := range steps {
+		if set != nil && !set[stepName] {
+			continue
+		}
+		for _, next := range step.OnSuccess {
+			hasPredecessor[next] = true
+		}
+		for _, next := range step.OnFailure {
+			hasPredecessor[next] = true
+		}
+	}
+	var result []string
+	for stepName := range steps {
+		if (set == nil || set[stepName]) && !hasPredecessor[stepName] {
+			result = append(result, stepName)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// replaceLinks replaces in links the ones to the given steps by links to the replacement steps
+func replaceLinks(links, replaced, replacements []string) []string {
+	if links == nil {
+		return nil
+	}
+	result := make([]string, 0, len(links))
+	for _, link := range links {
+		if !collections.ContainsString(replaced, link) {
+			if !collections.ContainsString(result, link) {
+				result = append(result, link)
+			}
+			continue
+		}
+		for _, r := range replacements {
+			if !collections.ContainsString(result, r) {
+				result = append(result, r)
+			}
+		}
+	}
+	return result
+}
+
+// removeStep removes a step from a workflow, the steps linked to it are linked to its successors instead
+func removeStep(steps map[string]tosca.Step, stepName string) {
+	removed := steps[stepName]
+	delete(steps, stepName)
+	for name, step := range steps {
+		step.OnSuccess = replaceLinks(step.OnSuccess, []string{stepName}, removed.OnSuccess)
+		step.OnFailure = replaceLinks(step.OnFailure, []string{stepName}, removed.OnSuccess)
+		steps[name] = step
+	}
+}
+
+func sortedNodeTemplatesNames(nodeTemplates map[string]tosca.NodeTemplate) []string {
+	names := make([]string, 0, len(nodeTemplates))
+	for name := range nodeTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedStringsMapValues(m map[string]string) []string {
+	values := make([]string, 0, len(m))
+	for _, v := range m {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+	return values
+}
+
+// storeSubstitutions stores for each substituted node the mapping of its attributes
+func storeSubstitutions(ctx context.Context, substitutions []*substitution, topologyPrefix string) {
+	consulStore := ctx.Value(consulStoreKey).(consulutil.ConsulStore)
+	for _, sub := range substitutions {
+		substitutionPrefix := path.Join(topologyPrefix, "nodes", sub.nodeName, "substitution")
+		for _, nodeName := range sortedStringsMapValues(sub.nodes) {
+			consulStore.StoreConsulKeyAsString(path.Join(substitutionPrefix, "nodes", nodeName), "")
+		}
+		for attrName, target := range sub.attributes {
+			attrPrefix := path.Join(substitutionPrefix, "attributes", attrName)
+			consulStore.StoreConsulKeyAsString(path.Join(attrPrefix, "node"), target[0])
+			consulStore.StoreConsulKeyAsString(path.Join(attrPrefix, "attribute"), target[1])
+		}
+	}
+}
+
+// GetSubstitutionNodes returns the names of the nodes in which a substituted node has been expanded
+//
+// An empty list is returned if the node is not substituted.
+func GetSubstitutionNodes(kv *api.KV, deploymentID, nodeName string) ([]string, error) {
+	substitutionPrefix := path.Join(deploymentKVPrefix(deploymentID), "topology", "nodes", nodeName, "substitution", "nodes")
+	keys, _, err := kv.Keys(substitutionPrefix+"/", "/", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	nodes := make([]string, len(keys))
+	for i, key := range keys {
+		nodes[i] = path.Base(key)
+	}
+	return nodes, nil
+}
+
+// getSubstitutionAttributes returns the names of the attributes mapped by a substituted node
+func getSubstitutionAttributes(kv *api.KV, deploymentID, nodeName string) ([]string, error) {
+	attributesPrefix := path.Join(deploymentKVPrefix(deploymentID), "topology", "nodes", nodeName, "substitution", "attributes")
+	keys, _, err := kv.Keys(attributesPrefix+"/", "/", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	attributes := make([]string, len(keys))
+	for i, key := range keys {
+		attributes[i] = path.Base(key)
+	}
+	return attributes, nil
+}
+
+// getSubstitutionAttributeInstance returns the node instance and the attribute to which an attribute
+// of a substituted node instance is mapped.
+//
+// The instance of the mapped node with the same name is used if it exists, its first instance otherwise.
+func getSubstitutionAttributeInstance(kv *api.KV, deploymentID, nodeName, instanceName, attributeName string) (bool, string, string, string, error) {
+	attrPrefix := path.Join(deploymentKVPrefix(deploymentID), "topology", "nodes", nodeName, "substitution", "attributes", attributeName)
+	kvp, _, err := kv.Get(path.Join(attrPrefix, "node"), nil)
+	if err != nil {
+		return false, "", "", "", errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp == nil || len(kvp.Value) == 0 {
+		return false, "", "", "", nil
+	}
+	targetNode := string(kvp.Value)
+	kvp, _, err = kv.Get(path.Join(attrPrefix, "attribute"), nil)
+	if err != nil {
+		return false, "", "", "", errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp == nil || len(kvp.Value) == 0 {
+		return false, "", "", "", errors.Errorf("missing mapped attribute for attribute %q of substituted node %q", attributeName, nodeName)
+	}
+	targetAttribute := string(kvp.Value)
+	instances, err := GetNodeInstancesIds(kv, deploymentID, targetNode)
+	if err != nil {
+		return false, "", "", "", err
+	}
+	targetInstance := instanceName
+	if len(instances) > 0 && !collections.ContainsString(instances, instanceName) {
+		sort.Strings(instances)
+		targetInstance = instances[0]
+	}
+	return true, targetNode, targetInstance, targetAttribute, nil
+}
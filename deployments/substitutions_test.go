@@ -0,0 +1,159 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/testutil"
+	"github.com/ystia/yorc/tosca"
+)
+
+func TestExpandSubstitutions(t *testing.T) {
+	t.Parallel()
+	topology, err := readTopologyDefinition("testdata/substitution.yaml")
+	require.NoError(t, err)
+	substitutions, err := expandSubstitutions(&topology, "testdata")
+	require.NoError(t, err)
+	require.Len(t, substitutions, 1)
+	assert.Equal(t, "App", substitutions[0].nodeName)
+	assert.Equal(t, map[string][2]string{"url": {"App_Server", "url"}}, substitutions[0].attributes)
+
+	nodes := topology.TopologyTemplate.NodeTemplates
+	assert.Equal(t, []string{"App", "App_Compute", "App_Server", "Client", "DB"}, sortedNodeTemplatesNames(nodes))
+
+	app := nodes["App"]
+	assert.Equal(t, "yorc.tests.nodes.WebApp", app.Type)
+	assert.Len(t, app.Requirements, 0)
+	assert.Equal(t, "/shop", app.Properties["context_root"].String())
+
+	server := nodes["App_Server"]
+	assert.Equal(t, "9090", server.Properties["port"].String())
+	assert.Equal(t, "/shop", server.Properties["context_root"].String())
+	assert.Equal(t, "9090", server.Capabilities["endpoint"].Properties["port"].String())
+	assert.Equal(t, `concat: ["http://", get_attribute: [App_Compute, public_address], ":", 9090]`, server.Attributes["url"].String())
+	require.Len(t, server.Requirements, 2)
+	assert.Equal(t, "App_Compute", server.Requirements[0]["host"].Node)
+	assert.Equal(t, "DB", server.Requirements[1]["database"].Node)
+	assert.Equal(t, "tosca.relationships.ConnectsTo", server.Requirements[1]["database"].Relationship)
+
+	client := nodes["Client"]
+	assert.Equal(t, "App_Server", client.Requirements[0]["app"].Node)
+	assert.Equal(t, "tosca.capabilities.Endpoint", client.Requirements[0]["app"].Capability)
+
+	steps := topology.TopologyTemplate.Workflows["install"].Steps
+	assert.NotContains(t, steps, "App_create")
+	assert.Equal(t, []string{"App_Compute_install"}, steps["DB_started"].OnSuccess)
+	assert.Equal(t, []string{"App_Server_install"}, steps["App_Compute_install"].OnSuccess)
+	assert.Equal(t, []string{"App_initial"}, steps["App_Server_install"].OnSuccess)
+	assert.Equal(t, []string{"App_started"}, steps["App_initial"].OnSuccess)
+	assert.Equal(t, "App_Server", steps["App_Server_install"].Target)
+	assert.Contains(t, steps["App_Server_install"].Activities, tosca.Activity{CallOperation: "tosca.interfaces.node.lifecycle.Standard.start"})
+}
+
+func TestExpandNestedSubstitutions(t *testing.T) {
+	t.Parallel()
+	topology, err := readTopologyDefinition("testdata/substitution_nested.yaml")
+	require.NoError(t, err)
+	substitutions, err := expandSubstitutions(&topology, "testdata")
+	require.NoError(t, err)
+	require.Len(t, substitutions, 2)
+	assert.Equal(t, "MyShop", substitutions[0].nodeName)
+	assert.Equal(t, "MyShop_Front", substitutions[1].nodeName)
+
+	nodes := topology.TopologyTemplate.NodeTemplates
+	assert.Equal(t, []string{"MyShop", "MyShop_DB", "MyShop_Front", "MyShop_Front_Compute", "MyShop_Front_Server"}, sortedNodeTemplatesNames(nodes))
+	server := nodes["MyShop_Front_Server"]
+	// port is not set on the abstract node so the default value of its type is used
+	assert.Equal(t, "8080", server.Properties["port"].String())
+	assert.Equal(t, "/front", server.Properties["context_root"].String())
+	assert.Equal(t, "MyShop_DB", server.Requirements[1]["database"].Node)
+
+	steps := topology.TopologyTemplate.Workflows["install"].Steps
+	assert.Equal(t, []string{"MyShop_Front_Server_install"}, steps["MyShop_Front_Compute_install"].OnSuccess)
+	assert.Equal(t, []string{"MyShop_Front_started"}, steps["MyShop_Front_Server_install"].OnSuccess)
+	assert.Equal(t, []string{"MyShop_started"}, steps["MyShop_Front_started"].OnSuccess)
+}
+
+func TestExpandSubstitutionsErrors(t *testing.T) {
+	t.Parallel()
+	topology, err := readTopologyDefinition("testdata/substitution.yaml")
+	require.NoError(t, err)
+	client := topology.TopologyTemplate.NodeTemplates["Client"]
+	client.Requirements[0]["app"] = tosca.RequirementAssignment{Node: "App", Capability: "tosca.capabilities.Compute"}
+	_, err = expandSubstitutions(&topology, "testdata")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `no capability of substituted node "App" matches "tosca.capabilities.Compute"`)
+
+	topology, err = readTopologyDefinition("testdata/substitution.yaml")
+	require.NoError(t, err)
+	app := topology.TopologyTemplate.NodeTemplates["App"]
+	app.Requirements = append(app.Requirements, tosca.RequirementAssignmentMap{"host": tosca.RequirementAssignment{Node: "DB"}})
+	topology.TopologyTemplate.NodeTemplates["App"] = app
+	_, err = expandSubstitutions(&topology, "testdata")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `requirement "host" of node "App" is not mapped`)
+}
+
+func testSubstitutions(t *testing.T, kv *api.KV) {
+	t.Parallel()
+	deploymentID := testutil.BuildDeploymentID(t)
+	err := StoreDeploymentDefinition(context.Background(), kv, deploymentID, "testdata/substitution.yaml")
+	require.NoError(t, err)
+
+	nodes, err := GetNodes(kv, deploymentID)
+	require.NoError(t, err)
+	sort.Strings(nodes)
+	assert.Equal(t, []string{"App", "App_Compute", "App_Server", "Client", "DB"}, nodes)
+
+	substitutionNodes, err := GetSubstitutionNodes(kv, deploymentID, "App")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"App_Compute", "App_Server"}, substitutionNodes)
+	substitutionNodes, err = GetSubstitutionNodes(kv, deploymentID, "DB")
+	require.NoError(t, err)
+	assert.Len(t, substitutionNodes, 0)
+
+	found, value, err := GetNodeProperty(kv, deploymentID, "App_Server", "port")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "9090", value)
+
+	target, err := GetTargetNodeForRequirement(kv, deploymentID, "Client", "0")
+	require.NoError(t, err)
+	assert.Equal(t, "App_Server", target)
+
+	err = SetInstanceAttribute(deploymentID, "App_Server", "0", "url", "http://10.0.0.1:9090/shop")
+	require.NoError(t, err)
+	found, value, err = GetInstanceAttribute(kv, deploymentID, "App", "0", "url")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "http://10.0.0.1:9090/shop", value)
+
+	attributes, err := GetNodeAttributesNames(kv, deploymentID, "App")
+	require.NoError(t, err)
+	assert.Contains(t, attributes, "url")
+
+	wf, err := ReadWorkflow(kv, deploymentID, "install")
+	require.NoError(t, err)
+	require.Contains(t, wf.Steps, "App_Server_install")
+	assert.Equal(t, "App_Server", wf.Steps["App_Server_install"].Target)
+	assert.NotContains(t, wf.Steps, "App_create")
+}
@@ -28,7 +28,7 @@ func GetTopologyOutput(kv *api.KV, deploymentID, outputName string, nestedKeys .
 	if err != nil {
 		return false, "", err
 	}
-	valuePath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/outputs", outputName, "value")
+	valuePath := path.Join(deploymentKVPrefix(deploymentID), "topology/outputs", outputName, "value")
 	// TODO this is not clear in the specification but why do we return a single value in this context as in case of attributes and multi-instances
 	// we can have different results.
 	// We have to improve this.
@@ -37,13 +37,13 @@ func GetTopologyOutput(kv *api.KV, deploymentID, outputName string, nestedKeys .
 		return found, res, err
 	}
 	// check the default
-	defaultPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/outputs", outputName, "default")
+	defaultPath := path.Join(deploymentKVPrefix(deploymentID), "topology/outputs", outputName, "default")
 	return getValueAssignmentWithDataType(kv, deploymentID, defaultPath, "", "0", "", dataType, nestedKeys...)
 }
 
 // GetTopologyOutputsNames returns the list of outputs for the deployment
 func GetTopologyOutputsNames(kv *api.KV, deploymentID string) ([]string, error) {
-	optPaths, _, err := kv.Keys(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "/topology/outputs")+"/", "/", nil)
+	optPaths, _, err := kv.Keys(path.Join(deploymentKVPrefix(deploymentID), "/topology/outputs")+"/", "/", nil)
 	if err != nil {
 		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
 	}
@@ -0,0 +1,183 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"path"
+	"strings"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/helper/consulutil"
+)
+
+// topologyTemplatesKind describes a kind of templates defined at the topology level and applied to nodes like groups
+// or policies
+type topologyTemplatesKind struct {
+	// name of the kind used in error messages
+	name string
+	// path of the templates relative to the topology
+	path string
+	// key of the nodes the templates apply to in templates definitions as well as the allowed types of those nodes
+	// in templates types definitions
+	nodesKey string
+}
+
+var groupsKind = topologyTemplatesKind{name: "group", path: "groups", nodesKey: "members"}
+var policiesKind = topologyTemplatesKind{name: "policy", path: "policies", nodesKey: "targets"}
+
+func (k topologyTemplatesKind) templatePath(deploymentID, templateName string) string {
+	return path.Join(deploymentKVPrefix(deploymentID), "topology", k.path, templateName)
+}
+
+// getTemplates returns the names of the templates of this kind defined in the topology of a given deployment
+func (k topologyTemplatesKind) getTemplates(kv *api.KV, deploymentID string) ([]string, error) {
+	templatePaths, _, err := kv.Keys(k.templatePath(deploymentID, "")+"/", "/", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	names := make([]string, len(templatePaths))
+	for i := range templatePaths {
+		names[i] = path.Base(templatePaths[i])
+	}
+	return names, nil
+}
+
+// doesTemplateExist checks if a given template exists in the topology of a given deployment
+func (k topologyTemplatesKind) doesTemplateExist(kv *api.KV, deploymentID, templateName string) (bool, error) {
+	kvp, _, err := kv.Get(path.Join(k.templatePath(deploymentID, templateName), "name"), nil)
+	if err != nil {
+		return false, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	return kvp != nil, nil
+}
+
+// getTemplateType returns the type of a given template
+func (k topologyTemplatesKind) getTemplateType(kv *api.KV, deploymentID, templateName string) (string, error) {
+	kvp, _, err := kv.Get(path.Join(k.templatePath(deploymentID, templateName), "type"), nil)
+	if err != nil {
+		return "", errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp == nil || len(kvp.Value) == 0 {
+		return "", errors.Errorf("Missing mandatory parameter \"type\" for %s %q", k.name, templateName)
+	}
+	return string(kvp.Value), nil
+}
+
+// getTemplateDescription returns the description of a given template
+func (k topologyTemplatesKind) getTemplateDescription(kv *api.KV, deploymentID, templateName string) (string, error) {
+	kvp, _, err := kv.Get(path.Join(k.templatePath(deploymentID, templateName), "description"), nil)
+	if err != nil || kvp == nil {
+		return "", errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	return string(kvp.Value), nil
+}
+
+// getTemplateNodes returns the names of the nodes (or groups for policies) a given template applies to
+func (k topologyTemplatesKind) getTemplateNodes(kv *api.KV, deploymentID, templateName string) ([]string, error) {
+	kvp, _, err := kv.Get(path.Join(k.templatePath(deploymentID, templateName), k.nodesKey), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp == nil || len(kvp.Value) == 0 {
+		return nil, nil
+	}
+	return strings.Split(string(kvp.Value), ","), nil
+}
+
+// getTemplatePropertiesNames returns the names of the properties defined for a given template
+// either in the template itself or in its type hierarchy
+func (k topologyTemplatesKind) getTemplatePropertiesNames(kv *api.KV, deploymentID, templateName string) ([]string, error) {
+	templateType, err := k.getTemplateType(kv, deploymentID, templateName)
+	if err != nil {
+		return nil, err
+	}
+	propertiesSet := make(map[string]struct{})
+	err = storeSubKeysInSet(kv, path.Join(k.templatePath(deploymentID, templateName), "properties"), propertiesSet)
+	if err != nil {
+		return nil, err
+	}
+	typeProps, err := GetTypeProperties(kv, deploymentID, templateType, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, prop := range typeProps {
+		propertiesSet[prop] = struct{}{}
+	}
+	props := make([]string, 0, len(propertiesSet))
+	for prop := range propertiesSet {
+		props = append(props, prop)
+	}
+	return props, nil
+}
+
+// getTemplateProperty retrieves the value for a given property in a given template
+//
+// It returns true if a value is found false otherwise as first return parameter.
+// If the property is not found in the template then the type hierarchy is explored to find a default value.
+func (k topologyTemplatesKind) getTemplateProperty(kv *api.KV, deploymentID, templateName, propertyName string, nestedKeys ...string) (bool, string, error) {
+	templateType, err := k.getTemplateType(kv, deploymentID, templateName)
+	if err != nil {
+		return false, "", err
+	}
+	var propDataType string
+	hasProp, err := TypeHasProperty(kv, deploymentID, templateType, propertyName, true)
+	if err != nil {
+		return false, "", err
+	}
+	if hasProp {
+		propDataType, err = GetTypePropertyDataType(kv, deploymentID, templateType, propertyName)
+		if err != nil {
+			return false, "", err
+		}
+	}
+	found, result, err := getValueAssignmentWithDataType(kv, deploymentID, path.Join(k.templatePath(deploymentID, templateName), "properties", propertyName), "", "", "", propDataType, nestedKeys...)
+	if err != nil {
+		return false, "", errors.Wrapf(err, "Failed to get property %q for %s %q", propertyName, k.name, templateName)
+	}
+	if found {
+		return true, result, nil
+	}
+
+	ok, value, isFunction, err := getTypeDefaultProperty(kv, deploymentID, templateType, propertyName, nestedKeys...)
+	if err != nil || !ok {
+		return false, "", err
+	}
+	if !isFunction {
+		return true, value, nil
+	}
+	value, err = resolveValueAssignmentAsString(kv, deploymentID, "", "", "", value, nestedKeys...)
+	return true, value, err
+}
+
+// getTypeAllowedNodesTypes returns the types of the nodes (or groups for policies) allowed by a given template type.
+//
+// If they are not defined in the given type then its type hierarchy is explored.
+// An empty result means that there is no restriction.
+func (k topologyTemplatesKind) getTypeAllowedNodesTypes(kv *api.KV, deploymentID, typeName string) ([]string, error) {
+	kvp, _, err := kv.Get(path.Join(deploymentKVPrefix(deploymentID), "topology/types", typeName, k.nodesKey), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp != nil && len(kvp.Value) != 0 {
+		return strings.Split(string(kvp.Value), ","), nil
+	}
+	parent, err := GetParentType(kv, deploymentID, typeName)
+	if err != nil || parent == "" {
+		return nil, err
+	}
+	return k.getTypeAllowedNodesTypes(kv, deploymentID, parent)
+}
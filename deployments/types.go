@@ -50,7 +50,7 @@ func GetParentType(kv *api.KV, deploymentID, typeName string) (string, error) {
 	if tosca.IsBuiltinType(typeName) {
 		return "", nil
 	}
-	typePath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/types", typeName)
+	typePath := path.Join(deploymentKVPrefix(deploymentID), "topology/types", typeName)
 	// Check if node type exist
 	if kvps, _, err := kv.List(typePath+"/", nil); err != nil {
 		return "", errors.Wrap(err, "Consul access error: ")
@@ -83,7 +83,7 @@ func IsTypeDerivedFrom(kv *api.KV, deploymentID, nodeType, derives string) (bool
 // GetTypes returns the names of the different types for a given deployment.
 func GetTypes(kv *api.KV, deploymentID string) ([]string, error) {
 	names := make([]string, 0)
-	types, _, err := kv.Keys(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/types")+"/", "/", nil)
+	types, _, err := kv.Keys(path.Join(deploymentKVPrefix(deploymentID), "topology/types")+"/", "/", nil)
 	if err != nil {
 		return names, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
 	}
@@ -108,7 +108,7 @@ func GetTypeAttributes(kv *api.KV, deploymentID, typeName string, exploreParents
 }
 
 func getTypeAttributesOrProperties(kv *api.KV, deploymentID, typeName, paramType string, exploreParents bool) ([]string, error) {
-	result, _, err := kv.Keys(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/types", typeName, paramType)+"/", "/", nil)
+	result, _, err := kv.Keys(path.Join(deploymentKVPrefix(deploymentID), "topology/types", typeName, paramType)+"/", "/", nil)
 	if err != nil {
 		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
 	}
@@ -189,7 +189,7 @@ func getTypeDefaultAttributeOrProperty(kv *api.KV, deploymentID, typeName, prope
 		return false, "", false, err
 	}
 	if hasProp {
-		typePath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "types", typeName)
+		typePath := path.Join(deploymentKVPrefix(deploymentID), "topology", "types", typeName)
 		var t string
 		if isProperty {
 			t = "properties"
@@ -267,7 +267,7 @@ func isTypePropOrAttrRequired(kv *api.KV, deploymentID, typeName, originalTypeNa
 	} else {
 		t = "attributes"
 	}
-	reqPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "types", typeName, t, elemName, "required")
+	reqPath := path.Join(deploymentKVPrefix(deploymentID), "topology", "types", typeName, t, elemName, "required")
 	kvp, _, err := kv.Get(reqPath, nil)
 	if err != nil {
 		return false, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
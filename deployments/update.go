@@ -0,0 +1,508 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/events"
+	"github.com/ystia/yorc/helper/collections"
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/tosca"
+)
+
+// UpdateUninstallWorkflowName is the name of the workflow generated on a deployment update to uninstall removed nodes
+const UpdateUninstallWorkflowName = "yorc_update_uninstall"
+
+// UpdateConfigureWorkflowName is the name of the workflow generated on a deployment update to configure again modified nodes
+const UpdateConfigureWorkflowName = "yorc_update_configure"
+
+// TopologyUpdate describes the differences between the stored definition of a deployment and an updated one
+//
+// AddedRelationships and RemovedRelationships are informative: no relationship operation is called for them when
+// the update is applied, the source node of a relationship added or removed between kept nodes is in ModifiedNodes.
+type TopologyUpdate struct {
+	AddedNodes           []string               `json:"added_nodes"`
+	RemovedNodes         []string               `json:"removed_nodes"`
+	ModifiedNodes        []string               `json:"modified_nodes"`
+	AddedRelationships   []TopologyRelationship `json:"added_relationships"`
+	RemovedRelationships []TopologyRelationship `json:"removed_relationships"`
+}
+
+// TopologyRelationship identifies a relationship between two nodes of a topology
+type TopologyRelationship struct {
+	Source      string `json:"source"`
+	Requirement string `json:"requirement"`
+	Target      string `json:"target"`
+	Type        string `json:"type,omitempty"`
+}
+
+func (r TopologyRelationship) String() string {
+	return fmt.Sprintf("%s.%s -> %s", r.Source, r.Requirement, r.Target)
+}
+
+// IsEmpty checks if this update doesn't change anything in the topology
+func (u *TopologyUpdate) IsEmpty() bool {
+	return len(u.AddedNodes) == 0 && len(u.RemovedNodes) == 0 && len(u.ModifiedNodes) == 0 &&
+		len(u.AddedRelationships) == 0 && len(u.RemovedRelationships) == 0
+}
+
+// maxTxnOps is the maximum number of operations accepted by Consul in a single transaction
+const maxTxnOps = 64
+
+// UpdateDeploymentDefinition takes a defPath and parse it as a tosca.Topology then it replaces the definition stored
+// in consul for an existing deployment.
+//
+// The new definition is first stored and checked under a throwaway deployment id so an invalid definition leaves the
+// deployment untouched. It is then applied to the deployment using Consul transactions.
+// Instances and attributes of nodes that exist in both definitions are kept as well as relationships instances of
+// those nodes which target didn't change. Definitions of nodes that are removed by the update are kept until they are
+// uninstalled. The computed differences between both definitions are returned and the UpdateUninstallWorkflowName and
+// UpdateConfigureWorkflowName workflows are generated accordingly.
+// The deployment status is not modified unless the new definition could not be applied, in this case it is set to
+// UPDATE_FAILED.
+func UpdateDeploymentDefinition(ctx context.Context, kv *api.KV, deploymentID string, defPath string) (*TopologyUpdate, error) {
+	topology, err := readTopologyDefinition(defPath)
+	if err != nil {
+		return nil, err
+	}
+
+	scratchID := newThrowawayDeploymentID()
+	defer deleteThrowawayDeployment(kv, scratchID)
+	defer ClearAttributeConstraintsCache(deploymentID)
+	update, err := prepareDeploymentUpdate(ctx, kv, deploymentID, scratchID, topology, filepath.Dir(defPath))
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to store updated TOSCA Definition for deployment with id %q, (file path %q)", deploymentID, defPath)
+	}
+
+	err = applyDeploymentUpdate(kv, deploymentID, scratchID, update)
+	if err != nil {
+		_, statusErr := kv.Put(&api.KVPair{Key: path.Join(deploymentKVPrefix(deploymentID), "status"), Value: []byte(UPDATE_FAILED.String())}, nil)
+		if statusErr == nil {
+			events.DeploymentStatusChange(kv, deploymentID, strings.ToLower(UPDATE_FAILED.String()))
+		}
+		return nil, errors.Wrapf(err, "Failed to apply updated TOSCA Definition for deployment with id %q, (file path %q)", deploymentID, defPath)
+	}
+	return update, nil
+}
+
+// prepareDeploymentUpdate stores the updated topology under the scratchID throwaway deployment and computes the
+// differences with the current definition of the deployment.
+func prepareDeploymentUpdate(ctx context.Context, kv *api.KV, deploymentID, scratchID string, topology tosca.Topology, rootDefPath string) (*TopologyUpdate, error) {
+	oldNodes, err := readTreeByName(kv, path.Join(deploymentKVPrefix(deploymentID), "topology", "nodes"))
+	if err != nil {
+		return nil, err
+	}
+	// Existing instances are kept, register their ids so instances and relationships instances of kept nodes are
+	// computed accordingly.
+	// Nodes which instances are all in the initial state were never installed, typically nodes added by a previous
+	// update which failed before installing them, they are considered as added by this update.
+	notInstalledNodes := make(map[string]bool)
+	for nodeName := range oldNodes {
+		instances, err := GetNodeInstancesIds(kv, deploymentID, nodeName)
+		if err != nil {
+			return nil, err
+		}
+		notInstalledNodes[nodeName] = len(instances) > 0
+		for _, instanceName := range instances {
+			// An instance which state can't be read is conservatively considered as installed
+			state, err := GetInstanceState(kv, deploymentID, nodeName, instanceName)
+			if err != nil || state != tosca.NodeStateInitial {
+				notInstalledNodes[nodeName] = false
+			}
+			_, err = kv.Put(&api.KVPair{Key: path.Join(deploymentKVPrefix(scratchID), "topology", "instances", nodeName, instanceName, "attributes", "tosca_name"), Value: []byte(nodeName)}, nil)
+			if err != nil {
+				return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+			}
+		}
+	}
+
+	err = storeDeployment(ctx, topology, scratchID, rootDefPath, true)
+	if err != nil {
+		return nil, err
+	}
+	err = registerImplementationTypes(ctx, kv, scratchID)
+	if err != nil {
+		return nil, err
+	}
+	err = checkGroupsMembers(kv, scratchID)
+	if err != nil {
+		return nil, err
+	}
+	err = checkPoliciesTargets(kv, scratchID)
+	if err != nil {
+		return nil, err
+	}
+	err = checkTopologyConstraints(kv, scratchID)
+	if err != nil {
+		return nil, err
+	}
+	err = enhanceNodes(ctx, kv, scratchID)
+	if err != nil {
+		return nil, err
+	}
+
+	newNodes, err := readTreeByName(kv, path.Join(deploymentKVPrefix(scratchID), "topology", "nodes"))
+	if err != nil {
+		return nil, err
+	}
+	update := computeTopologyUpdate(oldNodes, newNodes, notInstalledNodes)
+	err = storeUpdateWorkflows(ctx, kv, scratchID, update, oldNodes, newNodes)
+	if err != nil {
+		return nil, err
+	}
+	return update, nil
+}
+
+// applyDeploymentUpdate replaces the definition of a deployment by the one stored under the scratchID throwaway deployment
+func applyDeploymentUpdate(kv *api.KV, deploymentID, scratchID string, update *TopologyUpdate) error {
+	livePrefix := deploymentKVPrefix(deploymentID)
+	scratchPrefix := deploymentKVPrefix(scratchID)
+
+	instancesKeys, _, err := kv.Keys(path.Join(livePrefix, "topology", "instances")+"/", "/", nil)
+	if err != nil {
+		return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	nodesWithInstances := make(map[string]bool, len(instancesKeys))
+	for _, k := range instancesKeys {
+		nodesWithInstances[path.Base(k)] = true
+	}
+
+	scratchKVPs, _, err := kv.List(scratchPrefix+"/", nil)
+	if err != nil {
+		return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	newKeys := make(map[string][]byte, len(scratchKVPs))
+	ops := make(api.KVTxnOps, 0, len(scratchKVPs))
+	newInstances := make([][2]string, 0)
+	for _, kvp := range scratchKVPs {
+		key := strings.TrimPrefix(kvp.Key, scratchPrefix+"/")
+		if key == "status" {
+			continue
+		}
+		if parts := strings.Split(key, "/"); len(parts) > 3 && parts[0] == "topology" && parts[1] == "instances" {
+			if nodesWithInstances[parts[2]] {
+				// Instances of existing nodes are kept as is
+				continue
+			}
+			if strings.Join(parts[4:], "/") == "attributes/state" {
+				newInstances = append(newInstances, [2]string{parts[2], parts[3]})
+			}
+		}
+		newKeys[key] = kvp.Value
+		ops = append(ops, &api.KVTxnOp{Verb: api.KVSet, Key: path.Join(livePrefix, key), Value: kvp.Value})
+	}
+
+	removedNodes := make(map[string]bool, len(update.RemovedNodes))
+	for _, nodeName := range update.RemovedNodes {
+		removedNodes[nodeName] = true
+	}
+	// Those parts of the definition are entirely generated from the new topology
+	for _, p := range []string{
+		path.Join("topology", "nodes"),
+		path.Join("topology", "relationship_instances"),
+		path.Join("topology", "inputs"),
+		path.Join("topology", "outputs"),
+		path.Join("topology", "groups"),
+		path.Join("topology", "policies"),
+		path.Join("topology", implementationArtifactsExtensionsPath),
+		"workflows",
+	} {
+		liveKVPs, _, err := kv.List(path.Join(livePrefix, p)+"/", nil)
+		if err != nil {
+			return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+		}
+		liveValues := make(map[string][]byte, len(liveKVPs))
+		for _, kvp := range liveKVPs {
+			liveValues[strings.TrimPrefix(kvp.Key, livePrefix+"/")] = kvp.Value
+		}
+		for key := range liveValues {
+			if _, ok := newKeys[key]; ok || isKeptOnUpdate(key, removedNodes, liveValues, newKeys) {
+				continue
+			}
+			ops = append(ops, &api.KVTxnOp{Verb: api.KVDelete, Key: path.Join(livePrefix, key)})
+		}
+	}
+
+	err = runTxnOps(kv, ops)
+	if err != nil {
+		return err
+	}
+	for _, instance := range newInstances {
+		events.InstanceStatusChange(kv, deploymentID, instance[0], instance[1], tosca.NodeStateInitial.String())
+	}
+	return nil
+}
+
+// isKeptOnUpdate checks if a key of the current definition of a deployment that is not part of the updated
+// definition should be kept.
+//
+// Definitions and relationships instances of removed nodes are required to uninstall them. Runtime attributes of
+// relationships instances are kept if the relationship still targets the same node.
+func isKeptOnUpdate(key string, removedNodes map[string]bool, liveValues, newValues map[string][]byte) bool {
+	parts := strings.Split(key, "/")
+	if len(parts) < 3 || parts[0] != "topology" {
+		return false
+	}
+	switch parts[1] {
+	case "nodes":
+		return removedNodes[parts[2]]
+	case "relationship_instances":
+		if removedNodes[parts[2]] {
+			return true
+		}
+		if len(parts) < 5 {
+			return false
+		}
+		// relationship_instances/<node>/<requirement index>/<instance>/target/name
+		targetKey := path.Join(append(parts[:5:5], "target", "name")...)
+		newTarget, ok := newValues[targetKey]
+		return ok && string(newTarget) == string(liveValues[targetKey])
+	}
+	return false
+}
+
+// runTxnOps runs the given operations using as few Consul transactions as possible
+func runTxnOps(kv *api.KV, ops api.KVTxnOps) error {
+	for start := 0; start < len(ops); start += maxTxnOps {
+		end := start + maxTxnOps
+		if end > len(ops) {
+			end = len(ops)
+		}
+		ok, response, _, err := kv.Txn(ops[start:end], nil)
+		if err != nil {
+			return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+		}
+		if !ok {
+			errs := make([]string, 0, len(response.Errors))
+			for _, e := range response.Errors {
+				errs = append(errs, e.What)
+			}
+			return errors.Errorf("transaction failed: %s", strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+// DeleteNode removes the definition and the instances of a node
+func DeleteNode(kv *api.KV, deploymentID, nodeName string) error {
+	topologyPrefix := path.Join(deploymentKVPrefix(deploymentID), "topology")
+	for _, p := range []string{"nodes", "instances", "relationship_instances"} {
+		_, err := kv.DeleteTree(path.Join(topologyPrefix, p, nodeName)+"/", nil)
+		if err != nil {
+			return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+		}
+	}
+	return nil
+}
+
+// readTreeByName reads all keys under the given prefix and groups them by their first path element
+//
+// Keys are relative to the first path element.
+func readTreeByName(kv *api.KV, prefix string) (map[string]map[string]string, error) {
+	kvps, _, err := kv.List(prefix+"/", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	tree := make(map[string]map[string]string)
+	for _, kvp := range kvps {
+		parts := strings.SplitN(strings.TrimPrefix(kvp.Key, prefix+"/"), "/", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if tree[parts[0]] == nil {
+			tree[parts[0]] = make(map[string]string)
+		}
+		tree[parts[0]][parts[1]] = string(kvp.Value)
+	}
+	return tree, nil
+}
+
+// computeTopologyUpdate compares nodes definitions as read by readTreeByName
+//
+// Nodes of both definitions which are part of notInstalledNodes are reported as added.
+func computeTopologyUpdate(oldNodes, newNodes map[string]map[string]string, notInstalledNodes map[string]bool) *TopologyUpdate {
+	update := &TopologyUpdate{
+		AddedNodes:           make([]string, 0),
+		RemovedNodes:         make([]string, 0),
+		ModifiedNodes:        make([]string, 0),
+		AddedRelationships:   make([]TopologyRelationship, 0),
+		RemovedRelationships: make([]TopologyRelationship, 0),
+	}
+	for nodeName, newDef := range newNodes {
+		oldDef, ok := oldNodes[nodeName]
+		if !ok || notInstalledNodes[nodeName] {
+			update.AddedNodes = append(update.AddedNodes, nodeName)
+			continue
+		}
+		if !reflect.DeepEqual(nodeDefinitionForComparison(oldDef), nodeDefinitionForComparison(newDef)) {
+			update.ModifiedNodes = append(update.ModifiedNodes, nodeName)
+		}
+	}
+	for nodeName := range oldNodes {
+		if _, ok := newNodes[nodeName]; !ok {
+			update.RemovedNodes = append(update.RemovedNodes, nodeName)
+		}
+	}
+
+	oldRelationships := relationshipsSet(oldNodes)
+	newRelationships := relationshipsSet(newNodes)
+	for r := range newRelationships {
+		if !oldRelationships[r] {
+			update.AddedRelationships = append(update.AddedRelationships, r)
+		}
+	}
+	for r := range oldRelationships {
+		if !newRelationships[r] {
+			update.RemovedRelationships = append(update.RemovedRelationships, r)
+		}
+	}
+
+	sort.Strings(update.AddedNodes)
+	sort.Strings(update.RemovedNodes)
+	sort.Strings(update.ModifiedNodes)
+	sortRelationships(update.AddedRelationships)
+	sortRelationships(update.RemovedRelationships)
+	return update
+}
+
+// nodeDefinitionForComparison returns the node definition without keys that are not part of the TOSCA definition
+func nodeDefinitionForComparison(def map[string]string) map[string]string {
+	result := make(map[string]string, len(def))
+	for k, v := range def {
+		if k == "nbInstances" {
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
+
+// relationshipsSet returns the relationships defined by the requirements of the given nodes definitions
+func relationshipsSet(nodes map[string]map[string]string) map[TopologyRelationship]bool {
+	relationships := make(map[TopologyRelationship]bool)
+	for nodeName, def := range nodes {
+		for k, v := range def {
+			if !strings.HasPrefix(k, "requirements/") || !strings.HasSuffix(k, "/node") || v == "" {
+				continue
+			}
+			reqPrefix := strings.TrimSuffix(k, "/node")
+			if strings.Count(reqPrefix, "/") != 1 {
+				// this is a requirement property
+				continue
+			}
+			relationships[TopologyRelationship{
+				Source:      nodeName,
+				Requirement: def[reqPrefix+"/name"],
+				Target:      v,
+				Type:        def[reqPrefix+"/relationship"],
+			}] = true
+		}
+	}
+	return relationships
+}
+
+func sortRelationships(relationships []TopologyRelationship) {
+	sort.Slice(relationships, func(i, j int) bool {
+		return relationships[i].String() < relationships[j].String()
+	})
+}
+
+// nodesDependencies returns for each of the given nodes the ones it depends on (targets of its requirements) among them
+func nodesDependencies(nodes []string, defs map[string]map[string]string) map[string][]string {
+	inSet := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		inSet[n] = true
+	}
+	deps := make(map[string][]string, len(nodes))
+	for r := range relationshipsSet(defs) {
+		if inSet[r.Source] && inSet[r.Target] && r.Source != r.Target && !collections.ContainsString(deps[r.Source], r.Target) {
+			deps[r.Source] = append(deps[r.Source], r.Target)
+		}
+	}
+	for n := range deps {
+		sort.Strings(deps[n])
+	}
+	return deps
+}
+
+// storeUpdateWorkflows generates the workflows used to apply a topology update
+//
+// Removed nodes are uninstalled before the nodes they depend on. Modified nodes are configured again after the
+// nodes they depend on.
+func storeUpdateWorkflows(ctx context.Context, kv *api.KV, deploymentID string, update *TopologyUpdate, oldNodes, newNodes map[string]map[string]string) error {
+	_, errGroup, consulStore := consulutil.WithContext(ctx)
+	workflowsPrefix := path.Join(deploymentKVPrefix(deploymentID), "workflows")
+
+	uninstallPrefix := path.Join(workflowsPrefix, UpdateUninstallWorkflowName, "steps")
+	for _, nodeName := range update.RemovedNodes {
+		stepPrefix := path.Join(uninstallPrefix, "update_uninstall_"+nodeName)
+		consulStore.StoreConsulKeyAsString(stepPrefix+"/target", nodeName)
+		nodeType := oldNodes[nodeName]["type"]
+		var activities [][2]string
+		if _, err := reg.GetDelegateExecutor(nodeType); err == nil {
+			activities = [][2]string{{"delegate", "uninstall"}}
+		} else {
+			activities = [][2]string{
+				{"set-state", "stopping"},
+				{"call-operation", "tosca.interfaces.node.lifecycle.standard.stop"},
+				{"set-state", "stopped"},
+				{"set-state", "deleting"},
+				{"call-operation", "tosca.interfaces.node.lifecycle.standard.delete"},
+				{"set-state", "deleted"},
+			}
+		}
+		storeGeneratedActivities(consulStore, stepPrefix, activities)
+	}
+	// a node is uninstalled before the nodes it depends on
+	for nodeName, deps := range nodesDependencies(update.RemovedNodes, oldNodes) {
+		for _, dep := range deps {
+			consulStore.StoreConsulKeyAsString(path.Join(uninstallPrefix, "update_uninstall_"+nodeName, "next", "update_uninstall_"+dep), "")
+		}
+	}
+
+	configurePrefix := path.Join(workflowsPrefix, UpdateConfigureWorkflowName, "steps")
+	for _, nodeName := range update.ModifiedNodes {
+		stepPrefix := path.Join(configurePrefix, "update_configure_"+nodeName)
+		consulStore.StoreConsulKeyAsString(stepPrefix+"/target", nodeName)
+		storeGeneratedActivities(consulStore, stepPrefix, [][2]string{
+			{"set-state", "configuring"},
+			{"call-operation", "tosca.interfaces.node.lifecycle.standard.configure"},
+			{"set-state", "started"},
+		})
+	}
+	// a node is configured after the nodes it depends on
+	for nodeName, deps := range nodesDependencies(update.ModifiedNodes, newNodes) {
+		for _, dep := range deps {
+			consulStore.StoreConsulKeyAsString(path.Join(configurePrefix, "update_configure_"+dep, "next", "update_configure_"+nodeName), "")
+		}
+	}
+	return errGroup.Wait()
+}
+
+func storeGeneratedActivities(consulStore consulutil.ConsulStore, stepPrefix string, activities [][2]string) {
+	for i, activity := range activities {
+		consulStore.StoreConsulKeyAsString(path.Join(stepPrefix, "activities", fmt.Sprint(i), activity[0]), activity[1])
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"context"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/helper/consulutil"
+)
+
+func TestComputeTopologyUpdate(t *testing.T) {
+	oldNodes := map[string]map[string]string{
+		"Compute": {"type": "tosca.nodes.Compute", "nbInstances": "1"},
+		"Soft": {
+			"type":                         "tosca.nodes.SoftwareComponent",
+			"properties/component_version": "1.0",
+			"requirements/0/name":          "host",
+			"requirements/0/node":          "Compute",
+			"requirements/0/relationship":  "tosca.relationships.HostedOn",
+		},
+		"Old": {
+			"type":                        "tosca.nodes.SoftwareComponent",
+			"requirements/0/name":         "host",
+			"requirements/0/node":         "Compute",
+			"requirements/0/relationship": "tosca.relationships.HostedOn",
+		},
+	}
+	newNodes := map[string]map[string]string{
+		// Instances count is not part of the definition
+		"Compute": {"type": "tosca.nodes.Compute", "nbInstances": "3"},
+		"Soft": {
+			"type":                         "tosca.nodes.SoftwareComponent",
+			"properties/component_version": "2.0",
+			"requirements/0/name":          "host",
+			"requirements/0/node":          "Compute",
+			"requirements/0/relationship":  "tosca.relationships.HostedOn",
+		},
+		"New": {
+			"type":                        "tosca.nodes.SoftwareComponent",
+			"requirements/0/name":         "host",
+			"requirements/0/node":         "Compute",
+			"requirements/0/relationship": "tosca.relationships.HostedOn",
+		},
+	}
+
+	update := computeTopologyUpdate(oldNodes, newNodes, nil)
+	require.Equal(t, []string{"New"}, update.AddedNodes)
+	require.Equal(t, []string{"Old"}, update.RemovedNodes)
+	require.Equal(t, []string{"Soft"}, update.ModifiedNodes)
+	require.Equal(t, []TopologyRelationship{{Source: "New", Requirement: "host", Target: "Compute", Type: "tosca.relationships.HostedOn"}}, update.AddedRelationships)
+	require.Equal(t, []TopologyRelationship{{Source: "Old", Requirement: "host", Target: "Compute", Type: "tosca.relationships.HostedOn"}}, update.RemovedRelationships)
+	require.False(t, update.IsEmpty())
+
+	require.True(t, computeTopologyUpdate(oldNodes, oldNodes, nil).IsEmpty())
+
+	// Nodes that were never installed are added again
+	update = computeTopologyUpdate(oldNodes, oldNodes, map[string]bool{"Old": true})
+	require.Equal(t, []string{"Old"}, update.AddedNodes)
+	require.Len(t, update.ModifiedNodes, 0)
+	require.Len(t, update.RemovedNodes, 0)
+}
+
+func TestNodesDependencies(t *testing.T) {
+	defs := map[string]map[string]string{
+		"Compute": {"type": "tosca.nodes.Compute"},
+		"Soft": {
+			"requirements/0/name": "host",
+			"requirements/0/node": "Compute",
+			"requirements/1/name": "dependency",
+			"requirements/1/node": "DB",
+		},
+		"DB": {
+			"requirements/0/name": "host",
+			"requirements/0/node": "Compute",
+		},
+	}
+	deps := nodesDependencies([]string{"Compute", "Soft", "DB"}, defs)
+	require.Equal(t, map[string][]string{"Soft": {"Compute", "DB"}, "DB": {"Compute"}}, deps)
+
+	// Only dependencies between the given nodes are considered
+	deps = nodesDependencies([]string{"Soft", "DB"}, defs)
+	require.Equal(t, map[string][]string{"Soft": {"DB"}}, deps)
+}
+
+func testUpdateDeploymentDefinition(t *testing.T, kv *api.KV) {
+	t.Parallel()
+	deploymentID := strings.Replace(t.Name(), "/", "_", -1)
+	err := StoreDeploymentDefinition(context.Background(), kv, deploymentID, "testdata/update_topology.yaml")
+	require.NoError(t, err)
+	_, err = kv.Put(&api.KVPair{Key: path.Join(consulutil.DeploymentKVPrefix, deploymentID, "status"), Value: []byte(DEPLOYED.String())}, nil)
+	require.NoError(t, err)
+	attrPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "instances", "Soft", "0", "attributes", "my_attr")
+	_, err = kv.Put(&api.KVPair{Key: attrPath, Value: []byte("my_value")}, nil)
+	require.NoError(t, err)
+
+	relAttrPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "relationship_instances", "Soft", "0", "0", "attributes", "my_rel_attr")
+	_, err = kv.Put(&api.KVPair{Key: relAttrPath, Value: []byte("my_rel_value")}, nil)
+	require.NoError(t, err)
+
+	// An invalid update doesn't modify the deployment
+	_, err = UpdateDeploymentDefinition(context.Background(), kv, deploymentID, "testdata/update_topology_invalid.yaml")
+	require.Error(t, err)
+	nodes, err := GetNodes(kv, deploymentID)
+	require.NoError(t, err)
+	require.Contains(t, nodes, "Old")
+	require.NotContains(t, nodes, "New")
+	groups, err := GetGroups(kv, deploymentID)
+	require.NoError(t, err)
+	require.Len(t, groups, 0)
+	deploymentsIDs, _, err := kv.Keys(consulutil.DeploymentKVPrefix+"/", "/", nil)
+	require.NoError(t, err)
+	for _, k := range deploymentsIDs {
+		require.False(t, IsValidationDeploymentID(path.Base(k)), "throwaway deployment %q stored with deployments", k)
+	}
+
+	update, err := UpdateDeploymentDefinition(context.Background(), kv, deploymentID, "testdata/update_topology_updated.yaml")
+	require.NoError(t, err)
+	require.Equal(t, []string{"New"}, update.AddedNodes)
+	require.Equal(t, []string{"Old"}, update.RemovedNodes)
+	require.Equal(t, []string{"Soft"}, update.ModifiedNodes)
+
+	status, err := GetDeploymentStatus(kv, deploymentID)
+	require.NoError(t, err)
+	require.Equal(t, DEPLOYED, status, "deployment status should not be modified by an update")
+
+	// Instances and attributes of existing nodes are kept
+	kvp, _, err := kv.Get(attrPath, nil)
+	require.NoError(t, err)
+	require.NotNil(t, kvp)
+	require.Equal(t, "my_value", string(kvp.Value))
+
+	// Runtime attributes of relationships of kept nodes are kept
+	kvp, _, err = kv.Get(relAttrPath, nil)
+	require.NoError(t, err)
+	require.NotNil(t, kvp)
+	require.Equal(t, "my_rel_value", string(kvp.Value))
+
+	instances, err := GetNodeInstancesIds(kv, deploymentID, "New")
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+
+	// Removed nodes are kept until they are uninstalled
+	nodeType, err := GetNodeType(kv, deploymentID, "Old")
+	require.NoError(t, err)
+	require.Equal(t, "tosca.nodes.SoftwareComponent", nodeType)
+
+	wf, err := ReadWorkflow(kv, deploymentID, UpdateUninstallWorkflowName)
+	require.NoError(t, err)
+	require.Len(t, wf.Steps, 1)
+	require.Contains(t, wf.Steps, "update_uninstall_Old")
+
+	wf, err = ReadWorkflow(kv, deploymentID, UpdateConfigureWorkflowName)
+	require.NoError(t, err)
+	require.Len(t, wf.Steps, 1)
+	require.Contains(t, wf.Steps, "update_configure_Soft")
+	require.Equal(t, "Soft", wf.Steps["update_configure_Soft"].Target)
+
+	require.NoError(t, DeleteNode(kv, deploymentID, "Old"))
+	nodes, err = GetNodes(kv, deploymentID)
+	require.NoError(t, err)
+	require.NotContains(t, nodes, "Old")
+}
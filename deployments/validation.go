@@ -0,0 +1,523 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/tosca"
+	"gopkg.in/yaml.v2"
+)
+
+// validationDeploymentIDPrefix is the prefix of the throwaway deployments ids used to validate a deployment definition.
+//
+// It contains a character that is not allowed in user-defined deployments ids to prevent any collision.
+const validationDeploymentIDPrefix = "_yorc_validation."
+
+var yamlErrorLineRegexp = regexp.MustCompile(`line (\d+):`)
+
+// A ValidationMessage is an error or a warning detected while validating a deployment definition
+type ValidationMessage struct {
+	// File is the path of the definition file relative to the archive root
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+func (m ValidationMessage) String() string {
+	if m.File == "" {
+		return m.Message
+	}
+	if m.Line == 0 {
+		return fmt.Sprintf("%s: %s", m.File, m.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s", m.File, m.Line, m.Message)
+}
+
+// A ValidationReport is the result of a deployment definition validation
+type ValidationReport struct {
+	Valid    bool                `json:"valid"`
+	Errors   []ValidationMessage `json:"errors,omitempty"`
+	Warnings []ValidationMessage `json:"warnings,omitempty"`
+}
+
+// IsValidationDeploymentID checks if the given deployment id is a throwaway id used to validate a deployment definition
+func IsValidationDeploymentID(deploymentID string) bool {
+	return strings.HasPrefix(deploymentID, validationDeploymentIDPrefix)
+}
+
+// definitionFile is a parsed definition file of a deployment archive
+type definitionFile struct {
+	// path relative to the archive root
+	path     string
+	content  []byte
+	topology tosca.Topology
+}
+
+// validator holds the state of a deployment definition validation
+type validator struct {
+	kv           *api.KV
+	deploymentID string
+	rootDir      string
+	files        []*definitionFile
+	report       *ValidationReport
+}
+
+func (v *validator) addError(file string, line int, format string, args ...interface{}) {
+	v.report.Errors = append(v.report.Errors, ValidationMessage{File: file, Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) addWarning(file string, line int, format string, args ...interface{}) {
+	v.report.Warnings = append(v.report.Warnings, ValidationMessage{File: file, Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+// ValidateDeploymentDefinition checks a deployment definition without creating a deployment.
+//
+// The definition is parsed and stored exactly as StoreDeploymentDefinition would do but under a throwaway deployment id
+// which is removed at the end of the validation. Problems found in the definition are reported in the returned
+// ValidationReport, an error is returned only if the validation itself could not be performed.
+func ValidateDeploymentDefinition(ctx context.Context, kv *api.KV, defPath string) (*ValidationReport, error) {
+	v := &validator{
+		kv:           kv,
+		deploymentID: newThrowawayDeploymentID(),
+		rootDir:      filepath.Dir(defPath),
+		report:       &ValidationReport{},
+	}
+	defer v.cleanup()
+
+	err := v.validate(ctx, defPath)
+	sortValidationMessages(v.report.Errors)
+	sortValidationMessages(v.report.Warnings)
+	v.report.Valid = len(v.report.Errors) == 0
+	return v.report, err
+}
+
+func (v *validator) validate(ctx context.Context, defPath string) error {
+	root := v.loadDefinitionFile(filepath.Base(defPath), 0, "")
+	if root == nil || len(v.report.Errors) > 0 {
+		return nil
+	}
+	if err := checkNestedWorkflows(root.topology); err != nil {
+		v.addError(root.path, findLine(root.content, "workflows:"), "%v", err)
+	}
+	v.checkGetInputs(root)
+	if len(v.report.Errors) > 0 {
+		return nil
+	}
+
+	if err := storeDeployment(ctx, root.topology, v.deploymentID, v.rootDir, true); err != nil {
+		v.addError(root.path, 0, "%v", errors.Cause(err))
+		return nil
+	}
+	if err := registerImplementationTypes(ctx, v.kv, v.deploymentID); err != nil {
+		v.addError(root.path, 0, "%v", err)
+		return nil
+	}
+	if err := v.checkTemplatesTypes(root); err != nil {
+		return err
+	}
+	if err := v.checkFunctionsReferences(root); err != nil {
+		return err
+	}
+	for _, f := range v.files {
+		if err := v.checkOperationsImplementations(f); err != nil {
+			return err
+		}
+		v.checkArtifacts(f)
+	}
+	if len(v.report.Errors) > 0 {
+		return nil
+	}
+	if err := enhanceNodes(ctx, v.kv, v.deploymentID); err != nil {
+		v.addError(root.path, 0, "%v", errors.Cause(err))
+	}
+	return nil
+}
+
+// cleanup removes everything stored under the throwaway deployment id
+func (v *validator) cleanup() {
+	deleteThrowawayDeployment(v.kv, v.deploymentID)
+}
+
+// newThrowawayDeploymentID returns a deployment id used to store a deployment definition temporarily
+func newThrowawayDeploymentID() string {
+	return validationDeploymentIDPrefix + fmt.Sprint(uuid.NewV4())
+}
+
+// deleteThrowawayDeployment removes everything stored under a throwaway deployment id
+func deleteThrowawayDeployment(kv *api.KV, deploymentID string) {
+	for _, prefix := range []string{deploymentKVPrefix(deploymentID), path.Join(consulutil.EventsPrefix, deploymentID), path.Join(consulutil.LogsPrefix, deploymentID)} {
+		kv.DeleteTree(prefix+"/", nil)
+	}
+}
+
+// loadDefinitionFile parses a definition file and recursively its imports.
+//
+// importLine is the line of the import statement in importingFile, it is used to report a missing file.
+func (v *validator) loadDefinitionFile(filePath string, importLine int, importingFile string) *definitionFile {
+	content, err := ioutil.ReadFile(filepath.Join(v.rootDir, filepath.FromSlash(filePath)))
+	if err != nil {
+		v.addError(importingFile, importLine, "failed to read definition file %q: %v", filePath, err)
+		return nil
+	}
+	f := &definitionFile{path: filePath, content: content}
+	if err = yaml.Unmarshal(content, &f.topology); err != nil {
+		v.addError(filePath, yamlErrorLine(err), "failed to parse definition: %v", err)
+		return nil
+	}
+	v.files = append(v.files, f)
+
+	for _, imp := range f.topology.Imports {
+		importURI := strings.Trim(imp.File, " \t")
+		line := findImportLine(content, importURI)
+		if strings.HasPrefix(importURI, "<") && strings.HasSuffix(importURI, ">") {
+			if _, err := reg.GetToscaDefinition(strings.Trim(importURI, "<>")); err != nil {
+				v.addError(filePath, line, "unknown internal definition %s", importURI)
+			}
+			continue
+		}
+		v.loadDefinitionFile(path.Join(path.Dir(filePath), importURI), line, filePath)
+	}
+	return f
+}
+
+// checkGetInputs checks that get_input functions used in the topology template refer to declared inputs
+func (v *validator) checkGetInputs(root *definitionFile) {
+	inputs := root.topology.TopologyTemplate.Inputs
+	forEachTemplateValue(root, func(va *tosca.ValueAssignment, lineTokens ...string) error {
+		for _, input := range getInputsNames(va) {
+			if _, ok := inputs[input]; !ok {
+				v.addError(root.path, findLine(root.content, lineTokens...), "get_input function references an undefined input %q", input)
+			}
+		}
+		return nil
+	})
+}
+
+// checkFunctionsReferences checks that types referenced by get_nodes_of_type functions and node templates referenced
+// by get_artifact functions exist
+func (v *validator) checkFunctionsReferences(root *definitionFile) error {
+	nodes := root.topology.TopologyTemplate.NodeTemplates
+	return forEachTemplateValue(root, func(va *tosca.ValueAssignment, lineTokens ...string) error {
+		if va == nil || va.GetFunction() == nil {
+			return nil
+		}
+		f := va.GetFunction()
+		for _, fn := range f.GetFunctionsByOperator(tosca.GetNodesOfTypeOperator) {
+			if !fn.Operands[0].IsLiteral() {
+				continue
+			}
+			typeName := string(fn.Operands[0].(tosca.LiteralOperand))
+			_, err := GetParentType(v.kv, v.deploymentID, typeName)
+			if IsTypeMissingError(err) {
+				v.addError(root.path, findLine(root.content, lineTokens...), "get_nodes_of_type function references an unknown type %q", typeName)
+			} else if err != nil {
+				return err
+			}
+		}
+		for _, fn := range f.GetFunctionsByOperator(tosca.GetArtifactOperator) {
+			if !fn.Operands[0].IsLiteral() {
+				continue
+			}
+			entity := string(fn.Operands[0].(tosca.LiteralOperand))
+			switch entity {
+			case funcKeywordSELF, funcKeywordSOURCE, funcKeywordTARGET, funcKeywordHOST:
+				continue
+			}
+			if _, ok := nodes[entity]; !ok {
+				v.addError(root.path, findLine(root.content, lineTokens...), "get_artifact function references an unknown node template %q", entity)
+			}
+		}
+		return nil
+	})
+}
+
+// forEachTemplateValue calls fn for each value assignment of node templates and outputs of a definition file
+// with the tokens identifying its line
+func forEachTemplateValue(root *definitionFile, fn func(va *tosca.ValueAssignment, lineTokens ...string) error) error {
+	for nodeName, node := range root.topology.TopologyTemplate.NodeTemplates {
+		for propName, va := range node.Properties {
+			if err := fn(va, "node_templates:", nodeName+":", "properties:", propName+":"); err != nil {
+				return err
+			}
+		}
+		for attrName, va := range node.Attributes {
+			if err := fn(va, "node_templates:", nodeName+":", "attributes:", attrName+":"); err != nil {
+				return err
+			}
+		}
+		for capName, capability := range node.Capabilities {
+			for propName, va := range capability.Properties {
+				if err := fn(va, "node_templates:", nodeName+":", "capabilities:", capName+":", propName+":"); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for outputName, output := range root.topology.TopologyTemplate.Outputs {
+		if err := fn(output.Value, "outputs:", outputName+":", "value:"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkTemplatesTypes checks that types used by node templates and their requirements, groups and policies are known
+func (v *validator) checkTemplatesTypes(root *definitionFile) error {
+	for nodeName, node := range root.topology.TopologyTemplate.NodeTemplates {
+		if err := v.checkTypeExists(node.Type, root, "node_templates:", nodeName+":", "type:"); err != nil {
+			return err
+		}
+		for _, reqMap := range node.Requirements {
+			for reqName, req := range reqMap {
+				if req.Relationship == "" {
+					continue
+				}
+				if err := v.checkTypeExists(req.Relationship, root, "node_templates:", nodeName+":", "requirements:", reqName+":", "relationship:"); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for groupName, group := range root.topology.TopologyTemplate.Groups {
+		if err := v.checkTypeExists(group.Type, root, "groups:", groupName+":", "type:"); err != nil {
+			return err
+		}
+	}
+	for policyName, policy := range root.topology.TopologyTemplate.Policies {
+		if err := v.checkTypeExists(policy.Type, root, "policies:", policyName+":", "type:"); err != nil {
+			return err
+		}
+	}
+	if len(v.report.Errors) > 0 {
+		return nil
+	}
+	if err := checkGroupsMembers(v.kv, v.deploymentID); err != nil {
+		v.addError(root.path, findLine(root.content, "topology_template:", "groups:"), "%v", err)
+	}
+	if err := checkPoliciesTargets(v.kv, v.deploymentID); err != nil {
+		v.addError(root.path, findLine(root.content, "topology_template:", "policies:"), "%v", err)
+	}
+	err := checkTopologyConstraints(v.kv, v.deploymentID)
+	if violations, ok := err.(constraintViolations); ok {
+		for _, violation := range violations {
+			lineTokens := []string{"topology_template:"}
+			for _, token := range violation.templatePath {
+				lineTokens = append(lineTokens, token+":")
+			}
+			v.addError(root.path, findLine(root.content, lineTokens...), "%v", violation.err)
+		}
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v *validator) checkTypeExists(typeName string, f *definitionFile, lineTokens ...string) error {
+	_, err := GetParentType(v.kv, v.deploymentID, typeName)
+	if IsTypeMissingError(err) {
+		v.addError(f.path, findLine(f.content, lineTokens...), "unknown type %q", typeName)
+		return nil
+	}
+	return err
+}
+
+// checkOperationsImplementations checks that operations implemented in types declared in a definition file have an
+// implementation artifact that could be executed by a registered operation executor
+func (v *validator) checkOperationsImplementations(f *definitionFile) error {
+	checkInterfaces := func(section, typeName string, interfaces map[string]tosca.InterfaceDefinition) error {
+		for intName, intDef := range interfaces {
+			for opName, opDef := range intDef.Operations {
+				impl := opDef.Implementation
+				if impl.Primary == "" && impl.Artifact.Type == "" {
+					continue
+				}
+				line := findLine(f.content, section, typeName+":", "interfaces:", intName+":", opName+":")
+				opFullName := strings.ToLower(intName + "." + opName)
+				artifactType, err := GetOperationImplementationType(v.kv, v.deploymentID, typeName, opFullName)
+				if err != nil {
+					v.addError(f.path, line, "%v", err)
+					continue
+				}
+				found, err := v.hasOperationExecutor(artifactType)
+				if err != nil {
+					return err
+				}
+				if !found {
+					v.addError(f.path, line, "no operation executor registered for implementation artifact type %q of operation %q of type %q", artifactType, opFullName, typeName)
+				}
+				if impl.Primary != "" && impl.Artifact.Repository == "" {
+					v.checkFileExists(f, path.Join(path.Dir(f.path), impl.Primary), line)
+				}
+			}
+		}
+		return nil
+	}
+
+	for typeName, nodeType := range f.topology.NodeTypes {
+		if err := checkInterfaces("node_types:", typeName, nodeType.Interfaces); err != nil {
+			return err
+		}
+	}
+	for typeName, relType := range f.topology.RelationshipTypes {
+		if err := checkInterfaces("relationship_types:", typeName, relType.Interfaces); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasOperationExecutor checks if an operation executor is registered for the given artifact type or one of its parents
+func (v *validator) hasOperationExecutor(artifactType string) (bool, error) {
+	for artifactType != "" {
+		if _, err := reg.GetOperationExecutor(artifactType); err == nil {
+			return true, nil
+		}
+		var err error
+		artifactType, err = GetParentType(v.kv, v.deploymentID, artifactType)
+		if err != nil && !IsTypeMissingError(err) {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// checkArtifacts checks that local artifacts files declared in a definition file are present in the archive
+func (v *validator) checkArtifacts(f *definitionFile) {
+	checkArtifactsMap := func(baseDir, section, elemName string, artifacts tosca.ArtifactDefMap) {
+		for artName, art := range artifacts {
+			if art.File == "" || art.Repository != "" {
+				continue
+			}
+			v.checkFileExists(f, path.Join(baseDir, art.File), findLine(f.content, section, elemName+":", "artifacts:", artName))
+		}
+	}
+	for typeName, nodeType := range f.topology.NodeTypes {
+		checkArtifactsMap(path.Dir(f.path), "node_types:", typeName, nodeType.Artifacts)
+	}
+	for typeName, relType := range f.topology.RelationshipTypes {
+		checkArtifactsMap(path.Dir(f.path), "relationship_types:", typeName, relType.Artifacts)
+	}
+	for nodeName, node := range f.topology.TopologyTemplate.NodeTemplates {
+		// Node templates artifacts are relative to the archive root
+		checkArtifactsMap("", "node_templates:", nodeName, node.Artifacts)
+	}
+}
+
+func (v *validator) checkFileExists(f *definitionFile, filePath string, line int) {
+	if _, err := os.Stat(filepath.Join(v.rootDir, filepath.FromSlash(filePath))); err != nil {
+		v.addWarning(f.path, line, "file %q not found in the deployment archive", filePath)
+	}
+}
+
+// getInputsNames returns the names of the inputs referenced by get_input functions in a value assignment
+func getInputsNames(va *tosca.ValueAssignment) []string {
+	names := make([]string, 0)
+	if va == nil {
+		return names
+	}
+	f := va.GetFunction()
+	if f == nil {
+		return names
+	}
+	for _, getInput := range f.GetFunctionsByOperator(tosca.GetInputOperator) {
+		if len(getInput.Operands) > 0 && getInput.Operands[0].IsLiteral() {
+			names = append(names, getInput.Operands[0].String())
+		}
+	}
+	return names
+}
+
+// yamlErrorLine returns the line number reported in a YAML parsing error or 0 if there is none
+func yamlErrorLine(err error) int {
+	m := yamlErrorLineRegexp.FindStringSubmatch(err.Error())
+	if m == nil {
+		return 0
+	}
+	line, _ := strconv.Atoi(m[1])
+	return line
+}
+
+// findLine returns the line number (starting at 1) of the YAML element identified by the given path of tokens.
+//
+// Each token is searched as the beginning of a line (ignoring indentation and list markers) within the block of
+// the line of the previous token. The line of the deepest token found is returned, 0 means that no token was found.
+func findLine(content []byte, tokens ...string) int {
+	lines := strings.Split(string(content), "\n")
+	result := 0
+	parentIndent := -1
+	for _, token := range tokens {
+		found := false
+		for i := result; i < len(lines); i++ {
+			l := strings.TrimLeft(lines[i], " \t-")
+			if l == "" || strings.HasPrefix(l, "#") {
+				continue
+			}
+			indent := len(lines[i]) - len(l)
+			if indent <= parentIndent {
+				// End of the parent block
+				break
+			}
+			if strings.HasPrefix(strings.TrimLeft(l, `"'`), token) {
+				result = i + 1
+				parentIndent = indent
+				found = true
+				break
+			}
+		}
+		if !found {
+			break
+		}
+	}
+	return result
+}
+
+// findImportLine returns the line number (starting at 1) of the import statement of the given file or 0 if not found
+func findImportLine(content []byte, importURI string) int {
+	lines := bytes.Split(content, []byte("\n"))
+	for i := findLine(content, "imports:"); i > 0 && i < len(lines); i++ {
+		if bytes.Contains(lines[i], []byte(importURI)) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// sortValidationMessages sorts messages by file and line to get a stable report
+func sortValidationMessages(messages []ValidationMessage) {
+	sort.Slice(messages, func(i, j int) bool {
+		if messages[i].File != messages[j].File {
+			return messages[i].File < messages[j].File
+		}
+		if messages[i].Line != messages[j].Line {
+			return messages[i].Line < messages[j].Line
+		}
+		return messages[i].Message < messages[j].Message
+	})
+}
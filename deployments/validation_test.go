@@ -0,0 +1,119 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/ystia/yorc/helper/consulutil"
+	"gopkg.in/yaml.v2"
+)
+
+func TestFindLine(t *testing.T) {
+	t.Parallel()
+	content := []byte(`imports:
+  - normative: <normative-types.yml>
+  - file: types/my-types.yml
+topology_template:
+  node_templates:
+    Compute:
+      type: tosca.nodes.Compute
+    Soft:
+      type: tosca.nodes.SoftwareComponent
+      properties:
+        component_version: "1.0"
+`)
+	tests := []struct {
+		name   string
+		tokens []string
+		want   int
+	}{
+		{"NodeType", []string{"node_templates:", "Soft:", "type:"}, 9},
+		{"NodeProperty", []string{"node_templates:", "Soft:", "properties:", "component_version:"}, 11},
+		{"FirstTokenOnly", []string{"topology_template:"}, 4},
+		{"MissingLastToken", []string{"node_templates:", "Compute:", "properties:", "component_version:"}, 6},
+		{"ListItem", []string{"imports:", "file:"}, 3},
+		{"MissingFirstToken", []string{"workflows:"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, findLine(content, tt.tokens...))
+		})
+	}
+	require.Equal(t, 2, findImportLine(content, "<normative-types.yml>"))
+	require.Equal(t, 3, findImportLine(content, "types/my-types.yml"))
+	require.Equal(t, 0, findImportLine(content, "other.yml"))
+}
+
+func TestYamlErrorLine(t *testing.T) {
+	t.Parallel()
+	var m map[string]int
+	err := yaml.Unmarshal([]byte("a: 1\nb: two\n"), &m)
+	require.Error(t, err)
+	require.Equal(t, 2, yamlErrorLine(err))
+	require.Equal(t, 0, yamlErrorLine(errors.New("no line here")))
+}
+
+func TestValidatorCheckGetInputs(t *testing.T) {
+	t.Parallel()
+	v := &validator{rootDir: "testdata", report: &ValidationReport{}}
+	root := v.loadDefinitionFile("validation_get_input.yaml", 0, "")
+	require.NotNil(t, root)
+	require.Len(t, v.report.Errors, 0)
+
+	v.checkGetInputs(root)
+	sortValidationMessages(v.report.Errors)
+	require.Equal(t, []ValidationMessage{
+		{File: "validation_get_input.yaml", Line: 22, Message: `get_input function references an undefined input "credentials"`},
+		{File: "validation_get_input.yaml", Line: 25, Message: `get_input function references an undefined input "host"`},
+	}, v.report.Errors)
+}
+
+func testValidateDeploymentDefinition(t *testing.T, kv *api.KV) {
+	t.Parallel()
+
+	report, err := ValidateDeploymentDefinition(context.Background(), kv, "testdata/update_topology.yaml")
+	require.NoError(t, err)
+	require.True(t, report.Valid, "unexpected errors: %v", report.Errors)
+	require.Len(t, report.Warnings, 0)
+
+	report, err = ValidateDeploymentDefinition(context.Background(), kv, "testdata/validation_invalid.yaml")
+	require.NoError(t, err)
+	require.False(t, report.Valid)
+	require.Equal(t, []ValidationMessage{
+		{File: "validation_invalid.yaml", Line: 22, Message: `no operation executor registered for implementation artifact type "tosca.artifacts.Implementation.Bash" of operation "standard.create" of type "yorc.tests.nodes.Soft"`},
+		{File: "validation_invalid.yaml", Line: 37, Message: `unknown type "yorc.tests.nodes.Unknown"`},
+	}, report.Errors)
+	require.Equal(t, []ValidationMessage{
+		{File: "validation_invalid.yaml", Line: 17, Message: `file "missing/config.cfg" not found in the deployment archive`},
+		{File: "validation_invalid.yaml", Line: 22, Message: `file "scripts/create.sh" not found in the deployment archive`},
+	}, report.Warnings)
+
+	report, err = ValidateDeploymentDefinition(context.Background(), kv, "testdata/validation_get_input.yaml")
+	require.NoError(t, err)
+	require.False(t, report.Valid)
+	require.Len(t, report.Errors, 2)
+
+	// Validations should not be stored with deployments
+	keys, _, err := kv.Keys(consulutil.DeploymentKVPrefix+"/", "/", nil)
+	require.NoError(t, err)
+	for _, k := range keys {
+		require.False(t, IsValidationDeploymentID(k[len(consulutil.DeploymentKVPrefix)+1:]), "validation deployment %q stored with deployments", k)
+	}
+}
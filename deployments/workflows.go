@@ -23,13 +23,14 @@ import (
 	"github.com/pkg/errors"
 	"github.com/ystia/yorc/helper/consulutil"
 	"github.com/ystia/yorc/tosca"
+	"gopkg.in/yaml.v2"
 	"strconv"
 	"strings"
 )
 
 // GetWorkflows returns the list of workflows names for a given deployment
 func GetWorkflows(kv *api.KV, deploymentID string) ([]string, error) {
-	workflowsPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "workflows")
+	workflowsPath := path.Join(deploymentKVPrefix(deploymentID), "workflows")
 	keys, _, err := kv.Keys(workflowsPath+"/", "/", nil)
 	if err != nil {
 		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
@@ -43,7 +44,7 @@ func GetWorkflows(kv *api.KV, deploymentID string) ([]string, error) {
 
 // ReadWorkflow reads a workflow definition from Consul and built its TOSCA representation
 func ReadWorkflow(kv *api.KV, deploymentID, workflowName string) (tosca.Workflow, error) {
-	workflowPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "workflows", workflowName)
+	workflowPath := path.Join(deploymentKVPrefix(deploymentID), "workflows", workflowName)
 	steps, _, err := kv.Keys(workflowPath+"/steps/", "/", nil)
 	wf := tosca.Workflow{}
 	if err != nil {
@@ -83,6 +84,10 @@ func readWfStep(kv *api.KV, stepKey string, stepName string, wfName string) (tos
 	if kvp != nil && len(kvp.Value) != 0 {
 		step.TargetRelationShip = string(kvp.Value)
 	}
+	step.Retry, err = ReadWorkflowStepRetry(kv, stepKey)
+	if err != nil {
+		return step, err
+	}
 	// Get the step's activities
 	activitiesKeys, _, err := kv.List(stepKey+"/activities", nil)
 	if err != nil {
@@ -125,20 +130,164 @@ func readWfStep(kv *api.KV, stepKey string, stepName string, wfName string) (tos
 		step.Target = string(kvp.Value)
 	}
 
+	step.Filter, err = ReadWorkflowStepFilter(kv, stepKey)
+	if err != nil {
+		return step, err
+	}
+
 	// Get the next steps of the current step and use it to set the OnSuccess filed
-	nextSteps, _, err := kv.Keys(stepKey+"/next/", "/", nil)
+	step.OnSuccess, err = readWfStepLinks(kv, stepKey, "next")
 	if err != nil {
-		return step, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+		return step, err
+	}
+	// Get the steps to run on failure of the current step and use it to set the OnFailure filed
+	step.OnFailure, err = readWfStepLinks(kv, stepKey, "on_failure")
+	return step, err
+}
+
+func readWfStepLinks(kv *api.KV, stepKey, linkType string) ([]string, error) {
+	linkedSteps, _, err := kv.Keys(path.Join(stepKey, linkType)+"/", "/", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if len(linkedSteps) == 0 {
+		return nil, nil
+	}
+	links := make([]string, len(linkedSteps))
+	for i, linkedKey := range linkedSteps {
+		links[i], err = url.QueryUnescape(path.Base(linkedKey))
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to get back step name from Consul")
+		}
+	}
+	return links, nil
+}
+
+// ReadWorkflowStepFilter reads the filter of a workflow step stored under the given step Consul key prefix.
+//
+// Returns nil if no filter is defined for this step.
+func ReadWorkflowStepFilter(kv *api.KV, stepKey string) ([]tosca.ConditionClause, error) {
+	kvp, _, err := kv.Get(path.Join(stepKey, "filter"), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp == nil || len(kvp.Value) == 0 {
+		return nil, nil
+	}
+	var filter []tosca.ConditionClause
+	err = yaml.Unmarshal(kvp.Value, &filter)
+	return filter, errors.Wrapf(err, "Failed to read filter of step %q", path.Base(stepKey))
+}
+
+// ReadWorkflowStepRetry reads the retry policy of a workflow step stored under the given step Consul key prefix.
+//
+// Returns nil if no retry policy is defined for this step.
+func ReadWorkflowStepRetry(kv *api.KV, stepKey string) (*tosca.Retry, error) {
+	retryPrefix := path.Join(stepKey, "retry")
+	kvps, _, err := kv.List(retryPrefix+"/", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if len(kvps) == 0 {
+		return nil, nil
 	}
-	if len(nextSteps) > 0 {
-		step.OnSuccess = make([]string, len(nextSteps))
-		for i, nextKey := range nextSteps {
-			nextName, err := url.QueryUnescape(path.Base(nextKey))
+	retry := &tosca.Retry{}
+	retryableErrors := make(map[int]string)
+	for _, kvp := range kvps {
+		key := strings.TrimPrefix(kvp.Key, retryPrefix+"/")
+		switch {
+		case key == "max_attempts":
+			retry.MaxAttempts, err = strconv.Atoi(string(kvp.Value))
 			if err != nil {
-				return step, errors.Wrapf(err, "Failed to get back step name from Consul")
+				return nil, errors.Wrapf(err, "Invalid max_attempts value %q for step %q", string(kvp.Value), path.Base(stepKey))
+			}
+		case key == "delay":
+			retry.Delay = string(kvp.Value)
+		case key == "max_delay":
+			retry.MaxDelay = string(kvp.Value)
+		case key == "backoff":
+			retry.Backoff = string(kvp.Value)
+		case strings.HasPrefix(key, "retryable_errors/"):
+			index, err := strconv.Atoi(path.Base(key))
+			if err != nil {
+				return nil, errors.Wrapf(err, "Invalid retryable error index %q for step %q", path.Base(key), path.Base(stepKey))
+			}
+			retryableErrors[index] = string(kvp.Value)
+		}
+	}
+	if len(retryableErrors) > 0 {
+		retry.RetryableErrors = make([]string, len(retryableErrors))
+		for i := range retry.RetryableErrors {
+			retry.RetryableErrors[i] = retryableErrors[i]
+		}
+	}
+	return retry, nil
+}
+
+// expandGroupsSteps returns the steps of a workflow where each step targeting a group is replaced by
+// one step per member of this group.
+//
+// Expanded steps are named <step name>_<member name> and inherit the activities, filter, retry policy
+// and links of the original step. Links to the original step are replaced by links to all its expanded steps
+// so members of a group are operated in parallel.
+func expandGroupsSteps(wfName string, steps map[string]tosca.Step, groups map[string]tosca.Group) (map[string]tosca.Step, error) {
+	expansions := make(map[string][]string)
+	for stepName, step := range steps {
+		group, ok := groups[step.Target]
+		if !ok {
+			continue
+		}
+		if step.TargetRelationShip != "" {
+			return nil, errors.Errorf("step %q in workflow %q targets group %q: relationship operations can't be applied on groups", stepName, wfName, step.Target)
+		}
+		if len(group.Members) == 0 {
+			return nil, errors.Errorf("step %q in workflow %q targets group %q that has no members", stepName, wfName, step.Target)
+		}
+		names := make([]string, len(group.Members))
+		for i, member := range group.Members {
+			names[i] = stepName + "_" + member
+		}
+		expansions[stepName] = names
+	}
+	if len(expansions) == 0 {
+		return steps, nil
+	}
+
+	rewriteLinks := func(links []string) []string {
+		if links == nil {
+			return nil
+		}
+		result := make([]string, 0, len(links))
+		for _, link := range links {
+			if names, ok := expansions[link]; ok {
+				result = append(result, names...)
+			} else {
+				result = append(result, link)
+			}
+		}
+		return result
+	}
+
+	result := make(map[string]tosca.Step, len(steps))
+	for stepName, step := range steps {
+		step.OnSuccess = rewriteLinks(step.OnSuccess)
+		step.OnFailure = rewriteLinks(step.OnFailure)
+		names, ok := expansions[stepName]
+		if !ok {
+			result[stepName] = step
+			continue
+		}
+		for i, member := range groups[step.Target].Members {
+			if _, exists := steps[names[i]]; exists {
+				return nil, errors.Errorf("can't expand step %q targeting group %q in workflow %q: a step named %q already exists", stepName, step.Target, wfName, names[i])
+			}
+			if _, exists := result[names[i]]; exists {
+				return nil, errors.Errorf("can't expand step %q targeting group %q in workflow %q: step %q is generated several times", stepName, step.Target, wfName, names[i])
 			}
-			step.OnSuccess[i] = nextName
+			memberStep := step
+			memberStep.Target = member
+			result[names[i]] = memberStep
 		}
 	}
-	return step, nil
+	return result, nil
 }
@@ -0,0 +1,95 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/tosca"
+)
+
+func TestExpandGroupsSteps(t *testing.T) {
+	t.Parallel()
+	groups := map[string]tosca.Group{
+		"replicas": {Type: "tosca.groups.Root", Members: []string{"DB1", "DB2"}},
+		"empty":    {Type: "tosca.groups.Root"},
+	}
+	activities := []tosca.Activity{{CallOperation: "Standard.start"}}
+
+	t.Run("NoGroupTarget", func(t *testing.T) {
+		steps := map[string]tosca.Step{
+			"DB1_start": {Target: "DB1", Activities: activities},
+		}
+		result, err := expandGroupsSteps("install", steps, groups)
+		require.NoError(t, err)
+		assert.Equal(t, steps, result)
+	})
+
+	t.Run("GroupTarget", func(t *testing.T) {
+		steps := map[string]tosca.Step{
+			"Compute_start":  {Target: "Compute", Activities: activities, OnSuccess: []string{"replicas_start"}},
+			"replicas_start": {Target: "replicas", Activities: activities, OnSuccess: []string{"App_start"}, OnFailure: []string{"Rollback"}},
+			"App_start":      {Target: "App", Activities: activities},
+			"Rollback":       {Target: "App", Activities: activities},
+		}
+		result, err := expandGroupsSteps("install", steps, groups)
+		require.NoError(t, err)
+		names := make([]string, 0, len(result))
+		for name := range result {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		assert.Equal(t, []string{"App_start", "Compute_start", "Rollback", "replicas_start_DB1", "replicas_start_DB2"}, names)
+		assert.Equal(t, []string{"replicas_start_DB1", "replicas_start_DB2"}, result["Compute_start"].OnSuccess)
+		for _, member := range []string{"DB1", "DB2"} {
+			step := result["replicas_start_"+member]
+			assert.Equal(t, member, step.Target)
+			assert.Equal(t, activities, step.Activities)
+			assert.Equal(t, []string{"App_start"}, step.OnSuccess)
+			assert.Equal(t, []string{"Rollback"}, step.OnFailure)
+		}
+		// Original steps should not be modified
+		assert.Equal(t, []string{"replicas_start"}, steps["Compute_start"].OnSuccess)
+	})
+
+	t.Run("NameCollision", func(t *testing.T) {
+		steps := map[string]tosca.Step{
+			"replicas_start":     {Target: "replicas", Activities: activities},
+			"replicas_start_DB1": {Target: "DB1", Activities: activities},
+		}
+		_, err := expandGroupsSteps("install", steps, groups)
+		assert.Error(t, err)
+	})
+
+	t.Run("EmptyGroup", func(t *testing.T) {
+		steps := map[string]tosca.Step{
+			"empty_start": {Target: "empty", Activities: activities},
+		}
+		_, err := expandGroupsSteps("install", steps, groups)
+		assert.Error(t, err)
+	})
+
+	t.Run("RelationshipStep", func(t *testing.T) {
+		steps := map[string]tosca.Step{
+			"replicas_rel": {Target: "replicas", TargetRelationShip: "host", Activities: activities},
+		}
+		_, err := expandGroupsSteps("install", steps, groups)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,80 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Filter allows to select status updates and log entries based on the node, task,
+// workflow or log level they relate to.
+//
+// Each criterion accepts a set of values and an entry matches a criterion if it matches
+// one of its values. An empty criterion matches everything.
+// Criteria that do not make sense for a kind of entry are ignored: status updates
+// are not related to a workflow or a log level.
+type Filter struct {
+	Nodes     []string
+	Tasks     []string
+	Workflows []string
+	Levels    []string
+}
+
+// IsEmpty returns true if no criterion is set on this filter
+func (f Filter) IsEmpty() bool {
+	return len(f.Nodes) == 0 && len(f.Tasks) == 0 && len(f.Workflows) == 0 && len(f.Levels) == 0
+}
+
+// MatchStatusUpdate checks if a given status update matches this filter
+func (f Filter) MatchStatusUpdate(su StatusUpdate) bool {
+	return matchValue(f.Nodes, su.Node, false) && matchValue(f.Tasks, su.TaskID, false)
+}
+
+// MatchLog checks if a given log entry, as stored by LogEntry.Register, matches this filter
+func (f Filter) MatchLog(entry json.RawMessage) (bool, error) {
+	if f.IsEmpty() {
+		return true, nil
+	}
+	var flat map[string]interface{}
+	err := json.Unmarshal(entry, &flat)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to decode log entry")
+	}
+	getField := func(name string) string {
+		if v, ok := flat[name].(string); ok {
+			return v
+		}
+		return ""
+	}
+	return matchValue(f.Nodes, getField(NodeID.String()), false) &&
+		matchValue(f.Tasks, getField(ExecutionID.String()), false) &&
+		matchValue(f.Workflows, getField(WorkFlowID.String()), false) &&
+		matchValue(f.Levels, getField("level"), true), nil
+}
+
+func matchValue(values []string, value string, ignoreCase bool) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if v == value || ignoreCase && strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
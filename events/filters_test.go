@@ -0,0 +1,86 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterMatchStatusUpdate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		filter Filter
+		su     StatusUpdate
+		want   bool
+	}{
+		{"EmptyFilter", Filter{}, StatusUpdate{Type: "deployment", Status: "deployed"}, true},
+		{"NodeMatch", Filter{Nodes: []string{"Compute", "DB"}}, StatusUpdate{Type: "instance", Node: "DB", Instance: "0"}, true},
+		{"NodeMismatch", Filter{Nodes: []string{"Compute"}}, StatusUpdate{Type: "instance", Node: "DB", Instance: "0"}, false},
+		{"NodeOnDeploymentEvent", Filter{Nodes: []string{"Compute"}}, StatusUpdate{Type: "deployment", Status: "deployed"}, false},
+		{"TaskMatch", Filter{Tasks: []string{"t1"}}, StatusUpdate{Type: "workflow", TaskID: "t1"}, true},
+		{"TaskMismatch", Filter{Tasks: []string{"t1"}}, StatusUpdate{Type: "workflow", TaskID: "t2"}, false},
+		{"WorkflowAndLevelIgnored", Filter{Workflows: []string{"install"}, Levels: []string{"ERROR"}}, StatusUpdate{Type: "instance", Node: "DB"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.MatchStatusUpdate(tt.su))
+		})
+	}
+}
+
+func TestFilterMatchLog(t *testing.T) {
+	t.Parallel()
+	entry, err := json.Marshal(map[string]interface{}{
+		"deploymentId":         "dep",
+		"level":                "ERROR",
+		"content":              "failure",
+		"timestamp":            "2018-07-02T10:21:00.123456789Z",
+		WorkFlowID.String():    "install",
+		ExecutionID.String():   "t1",
+		NodeID.String():        "Compute",
+		OperationName.String(): "standard.create",
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"EmptyFilter", Filter{}, true},
+		{"NodeMatch", Filter{Nodes: []string{"DB", "Compute"}}, true},
+		{"NodeMismatch", Filter{Nodes: []string{"DB"}}, false},
+		{"TaskMatch", Filter{Tasks: []string{"t1"}}, true},
+		{"WorkflowMismatch", Filter{Workflows: []string{"uninstall"}}, false},
+		{"LevelIgnoreCase", Filter{Levels: []string{"error", "warn"}}, true},
+		{"LevelMismatch", Filter{Levels: []string{"INFO"}}, false},
+		{"AllCriteria", Filter{Nodes: []string{"Compute"}, Tasks: []string{"t1"}, Workflows: []string{"install"}, Levels: []string{"ERROR"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.filter.MatchLog(entry)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err = Filter{Nodes: []string{"Compute"}}.MatchLog(json.RawMessage("not json"))
+	assert.Error(t, err)
+}
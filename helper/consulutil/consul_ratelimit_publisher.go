@@ -144,6 +144,16 @@ func InitConsulPublisher(maxItems int, kv *api.KV) {
 
 }
 
+// GetKV returns the Consul KV client used by the Consul Publisher
+//
+// It is nil if InitConsulPublisher was not called.
+func GetKV() *api.KV {
+	if consulPub == nil {
+		return nil
+	}
+	return consulPub.kv
+}
+
 func (c *rateLimitedConsulPublisher) publish(ctx context.Context, kvp *api.KVPair) {
 	select {
 	case <-ctx.Done():
@@ -19,6 +19,11 @@ const yorcPrefix string = "_yorc"
 // DeploymentKVPrefix is the prefix in Consul KV store for deployments
 const DeploymentKVPrefix string = yorcPrefix + "/deployments"
 
+// ValidationsKVPrefix is the prefix in Consul KV store for deployments definitions temporarily stored to be validated
+//
+// They are kept apart from deployments so they are never seen as actual deployments.
+const ValidationsKVPrefix string = yorcPrefix + "/validations"
+
 // TasksPrefix is the prefix in Consul KV store for tasks
 const TasksPrefix = yorcPrefix + "/tasks"
 
@@ -36,3 +41,6 @@ const LogsPrefix = yorcPrefix + "/logs"
 
 // HostsPoolPrefix is the prefix on KV store for the hosts pool service
 const HostsPoolPrefix = yorcPrefix + "/hosts_pool"
+
+// SchedulingPrefix is the prefix on KV store for the tasks scheduler service
+const SchedulingPrefix = yorcPrefix + "/scheduling"
@@ -0,0 +1,226 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cronutil provides a parser for cron expressions and allows to compute their next occurrences.
+package cronutil
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxSearchYears is the number of years after which we consider that an expression will never be activated (like 30 FEB)
+const maxSearchYears = 5
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var dayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+type field struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	minuteField = field{"minute", 0, 59, nil}
+	hourField   = field{"hour", 0, 23, nil}
+	domField    = field{"day of month", 1, 31, nil}
+	monthField  = field{"month", 1, 12, monthNames}
+	// 7 is an alias for sunday
+	dowField = field{"day of week", 0, 7, dayNames}
+)
+
+// A Schedule is a parsed cron expression
+type Schedule struct {
+	expression string
+	minutes    uint64
+	hours      uint64
+	doms       uint64
+	months     uint64
+	dows       uint64
+	// when both days of month and days of week are restricted a day matches if any of them matches
+	domRestricted bool
+	dowRestricted bool
+}
+
+// Parse parses a standard 5-fields cron expression (minute, hour, day of month, month and day of week)
+//
+// Fields support lists (1,15), ranges (1-5), steps (*/10 or 0-30/5) and names for months and days of week (JAN, MON).
+// Descriptors @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly are also supported.
+func Parse(expression string) (*Schedule, error) {
+	expr := strings.TrimSpace(expression)
+	if d, ok := descriptors[strings.ToLower(expr)]; ok {
+		expr = d
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, errors.Errorf("invalid cron expression %q: expecting 5 fields, got %d", expression, len(fields))
+	}
+	s := &Schedule{expression: expression}
+	var err error
+	if s.minutes, err = parseField(fields[0], minuteField); err != nil {
+		return nil, errors.Wrapf(err, "invalid cron expression %q", expression)
+	}
+	if s.hours, err = parseField(fields[1], hourField); err != nil {
+		return nil, errors.Wrapf(err, "invalid cron expression %q", expression)
+	}
+	if s.doms, err = parseField(fields[2], domField); err != nil {
+		return nil, errors.Wrapf(err, "invalid cron expression %q", expression)
+	}
+	if s.months, err = parseField(fields[3], monthField); err != nil {
+		return nil, errors.Wrapf(err, "invalid cron expression %q", expression)
+	}
+	if s.dows, err = parseField(fields[4], dowField); err != nil {
+		return nil, errors.Wrapf(err, "invalid cron expression %q", expression)
+	}
+	if s.dows&(1<<7) != 0 {
+		s.dows |= 1
+	}
+	s.domRestricted = !strings.HasPrefix(fields[2], "*")
+	s.dowRestricted = !strings.HasPrefix(fields[4], "*")
+	return s, nil
+}
+
+// String returns the original cron expression
+func (s *Schedule) String() string {
+	return s.expression
+}
+
+// Next returns the first activation time of the schedule strictly after the given time
+//
+// A zero time is returned if the schedule will never be activated.
+func (s *Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Add(time.Minute - time.Duration(t.Second())*time.Second - time.Duration(t.Nanosecond()))
+	limit := t.AddDate(maxSearchYears, 0, 0)
+	for t.Before(limit) {
+		if s.months&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hours&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minutes&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) matchDay(t time.Time) bool {
+	domMatch := s.doms&(1<<uint(t.Day())) != 0
+	dowMatch := s.dows&(1<<uint(t.Weekday())) != 0
+	if s.domRestricted && s.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+func parseField(value string, f field) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(value, ",") {
+		b, err := parseFieldPart(part, f)
+		if err != nil {
+			return 0, err
+		}
+		bits |= b
+	}
+	return bits, nil
+}
+
+func parseFieldPart(part string, f field) (uint64, error) {
+	rangeAndStep := strings.SplitN(part, "/", 2)
+	start, end := f.min, f.max
+	step := 1
+	switch r := rangeAndStep[0]; {
+	case r == "*":
+	case strings.Contains(r, "-"):
+		bounds := strings.SplitN(r, "-", 2)
+		var err error
+		if start, err = parseValue(bounds[0], f); err != nil {
+			return 0, err
+		}
+		if end, err = parseValue(bounds[1], f); err != nil {
+			return 0, err
+		}
+		if start > end {
+			return 0, errors.Errorf("invalid range %q for %s", r, f.name)
+		}
+	default:
+		v, err := parseValue(r, f)
+		if err != nil {
+			return 0, err
+		}
+		start = v
+		end = v
+		if len(rangeAndStep) == 2 {
+			// like 5/10 which means from 5 to max every 10
+			end = f.max
+		}
+	}
+	if len(rangeAndStep) == 2 {
+		var err error
+		step, err = strconv.Atoi(rangeAndStep[1])
+		if err != nil || step <= 0 {
+			return 0, errors.Errorf("invalid step %q for %s", rangeAndStep[1], f.name)
+		}
+	}
+	var bits uint64
+	for i := start; i <= end; i += step {
+		bits |= 1 << uint(i)
+	}
+	return bits, nil
+}
+
+func parseValue(value string, f field) (int, error) {
+	if v, ok := f.names[strings.ToLower(value)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, errors.Errorf("invalid value %q for %s", value, f.name)
+	}
+	if v < f.min || v > f.max {
+		return 0, errors.Errorf("value %d out of range [%d-%d] for %s", v, f.min, f.max, f.name)
+	}
+	return v, nil
+}
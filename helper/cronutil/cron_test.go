@@ -0,0 +1,77 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cronutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+	tests := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"@every 5m",
+	}
+	for _, expr := range tests {
+		t.Run(expr, func(t *testing.T) {
+			_, err := Parse(expr)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	t.Parallel()
+	// Monday 15 January 2018
+	from := time.Date(2018, time.January, 15, 10, 42, 30, 0, time.UTC)
+	tests := []struct {
+		expression string
+		want       time.Time
+	}{
+		{"* * * * *", time.Date(2018, time.January, 15, 10, 43, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2018, time.January, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 2 * * *", time.Date(2018, time.January, 16, 2, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2018, time.January, 16, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2018, time.January, 15, 11, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2018, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"30 1 * * SAT,sun", time.Date(2018, time.January, 20, 1, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2018, time.January, 21, 0, 0, 0, 0, time.UTC)},
+		{"0 8-18/4 * * MON-FRI", time.Date(2018, time.January, 15, 12, 0, 0, 0, time.UTC)},
+		{"0 0 29 FEB *", time.Date(2020, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		// Either the 1st of the month or a friday
+		{"0 0 1 * 5", time.Date(2018, time.January, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			s, err := Parse(tt.expression)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, s.Next(from))
+		})
+	}
+}
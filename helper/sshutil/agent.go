@@ -0,0 +1,50 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sshutil
+
+import (
+	"net"
+	"os"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+const agentSocketEnvVariable = "SSH_AUTH_SOCK"
+
+// SSHAgentAuth returns an authentication method relying on the keys held by the SSH agent listening on the socket
+// defined by the SSH_AUTH_SOCK environment variable.
+//
+// ok is false if this environment variable is not set.
+func SSHAgentAuth() (auth ssh.AuthMethod, ok bool) {
+	socket := os.Getenv(agentSocketEnvVariable)
+	if socket == "" {
+		return nil, false
+	}
+	return ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+		// The connection is kept open as signers use it to sign the authentication requests
+		conn, err := net.Dial("unix", socket)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to connect to the SSH agent on %q", socket)
+		}
+		signers, err := agent.NewClient(conn).Signers()
+		if err != nil {
+			conn.Close()
+			return nil, errors.Wrap(err, "failed to retrieve the keys of the SSH agent")
+		}
+		return signers, nil
+	}), true
+}
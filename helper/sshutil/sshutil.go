@@ -16,12 +16,18 @@ package sshutil
 
 import (
 	"bytes"
+	"encoding/pem"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	homedir "github.com/mitchellh/go-homedir"
 	"github.com/pkg/errors"
 	"github.com/ystia/yorc/log"
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/net/context"
-	"io"
 )
 
 // Client is interface allowing running command
@@ -41,6 +47,12 @@ type SSHClient struct {
 	Config *ssh.ClientConfig
 	Host   string
 	Port   int
+	// ReuseConnections allows to open sessions on already opened connections instead of opening a new
+	// connection for each session. Connections are kept open until Close is called.
+	ReuseConnections bool
+
+	lock        sync.Mutex
+	connections []*ssh.Client
 }
 
 // GetSessionWrapper allows to return a session wrapper in order to handle stdout/stderr for running long synchronous commands
@@ -83,19 +95,85 @@ func (client *SSHClient) RunCommand(cmd string) (string, error) {
 }
 
 func (client *SSHClient) newSession() (*ssh.Session, error) {
+	if !client.ReuseConnections {
+		connection, err := client.dial()
+		if err != nil {
+			return nil, err
+		}
+		return newSessionOnConnection(connection)
+	}
+
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	openedConnections := client.connections[:0]
+	var session *ssh.Session
+	for _, connection := range client.connections {
+		if session != nil {
+			openedConnections = append(openedConnections, connection)
+			continue
+		}
+		var err error
+		session, err = connection.NewSession()
+		if err == nil {
+			openedConnections = append(openedConnections, connection)
+			continue
+		}
+		if _, ok := err.(*ssh.OpenChannelError); ok {
+			// The maximum number of sessions for this connection is reached but the connection is still usable
+			openedConnections = append(openedConnections, connection)
+			continue
+		}
+		log.Debugf("[SSHSession] closing broken connection to %s:%d: %v", client.Host, client.Port, err)
+		connection.Close()
+	}
+	client.connections = openedConnections
+	if session != nil {
+		return session, nil
+	}
+
+	connection, err := client.dial()
+	if err != nil {
+		return nil, err
+	}
+	session, err = newSessionOnConnection(connection)
+	if err != nil {
+		return nil, err
+	}
+	client.connections = append(client.connections, connection)
+	return session, nil
+}
+
+func (client *SSHClient) dial() (*ssh.Client, error) {
 	connection, err := ssh.Dial("tcp", fmt.Sprintf("%s:%d", client.Host, client.Port), client.Config)
 	if err != nil {
 		return nil, errors.Wrapf(err, "Failed to open SSH connection")
 	}
+	return connection, nil
+}
 
+func newSessionOnConnection(connection *ssh.Client) (*ssh.Session, error) {
 	session, err := connection.NewSession()
 	if err != nil {
+		connection.Close()
 		return nil, errors.Wrapf(err, "Failed to create session")
 	}
-
 	return session, nil
 }
 
+// Close closes the connections kept open when ReuseConnections is set
+func (client *SSHClient) Close() error {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	var err error
+	for _, connection := range client.connections {
+		if errClose := connection.Close(); errClose != nil {
+			err = errClose
+		}
+	}
+	client.connections = nil
+	return err
+}
+
 // RunCommand allows to run a specified command from a session wrapper in order to handle stdout/stderr during long synchronous commands
 func (sw *SSHSessionWrapper) RunCommand(ctx context.Context, cmd string) error {
 	chClosed := make(chan struct{})
@@ -119,3 +197,42 @@ func (sw *SSHSessionWrapper) RunCommand(ctx context.Context, cmd string) error {
 	err := sw.Session.Run(cmd)
 	return err
 }
+
+// ReadPrivateKey returns an authentication method relying on a given private key
+//
+// pk could be either the path of a key file or the key content itself.
+func ReadPrivateKey(pk string) (ssh.AuthMethod, error) {
+	var p []byte
+	// check if pk is a path
+	keyPath, err := homedir.Expand(pk)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to expand key path")
+	}
+	if _, err := os.Stat(keyPath); err == nil {
+		p, err = ioutil.ReadFile(keyPath)
+		if err != nil {
+			p = []byte(pk)
+		}
+	} else {
+		p = []byte(pk)
+	}
+
+	// We parse the private key on our own first so that we can
+	// show a nicer error if the private key has a password.
+	block, _ := pem.Decode(p)
+	if block == nil {
+		return nil, errors.Errorf("Failed to read key %q: no key found", pk)
+	}
+	if block.Headers["Proc-Type"] == "4,ENCRYPTED" {
+		return nil, errors.Errorf(
+			"Failed to read key %q: password protected keys are\n"+
+				"not supported. Please decrypt the key prior to use.", pk)
+	}
+
+	signer, err := ssh.ParsePrivateKey(p)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to parse key file %q", pk)
+	}
+
+	return ssh.PublicKeys(signer), nil
+}
@@ -17,6 +17,7 @@ package main
 import (
 	"github.com/ystia/yorc/commands"
 	_ "github.com/ystia/yorc/commands/deployments"
+	_ "github.com/ystia/yorc/commands/deployments/schedules"
 	_ "github.com/ystia/yorc/commands/deployments/tasks"
 	_ "github.com/ystia/yorc/commands/deployments/workflows"
 	_ "github.com/ystia/yorc/commands/hostspool"
@@ -25,11 +25,15 @@ import (
 	"os/exec"
 	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
 
 	"gopkg.in/yaml.v2"
@@ -37,6 +41,7 @@ import (
 	"github.com/ystia/yorc/config"
 	"github.com/ystia/yorc/deployments"
 	"github.com/ystia/yorc/events"
+	"github.com/ystia/yorc/helper/collections"
 	"github.com/ystia/yorc/helper/consulutil"
 	"github.com/ystia/yorc/helper/provutil"
 	"github.com/ystia/yorc/helper/stringutil"
@@ -54,6 +59,10 @@ stdout_callback = json
 retry_files_save_path = #PLAY_PATH#
 `
 
+// instancesParallelismPolicyType is the type of the policies allowing to override the number of instances of their
+// targets on which a per-instance operation is run concurrently
+const instancesParallelismPolicyType = "yorc.policies.InstancesParallelism"
+
 type ansibleRetriableError struct {
 	root error
 }
@@ -97,6 +106,8 @@ type execution interface {
 
 type ansibleRunner interface {
 	runAnsible(ctx context.Context, retry bool, currentInstance, ansibleRecipePath string) error
+	// withExecutionCommon returns a copy of this runner bound to the given executionCommon
+	withExecutionCommon(e *executionCommon) ansibleRunner
 }
 type executionCommon struct {
 	kv                       *api.KV
@@ -132,6 +143,9 @@ type executionCommon struct {
 	ansibleRunner            ansibleRunner
 	sourceNodeInstances      []string
 	targetNodeInstances      []string
+	// succeededInstances are the instances on which a per-instance operation already succeeded, they are skipped
+	// when the operation is retried
+	succeededInstances map[string]bool
 }
 
 func newExecution(kv *api.KV, cfg config.Configuration, taskID, deploymentID, nodeName string, operation prov.Operation) (execution, error) {
@@ -146,6 +160,7 @@ func newExecution(kv *api.KV, cfg config.Configuration, taskID, deploymentID, no
 		EnvInputs:               make([]*operations.EnvInput, 0),
 		taskID:                  taskID,
 		Outputs:                 make(map[string]string),
+		succeededInstances:      make(map[string]bool),
 	}
 	if err := execCommon.resolveOperation(); err != nil {
 		return nil, err
@@ -301,6 +316,37 @@ func (e *executionCommon) resolveArtifacts() error {
 	return nil
 }
 
+// InputValue returns the value of an operation input as provided to the operation.
+//
+// Artifacts paths returned by get_artifact functions are replaced by the path of the artifact on the remote host.
+// This method is exported in order to be used by text.Template but should be consider as internal
+func (e *executionCommon) InputValue(envInput *operations.EnvInput) string {
+	if envInput.IsArtifact {
+		return fmt.Sprintf("{{ ansible_env.HOME}}/%s/%s", e.OperationRemotePath, envInput.Value)
+	}
+	return envInput.Value
+}
+
+// UploadedArtifacts returns the sorted list of artifacts files to upload on remote hosts.
+//
+// It contains the artifacts of the operation and those returned by get_artifact functions in the operation inputs.
+// This method is exported in order to be used by text.Template but should be consider as internal
+func (e *executionCommon) UploadedArtifacts() []string {
+	artifacts := make([]string, 0, len(e.Artifacts))
+	for _, art := range e.Artifacts {
+		if !collections.ContainsString(artifacts, art) {
+			artifacts = append(artifacts, art)
+		}
+	}
+	for _, envInput := range e.EnvInputs {
+		if envInput.IsArtifact && !collections.ContainsString(artifacts, envInput.Value) {
+			artifacts = append(artifacts, envInput.Value)
+		}
+	}
+	sort.Strings(artifacts)
+	return artifacts
+}
+
 func (e *executionCommon) setEndpointCredentials(kv *api.KV, host, instanceID, capType string, conn *hostConnection) error {
 	hasEndpoint, err := deployments.IsTypeDerivedFrom(e.kv, e.deploymentID, capType, "yorc.capabilities.Endpoint.ProvisioningAdmin")
 	if err != nil {
@@ -622,13 +668,25 @@ func (e *executionCommon) execute(ctx context.Context, retry bool) error {
 			instances = e.sourceNodeInstances
 		}
 
+		parallelism, err := e.getInstancesParallelism()
+		if err != nil {
+			return err
+		}
+		if parallelism > 1 && len(instances) > 1 {
+			return e.executeInstancesConcurrently(ctx, retry, nodeName, instances, parallelism)
+		}
+
 		for _, instanceID := range instances {
 			instanceName := operations.GetInstanceName(nodeName, instanceID)
+			if e.succeededInstances[instanceName] {
+				continue
+			}
 			log.Debugf("Executing operation %q, on node %q, with current instance %q", e.operation.Name, e.NodeName, instanceName)
 			err := e.executeWithCurrentInstance(ctx, retry, instanceName)
 			if err != nil {
 				return err
 			}
+			e.succeededInstances[instanceName] = true
 		}
 	} else {
 		return e.executeWithCurrentInstance(ctx, retry, "")
@@ -636,6 +694,98 @@ func (e *executionCommon) execute(ctx context.Context, retry bool) error {
 	return nil
 }
 
+// getInstancesParallelism returns the number of instances on which a per-instance operation could be run concurrently
+//
+// yorc.policies.InstancesParallelism policies targeting the node take precedence over the server configuration.
+// If several of them target the node the lowest parallelism applies.
+func (e *executionCommon) getInstancesParallelism() (int, error) {
+	policies, err := deployments.GetPoliciesForNode(e.kv, e.deploymentID, e.NodeName, instancesParallelismPolicyType)
+	if err != nil {
+		return 0, err
+	}
+	if len(policies) == 0 {
+		return e.cfg.Ansible.InstancesParallelism, nil
+	}
+	result := 0
+	for _, policy := range policies {
+		found, value, err := deployments.GetPolicyProperty(e.kv, e.deploymentID, policy, "parallelism")
+		if err != nil {
+			return 0, err
+		}
+		parallelism, err := strconv.Atoi(value)
+		if !found || err != nil || parallelism < 1 {
+			return 0, errors.Errorf("Invalid parallelism %q for policy %q, expecting a strictly positive integer", value, policy)
+		}
+		if result == 0 || parallelism < result {
+			result = parallelism
+		}
+	}
+	return result, nil
+}
+
+// executeInstancesConcurrently runs a per-instance operation on the given instances with at most parallelism concurrent executions
+//
+// Contrary to sequential executions, a failure on an instance does not prevent the operation from being run on the others.
+// Errors of all instances are aggregated, the resulting error is retriable only if all of them are retriable.
+// Instances on which the operation succeeded are recorded so only failed ones are run again on retry.
+func (e *executionCommon) executeInstancesConcurrently(ctx context.Context, retry bool, nodeName string, instances []string, parallelism int) error {
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	var errs *multierror.Error
+	allRetriable := true
+	sem := make(chan struct{}, parallelism)
+instancesLoop:
+	for _, instanceID := range instances {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			lock.Lock()
+			errs = multierror.Append(errs, ctx.Err())
+			allRetriable = false
+			lock.Unlock()
+			break instancesLoop
+		}
+		instanceName := operations.GetInstanceName(nodeName, instanceID)
+		lock.Lock()
+		succeeded := e.succeededInstances[instanceName]
+		lock.Unlock()
+		if succeeded {
+			<-sem
+			continue
+		}
+		wg.Add(1)
+		go func(instanceName string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			log.Debugf("Executing operation %q, on node %q, with current instance %q", e.operation.Name, e.NodeName, instanceName)
+			err := e.copyForInstance().executeWithCurrentInstance(ctx, retry, instanceName)
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				errs = multierror.Append(errs, errors.Wrapf(err, "instance %q", instanceName))
+				allRetriable = allRetriable && IsRetriable(err)
+				return
+			}
+			e.succeededInstances[instanceName] = true
+		}(instanceName)
+	}
+	wg.Wait()
+	err := errs.ErrorOrNil()
+	if err != nil && allRetriable {
+		return ansibleRetriableError{root: err}
+	}
+	return err
+}
+
+// copyForInstance returns a shallow copy of this execution to be run concurrently with others on a given instance
+//
+// Executions on an instance update some fields like the remote operation path, so they should not be shared.
+func (e *executionCommon) copyForInstance() *executionCommon {
+	c := *e
+	c.ansibleRunner = e.ansibleRunner.withExecutionCommon(&c)
+	return &c
+}
+
 func (e *executionCommon) executeWithCurrentInstance(ctx context.Context, retry bool, currentInstance string) error {
 	// Fill log optional fields for log registration
 	wfName, _ := tasks.GetTaskData(e.kv, e.taskID, "workflowName")
@@ -648,6 +798,15 @@ func (e *executionCommon) executeWithCurrentInstance(ctx context.Context, retry
 	}
 
 	events.WithOptionalFields(logOptFields).NewLogEntry(events.INFO, e.deploymentID).RegisterAsString("Start the ansible execution of : " + e.NodeName + " with operation : " + e.operation.Name)
+	// e.OperationRemoteBaseDir is an unique base temp directory for multiple executions
+	// It is computed first as inputs returned by get_artifact functions refer to it
+	e.OperationRemoteBaseDir = stringutil.UniqueTimestampedName(e.cfg.Ansible.OperationRemoteBaseDir+"_", "")
+	if e.operation.RelOp.IsRelationshipOperation {
+		e.OperationRemotePath = path.Join(e.OperationRemoteBaseDir, e.NodeName, e.relationshipType, e.operation.Name)
+	} else {
+		e.OperationRemotePath = path.Join(e.OperationRemoteBaseDir, e.NodeName, e.operation.Name)
+	}
+	log.Debugf("OperationRemotePath:%s", e.OperationRemotePath)
 	var ansibleRecipePath string
 	if e.operation.RelOp.IsRelationshipOperation {
 		ansibleRecipePath = filepath.Join(e.cfg.WorkingDirectory, "deployments", e.deploymentID, "ansible", e.NodeName, e.relationshipType, e.operation.TargetRelationship, e.operation.Name, currentInstance)
@@ -724,7 +883,7 @@ func (e *executionCommon) executeWithCurrentInstance(ctx context.Context, retry
 			} else {
 				for _, envInput := range e.EnvInputs {
 					if envInput.Name == varInput && (envInput.InstanceName == instanceName || e.isPerInstanceOperation && envInput.InstanceName == currentInstance) {
-						perInstanceInputsBuffer.WriteString(fmt.Sprintf("%s: %q\n", varInput, envInput.Value))
+						perInstanceInputsBuffer.WriteString(fmt.Sprintf("%s: %q\n", varInput, e.InputValue(envInput)))
 						goto NEXT
 					}
 				}
@@ -741,7 +900,7 @@ func (e *executionCommon) executeWithCurrentInstance(ctx context.Context, retry
 							instanceID := instanceName[instanceIDIdx:]
 							for _, envInput := range e.EnvInputs {
 								if envInput.Name == varInput && strings.HasSuffix(envInput.InstanceName, instanceID) {
-									perInstanceInputsBuffer.WriteString(fmt.Sprintf("%s: %q\n", varInput, envInput.Value))
+									perInstanceInputsBuffer.WriteString(fmt.Sprintf("%s: %q\n", varInput, e.InputValue(envInput)))
 									goto NEXT
 								}
 							}
@@ -751,7 +910,7 @@ func (e *executionCommon) executeWithCurrentInstance(ctx context.Context, retry
 				// Not found with the combination inputName/instanceName let's use the first that matches the input name
 				for _, envInput := range e.EnvInputs {
 					if envInput.Name == varInput {
-						perInstanceInputsBuffer.WriteString(fmt.Sprintf("%s: %q\n", varInput, envInput.Value))
+						perInstanceInputsBuffer.WriteString(fmt.Sprintf("%s: %q\n", varInput, e.InputValue(envInput)))
 						goto NEXT
 					}
 				}
@@ -776,14 +935,6 @@ func (e *executionCommon) executeWithCurrentInstance(ctx context.Context, retry
 		events.WithOptionalFields(logOptFields).NewLogEntry(events.ERROR, e.deploymentID).RegisterAsString(err.Error())
 		return err
 	}
-	// e.OperationRemoteBaseDir is an unique base temp directory for multiple executions
-	e.OperationRemoteBaseDir = stringutil.UniqueTimestampedName(e.cfg.Ansible.OperationRemoteBaseDir+"_", "")
-	if e.operation.RelOp.IsRelationshipOperation {
-		e.OperationRemotePath = path.Join(e.OperationRemoteBaseDir, e.NodeName, e.relationshipType, e.operation.Name)
-	} else {
-		e.OperationRemotePath = path.Join(e.OperationRemoteBaseDir, e.NodeName, e.operation.Name)
-	}
-	log.Debugf("OperationRemotePath:%s", e.OperationRemotePath)
 	err = e.ansibleRunner.runAnsible(ctx, retry, currentInstance, ansibleRecipePath)
 	if err != nil {
 		return err
@@ -39,7 +39,7 @@ const ansiblePlaybook = `
   hosts: all
   strategy: free
   tasks:
-[[[ range $artName, $art := .Artifacts ]]]    [[[printf "- file: path=\"{{ ansible_env.HOME}}/%s/%s\" state=directory mode=0755" $.OperationRemotePath (path $art)]]]
+[[[ range $art := .UploadedArtifacts ]]]    [[[printf "- file: path=\"{{ ansible_env.HOME}}/%s/%s\" state=directory mode=0755" $.OperationRemotePath (path $art)]]]
     [[[printf "- copy: src=\"%s/%s\" dest=\"{{ ansible_env.HOME}}/%s/%s\"" $.OverlayPath $art $.OperationRemotePath (path $art)]]]
 [[[end]]]
 - import_playbook: [[[.PlaybookPath]]]
@@ -66,6 +66,10 @@ type executionAnsible struct {
 	PlaybookPath string
 }
 
+func (e *executionAnsible) withExecutionCommon(ec *executionCommon) ansibleRunner {
+	return &executionAnsible{executionCommon: ec, PlaybookPath: e.PlaybookPath}
+}
+
 func (e *executionAnsible) runAnsible(ctx context.Context, retry bool, currentInstance, ansibleRecipePath string) error {
 	var err error
 	// Fill log optional fields for log registration
@@ -95,7 +99,7 @@ func (e *executionAnsible) runAnsible(ctx context.Context, retry bool, currentIn
 			buffer.WriteString(envInput.InstanceName)
 			buffer.WriteString("_")
 		}
-		buffer.WriteString(fmt.Sprintf("%s: %q", envInput.Name, envInput.Value))
+		buffer.WriteString(fmt.Sprintf("%s: %q", envInput.Name, e.InputValue(envInput)))
 		buffer.WriteString("\n")
 	}
 
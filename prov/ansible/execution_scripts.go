@@ -131,15 +131,15 @@ const shellAnsiblePlaybook = `
     - file: path="{{ ansible_env.HOME}}/[[[.OperationRemotePath]]]" state=directory mode=0755
     [[[printf  "- copy: src=\"{{ wrapper_location }}\" dest=\"{{ ansible_env.HOME}}/%s/wrapper\" mode=0744" $.OperationRemotePath]]]
     - copy: src="{{ script_to_run }}" dest="{{ ansible_env.HOME}}/[[[.OperationRemotePath]]]" mode=0744
-    [[[ range $artName, $art := .Artifacts -]]]
+    [[[ range $art := .UploadedArtifacts -]]]
     [[[printf "- file: path=\"{{ ansible_env.HOME}}/%s/%s\" state=directory mode=0755" $.OperationRemotePath (path $art)]]]
     [[[printf "- copy: src=\"%s/%s\" dest=\"{{ ansible_env.HOME}}/%s/%s\"" $.OverlayPath $art $.OperationRemotePath (path $art)]]]
     [[[end]]]
     [[[printf "- shell: \"/bin/bash -l -c {{ ansible_env.HOME}}/%s/wrapper\"" $.OperationRemotePath]]]
       environment:
         [[[ range $key, $envInput := .EnvInputs -]]]
-        [[[ if (len $envInput.InstanceName) gt 0]]][[[ if (len $envInput.Value) gt 0]]][[[printf  "%s_%s: %q" $envInput.InstanceName $envInput.Name $envInput.Value]]][[[else]]][[[printf  "%s_%s: \"\"" $envInput.InstanceName $envInput.Name]]]
-        [[[end]]][[[else]]][[[ if (len $envInput.Value) gt 0]]][[[printf  "%s: %q" $envInput.Name $envInput.Value]]][[[else]]]
+        [[[ if (len $envInput.InstanceName) gt 0]]][[[ if (len $envInput.Value) gt 0]]][[[printf  "%s_%s: %q" $envInput.InstanceName $envInput.Name ($.InputValue $envInput)]]][[[else]]][[[printf  "%s_%s: \"\"" $envInput.InstanceName $envInput.Name]]]
+        [[[end]]][[[else]]][[[ if (len $envInput.Value) gt 0]]][[[printf  "%s: %q" $envInput.Name ($.InputValue $envInput)]]][[[else]]]
         [[[printf  "%s: \"\"" $envInput.Name]]]
         [[[end]]][[[end]]]
         [[[end]]][[[ range $artName, $art := .Artifacts -]]]
@@ -167,6 +167,10 @@ func cutAfterLastUnderscore(str string) string {
 	return str[:idx]
 }
 
+func (e *executionScript) withExecutionCommon(ec *executionCommon) ansibleRunner {
+	return &executionScript{executionCommon: ec, isPython: e.isPython}
+}
+
 func (e *executionScript) runAnsible(ctx context.Context, retry bool, currentInstance, ansibleRecipePath string) error {
 	// Fill log optional fields for log registration
 	wfName, _ := tasks.GetTaskData(e.kv, e.taskID, "workflowName")
@@ -75,6 +75,41 @@ func TestTemplates(t *testing.T) {
 	require.Nil(t, err)
 }
 
+func TestCopyForInstance(t *testing.T) {
+	t.Parallel()
+	ec := &executionCommon{
+		NodeName:            "Welcome",
+		OperationRemotePath: ".yorc/path/on/remote",
+	}
+	ec.ansibleRunner = &executionScript{executionCommon: ec, isPython: true}
+
+	c := ec.copyForInstance()
+	c.OperationRemotePath = ".yorc/other/path"
+	require.Equal(t, ".yorc/path/on/remote", ec.OperationRemotePath)
+
+	runner, ok := c.ansibleRunner.(*executionScript)
+	require.True(t, ok)
+	require.True(t, runner.isPython)
+	require.Equal(t, ".yorc/other/path", runner.OperationRemotePath)
+}
+
+func TestArtifactsInputs(t *testing.T) {
+	t.Parallel()
+	ec := &executionCommon{
+		Artifacts: map[string]string{"scripts": "my_scripts", "conf": "config/app.cfg"},
+		EnvInputs: []*operations.EnvInput{
+			{Name: "PORT", Value: "8080"},
+			{Name: "CONF", Value: "config/app.cfg", IsArtifact: true},
+			{Name: "OTHER", Value: "other/data.bin", IsArtifact: true},
+		},
+		OperationRemotePath: ".yorc/path/on/remote",
+	}
+
+	require.Equal(t, "8080", ec.InputValue(ec.EnvInputs[0]))
+	require.Equal(t, "{{ ansible_env.HOME}}/.yorc/path/on/remote/config/app.cfg", ec.InputValue(ec.EnvInputs[1]))
+	require.Equal(t, []string{"config/app.cfg", "my_scripts", "other/data.bin"}, ec.UploadedArtifacts())
+}
+
 func testExecution(t *testing.T, srv1 *testutil.TestServer, kv *api.KV) {
 	deploymentID := yorc_testutil.BuildDeploymentID(t)
 	err := deployments.StoreDeploymentDefinition(context.Background(), kv, deploymentID, "testdata/execTemplate.yml")
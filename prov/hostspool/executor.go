@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/ystia/yorc/helper/labelsutil"
 
@@ -108,13 +109,36 @@ func (e *defaultExecutor) hostsPoolCreate(ctx context.Context, cc *api.Client, c
 		filters = append(filters, f)
 	}
 
+	var leaseTTL time.Duration
+	_, ttlProp, err := deployments.GetNodeProperty(cc.KV(), deploymentID, nodeName, "lease_ttl")
+	if err != nil {
+		return err
+	}
+	if ttlProp != "" {
+		leaseTTL, err = time.ParseDuration(ttlProp)
+		if err != nil {
+			return errors.Wrapf(err, `failed to parse property "lease_ttl" for node %q as a duration`, nodeName)
+		}
+	}
+
 	instances, err := tasks.GetInstances(cc.KV(), taskID, deploymentID, nodeName)
 	if err != nil {
 		return err
 	}
 	for _, instance := range instances {
 		logOptFields[events.InstanceID] = instance
-		hostname, warnings, err := hpManager.Allocate(fmt.Sprintf(`allocated for node instance "%s-%s" in deployment %q`, nodeName, instance, deploymentID), filters...)
+		lease := &Lease{
+			DeploymentID:   deploymentID,
+			NodeName:       nodeName,
+			InstanceID:     instance,
+			AllocationDate: time.Now(),
+			TTL:            leaseTTL,
+		}
+		if leaseTTL > 0 {
+			expirationDate := lease.AllocationDate.Add(leaseTTL)
+			lease.ExpirationDate = &expirationDate
+		}
+		hostname, warnings, err := hpManager.Allocate(fmt.Sprintf(`allocated for node instance "%s-%s" in deployment %q`, nodeName, instance, deploymentID), lease, filters...)
 		for _, warn := range warnings {
 			events.WithOptionalFields(logOptFields).
 				NewLogEntry(events.WARN, deploymentID).Registerf(`%v`, warn)
@@ -259,7 +283,7 @@ func (e *defaultExecutor) hostsPoolDelete(ctx context.Context, cc *api.Client, c
 			events.WithOptionalFields(logOptFields).NewLogEntry(events.WARN, deploymentID).Registerf("instance %q of node %q does not have a registered hostname. This may be due to an error at creation time. Should be checked.", instance, nodeName)
 			continue
 		}
-		err = hpManager.Release(hostname)
+		err = hpManager.Release(hostname, deploymentID, nodeName, instance)
 		if err != nil {
 			errs = multierror.Append(errs, err)
 		}
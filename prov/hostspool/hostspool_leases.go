@@ -0,0 +1,278 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostspool
+
+import (
+	"path"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/log"
+)
+
+// leasesReconciliationPeriod is the period at which hosts leases are renewed or released
+const leasesReconciliationPeriod = time.Minute
+
+// leaseTxnOps returns the transaction operations replacing the lease of an host
+func leaseTxnOps(hostname string, lease Lease) api.KVTxnOps {
+	leaseKVPrefix := path.Join(consulutil.HostsPoolPrefix, hostname, "lease")
+	ops := api.KVTxnOps{
+		&api.KVTxnOp{
+			Verb: api.KVDeleteTree,
+			Key:  leaseKVPrefix,
+		},
+		&api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(leaseKVPrefix, "deployment_id"),
+			Value: []byte(lease.DeploymentID),
+		},
+		&api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(leaseKVPrefix, "node_name"),
+			Value: []byte(lease.NodeName),
+		},
+		&api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(leaseKVPrefix, "instance_id"),
+			Value: []byte(lease.InstanceID),
+		},
+		&api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(leaseKVPrefix, "allocation_date"),
+			Value: []byte(lease.AllocationDate.Format(time.RFC3339Nano)),
+		},
+	}
+	if lease.ExpirationDate != nil {
+		ops = append(ops, &api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(leaseKVPrefix, "expiration_date"),
+			Value: []byte(lease.ExpirationDate.Format(time.RFC3339Nano)),
+		})
+	}
+	if lease.TTL > 0 {
+		ops = append(ops, &api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(leaseKVPrefix, "ttl"),
+			Value: []byte(lease.TTL.String()),
+		})
+	}
+	return ops
+}
+
+func (cm *consulManager) setHostLease(hostname string, lease Lease) error {
+	return cm.runHostTxn(hostname, leaseTxnOps(hostname, lease))
+}
+
+// runHostTxn atomically applies the given operations on the definition of an host
+func (cm *consulManager) runHostTxn(hostname string, ops api.KVTxnOps) error {
+	ok, response, _, err := cm.cc.KV().Txn(ops, nil)
+	if err != nil {
+		return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if !ok {
+		return errors.Errorf("Failed to update host %q: %v", hostname, response.Errors)
+	}
+	return nil
+}
+
+// GetHostLease returns the lease of an allocated host or nil if the host has no lease
+func (cm *consulManager) GetHostLease(hostname string) (*Lease, error) {
+	if hostname == "" {
+		return nil, errors.WithStack(badRequestError{`"hostname" missing`})
+	}
+	kvps, _, err := cm.cc.KV().List(path.Join(consulutil.HostsPoolPrefix, hostname, "lease"), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if len(kvps) == 0 {
+		return nil, nil
+	}
+	lease := &Lease{}
+	for _, kvp := range kvps {
+		switch path.Base(kvp.Key) {
+		case "deployment_id":
+			lease.DeploymentID = string(kvp.Value)
+		case "node_name":
+			lease.NodeName = string(kvp.Value)
+		case "instance_id":
+			lease.InstanceID = string(kvp.Value)
+		case "allocation_date":
+			lease.AllocationDate, err = time.Parse(time.RFC3339Nano, string(kvp.Value))
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid lease allocation date for host %q", hostname)
+			}
+		case "expiration_date":
+			expirationDate, err := time.Parse(time.RFC3339Nano, string(kvp.Value))
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid lease expiration date for host %q", hostname)
+			}
+			lease.ExpirationDate = &expirationDate
+		case "ttl":
+			lease.TTL, err = time.ParseDuration(string(kvp.Value))
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid lease TTL for host %q", hostname)
+			}
+		}
+	}
+	return lease, nil
+}
+
+// ReconcileLeases releases allocated hosts whose owning deployment does not exist anymore
+//
+// Leases with a TTL are renewed while their deployment exists, once the deployment is removed their hosts are released
+// when the lease expires. Hosts allocated before leases were introduced get a lease built from their allocation message.
+// It returns the names of released hosts.
+func (cm *consulManager) ReconcileLeases() ([]string, error) {
+	return cm.reconcileLeasesWait(45 * time.Second)
+}
+func (cm *consulManager) reconcileLeasesWait(maxWaitTime time.Duration) ([]string, error) {
+	hosts, _, err := cm.List()
+	if err != nil {
+		return nil, err
+	}
+	released := make([]string, 0)
+	for _, hostname := range hosts {
+		ok, err := cm.reconcileLease(hostname, maxWaitTime)
+		if err != nil {
+			return released, err
+		}
+		if ok {
+			released = append(released, hostname)
+		}
+	}
+	return released, nil
+}
+
+// reconcileLease renews, creates or releases the lease of a host, the lease is checked while holding the hosts pool admin lock.
+//
+// It returns true if the host was released.
+func (cm *consulManager) reconcileLease(hostname string, maxWaitTime time.Duration) (bool, error) {
+	// Avoid taking the lock for hosts that are obviously not concerned
+	status, err := cm.GetHostStatus(hostname)
+	if err != nil || status != HostStatusAllocated {
+		if IsHostNotFoundError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	_, cleanupFn, err := cm.lockKey(hostname, "lease reconciliation", maxWaitTime)
+	if err != nil {
+		return false, err
+	}
+	defer cleanupFn()
+
+	status, err = cm.GetHostStatus(hostname)
+	if err != nil {
+		if IsHostNotFoundError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if status != HostStatusAllocated {
+		return false, nil
+	}
+	lease, err := cm.GetHostLease(hostname)
+	if err != nil {
+		return false, err
+	}
+	if lease == nil {
+		return false, cm.migrateLegacyAllocation(hostname)
+	}
+	exist, err := deployments.DoesDeploymentExists(cm.cc.KV(), lease.DeploymentID)
+	if err != nil {
+		return false, err
+	}
+	now := time.Now()
+	if exist {
+		if lease.TTL > 0 {
+			expirationDate := now.Add(lease.TTL)
+			lease.ExpirationDate = &expirationDate
+			return false, cm.setHostLease(hostname, *lease)
+		}
+		return false, nil
+	}
+	if lease.TTL > 0 && !lease.IsExpired(now) {
+		// Let the lease expire
+		return false, nil
+	}
+	log.Printf("Releasing host %q allocated to instance %q of node %q in deployment %q: owning deployment does not exist anymore", hostname, lease.InstanceID, lease.NodeName, lease.DeploymentID)
+	return true, cm.release(hostname, nil)
+}
+
+// legacyAllocationMessageRegexp matches the message set on hosts allocated by the hosts pool executor
+var legacyAllocationMessageRegexp = regexp.MustCompile(`^allocated for node instance "(.+)-([^-"]+)" in deployment "(.+)"$`)
+
+// migrateLegacyAllocation creates the lease of a host allocated before leases were introduced using its allocation message.
+//
+// Hosts whose message doesn't describe their allocation are left without lease and are never released automatically.
+func (cm *consulManager) migrateLegacyAllocation(hostname string) error {
+	message, err := cm.GetHostMessage(hostname)
+	if err != nil {
+		return err
+	}
+	matches := legacyAllocationMessageRegexp.FindStringSubmatch(message)
+	if matches == nil {
+		log.Debugf("Can't create a lease for host %q allocated without lease, unexpected allocation message %q", hostname, message)
+		return nil
+	}
+	deploymentID, err := strconv.Unquote(`"` + matches[3] + `"`)
+	if err != nil {
+		deploymentID = matches[3]
+	}
+	lease := Lease{
+		DeploymentID:   deploymentID,
+		NodeName:       matches[1],
+		InstanceID:     matches[2],
+		AllocationDate: time.Now(),
+	}
+	log.Printf("Creating lease for host %q allocated without lease to instance %q of node %q in deployment %q", hostname, lease.InstanceID, lease.NodeName, lease.DeploymentID)
+	return cm.setHostLease(hostname, lease)
+}
+
+// StartLeasesReconciliation periodically releases hosts of the pool whose lease is stale until the shutdown channel is closed
+//
+// See Manager.ReconcileLeases for details.
+func StartLeasesReconciliation(cc *api.Client, shutdownCh chan struct{}, wg *sync.WaitGroup) {
+	mgr := NewManager(cc)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(leasesReconciliationPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-shutdownCh:
+				log.Debugf("Hosts pool leases reconciliation stopped")
+				return
+			case <-ticker.C:
+				released, err := mgr.ReconcileLeases()
+				if err != nil {
+					log.Printf("[WARNING] Hosts pool leases reconciliation failed: %+v", err)
+				}
+				if len(released) > 0 {
+					log.Printf("Hosts pool leases reconciliation released %d host(s): %v", len(released), released)
+				}
+			}
+		}
+	}()
+}
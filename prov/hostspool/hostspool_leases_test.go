@@ -0,0 +1,34 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostspool
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLegacyAllocationMessageRegexp(t *testing.T) {
+	message := fmt.Sprintf(`allocated for node instance "%s-%s" in deployment %q`, "My-Compute", "12", "my-app")
+	matches := legacyAllocationMessageRegexp.FindStringSubmatch(message)
+	require.NotNil(t, matches)
+	assert.Equal(t, "My-Compute", matches[1])
+	assert.Equal(t, "12", matches[2])
+	assert.Equal(t, "my-app", matches[3])
+
+	assert.Nil(t, legacyAllocationMessageRegexp.FindStringSubmatch("allocated manually"))
+}
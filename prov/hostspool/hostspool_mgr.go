@@ -15,18 +15,14 @@
 package hostspool
 
 import (
-	"encoding/pem"
 	"fmt"
-	"io/ioutil"
 	"net/url"
-	"os"
 	"path"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/hashicorp/consul/api"
-	homedir "github.com/mitchellh/go-homedir"
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/ssh"
 
@@ -45,8 +41,9 @@ type Manager interface {
 	UpdateConnection(hostname string, connection Connection) error
 	List(filters ...labelsutil.Filter) ([]string, []labelsutil.Warning, error)
 	GetHost(hostname string) (Host, error)
-	Allocate(message string, filters ...labelsutil.Filter) (string, []labelsutil.Warning, error)
-	Release(hostname string) error
+	Allocate(message string, lease *Lease, filters ...labelsutil.Filter) (string, []labelsutil.Warning, error)
+	Release(hostname, deploymentID, nodeName, instanceID string) error
+	ReconcileLeases() ([]string, error)
 }
 
 // SSHClientFactory is a that could be called to customize the client used to check the connection.
@@ -586,6 +583,23 @@ func (cm *consulManager) setHostStatusWithMessage(hostname string, status HostSt
 	return cm.setHostMessage(hostname, message)
 }
 
+// hostStatusTxnOps returns the transaction operations setting the status and message of a host
+func hostStatusTxnOps(hostname string, status HostStatus, message string) api.KVTxnOps {
+	hostKVPrefix := path.Join(consulutil.HostsPoolPrefix, hostname)
+	return api.KVTxnOps{
+		&api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(hostKVPrefix, "status"),
+			Value: []byte(status.String()),
+		},
+		&api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(hostKVPrefix, "message"),
+			Value: []byte(message),
+		},
+	}
+}
+
 func (cm *consulManager) GetHostStatus(hostname string) (HostStatus, error) {
 	if hostname == "" {
 		return HostStatus(0), errors.WithStack(badRequestError{`"hostname" missing`})
@@ -742,13 +756,18 @@ func (cm *consulManager) GetHost(hostname string) (Host, error) {
 	}
 
 	host.Labels, err = cm.GetHostLabels(hostname)
+	if err != nil {
+		return host, err
+	}
+
+	host.Lease, err = cm.GetHostLease(hostname)
 	return host, err
 }
 
-func (cm *consulManager) Allocate(message string, filters ...labelsutil.Filter) (string, []labelsutil.Warning, error) {
-	return cm.allocateWait(45*time.Second, message, filters...)
+func (cm *consulManager) Allocate(message string, lease *Lease, filters ...labelsutil.Filter) (string, []labelsutil.Warning, error) {
+	return cm.allocateWait(45*time.Second, message, lease, filters...)
 }
-func (cm *consulManager) allocateWait(maxWaitTime time.Duration, message string, filters ...labelsutil.Filter) (string, []labelsutil.Warning, error) {
+func (cm *consulManager) allocateWait(maxWaitTime time.Duration, message string, lease *Lease, filters ...labelsutil.Filter) (string, []labelsutil.Warning, error) {
 	lockCh, cleanupFn, err := cm.lockKey("", "allocation", maxWaitTime)
 	if err != nil {
 		return "", nil, err
@@ -794,18 +813,35 @@ func (cm *consulManager) allocateWait(maxWaitTime time.Duration, message string,
 		return "", warnings, errors.New("admin lock lost on hosts pool during host allocation")
 	default:
 	}
-	return hostname, warnings, cm.setHostStatusWithMessage(hostname, HostStatusAllocated, message)
+	// Status and lease are updated at once so an allocated host always has a lease
+	ops := hostStatusTxnOps(hostname, HostStatusAllocated, message)
+	if lease != nil {
+		ops = append(ops, leaseTxnOps(hostname, *lease)...)
+	}
+	return hostname, warnings, cm.runHostTxn(hostname, ops)
 }
-func (cm *consulManager) Release(hostname string) error {
-	return cm.releaseWait(hostname, 45*time.Second)
+
+// Release frees a host allocated to the given node instance of a deployment
+//
+// An error is returned if the host is leased to another node instance.
+func (cm *consulManager) Release(hostname, deploymentID, nodeName, instanceID string) error {
+	return cm.releaseWait(hostname, &Lease{DeploymentID: deploymentID, NodeName: nodeName, InstanceID: instanceID}, 45*time.Second)
 }
-func (cm *consulManager) releaseWait(hostname string, maxWaitTime time.Duration) error {
+func (cm *consulManager) releaseWait(hostname string, holder *Lease, maxWaitTime time.Duration) error {
 	_, cleanupFn, err := cm.lockKey(hostname, "release", maxWaitTime)
 	if err != nil {
 		return err
 	}
 	defer cleanupFn()
 
+	return cm.release(hostname, holder)
+}
+
+// release frees an allocated host, the admin lock on the hosts pool should be held by the caller
+//
+// If holder is not nil, the host lease should be held by the same node instance. Hosts allocated without
+// lease are released whatever the holder.
+func (cm *consulManager) release(hostname string, holder *Lease) error {
 	status, err := cm.GetHostStatus(hostname)
 	if err != nil {
 		return err
@@ -813,7 +849,22 @@ func (cm *consulManager) releaseWait(hostname string, maxWaitTime time.Duration)
 	if status != HostStatusAllocated {
 		return errors.WithStack(badRequestError{fmt.Sprintf("unexpected status %q when releasing host %q", status.String(), hostname)})
 	}
-	err = cm.setHostStatus(hostname, HostStatusFree)
+	if holder != nil {
+		lease, err := cm.GetHostLease(hostname)
+		if err != nil {
+			return err
+		}
+		if lease != nil && !lease.IsHeldBy(holder.DeploymentID, holder.NodeName, holder.InstanceID) {
+			return errors.WithStack(badRequestError{fmt.Sprintf("host %q is leased to instance %q of node %q in deployment %q, not to instance %q of node %q in deployment %q",
+				hostname, lease.InstanceID, lease.NodeName, lease.DeploymentID, holder.InstanceID, holder.NodeName, holder.DeploymentID)})
+		}
+	}
+	ops := hostStatusTxnOps(hostname, HostStatusFree, "")
+	ops = append(ops, &api.KVTxnOp{
+		Verb: api.KVDeleteTree,
+		Key:  path.Join(consulutil.HostsPoolPrefix, hostname, "lease"),
+	})
+	err = cm.runHostTxn(hostname, ops)
 	if err != nil {
 		return err
 	}
@@ -857,7 +908,7 @@ func getSSHConfig(conn Connection) (*ssh.ClientConfig, error) {
 	}
 
 	if conn.PrivateKey != "" {
-		keyAuth, err := readPrivateKey(conn.PrivateKey)
+		keyAuth, err := sshutil.ReadPrivateKey(conn.PrivateKey)
 		if err != nil {
 			return nil, err
 		}
@@ -869,39 +920,3 @@ func getSSHConfig(conn Connection) (*ssh.ClientConfig, error) {
 	}
 	return conf, nil
 }
-
-func readPrivateKey(pk string) (ssh.AuthMethod, error) {
-	var p []byte
-	// check if pk is a path
-	keyPath, err := homedir.Expand(pk)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to expand key path")
-	}
-	if _, err := os.Stat(keyPath); err == nil {
-		p, err = ioutil.ReadFile(keyPath)
-		if err != nil {
-			p = []byte(pk)
-		}
-	} else {
-		p = []byte(pk)
-	}
-
-	// We parse the private key on our own first so that we can
-	// show a nicer error if the private key has a password.
-	block, _ := pem.Decode(p)
-	if block == nil {
-		return nil, errors.Errorf("Failed to read key %q: no key found", pk)
-	}
-	if block.Headers["Proc-Type"] == "4,ENCRYPTED" {
-		return nil, errors.Errorf(
-			"Failed to read key %q: password protected keys are\n"+
-				"not supported. Please decrypt the key prior to use.", pk)
-	}
-
-	signer, err := ssh.ParsePrivateKey(p)
-	if err != nil {
-		return nil, errors.Wrapf(err, "Failed to parse key file %q", pk)
-	}
-
-	return ssh.PublicKeys(signer), nil
-}
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -87,4 +88,29 @@ type Host struct {
 	Status     HostStatus        `json:"status"`
 	Message    string            `json:"reason,omitempty"`
 	Labels     map[string]string `json:"labels,omitempty"`
+	Lease      *Lease            `json:"lease,omitempty"`
+}
+
+// A Lease holds information on the allocation of an Host to a node instance of a deployment
+type Lease struct {
+	DeploymentID string `json:"deployment_id"`
+	NodeName     string `json:"node_name"`
+	InstanceID   string `json:"instance_id"`
+	// AllocationDate is the date at which the host was allocated
+	AllocationDate time.Time `json:"allocation_date"`
+	// ExpirationDate is the date after which the host may be automatically released if its deployment does not exist anymore.
+	// Nil means that the lease never expires.
+	ExpirationDate *time.Time `json:"expiration_date,omitempty"`
+	// TTL is the duration by which the lease is renewed while its deployment exists. Zero means that the lease never expires.
+	TTL time.Duration `json:"-"`
+}
+
+// IsExpired checks if this lease expired at the given date
+func (l Lease) IsExpired(date time.Time) bool {
+	return l.ExpirationDate != nil && !date.Before(*l.ExpirationDate)
+}
+
+// IsHeldBy checks if this lease is held by the given node instance of a deployment
+func (l Lease) IsHeldBy(deploymentID, nodeName, instanceID string) bool {
+	return l.DeploymentID == deploymentID && l.NodeName == nodeName && l.InstanceID == instanceID
 }
@@ -17,6 +17,7 @@ package hostspool
 import (
 	"encoding/json"
 	"testing"
+	"time"
 )
 
 func TestHostStatusJSONMarshalling(t *testing.T) {
@@ -80,3 +81,36 @@ func TestHostStatusJSONUnmarshalling(t *testing.T) {
 		})
 	}
 }
+
+func TestLeaseIsExpired(t *testing.T) {
+	allocationDate := time.Date(2018, time.June, 18, 15, 4, 5, 0, time.UTC)
+	expirationDate := allocationDate.Add(72 * time.Hour)
+	tests := []struct {
+		name  string
+		lease Lease
+		date  time.Time
+		want  bool
+	}{
+		{"TestLeaseWithoutExpiration", Lease{AllocationDate: allocationDate}, allocationDate.Add(1000 * time.Hour), false},
+		{"TestLeaseNotExpired", Lease{AllocationDate: allocationDate, ExpirationDate: &expirationDate}, allocationDate.Add(time.Hour), false},
+		{"TestLeaseExpiredAtDate", Lease{AllocationDate: allocationDate, ExpirationDate: &expirationDate}, expirationDate, true},
+		{"TestLeaseExpired", Lease{AllocationDate: allocationDate, ExpirationDate: &expirationDate}, expirationDate.Add(time.Second), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.lease.IsExpired(tt.date); got != tt.want {
+				t.Errorf("Lease.IsExpired() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLeaseIsHeldBy(t *testing.T) {
+	lease := Lease{DeploymentID: "dep", NodeName: "Compute", InstanceID: "0"}
+	if !lease.IsHeldBy("dep", "Compute", "0") {
+		t.Errorf("Lease.IsHeldBy() = false for its holder")
+	}
+	if lease.IsHeldBy("dep", "Compute", "1") || lease.IsHeldBy("other", "Compute", "0") || lease.IsHeldBy("dep", "Web", "0") {
+		t.Errorf("Lease.IsHeldBy() = true for another node instance")
+	}
+}
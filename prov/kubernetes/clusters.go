@@ -0,0 +1,163 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/deployments"
+)
+
+const (
+	// infrastructureName is the name of the Kubernetes infrastructure in the Yorc configuration
+	infrastructureName = "kubernetes"
+	// clustersKey is the key of named Kubernetes clusters in the Kubernetes infrastructure configuration
+	clustersKey = "clusters"
+	// clusterInputName is the name of the topology input that selects the Kubernetes cluster of a whole deployment
+	clusterInputName = "kubernetes_cluster"
+)
+
+// A k8sClient holds a Kubernetes clientset and the URL of the cluster API it targets
+type k8sClient struct {
+	clientset *kubernetes.Clientset
+	masterURL string
+}
+
+// clients caches Kubernetes clients by cluster configuration in order to not rebuild them on each operation
+var clients = make(map[string]*k8sClient)
+var clientsLock sync.Mutex
+
+// getClusterConfig returns the configuration of a Kubernetes cluster.
+//
+// An empty cluster name refers to the default cluster defined at the root of the Kubernetes infrastructure configuration.
+// Named clusters are defined under the "clusters" key and inherit the parameters of the default cluster they do not override.
+func getClusterConfig(cfg config.Configuration, cluster string) (config.DynamicMap, error) {
+	infraCfg, exist := cfg.Infrastructures[infrastructureName]
+	if !exist {
+		return nil, errors.New("no kubernetes infrastructure configuration found")
+	}
+	clusterCfg, err := infraCfg.GetNamedConfig(clustersKey, cluster)
+	return clusterCfg, errors.Wrapf(err, "failed to get configuration of kubernetes cluster %q", cluster)
+}
+
+// getNodeClusterConfig returns the configuration of the Kubernetes cluster targeted by a node
+//
+// The cluster is defined by the "cluster" property of the node if any, otherwise by the "kubernetes_cluster" input of
+// the topology. The default cluster is used if none of them is set.
+func getNodeClusterConfig(kv *api.KV, cfg config.Configuration, deploymentID, nodeName string) (config.DynamicMap, error) {
+	_, cluster, err := deployments.GetNodeProperty(kv, deploymentID, nodeName, "cluster")
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(cluster) == "" {
+		cluster, err = deployments.GetInputValue(kv, deploymentID, clusterInputName)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return getClusterConfig(cfg, strings.TrimSpace(cluster))
+}
+
+// getClient returns a Kubernetes client for the given cluster configuration
+//
+// Clients are cached and shared by all operations targeting the same cluster.
+func getClient(clusterCfg config.DynamicMap) (*k8sClient, error) {
+	clientKey := clusterConfigKey(clusterCfg)
+	clientsLock.Lock()
+	defer clientsLock.Unlock()
+	if client, ok := clients[clientKey]; ok {
+		return client, nil
+	}
+
+	restConfig, err := buildRestConfig(clusterCfg)
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create kubernetes clientset from config")
+	}
+	client := &k8sClient{clientset: clientset, masterURL: restConfig.Host}
+	clients[clientKey] = client
+	return client, nil
+}
+
+// clusterConfigKey returns a key identifying a cluster configuration, this way a client is not reused if the
+// configuration changed
+func clusterConfigKey(clusterCfg config.DynamicMap) string {
+	keys := clusterCfg.Keys()
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", k, clusterCfg.Get(k)))
+	}
+	return strings.Join(parts, ";")
+}
+
+// buildRestConfig builds the Kubernetes client configuration of a cluster
+//
+// In-cluster configuration relies on the service account of the pod Yorc is running in. Otherwise the configuration is
+// loaded from a kubeconfig file (optionally using a given context) and/or from the master_url and TLS parameters that
+// override the kubeconfig values.
+func buildRestConfig(clusterCfg config.DynamicMap) (*rest.Config, error) {
+	if clusterCfg.GetBool("in_cluster") {
+		conf, err := rest.InClusterConfig()
+		return conf, errors.Wrap(err, "Failed to build in-cluster kubernetes config")
+	}
+
+	kubMasterIP := clusterCfg.GetString("master_url")
+	kubeconfig := clusterCfg.GetString("kubeconfig")
+	if kubMasterIP == "" && kubeconfig == "" {
+		return nil, errors.New(`Missing or invalid mandatory parameter master_url or kubeconfig in the "kubernetes" infrastructure configuration`)
+	}
+
+	overrides := &clientcmd.ConfigOverrides{
+		ClusterInfo:    clientcmdapi.Cluster{Server: kubMasterIP},
+		CurrentContext: clusterCfg.GetString("context"),
+	}
+	conf, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig}, overrides).ClientConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to build kubernetes config")
+	}
+
+	if clusterCfg.IsSet("insecure") {
+		conf.TLSClientConfig.Insecure = clusterCfg.GetBool("insecure")
+	}
+	if caFile := clusterCfg.GetString("ca_file"); caFile != "" {
+		conf.TLSClientConfig.CAFile = caFile
+		conf.TLSClientConfig.CAData = nil
+	}
+	if certFile := clusterCfg.GetString("cert_file"); certFile != "" {
+		conf.TLSClientConfig.CertFile = certFile
+		conf.TLSClientConfig.CertData = nil
+	}
+	if keyFile := clusterCfg.GetString("key_file"); keyFile != "" {
+		conf.TLSClientConfig.KeyFile = keyFile
+		conf.TLSClientConfig.KeyData = nil
+	}
+	return conf, nil
+}
@@ -0,0 +1,127 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/config"
+)
+
+func TestGetClusterConfig(t *testing.T) {
+	t.Parallel()
+	cfg := config.Configuration{
+		Infrastructures: map[string]config.DynamicMap{
+			infrastructureName: config.DynamicMap{
+				"kubeconfig": "/etc/yorc/kubeconfig",
+				"insecure":   true,
+				"clusters": map[string]interface{}{
+					"prod": map[interface{}]interface{}{
+						"context": "prod-admin",
+					},
+					"local": map[interface{}]interface{}{
+						"in_cluster": true,
+					},
+				},
+			}}}
+
+	defaultCfg, err := getClusterConfig(cfg, "")
+	require.NoError(t, err)
+	require.Equal(t, "/etc/yorc/kubeconfig", defaultCfg.GetString("kubeconfig"))
+	require.False(t, defaultCfg.IsSet("clusters"))
+
+	prodCfg, err := getClusterConfig(cfg, "prod")
+	require.NoError(t, err)
+	require.Equal(t, "prod-admin", prodCfg.GetString("context"))
+	require.Equal(t, "/etc/yorc/kubeconfig", prodCfg.GetString("kubeconfig"), "cluster should inherit default parameters")
+	require.True(t, prodCfg.GetBool("insecure"), "cluster should inherit default parameters")
+	require.Equal(t, "", defaultCfg.GetString("context"), "default cluster should not be modified")
+
+	localCfg, err := getClusterConfig(cfg, "local")
+	require.NoError(t, err)
+	require.True(t, localCfg.GetBool("in_cluster"))
+
+	require.NotEqual(t, clusterConfigKey(prodCfg), clusterConfigKey(localCfg))
+	require.Equal(t, clusterConfigKey(prodCfg), clusterConfigKey(prodCfg))
+
+	_, err = getClusterConfig(cfg, "unknown")
+	require.Error(t, err)
+
+	_, err = getClusterConfig(config.Configuration{}, "")
+	require.Error(t, err)
+}
+
+func TestBuildRestConfig(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := ioutil.TempDir("", "yorc-k8s-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	kubeconfig := filepath.Join(tmpDir, "config")
+	err = ioutil.WriteFile(kubeconfig, []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: c1
+  cluster:
+    server: https://10.0.0.1:6443
+- name: c2
+  cluster:
+    server: https://10.0.0.2:6443
+users:
+- name: u1
+  user:
+    token: secret
+contexts:
+- name: ctx1
+  context:
+    cluster: c1
+    user: u1
+- name: ctx2
+  context:
+    cluster: c2
+    user: u1
+current-context: ctx1
+`), 0600)
+	require.NoError(t, err)
+
+	conf, err := buildRestConfig(config.DynamicMap{"kubeconfig": kubeconfig})
+	require.NoError(t, err)
+	require.Equal(t, "https://10.0.0.1:6443", conf.Host)
+	require.Equal(t, "secret", conf.BearerToken)
+
+	conf, err = buildRestConfig(config.DynamicMap{"kubeconfig": kubeconfig, "context": "ctx2"})
+	require.NoError(t, err)
+	require.Equal(t, "https://10.0.0.2:6443", conf.Host)
+
+	conf, err = buildRestConfig(config.DynamicMap{"kubeconfig": kubeconfig, "master_url": "https://10.0.0.3:6443", "insecure": true})
+	require.NoError(t, err)
+	require.Equal(t, "https://10.0.0.3:6443", conf.Host, "master_url should override the kubeconfig server")
+	require.True(t, conf.TLSClientConfig.Insecure)
+
+	conf, err = buildRestConfig(config.DynamicMap{"master_url": "https://10.0.0.4:6443"})
+	require.NoError(t, err)
+	require.Equal(t, "https://10.0.0.4:6443", conf.Host)
+
+	_, err = buildRestConfig(config.DynamicMap{})
+	require.Error(t, err, "either master_url or kubeconfig is required")
+
+	_, err = buildRestConfig(config.DynamicMap{"kubeconfig": filepath.Join(tmpDir, "missing")})
+	require.Error(t, err)
+}
@@ -30,17 +30,23 @@ import (
 	"github.com/hashicorp/consul/api"
 	"github.com/pkg/errors"
 
+	appsv1beta1 "k8s.io/api/apps/v1beta1"
+	batchv1 "k8s.io/api/batch/v1"
 	"k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/ystia/yorc/config"
 	"github.com/ystia/yorc/deployments"
 	"github.com/ystia/yorc/events"
+	"github.com/ystia/yorc/helper/collections"
 	"github.com/ystia/yorc/log"
 	"github.com/ystia/yorc/prov"
 	"github.com/ystia/yorc/prov/operations"
 	"github.com/ystia/yorc/tasks"
+	"github.com/ystia/yorc/tosca"
 )
 
 // An EnvInput represent a TOSCA operation input
@@ -76,6 +82,7 @@ type executionCommon struct {
 	VarInputsNames []string
 	Repositories   map[string]string
 	SecretRepoName string
+	workloadKind   string
 }
 
 func newExecution(kv *api.KV, cfg config.Configuration, taskID, deploymentID, nodeName string, operation prov.Operation) (execution, error) {
@@ -101,9 +108,26 @@ func newExecution(kv *api.KV, cfg config.Configuration, taskID, deploymentID, no
 func (e *executionCommon) resolveOperation() error {
 	var err error
 	e.NodeType, err = deployments.GetNodeType(e.kv, e.deploymentID, e.NodeName)
+	if err != nil {
+		return err
+	}
+	e.workloadKind, err = getWorkloadKind(e.kv, e.deploymentID, e.NodeName)
 	return err
 }
 
+// getWorkloadKind returns the kind of Kubernetes workload used to deploy a node based on its type hierarchy
+func getWorkloadKind(kv *api.KV, deploymentID, nodeName string) (string, error) {
+	isStatefulSet, err := deployments.IsNodeDerivedFrom(kv, deploymentID, nodeName, statefulSetNodeType)
+	if err != nil || isStatefulSet {
+		return statefulSetWorkload, err
+	}
+	isJob, err := deployments.IsNodeDerivedFrom(kv, deploymentID, nodeName, jobNodeType)
+	if err != nil || isJob {
+		return jobWorkload, err
+	}
+	return deploymentWorkload, nil
+}
+
 func (e *executionCommon) execute(ctx context.Context) (err error) {
 	ctx = context.WithValue(ctx, "generator", newGenerator(e.kv, e.cfg))
 	instances, err := tasks.GetInstances(e.kv, e.taskID, e.deploymentID, e.NodeName)
@@ -213,14 +237,26 @@ func (e *executionCommon) scaleNode(ctx context.Context, scaleType tasks.TaskTyp
 		return err
 	}
 
+	switch e.workloadKind {
+	case jobWorkload:
+		return errors.Errorf("Scaling is not supported for node %q as Kubernetes Jobs run to completion", e.NodeName)
+	case statefulSetWorkload:
+		statefulSet, err := (clientset.(*kubernetes.Clientset)).AppsV1beta1().StatefulSets(namespace).Get(strings.ToLower(generatePodName(e.cfg.ResourcesPrefix+e.NodeName)), metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrap(err, "Failed to fetch statefulset")
+		}
+		replica := computeReplicas(*statefulSet.Spec.Replicas, scaleType, nbInstances)
+		statefulSet.Spec.Replicas = &replica
+		_, err = (clientset.(*kubernetes.Clientset)).AppsV1beta1().StatefulSets(namespace).Update(statefulSet)
+		if err != nil {
+			return errors.Wrap(err, "Failed to scale statefulset")
+		}
+		return nil
+	}
+
 	deployment, err := (clientset.(*kubernetes.Clientset)).ExtensionsV1beta1().Deployments(namespace).Get(strings.ToLower(e.cfg.ResourcesPrefix+e.NodeName), metav1.GetOptions{})
 
-	replica := *deployment.Spec.Replicas
-	if scaleType == tasks.ScaleOut {
-		replica = replica + nbInstances
-	} else if scaleType == tasks.ScaleIn {
-		replica = replica - nbInstances
-	}
+	replica := computeReplicas(*deployment.Spec.Replicas, scaleType, nbInstances)
 
 	deployment.Spec.Replicas = &replica
 	_, err = (clientset.(*kubernetes.Clientset)).ExtensionsV1beta1().Deployments(namespace).Update(deployment)
@@ -231,6 +267,15 @@ func (e *executionCommon) scaleNode(ctx context.Context, scaleType tasks.TaskTyp
 	return nil
 }
 
+func computeReplicas(replica int32, scaleType tasks.TaskType, nbInstances int32) int32 {
+	if scaleType == tasks.ScaleOut {
+		replica = replica + nbInstances
+	} else if scaleType == tasks.ScaleIn {
+		replica = replica - nbInstances
+	}
+	return replica
+}
+
 func (e *executionCommon) deployNode(ctx context.Context, nbInstances int32) error {
 	clientset := ctx.Value("clientset")
 	generator := ctx.Value("generator").(*k8sGenerator)
@@ -254,17 +299,54 @@ func (e *executionCommon) deployNode(ctx context.Context, nbInstances int32) err
 	if err != nil {
 		return err
 	}
+	for _, envInput := range e.EnvInputs {
+		if envInput.IsArtifact {
+			envInput.Value, err = generator.getArtifactPathInContainer(e.deploymentID, e.NodeName, envInput.Value)
+			if err != nil {
+				return errors.Wrapf(err, "Failed to resolve input %q", envInput.Name)
+			}
+		}
+	}
 	inputs := e.parseEnvInputs()
 
-	deployment, service, err := generator.generateDeployment(e.deploymentID, e.NodeName, e.Operation.Name, e.NodeType, e.SecretRepoName, inputs, nbInstances)
+	err = e.createVolumesObjects(ctx, namespace)
 	if err != nil {
 		return err
 	}
 
-	_, err = (clientset.(*kubernetes.Clientset)).ExtensionsV1beta1().Deployments(namespace).Create(&deployment)
+	var service v1.Service
+	switch e.workloadKind {
+	case statefulSetWorkload:
+		var statefulSet appsv1beta1.StatefulSet
+		statefulSet, service, err = generator.generateStatefulSet(e.deploymentID, e.NodeName, e.Operation.Name, e.NodeType, e.SecretRepoName, inputs, nbInstances)
+		if err != nil {
+			return err
+		}
+		_, err = (clientset.(*kubernetes.Clientset)).AppsV1beta1().StatefulSets(namespace).Create(&statefulSet)
+		if err != nil {
+			return errors.Wrap(err, "Failed to create statefulset")
+		}
+	case jobWorkload:
+		job, err := generator.generateJob(e.deploymentID, e.NodeName, e.Operation.Name, e.NodeType, e.SecretRepoName, inputs)
+		if err != nil {
+			return err
+		}
+		_, err = (clientset.(*kubernetes.Clientset)).BatchV1().Jobs(namespace).Create(&job)
+		if err != nil {
+			return errors.Wrap(err, "Failed to create job")
+		}
+	default:
+		var deployment v1beta1.Deployment
+		deployment, service, err = generator.generateDeployment(e.deploymentID, e.NodeName, e.Operation.Name, e.NodeType, e.SecretRepoName, inputs, nbInstances)
+		if err != nil {
+			return err
+		}
 
-	if err != nil {
-		return errors.Wrap(err, "Failed to create deployment")
+		_, err = (clientset.(*kubernetes.Clientset)).ExtensionsV1beta1().Deployments(namespace).Create(&deployment)
+
+		if err != nil {
+			return errors.Wrap(err, "Failed to create deployment")
+		}
 	}
 
 	if service.Name != "" {
@@ -273,9 +355,8 @@ func (e *executionCommon) deployNode(ctx context.Context, nbInstances int32) err
 			return errors.Wrap(err, "Failed to create service")
 		}
 		var s string
+		kubMasterIP, _ := ctx.Value("masterURL").(string)
 		for _, val := range serv.Spec.Ports {
-			kubConf := e.cfg.Infrastructures["kubernetes"]
-			kubMasterIP := kubConf.GetString("master_url")
 			u, _ := url.Parse(kubMasterIP)
 			h := strings.Split(u.Host, ":")
 			str := fmt.Sprintf("http://%s:%d", h[0], val.NodePort)
@@ -313,6 +394,126 @@ func (e *executionCommon) deployNode(ctx context.Context, nbInstances int32) err
 	return e.setUnDeployHook()
 }
 
+// createVolumesObjects creates the ConfigMaps and Secrets backing the volumes used by the node
+//
+// As those volumes may be shared by several nodes, already existing objects are updated with the current volume data.
+func (e *executionCommon) createVolumesObjects(ctx context.Context, namespace string) error {
+	clientset := ctx.Value("clientset").(*kubernetes.Clientset)
+	generator := ctx.Value("generator").(*k8sGenerator)
+
+	volumeNodeNames, err := getUsedVolumeNodesNames(e.kv, e.deploymentID, e.NodeName)
+	if err != nil {
+		return err
+	}
+	for _, volumeNodeName := range volumeNodeNames {
+		_, vtype, err := deployments.GetNodeProperty(e.kv, e.deploymentID, volumeNodeName, "volume_type")
+		if err != nil {
+			return err
+		}
+		switch vtype {
+		case "configMap":
+			configMap, err := generator.generateConfigMap(e.deploymentID, volumeNodeName)
+			if err != nil {
+				return err
+			}
+			_, err = clientset.CoreV1().ConfigMaps(namespace).Create(&configMap)
+			if apierrors.IsAlreadyExists(err) {
+				_, err = clientset.CoreV1().ConfigMaps(namespace).Update(&configMap)
+			}
+			if err != nil {
+				return errors.Wrapf(err, "Failed to create configmap for volume node %q", volumeNodeName)
+			}
+		case "secret":
+			secret, err := generator.generateSecret(e.deploymentID, volumeNodeName)
+			if err != nil {
+				return err
+			}
+			_, err = clientset.CoreV1().Secrets(namespace).Create(&secret)
+			if apierrors.IsAlreadyExists(err) {
+				_, err = clientset.CoreV1().Secrets(namespace).Update(&secret)
+			}
+			if err != nil {
+				return errors.Wrapf(err, "Failed to create secret for volume node %q", volumeNodeName)
+			}
+		}
+	}
+	return nil
+}
+
+// deleteVolumesObjects deletes the ConfigMaps and Secrets backing the volumes used by the node
+//
+// Objects of volumes still used by other nodes are kept, they are deleted when the last of those nodes is uninstalled.
+func (e *executionCommon) deleteVolumesObjects(ctx context.Context, namespace string) error {
+	clientset := ctx.Value("clientset").(*kubernetes.Clientset)
+	generator := ctx.Value("generator").(*k8sGenerator)
+
+	volumeNodeNames, err := getUsedVolumeNodesNames(e.kv, e.deploymentID, e.NodeName)
+	if err != nil {
+		return err
+	}
+	for _, volumeNodeName := range volumeNodeNames {
+		_, vtype, err := deployments.GetNodeProperty(e.kv, e.deploymentID, volumeNodeName, "volume_type")
+		if err != nil {
+			return err
+		}
+		if vtype != "configMap" && vtype != "secret" {
+			continue
+		}
+		used, err := isVolumeUsedByOtherNodes(e.kv, e.deploymentID, e.NodeName, volumeNodeName)
+		if err != nil {
+			return err
+		}
+		if used {
+			log.Debugf("Volume node %q is still used by other nodes, its %s is kept", volumeNodeName, vtype)
+			continue
+		}
+		objectName := generator.generateVolumeObjectName(volumeNodeName)
+		if vtype == "configMap" {
+			err = clientset.CoreV1().ConfigMaps(namespace).Delete(objectName, &metav1.DeleteOptions{})
+		} else {
+			err = clientset.CoreV1().Secrets(namespace).Delete(objectName, &metav1.DeleteOptions{})
+		}
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "Failed to delete %s for volume node %q", strings.ToLower(vtype), volumeNodeName)
+		}
+	}
+	return nil
+}
+
+// isVolumeUsedByOtherNodes checks if a volume node is used by another node than the given one which is not uninstalled
+func isVolumeUsedByOtherNodes(kv *api.KV, deploymentID, nodeName, volumeNodeName string) (bool, error) {
+	nodes, err := deployments.GetNodes(kv, deploymentID)
+	if err != nil {
+		return false, err
+	}
+	for _, otherNode := range nodes {
+		if otherNode == nodeName {
+			continue
+		}
+		usedVolumes, err := getUsedVolumeNodesNames(kv, deploymentID, otherNode)
+		if err != nil {
+			return false, err
+		}
+		if !collections.ContainsString(usedVolumes, volumeNodeName) {
+			continue
+		}
+		instances, err := deployments.GetNodeInstancesIds(kv, deploymentID, otherNode)
+		if err != nil {
+			return false, err
+		}
+		for _, instance := range instances {
+			state, err := deployments.GetInstanceState(kv, deploymentID, otherNode, instance)
+			if err != nil {
+				return false, err
+			}
+			if state != tosca.NodeStateInitial && state != tosca.NodeStateDeleting && state != tosca.NodeStateDeleted {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
 func (e *executionCommon) setUnDeployHook() error {
 	_, err := deployments.GetNodeTypeImplementingAnOperation(e.kv, e.deploymentID, e.NodeName, "tosca.interfaces.node.lifecycle.standard.stop")
 	if err != nil {
@@ -334,6 +535,16 @@ func (e *executionCommon) setUnDeployHook() error {
 }
 
 func (e *executionCommon) checkNode(ctx context.Context) error {
+	switch e.workloadKind {
+	case statefulSetWorkload:
+		return e.checkStatefulSet(ctx)
+	case jobWorkload:
+		return e.checkJob(ctx)
+	}
+	return e.checkDeployment(ctx)
+}
+
+func (e *executionCommon) checkDeployment(ctx context.Context) error {
 	clientset := ctx.Value("clientset")
 
 	namespace, err := getNamespace(e.kv, e.deploymentID, e.NodeName)
@@ -357,34 +568,120 @@ func (e *executionCommon) checkNode(ctx context.Context) error {
 		if deployment.Status.AvailableReplicas == *deployment.Spec.Replicas {
 			deploymentReady = true
 		} else {
-			selector := ""
-			for key, val := range deployment.Spec.Selector.MatchLabels {
-				if selector != "" {
-					selector += ","
-				}
-				selector += key + "=" + val
+			err = e.checkSelectedPods(ctx, namespace, deployment.Spec.Selector)
+			if err != nil {
+				return err
 			}
-			//log.Printf("selector: %s", selector)
-			pods, _ := (clientset.(*kubernetes.Clientset)).CoreV1().Pods(namespace).List(
-				metav1.ListOptions{
-					LabelSelector: selector,
-				})
-
-			// We should always have only 1 pod (as the Replica is set to 1)
-			for _, podItem := range pods.Items {
-
-				//log.Printf("Check pod %s", podItem.Name)
-				err := e.checkPod(ctx, podItem.Name)
-				if err != nil {
-					return err
-				}
+		}
 
-			}
+		time.Sleep(2 * time.Second)
+	}
+
+	return nil
+}
+
+func (e *executionCommon) checkStatefulSet(ctx context.Context) error {
+	clientset := ctx.Value("clientset")
+
+	namespace, err := getNamespace(e.kv, e.deploymentID, e.NodeName)
+	if err != nil {
+		return err
+	}
+
+	var ready int32 = -1
+	for {
+		statefulSet, err := (clientset.(*kubernetes.Clientset)).AppsV1beta1().StatefulSets(namespace).Get(strings.ToLower(generatePodName(e.cfg.ResourcesPrefix+e.NodeName)), metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrap(err, "Failed fetch statefulset")
+		}
+		if ready != statefulSet.Status.ReadyReplicas {
+			ready = statefulSet.Status.ReadyReplicas
+			log.Printf("StatefulSet %s : %d pod ready of %d", e.NodeName, ready, *statefulSet.Spec.Replicas)
+		}
+
+		if statefulSet.Status.ReadyReplicas == *statefulSet.Spec.Replicas {
+			return nil
+		}
+		err = e.checkSelectedPods(ctx, namespace, statefulSet.Spec.Selector)
+		if err != nil {
+			return err
 		}
 
 		time.Sleep(2 * time.Second)
 	}
+}
 
+// checkJob waits for the Job to run to completion
+//
+// An error is returned if the Job fails, this way the node state reflects the Job result.
+func (e *executionCommon) checkJob(ctx context.Context) error {
+	wfName, _ := tasks.GetTaskData(e.kv, e.taskID, "workflowName")
+	logOptFields := events.LogOptionalFields{
+		events.NodeID:        e.NodeName,
+		events.WorkFlowID:    wfName,
+		events.InterfaceName: "delegate",
+		events.OperationName: e.Operation.Name,
+	}
+	clientset := ctx.Value("clientset")
+
+	namespace, err := getNamespace(e.kv, e.deploymentID, e.NodeName)
+	if err != nil {
+		return err
+	}
+
+	jobName := strings.ToLower(generatePodName(e.cfg.ResourcesPrefix + e.NodeName))
+	var active, succeeded, failed int32 = -1, -1, -1
+	for {
+		job, err := (clientset.(*kubernetes.Clientset)).BatchV1().Jobs(namespace).Get(jobName, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrap(err, "Failed fetch job")
+		}
+		if active != job.Status.Active || succeeded != job.Status.Succeeded || failed != job.Status.Failed {
+			active, succeeded, failed = job.Status.Active, job.Status.Succeeded, job.Status.Failed
+			events.WithOptionalFields(logOptFields).NewLogEntry(events.INFO, e.deploymentID).Registerf("Job %s : %d active, %d succeeded, %d failed pods", jobName, active, succeeded, failed)
+		}
+		for _, condition := range job.Status.Conditions {
+			if condition.Status != v1.ConditionTrue {
+				continue
+			}
+			switch condition.Type {
+			case batchv1.JobComplete:
+				events.WithOptionalFields(logOptFields).NewLogEntry(events.INFO, e.deploymentID).Registerf("Job %s completed successfully", jobName)
+				return nil
+			case batchv1.JobFailed:
+				return errors.Errorf("Job %s failed: %s %s", jobName, condition.Reason, condition.Message)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "Job %s monitoring cancelled", jobName)
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+// checkSelectedPods checks the status of the pods matching a label selector
+func (e *executionCommon) checkSelectedPods(ctx context.Context, namespace string, labelSelector *metav1.LabelSelector) error {
+	clientset := ctx.Value("clientset")
+	selector := ""
+	for key, val := range labelSelector.MatchLabels {
+		if selector != "" {
+			selector += ","
+		}
+		selector += key + "=" + val
+	}
+	pods, _ := (clientset.(*kubernetes.Clientset)).CoreV1().Pods(namespace).List(
+		metav1.ListOptions{
+			LabelSelector: selector,
+		})
+
+	for _, podItem := range pods.Items {
+		err := e.checkPod(ctx, podItem.Name)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -493,6 +790,27 @@ func (e *executionCommon) uninstallNode(ctx context.Context) error {
 		log.Printf("Deployment deleted")
 	}
 
+	objectName := strings.ToLower(generatePodName(e.cfg.ResourcesPrefix + e.NodeName))
+	switch e.workloadKind {
+	case statefulSetWorkload:
+		if _, err = (clientset.(*kubernetes.Clientset)).AppsV1beta1().StatefulSets(namespace).Get(objectName, metav1.GetOptions{}); err == nil {
+			err = (clientset.(*kubernetes.Clientset)).AppsV1beta1().StatefulSets(namespace).Delete(objectName, &metav1.DeleteOptions{})
+			if err != nil {
+				return errors.Wrap(err, "Failed to delete statefulset")
+			}
+			log.Printf("StatefulSet deleted")
+		}
+	case jobWorkload:
+		if _, err = (clientset.(*kubernetes.Clientset)).BatchV1().Jobs(namespace).Get(objectName, metav1.GetOptions{}); err == nil {
+			propagation := metav1.DeletePropagationBackground
+			err = (clientset.(*kubernetes.Clientset)).BatchV1().Jobs(namespace).Delete(objectName, &metav1.DeleteOptions{PropagationPolicy: &propagation})
+			if err != nil {
+				return errors.Wrap(err, "Failed to delete job")
+			}
+			log.Printf("Job deleted")
+		}
+	}
+
 	if _, err = (clientset.(*kubernetes.Clientset)).CoreV1().Services(namespace).Get(strings.ToLower(generatePodName(e.cfg.ResourcesPrefix+e.NodeName)), metav1.GetOptions{}); err == nil {
 		err = (clientset.(*kubernetes.Clientset)).CoreV1().Services(namespace).Delete(strings.ToLower(generatePodName(e.cfg.ResourcesPrefix+e.NodeName)), &metav1.DeleteOptions{})
 		if err != nil {
@@ -510,6 +828,10 @@ func (e *executionCommon) uninstallNode(ctx context.Context) error {
 
 	}
 
+	if err = e.deleteVolumesObjects(ctx, namespace); err != nil {
+		return err
+	}
+
 	if err = (clientset.(*kubernetes.Clientset)).CoreV1().Namespaces().Delete(namespace, &metav1.DeleteOptions{}); err != nil {
 		return errors.Wrap(err, "Failed to delete namespace")
 	}
@@ -17,16 +17,11 @@ package kubernetes
 import (
 	"context"
 
-	"github.com/pkg/errors"
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/tools/clientcmd"
-
 	"github.com/ystia/yorc/config"
 	"github.com/ystia/yorc/prov"
 )
 
 type defaultExecutor struct {
-	clientset *kubernetes.Clientset
 }
 
 func (e *defaultExecutor) ExecOperation(ctx context.Context, conf config.Configuration, taskID, deploymentID, nodeName string, operation prov.Operation) error {
@@ -40,33 +35,17 @@ func (e *defaultExecutor) ExecOperation(ctx context.Context, conf config.Configu
 		return err
 	}
 
-	e.clientset, err = initClientSet(conf)
+	clusterCfg, err := getNodeClusterConfig(kv, conf, deploymentID, nodeName)
 	if err != nil {
 		return err
 	}
-
-	newCtx := context.WithValue(ctx, "clientset", e.clientset)
-
-	return exec.execute(newCtx)
-}
-
-func initClientSet(cfg config.Configuration) (*kubernetes.Clientset, error) {
-	kubConf := cfg.Infrastructures["kubernetes"]
-	kubMasterIP := kubConf.GetString("master_url")
-
-	if kubMasterIP == "" {
-		return nil, errors.New(`Missing or invalid mandatory parameter master_url in the "kubernetes" infrastructure configuration`)
-	}
-	conf, err := clientcmd.BuildConfigFromFlags(kubMasterIP, "")
+	client, err := getClient(clusterCfg)
 	if err != nil {
-		return nil, errors.Wrap(err, "Failed to build kubernetes config")
+		return err
 	}
 
-	conf.TLSClientConfig.Insecure = kubConf.GetBool("insecure")
-	conf.TLSClientConfig.CAFile = kubConf.GetString("ca_file")
-	conf.TLSClientConfig.CertFile = kubConf.GetString("cert_file")
-	conf.TLSClientConfig.KeyFile = kubConf.GetString("key_file")
+	newCtx := context.WithValue(ctx, "clientset", client.clientset)
+	newCtx = context.WithValue(newCtx, "masterURL", client.masterURL)
 
-	clientset, err := kubernetes.NewForConfig(conf)
-	return clientset, errors.Wrap(err, "Failed to create kubernetes clientset from config")
+	return exec.execute(newCtx)
 }
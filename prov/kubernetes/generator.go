@@ -15,13 +15,20 @@
 package kubernetes
 
 import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
 	"log"
+	"path"
+	"path/filepath"
 	"strconv"
 	"strings"
 
 	"github.com/hashicorp/consul/api"
 	"github.com/pkg/errors"
 
+	appsv1beta1 "k8s.io/api/apps/v1beta1"
+	batchv1 "k8s.io/api/batch/v1"
 	"k8s.io/api/core/v1"
 	"k8s.io/api/extensions/v1beta1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -159,20 +166,39 @@ func (k8s *k8sGenerator) generateContainer(nodeName, dockerImage, imagePullPolic
 }
 
 // Generate all the Kubernetes Volumes used by a node
-func (k8s *k8sGenerator) generateUsedVolumes(deploymentID, nodeName string) ([]v1.Volume, error) {
+//
+// Persistent volume claims that do not reference an existing claim are returned as claim templates if withClaimTemplates
+// is true (this is the case for StatefulSets) otherwise an error is returned.
+func (k8s *k8sGenerator) generateUsedVolumes(deploymentID, nodeName string, withClaimTemplates bool) ([]v1.Volume, []v1.PersistentVolumeClaim, error) {
 	usedVolumeNodeNames, err := getUsedVolumeNodesNames(k8s.kv, deploymentID, nodeName)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	err = nil
 	var usedVolumes []v1.Volume
+	var claimTemplates []v1.PersistentVolumeClaim
 	for _, volumeNodeName := range usedVolumeNodeNames {
+		isTemplate, err := k8s.isVolumeClaimTemplate(deploymentID, volumeNodeName)
+		if err != nil {
+			return nil, nil, err
+		}
+		if isTemplate {
+			if !withClaimTemplates {
+				return nil, nil, errors.Errorf("Volume node %q should define a claim_name property referencing an existing persistent volume claim as node %q is not a StatefulSet", volumeNodeName, nodeName)
+			}
+			claimTemplate, err := k8s.generateVolumeClaimTemplate(deploymentID, volumeNodeName)
+			if err != nil {
+				return nil, nil, err
+			}
+			claimTemplates = append(claimTemplates, claimTemplate)
+			continue
+		}
 		volume, err := k8s.generateVolume(deploymentID, volumeNodeName)
-		if err == nil {
-			usedVolumes = append(usedVolumes, volume)
+		if err != nil {
+			return nil, nil, err
 		}
+		usedVolumes = append(usedVolumes, volume)
 	}
-	return usedVolumes, err
+	return usedVolumes, claimTemplates, nil
 }
 
 // Generate the kubernetes Volume matched by a K8s Volume Node
@@ -189,12 +215,187 @@ func (k8s *k8sGenerator) generateVolume(deploymentID, volumeNodeName string) (v1
 		volumeSource.EmptyDir = &emptyDirVolumeSource
 		volume.VolumeSource = volumeSource
 		err = nil
+	case "persistentVolumeClaim":
+		var claimName string
+		_, claimName, err = deployments.GetNodeProperty(k8s.kv, deploymentID, volumeNodeName, "claim_name")
+		volumeSource.PersistentVolumeClaim = &v1.PersistentVolumeClaimVolumeSource{ClaimName: claimName}
+		volume.VolumeSource = volumeSource
+	case "configMap":
+		volumeSource.ConfigMap = &v1.ConfigMapVolumeSource{
+			LocalObjectReference: v1.LocalObjectReference{Name: k8s.generateVolumeObjectName(volumeNodeName)},
+		}
+		volume.VolumeSource = volumeSource
+		err = nil
+	case "secret":
+		volumeSource.Secret = &v1.SecretVolumeSource{SecretName: k8s.generateVolumeObjectName(volumeNodeName)}
+		volume.VolumeSource = volumeSource
+		err = nil
 	default:
 		err = errors.Errorf("Unsupported volume type %q", vtype)
 	}
 	return volume, err
 }
 
+// isVolumeClaimTemplate checks if a volume node is a persistent volume claim that does not reference an existing claim
+// and should therefore be created by Kubernetes from a claim template
+func (k8s *k8sGenerator) isVolumeClaimTemplate(deploymentID, volumeNodeName string) (bool, error) {
+	_, vtype, err := deployments.GetNodeProperty(k8s.kv, deploymentID, volumeNodeName, "volume_type")
+	if err != nil || vtype != "persistentVolumeClaim" {
+		return false, err
+	}
+	_, claimName, err := deployments.GetNodeProperty(k8s.kv, deploymentID, volumeNodeName, "claim_name")
+	return claimName == "", err
+}
+
+// Generate a PersistentVolumeClaim template for a persistent volume claim Volume Node
+//
+// The claim template is named after the volume name in order to be matched by the volume mount of the container
+func (k8s *k8sGenerator) generateVolumeClaimTemplate(deploymentID, volumeNodeName string) (v1.PersistentVolumeClaim, error) {
+	_, vname, err := deployments.GetNodeProperty(k8s.kv, deploymentID, volumeNodeName, "name")
+	if err != nil {
+		return v1.PersistentVolumeClaim{}, err
+	}
+	found, sizeStr, err := deployments.GetNodeProperty(k8s.kv, deploymentID, volumeNodeName, "size")
+	if err != nil {
+		return v1.PersistentVolumeClaim{}, err
+	}
+	if !found || sizeStr == "" {
+		return v1.PersistentVolumeClaim{}, errors.Errorf("Volume node %q needs a size property", volumeNodeName)
+	}
+	size, err := resource.ParseQuantity(sizeStr)
+	if err != nil {
+		return v1.PersistentVolumeClaim{}, errors.Wrapf(err, "Failed to parse size quantity of volume node %q", volumeNodeName)
+	}
+	_, accessModesStr, err := deployments.GetNodeProperty(k8s.kv, deploymentID, volumeNodeName, "access_modes")
+	if err != nil {
+		return v1.PersistentVolumeClaim{}, err
+	}
+	accessModes, err := parseAccessModes(accessModesStr)
+	if err != nil {
+		return v1.PersistentVolumeClaim{}, errors.Wrapf(err, "Failed to parse access_modes of volume node %q", volumeNodeName)
+	}
+
+	claim := v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: vname},
+		Spec: v1.PersistentVolumeClaimSpec{
+			AccessModes: accessModes,
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceStorage: size},
+			},
+		},
+	}
+
+	_, storageClass, err := deployments.GetNodeProperty(k8s.kv, deploymentID, volumeNodeName, "storage_class")
+	if err != nil {
+		return v1.PersistentVolumeClaim{}, err
+	}
+	if storageClass != "" {
+		claim.Spec.StorageClassName = &storageClass
+	}
+	return claim, nil
+}
+
+// parseAccessModes parses the JSON representation of a list of access modes, defaulting to ReadWriteOnce
+func parseAccessModes(accessModesStr string) ([]v1.PersistentVolumeAccessMode, error) {
+	if accessModesStr == "" {
+		return []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce}, nil
+	}
+	var modes []string
+	if err := json.Unmarshal([]byte(accessModesStr), &modes); err != nil {
+		return nil, err
+	}
+	if len(modes) == 0 {
+		return []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce}, nil
+	}
+	accessModes := make([]v1.PersistentVolumeAccessMode, len(modes))
+	for i, mode := range modes {
+		switch v1.PersistentVolumeAccessMode(mode) {
+		case v1.ReadWriteOnce, v1.ReadOnlyMany, v1.ReadWriteMany:
+			accessModes[i] = v1.PersistentVolumeAccessMode(mode)
+		default:
+			return nil, errors.Errorf("Unsupported access mode %q", mode)
+		}
+	}
+	return accessModes, nil
+}
+
+// generateVolumeObjectName returns the name of the Kubernetes ConfigMap or Secret backing a volume node
+func (k8s *k8sGenerator) generateVolumeObjectName(volumeNodeName string) string {
+	return strings.ToLower(generatePodName(k8s.cfg.ResourcesPrefix + volumeNodeName))
+}
+
+// generateVolumeData returns the data of a ConfigMap or Secret volume node
+//
+// Data are taken from the 'data' property of the node and from the node artifacts. Artifacts are stored using the
+// base name of their file as key.
+func (k8s *k8sGenerator) generateVolumeData(deploymentID, volumeNodeName string) (map[string]string, error) {
+	data := make(map[string]string)
+	_, dataStr, err := deployments.GetNodeProperty(k8s.kv, deploymentID, volumeNodeName, "data")
+	if err != nil {
+		return nil, err
+	}
+	if dataStr != "" {
+		var props map[string]interface{}
+		if err = json.Unmarshal([]byte(dataStr), &props); err != nil {
+			return nil, errors.Wrapf(err, "Failed to parse data property of volume node %q", volumeNodeName)
+		}
+		for k, v := range props {
+			data[k] = fmt.Sprint(v)
+		}
+	}
+
+	artifacts, err := deployments.GetArtifactsForNode(k8s.kv, deploymentID, volumeNodeName)
+	if err != nil {
+		return nil, err
+	}
+	overlayPath := filepath.Join(k8s.cfg.WorkingDirectory, "deployments", deploymentID, "overlay")
+	for artName, artPath := range artifacts {
+		key := path.Base(artPath)
+		if _, ok := data[key]; ok {
+			return nil, errors.Errorf("Artifact %q of volume node %q conflicts with an existing data key %q", artName, volumeNodeName, key)
+		}
+		content, err := ioutil.ReadFile(filepath.Join(overlayPath, filepath.FromSlash(artPath)))
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to read artifact %q of volume node %q", artName, volumeNodeName)
+		}
+		data[key] = string(content)
+	}
+	return data, nil
+}
+
+// generateConfigMap generates the Kubernetes ConfigMap backing a configMap volume node
+func (k8s *k8sGenerator) generateConfigMap(deploymentID, volumeNodeName string) (v1.ConfigMap, error) {
+	data, err := k8s.generateVolumeData(deploymentID, volumeNodeName)
+	if err != nil {
+		return v1.ConfigMap{}, err
+	}
+	return v1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: k8s.generateVolumeObjectName(volumeNodeName)},
+		Data:       data,
+	}, nil
+}
+
+// generateSecret generates the Kubernetes Secret backing a secret volume node
+func (k8s *k8sGenerator) generateSecret(deploymentID, volumeNodeName string) (v1.Secret, error) {
+	data, err := k8s.generateVolumeData(deploymentID, volumeNodeName)
+	if err != nil {
+		return v1.Secret{}, err
+	}
+	return v1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: k8s.generateVolumeObjectName(volumeNodeName)},
+		Type:       v1.SecretTypeOpaque,
+		StringData: data,
+	}, nil
+}
+
 // Generate an emptyDir Kubernetes Volume
 func (k8s *k8sGenerator) generateEmptyDirVolumeSource(deploymentID, volumeNodeName string) v1.EmptyDirVolumeSource {
 	found, mediumVal, err := deployments.GetNodeProperty(k8s.kv, deploymentID, volumeNodeName, "medium")
@@ -275,6 +476,48 @@ func (k8s *k8sGenerator) generateVolumeMounts(deploymentID, nodeName string) ([]
 	return volumeMounts, err
 }
 
+// getArtifactPathInContainer returns the path of an artifact file in the containers of a node
+//
+// Artifacts are available in containers only if they are artifacts of a configMap or secret volume used by the node.
+// artifactPath is the path of the artifact relative to the deployment root.
+func (k8s *k8sGenerator) getArtifactPathInContainer(deploymentID, nodeName, artifactPath string) (string, error) {
+	usedVolumeNodeNames, err := getUsedVolumeNodesNames(k8s.kv, deploymentID, nodeName)
+	if err != nil {
+		return "", err
+	}
+	for _, volumeNodeName := range usedVolumeNodeNames {
+		_, vtype, err := deployments.GetNodeProperty(k8s.kv, deploymentID, volumeNodeName, "volume_type")
+		if err != nil {
+			return "", err
+		}
+		if vtype != "configMap" && vtype != "secret" {
+			continue
+		}
+		artifacts, err := deployments.GetArtifactsForNode(k8s.kv, deploymentID, volumeNodeName)
+		if err != nil {
+			return "", err
+		}
+		for _, artPath := range artifacts {
+			if artPath != artifactPath {
+				continue
+			}
+			volumeMount, err := k8s.generateVolumeMount(deploymentID, volumeNodeName)
+			if err != nil {
+				return "", err
+			}
+			// Volume data keys are the base names of artifacts files
+			key := path.Base(artPath)
+			switch volumeMount.SubPath {
+			case "":
+				return path.Join(volumeMount.MountPath, key), nil
+			case key:
+				return volumeMount.MountPath, nil
+			}
+		}
+	}
+	return "", errors.Errorf("Artifact %q is not available in containers of node %q, only artifacts of configMap or secret volumes mounted by the node are supported", artifactPath, nodeName)
+}
+
 // Get the node names corresponding to volumes mounted by the node 'nodeName'
 // Used volumes are obtained based on the requirements named 'use_volume'
 func getUsedVolumeNodesNames(kv *api.KV, deploymentID, nodeName string) ([]string, error) {
@@ -296,11 +539,21 @@ func getUsedVolumeNodesNames(kv *api.KV, deploymentID, nodeName string) ([]strin
 	return usedVolumeNodesNames, nil
 }
 
-// GenerateDeployment generate Kubernetes Pod and Service to deploy based of given Node
-func (k8s *k8sGenerator) generateDeployment(deploymentID, nodeName, operation, nodeType, repoName string, inputs []v1.EnvVar, nbInstances int32) (v1beta1.Deployment, v1.Service, error) {
+// generateObjectMeta generates the metadata shared by the Kubernetes objects of a given Node
+func (k8s *k8sGenerator) generateObjectMeta(deploymentID, nodeName string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:   strings.ToLower(generatePodName(k8s.cfg.ResourcesPrefix + nodeName)),
+		Labels: map[string]string{"name": strings.ToLower(nodeName), "nodeId": deploymentID + "-" + generatePodName(nodeName)},
+	}
+}
+
+// generatePodTemplate generates the Kubernetes Pod template of a given Node
+//
+// It is shared by Deployments, StatefulSets and Jobs. Claim templates are returned only if withClaimTemplates is true.
+func (k8s *k8sGenerator) generatePodTemplate(deploymentID, nodeName, operation, nodeType, repoName string, inputs []v1.EnvVar, withClaimTemplates bool) (v1.PodTemplateSpec, []v1.PersistentVolumeClaim, error) {
 	imgName, err := deployments.GetOperationImplementationFile(k8s.kv, deploymentID, nodeType, operation)
 	if err != nil {
-		return v1beta1.Deployment{}, v1.Service{}, err
+		return v1.PodTemplateSpec{}, nil, err
 	}
 
 	// TODO make these properties coherent with Tosca node type properties
@@ -316,28 +569,22 @@ func (k8s *k8sGenerator) generateDeployment(deploymentID, nodeName, operation, n
 
 	_, imagePullPolicy, err := deployments.GetNodeProperty(k8s.kv, deploymentID, nodeName, "imagePullPolicy")
 	_, dockerRunCmd, err := deployments.GetNodeProperty(k8s.kv, deploymentID, nodeName, "docker_run_cmd")
-	_, dockerPorts, err := deployments.GetNodeProperty(k8s.kv, deploymentID, nodeName, "docker_ports")
 
 	limits, err := generateLimitsResources(cpuLimitStr, memLimitStr)
 	if err != nil {
-		return v1beta1.Deployment{}, v1.Service{}, err
+		return v1.PodTemplateSpec{}, nil, err
 	}
 
 	// mem_share does not exist neither in docker nor in K8s
 	// maybe should be replaced with mem_requests
 	requests, err := generateRequestResources(cpuShareStr, memShareStr)
 	if err != nil {
-		return v1beta1.Deployment{}, v1.Service{}, err
-	}
-
-	metadata := metav1.ObjectMeta{
-		Name:   strings.ToLower(generatePodName(k8s.cfg.ResourcesPrefix + nodeName)),
-		Labels: map[string]string{"name": strings.ToLower(nodeName), "nodeId": deploymentID + "-" + generatePodName(nodeName)},
+		return v1.PodTemplateSpec{}, nil, err
 	}
 
 	volumeMounts, err := k8s.generateVolumeMounts(deploymentID, nodeName)
 	if err != nil {
-		return v1beta1.Deployment{}, v1.Service{}, err
+		return v1.PodTemplateSpec{}, nil, err
 	}
 
 	container := k8s.generateContainer(nodeName, imgName, imagePullPolicy, dockerRunCmd, requests, limits, inputs, volumeMounts)
@@ -348,10 +595,31 @@ func (k8s *k8sGenerator) generateDeployment(deploymentID, nodeName, operation, n
 		pullRepo = append(pullRepo, v1.LocalObjectReference{Name: repoName})
 	}
 
-	usedVolumes, err := k8s.generateUsedVolumes(deploymentID, nodeName)
+	usedVolumes, claimTemplates, err := k8s.generateUsedVolumes(deploymentID, nodeName, withClaimTemplates)
+	if err != nil {
+		return v1.PodTemplateSpec{}, nil, err
+	}
+
+	podTemplate := v1.PodTemplateSpec{
+		ObjectMeta: k8s.generateObjectMeta(deploymentID, nodeName),
+		Spec: v1.PodSpec{
+			Volumes: usedVolumes,
+			Containers: []v1.Container{
+				container,
+			},
+			ImagePullSecrets: pullRepo,
+		},
+	}
+	return podTemplate, claimTemplates, nil
+}
+
+// GenerateDeployment generate Kubernetes Pod and Service to deploy based of given Node
+func (k8s *k8sGenerator) generateDeployment(deploymentID, nodeName, operation, nodeType, repoName string, inputs []v1.EnvVar, nbInstances int32) (v1beta1.Deployment, v1.Service, error) {
+	podTemplate, _, err := k8s.generatePodTemplate(deploymentID, nodeName, operation, nodeType, repoName, inputs, false)
 	if err != nil {
 		return v1beta1.Deployment{}, v1.Service{}, err
 	}
+	metadata := k8s.generateObjectMeta(deploymentID, nodeName)
 
 	deployment := v1beta1.Deployment{
 		TypeMeta: metav1.TypeMeta{
@@ -364,19 +632,124 @@ func (k8s *k8sGenerator) generateDeployment(deploymentID, nodeName, operation, n
 			Selector: &metav1.LabelSelector{
 				MatchLabels: metadata.Labels,
 			},
-			Template: v1.PodTemplateSpec{
-				ObjectMeta: metadata,
-				Spec: v1.PodSpec{
-					Volumes: usedVolumes,
-					Containers: []v1.Container{
-						container,
-					},
-					ImagePullSecrets: pullRepo,
-				},
+			Template: podTemplate,
+		},
+	}
+
+	service, err := k8s.generateService(deploymentID, nodeName)
+	return deployment, service, err
+}
+
+// generateStatefulSet generate Kubernetes StatefulSet and Service to deploy based of given Node
+//
+// Persistent volume claims used by the node that do not reference an existing claim are generated as claim templates,
+// this way each replica gets its own persistent volume.
+func (k8s *k8sGenerator) generateStatefulSet(deploymentID, nodeName, operation, nodeType, repoName string, inputs []v1.EnvVar, nbInstances int32) (appsv1beta1.StatefulSet, v1.Service, error) {
+	podTemplate, claimTemplates, err := k8s.generatePodTemplate(deploymentID, nodeName, operation, nodeType, repoName, inputs, true)
+	if err != nil {
+		return appsv1beta1.StatefulSet{}, v1.Service{}, err
+	}
+	metadata := k8s.generateObjectMeta(deploymentID, nodeName)
+
+	_, podManagementPolicy, err := deployments.GetNodeProperty(k8s.kv, deploymentID, nodeName, "pod_management_policy")
+	if err != nil {
+		return appsv1beta1.StatefulSet{}, v1.Service{}, err
+	}
+
+	statefulSet := appsv1beta1.StatefulSet{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "StatefulSet",
+			APIVersion: "apps/v1beta1",
+		},
+		ObjectMeta: metadata,
+		Spec: appsv1beta1.StatefulSetSpec{
+			Replicas: &nbInstances,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: metadata.Labels,
 			},
+			Template:             podTemplate,
+			VolumeClaimTemplates: claimTemplates,
+			ServiceName:          metadata.Name,
+			PodManagementPolicy:  appsv1beta1.PodManagementPolicyType(podManagementPolicy),
+		},
+	}
+
+	service, err := k8s.generateService(deploymentID, nodeName)
+	return statefulSet, service, err
+}
+
+// generateJob generate a Kubernetes Job running to completion based of given Node
+func (k8s *k8sGenerator) generateJob(deploymentID, nodeName, operation, nodeType, repoName string, inputs []v1.EnvVar) (batchv1.Job, error) {
+	podTemplate, _, err := k8s.generatePodTemplate(deploymentID, nodeName, operation, nodeType, repoName, inputs, false)
+	if err != nil {
+		return batchv1.Job{}, err
+	}
+
+	_, restartPolicy, err := deployments.GetNodeProperty(k8s.kv, deploymentID, nodeName, "restart_policy")
+	if err != nil {
+		return batchv1.Job{}, err
+	}
+	if restartPolicy == "" {
+		restartPolicy = string(v1.RestartPolicyOnFailure)
+	}
+	podTemplate.Spec.RestartPolicy = v1.RestartPolicy(restartPolicy)
+
+	job := batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Job",
+			APIVersion: "batch/v1",
+		},
+		ObjectMeta: k8s.generateObjectMeta(deploymentID, nodeName),
+		Spec: batchv1.JobSpec{
+			Template: podTemplate,
 		},
 	}
 
+	job.Spec.Completions, err = k8s.getInt32NodeProperty(deploymentID, nodeName, "completions")
+	if err != nil {
+		return batchv1.Job{}, err
+	}
+	job.Spec.Parallelism, err = k8s.getInt32NodeProperty(deploymentID, nodeName, "parallelism")
+	if err != nil {
+		return batchv1.Job{}, err
+	}
+	_, deadline, err := deployments.GetNodeProperty(k8s.kv, deploymentID, nodeName, "active_deadline_seconds")
+	if err != nil {
+		return batchv1.Job{}, err
+	}
+	if deadline != "" {
+		d, err := strconv.ParseInt(deadline, 10, 64)
+		if err != nil {
+			return batchv1.Job{}, errors.Wrapf(err, "Failed to parse active_deadline_seconds property of node %q", nodeName)
+		}
+		job.Spec.ActiveDeadlineSeconds = &d
+	}
+	return job, nil
+}
+
+// getInt32NodeProperty returns the value of an optional integer property of a given Node or nil if not set
+func (k8s *k8sGenerator) getInt32NodeProperty(deploymentID, nodeName, propertyName string) (*int32, error) {
+	_, value, err := deployments.GetNodeProperty(k8s.kv, deploymentID, nodeName, propertyName)
+	if err != nil || value == "" {
+		return nil, err
+	}
+	i, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to parse %s property of node %q", propertyName, nodeName)
+	}
+	res := int32(i)
+	return &res, nil
+}
+
+// generateService generate the Kubernetes Service exposing the docker_ports of a given Node
+//
+// An empty Service is returned if the node does not expose any port
+func (k8s *k8sGenerator) generateService(deploymentID, nodeName string) (v1.Service, error) {
+	_, dockerPorts, err := deployments.GetNodeProperty(k8s.kv, deploymentID, nodeName, "docker_ports")
+	if err != nil {
+		return v1.Service{}, err
+	}
+
 	service := v1.Service{}
 
 	if dockerPorts != "" {
@@ -406,7 +779,7 @@ func (k8s *k8sGenerator) generateDeployment(deploymentID, nodeName, operation, n
 				Kind:       "Service",
 				APIVersion: "v1",
 			},
-			ObjectMeta: metadata,
+			ObjectMeta: k8s.generateObjectMeta(deploymentID, nodeName),
 			Spec: v1.ServiceSpec{
 				Type:     v1.ServiceTypeNodePort,
 				Selector: map[string]string{"nodeId": deploymentID + "-" + generatePodName(nodeName)},
@@ -415,5 +788,5 @@ func (k8s *k8sGenerator) generateDeployment(deploymentID, nodeName, operation, n
 		}
 	}
 
-	return deployment, service, nil
+	return service, nil
 }
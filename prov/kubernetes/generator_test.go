@@ -0,0 +1,59 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+
+	"github.com/ystia/yorc/tasks"
+)
+
+func TestParseAccessModes(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		input   string
+		want    []v1.PersistentVolumeAccessMode
+		wantErr bool
+	}{
+		{"Default", "", []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce}, false},
+		{"EmptyList", "[]", []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce}, false},
+		{"Multiple", `["ReadOnlyMany","ReadWriteMany"]`, []v1.PersistentVolumeAccessMode{v1.ReadOnlyMany, v1.ReadWriteMany}, false},
+		{"Unsupported", `["WriteOnly"]`, nil, true},
+		{"NotAList", `ReadWriteOnce`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAccessModes(tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestComputeReplicas(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, int32(5), computeReplicas(3, tasks.ScaleOut, 2))
+	assert.Equal(t, int32(1), computeReplicas(3, tasks.ScaleIn, 2))
+	assert.Equal(t, int32(3), computeReplicas(3, tasks.Deploy, 2))
+}
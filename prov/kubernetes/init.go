@@ -18,6 +18,15 @@ import "github.com/ystia/yorc/registry"
 
 const (
 	kubernetesArtifactImplementation = "tosca.artifacts.Deployment.Image.Container.Docker.Kubernetes"
+	statefulSetNodeType              = "yorc.nodes.KubernetesStatefulSet"
+	jobNodeType                      = "yorc.nodes.KubernetesJob"
+)
+
+// Kinds of Kubernetes workloads used to deploy nodes
+const (
+	deploymentWorkload  = "deployment"
+	statefulSetWorkload = "statefulset"
+	jobWorkload         = "job"
 )
 
 func init() {
@@ -29,14 +29,17 @@ import (
 // An EnvInput represent a TOSCA operation input
 //
 // This element is exported in order to be used by text.Template but should be consider as internal
+//
+// IsArtifact is true if Value is the path of an artifact relative to the deployment root (see deployments.OperationInputResult)
 type EnvInput struct {
 	Name         string
 	Value        string
 	InstanceName string
+	IsArtifact   bool
 }
 
 func (ei EnvInput) String() string {
-	return fmt.Sprintf("EnvInput: [Name: %q, Value: %q, InstanceName: %q]", ei.Name, ei.Value, ei.InstanceName)
+	return fmt.Sprintf("EnvInput: [Name: %q, Value: %q, InstanceName: %q, IsArtifact: %t]", ei.Name, ei.Value, ei.InstanceName, ei.IsArtifact)
 }
 
 // ResolveInputs allows to resolve inputs for an operation
@@ -110,7 +113,7 @@ func ResolveInputsWithInstances(kv *api.KV, deploymentID, nodeName, taskID strin
 				return nil, nil, err
 			}
 			for i, iv := range inputValues {
-				envInputs = append(envInputs, &EnvInput{Name: input, InstanceName: GetInstanceName(iv.NodeName, iv.InstanceName), Value: iv.Value})
+				envInputs = append(envInputs, &EnvInput{Name: input, InstanceName: GetInstanceName(iv.NodeName, iv.InstanceName), Value: iv.Value, IsArtifact: iv.IsArtifact})
 				if i == 0 {
 					varInputsNames = append(varInputsNames, provutil.SanitizeForShell(input))
 				}
@@ -86,3 +86,48 @@ type OperationExecutor interface {
 type InfraUsageCollector interface {
 	GetUsageInfo(ctx context.Context, cfg config.Configuration, taskID, infraName string) (map[string]interface{}, error)
 }
+
+// DriftDetector is an optional interface that may be implemented by a DelegateExecutor managing infrastructure resources
+//
+// DetectDrift compares the infrastructure resources of the given nodeName with their expected state and returns the
+// resources that were modified or deleted outside of Yorc.
+// ReconcileDrift brings back those resources to their expected state.
+type DriftDetector interface {
+	DetectDrift(ctx context.Context, conf config.Configuration, taskID, deploymentID, nodeName string) ([]ResourceDrift, error)
+	ReconcileDrift(ctx context.Context, conf config.Configuration, taskID, deploymentID, nodeName string) error
+}
+
+// A DriftKind describes how an infrastructure resource diverges from its expected state
+type DriftKind string
+
+const (
+	// DriftModified is the drift of a resource modified outside of Yorc
+	DriftModified DriftKind = "modified"
+	// DriftDeleted is the drift of a resource deleted outside of Yorc
+	DriftDeleted DriftKind = "deleted"
+	// DriftReplaced is the drift of a resource modified outside of Yorc in a way that requires to replace it
+	DriftReplaced DriftKind = "replaced"
+	// DriftOrphaned is the drift of a resource that exists while it is no more expected
+	DriftOrphaned DriftKind = "orphaned"
+)
+
+// ResourceDrift describes an infrastructure resource that diverges from its expected state
+type ResourceDrift struct {
+	Resource string    `json:"resource"`
+	Kind     DriftKind `json:"kind"`
+}
+
+// NodeDrift gathers the drifted infrastructure resources of a node
+type NodeDrift struct {
+	NodeName  string          `json:"node"`
+	Resources []ResourceDrift `json:"resources,omitempty"`
+}
+
+// DriftReport is the result of a drift detection on a deployment
+//
+// It contains all the nodes that were checked, drifted or not.
+type DriftReport struct {
+	DeploymentID string      `json:"deployment_id"`
+	Drifted      bool        `json:"drifted"`
+	Nodes        []NodeDrift `json:"nodes,omitempty"`
+}
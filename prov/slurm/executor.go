@@ -37,7 +37,6 @@ import (
 
 type defaultExecutor struct {
 	generator defaultGenerator
-	client    *sshutil.SSHClient
 }
 
 type allocationResponse struct {
@@ -72,7 +71,12 @@ func (e *defaultExecutor) ExecDelegate(ctx context.Context, cfg config.Configura
 		events.OperationName: delegateOperation,
 	}
 
-	e.client, err = GetSSHClient(cfg)
+	// The executor is shared by concurrent operations so the client is specific to this one
+	var client *sshutil.SSHClient
+	location, locationCfg, err := getNodeLocationConfig(kv, cfg, deploymentID, nodeName)
+	if err == nil {
+		client, err = GetSSHClient(location, locationCfg)
+	}
 	if err != nil {
 		events.WithOptionalFields(logOptFields).NewLogEntry(events.ERROR, deploymentID).RegisterAsString(err.Error())
 		return err
@@ -81,16 +85,16 @@ func (e *defaultExecutor) ExecDelegate(ctx context.Context, cfg config.Configura
 	operation := strings.ToLower(delegateOperation)
 	switch {
 	case operation == "install":
-		err = e.installNode(ctx, kv, cfg, deploymentID, nodeName, instances, logOptFields, operation)
+		err = e.installNode(ctx, kv, cfg, client, deploymentID, nodeName, instances, logOptFields, operation)
 	case operation == "uninstall":
-		err = e.uninstallNode(ctx, kv, cfg, deploymentID, nodeName, instances, logOptFields, operation)
+		err = e.uninstallNode(ctx, kv, cfg, client, deploymentID, nodeName, instances, logOptFields, operation)
 	default:
 		return errors.Errorf("Unsupported operation %q", delegateOperation)
 	}
 	return err
 }
 
-func (e *defaultExecutor) installNode(ctx context.Context, kv *api.KV, cfg config.Configuration, deploymentID, nodeName string, instances []string, logOptFields events.LogOptionalFields, operation string) error {
+func (e *defaultExecutor) installNode(ctx context.Context, kv *api.KV, cfg config.Configuration, client *sshutil.SSHClient, deploymentID, nodeName string, instances []string, logOptFields events.LogOptionalFields, operation string) error {
 	for _, instance := range instances {
 		err := deployments.SetInstanceState(kv, deploymentID, nodeName, instance, tosca.NodeStateCreating)
 		if err != nil {
@@ -101,13 +105,13 @@ func (e *defaultExecutor) installNode(ctx context.Context, kv *api.KV, cfg confi
 	if err != nil {
 		return err
 	}
-	if err = e.createInfrastructure(ctx, kv, cfg, deploymentID, nodeName, infra, logOptFields); err != nil {
+	if err = e.createInfrastructure(ctx, kv, cfg, client, deploymentID, nodeName, infra, logOptFields); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (e *defaultExecutor) uninstallNode(ctx context.Context, kv *api.KV, cfg config.Configuration, deploymentID, nodeName string, instances []string, logOptFields events.LogOptionalFields, operation string) error {
+func (e *defaultExecutor) uninstallNode(ctx context.Context, kv *api.KV, cfg config.Configuration, client *sshutil.SSHClient, deploymentID, nodeName string, instances []string, logOptFields events.LogOptionalFields, operation string) error {
 	for _, instance := range instances {
 		err := deployments.SetInstanceState(kv, deploymentID, nodeName, instance, tosca.NodeStateDeleting)
 		if err != nil {
@@ -119,19 +123,19 @@ func (e *defaultExecutor) uninstallNode(ctx context.Context, kv *api.KV, cfg con
 		return err
 	}
 
-	if err = e.destroyInfrastructure(ctx, kv, cfg, deploymentID, nodeName, infra, logOptFields); err != nil {
+	if err = e.destroyInfrastructure(ctx, kv, cfg, client, deploymentID, nodeName, infra, logOptFields); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (e *defaultExecutor) createInfrastructure(ctx context.Context, kv *api.KV, cfg config.Configuration, deploymentID, nodeName string, infra *infrastructure, logOptFields events.LogOptionalFields) error {
+func (e *defaultExecutor) createInfrastructure(ctx context.Context, kv *api.KV, cfg config.Configuration, client *sshutil.SSHClient, deploymentID, nodeName string, infra *infrastructure, logOptFields events.LogOptionalFields) error {
 	events.WithOptionalFields(logOptFields).NewLogEntry(events.INFO, deploymentID).RegisterAsString("Creating the slurm infrastructure")
 	var g errgroup.Group
 	for _, compute := range infra.nodes {
 		func(comp *nodeAllocation) {
 			g.Go(func() error {
-				return e.createNodeAllocation(ctx, kv, comp, deploymentID, nodeName, logOptFields)
+				return e.createNodeAllocation(ctx, kv, client, comp, deploymentID, nodeName, logOptFields)
 			})
 		}(compute)
 	}
@@ -147,13 +151,13 @@ func (e *defaultExecutor) createInfrastructure(ctx context.Context, kv *api.KV,
 	return nil
 }
 
-func (e *defaultExecutor) destroyInfrastructure(ctx context.Context, kv *api.KV, cfg config.Configuration, deploymentID, nodeName string, infra *infrastructure, logOptFields events.LogOptionalFields) error {
+func (e *defaultExecutor) destroyInfrastructure(ctx context.Context, kv *api.KV, cfg config.Configuration, client *sshutil.SSHClient, deploymentID, nodeName string, infra *infrastructure, logOptFields events.LogOptionalFields) error {
 	events.WithOptionalFields(logOptFields).NewLogEntry(events.INFO, deploymentID).RegisterAsString("Destroying the slurm infrastructure")
 	var g errgroup.Group
 	for _, compute := range infra.nodes {
 		func(comp *nodeAllocation) {
 			g.Go(func() error {
-				return e.destroyNodeAllocation(ctx, kv, comp, deploymentID, nodeName, logOptFields)
+				return e.destroyNodeAllocation(ctx, kv, client, comp, deploymentID, nodeName, logOptFields)
 			})
 		}(compute)
 	}
@@ -169,7 +173,7 @@ func (e *defaultExecutor) destroyInfrastructure(ctx context.Context, kv *api.KV,
 	return nil
 }
 
-func (e *defaultExecutor) createNodeAllocation(ctx context.Context, kv *api.KV, nodeAlloc *nodeAllocation, deploymentID, nodeName string, logOptFields events.LogOptionalFields) error {
+func (e *defaultExecutor) createNodeAllocation(ctx context.Context, kv *api.KV, client *sshutil.SSHClient, nodeAlloc *nodeAllocation, deploymentID, nodeName string, logOptFields events.LogOptionalFields) error {
 	events.WithOptionalFields(logOptFields).NewLogEntry(events.INFO, deploymentID).RegisterAsString(fmt.Sprintf("Creating node allocation for: deploymentID:%q, node name:%q", deploymentID, nodeName))
 	// salloc cmd
 	var sallocCPUFlag, sallocMemFlag, sallocPartitionFlag, sallocGresFlag string
@@ -189,7 +193,7 @@ func (e *defaultExecutor) createNodeAllocation(ctx context.Context, kv *api.KV,
 	// salloc command can potentially be a long synchronous command according to the slurm cluster state
 	// so we run it with a session wrapper with stderr/stdout in order to allow job cancellation if user decides to give up the deployment
 	var wg sync.WaitGroup
-	sessionWrapper, err := e.client.GetSessionWrapper()
+	sessionWrapper, err := client.GetSessionWrapper()
 	if err != nil {
 		return errors.Wrap(err, "Failed to get an SSH session wrapper")
 	}
@@ -232,7 +236,7 @@ func (e *defaultExecutor) createNodeAllocation(ctx context.Context, kv *api.KV,
 		case <-ctx.Done():
 			if &allocResponse != nil && allocResponse.jobID != "" {
 				log.Debug("Cancellation message has been sent: the pending job allocation has to be removed")
-				if err := cancelJobID(allocResponse.jobID, e.client); err != nil {
+				if err := cancelJobID(allocResponse.jobID, client); err != nil {
 					log.Printf("[Warning] an error occurred during cancelling jobID:%q", allocResponse.jobID)
 					return
 				}
@@ -258,7 +262,7 @@ func (e *defaultExecutor) createNodeAllocation(ctx context.Context, kv *api.KV,
 	// run squeue cmd to get slurm node name
 	//TODO: use getAttribute function (modify it to be able to add more than one attribute)
 	squeueCmd := fmt.Sprintf("squeue -n %s -j %s --noheader -o \"%%N,%%P\"", nodeAlloc.jobName, allocResponse.jobID)
-	squeueOutput, err := e.client.RunCommand(squeueCmd)
+	squeueOutput, err := client.RunCommand(squeueCmd)
 	if err != nil {
 		return errors.Wrap(err, "Failed to retrieve Slurm node name/partition")
 	}
@@ -288,7 +292,7 @@ func (e *defaultExecutor) createNodeAllocation(ctx context.Context, kv *api.KV,
 
 	// Get cuda_visible_device attribute
 	var cudaVisibleDevice string
-	if cudaVisibleDevice, err = getAttribute(e.client, "cuda_visible_devices", allocResponse.jobID, nodeName); err != nil {
+	if cudaVisibleDevice, err = getAttribute(client, "cuda_visible_devices", allocResponse.jobID, nodeName); err != nil {
 		// cuda_visible_device attribute is not mandatory : just log the error
 		log.Println("[Warning]: " + err.Error())
 	}
@@ -307,7 +311,7 @@ func (e *defaultExecutor) createNodeAllocation(ctx context.Context, kv *api.KV,
 	return nil
 }
 
-func (e *defaultExecutor) destroyNodeAllocation(ctx context.Context, kv *api.KV, nodeAlloc *nodeAllocation, deploymentID, nodeName string, logOptFields events.LogOptionalFields) error {
+func (e *defaultExecutor) destroyNodeAllocation(ctx context.Context, kv *api.KV, client *sshutil.SSHClient, nodeAlloc *nodeAllocation, deploymentID, nodeName string, logOptFields events.LogOptionalFields) error {
 	events.WithOptionalFields(logOptFields).NewLogEntry(events.INFO, deploymentID).RegisterAsString(fmt.Sprintf("Destroying node allocation for: deploymentID:%q, node name:%q, instance name:%q", deploymentID, nodeName, nodeAlloc.instanceName))
 	// scancel cmd
 	found, jobID, err := deployments.GetInstanceAttribute(kv, deploymentID, nodeName, nodeAlloc.instanceName, "job_id")
@@ -318,7 +322,7 @@ func (e *defaultExecutor) destroyNodeAllocation(ctx context.Context, kv *api.KV,
 		if !found {
 			log.Printf("[Warning]: No job ID found for node name:%q, instance name:%q. We assume it has already been deleted", nodeName, nodeAlloc.instanceName)
 		} else {
-			if err := cancelJobID(jobID, e.client); err != nil {
+			if err := cancelJobID(jobID, client); err != nil {
 				return err
 			}
 			events.WithOptionalFields(logOptFields).NewLogEntry(events.INFO, deploymentID).RegisterAsString(fmt.Sprintf("Cancelling Job ID:%q", jobID))
@@ -16,69 +16,146 @@ package slurm
 
 import (
 	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
-	"github.com/pkg/errors"
-	"github.com/ystia/yorc/config"
-	"github.com/ystia/yorc/helper/sshutil"
-	"github.com/ystia/yorc/log"
-	"golang.org/x/crypto/ssh"
 	"io"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/helper/sshutil"
+	"github.com/ystia/yorc/log"
 )
 
+// locationsKey is the key of named Slurm locations in the Slurm infrastructure configuration
+const locationsKey = "locations"
+
+// locationClient is an SSH client cached for a location along with a hash of the configuration it was created from
+type locationClient struct {
+	configHash string
+	client     *sshutil.SSHClient
+}
+
+// clients caches SSH clients by location name in order to reuse their connections
+var clients = make(map[string]locationClient)
+var clientsLock sync.Mutex
+
+// getLocationConfig returns the configuration of a Slurm location.
+//
+// An empty location name refers to the default location defined at the root of the Slurm infrastructure configuration.
+// Named locations are defined under the "locations" key and inherit the parameters of the default location they do not override.
+func getLocationConfig(cfg config.Configuration, location string) (config.DynamicMap, error) {
+	infraCfg, exist := cfg.Infrastructures[infrastructureName]
+	if !exist {
+		return nil, errors.New("no slurm infrastructure configuration found")
+	}
+	locationCfg, err := infraCfg.GetNamedConfig(locationsKey, location)
+	return locationCfg, errors.Wrapf(err, "failed to get configuration of slurm location %q", location)
+}
+
+// getNodeLocationConfig returns the name and the configuration of the Slurm location defined by the "location" property of a node
+func getNodeLocationConfig(kv *api.KV, cfg config.Configuration, deploymentID, nodeName string) (string, config.DynamicMap, error) {
+	_, location, err := deployments.GetNodeProperty(kv, deploymentID, nodeName, "location")
+	if err != nil {
+		return "", nil, err
+	}
+	location = strings.TrimSpace(location)
+	locationCfg, err := getLocationConfig(cfg, location)
+	return location, locationCfg, err
+}
+
 // GetSSHClient returns a SSH client with slurm configuration credentials usage
-func GetSSHClient(cfg config.Configuration) (*sshutil.SSHClient, error) {
+//
+// Clients are shared by location and keep their connections open. If the configuration of a location changed,
+// its previous client is closed and replaced.
+func GetSSHClient(location string, locationCfg config.DynamicMap) (*sshutil.SSHClient, error) {
 	// Check slurm configuration
-	if err := checkInfraConfig(cfg); err != nil {
+	if err := checkLocationConfig(locationCfg); err != nil {
 		log.Printf("Unable to provide SSH client due to:%+v", err)
 		return nil, err
 	}
 
-	// Get SSH client
-	SSHConfig := &ssh.ClientConfig{
-		User: cfg.Infrastructures[infrastructureName].GetString("user_name"),
-		Auth: []ssh.AuthMethod{
-			ssh.Password(cfg.Infrastructures[infrastructureName].GetString("password")),
-		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-	}
-
-	port, err := strconv.Atoi(cfg.Infrastructures[infrastructureName].GetString("port"))
+	port, err := strconv.Atoi(locationCfg.GetString("port"))
 	if err != nil {
 		wrapErr := errors.Wrap(err, "slurm configuration port is not a valid port")
 		log.Printf("Unable to provide SSH client due to:%+v", wrapErr)
-		return nil, err
+		return nil, wrapErr
 	}
+	user := locationCfg.GetString("user_name")
+	host := locationCfg.GetString("url")
+	password := locationCfg.GetString("password")
+	privateKey := locationCfg.GetString("private_key")
 
-	return &sshutil.SSHClient{
-		Config: SSHConfig,
-		Host:   cfg.Infrastructures[infrastructureName].GetString("url"),
-		Port:   port,
-	}, nil
-}
+	// Credentials are hashed in order to detect configuration changes without keeping them in clear
+	sum := sha256.Sum256([]byte(strings.Join([]string{user, host, strconv.Itoa(port), password, privateKey}, "\x00")))
+	configHash := hex.EncodeToString(sum[:])
+	clientsLock.Lock()
+	defer clientsLock.Unlock()
+	cached, ok := clients[location]
+	if ok && cached.configHash == configHash {
+		return cached.client, nil
+	}
 
-// checkInfraConfig checks infrastructure mandatory configuration parameters
-func checkInfraConfig(cfg config.Configuration) error {
-	_, exist := cfg.Infrastructures[infrastructureName]
-	if !exist {
-		return errors.New("no slurm infrastructure configuration found")
+	// Get SSH client
+	SSHConfig := &ssh.ClientConfig{
+		User:            user,
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	}
+	if privateKey != "" {
+		keyAuth, err := sshutil.ReadPrivateKey(privateKey)
+		if err != nil {
+			return nil, err
+		}
+		SSHConfig.Auth = append(SSHConfig.Auth, keyAuth)
+	}
+	if agentAuth, ok := sshutil.SSHAgentAuth(); ok {
+		SSHConfig.Auth = append(SSHConfig.Auth, agentAuth)
+	}
+	if password != "" {
+		SSHConfig.Auth = append(SSHConfig.Auth, ssh.Password(password))
 	}
 
-	if strings.Trim(cfg.Infrastructures[infrastructureName].GetString("user_name"), "") == "" {
+	client := &sshutil.SSHClient{
+		Config:           SSHConfig,
+		Host:             host,
+		Port:             port,
+		ReuseConnections: true,
+	}
+	if ok {
+		log.Debugf("Configuration of slurm location %q changed, closing its previous SSH connections", location)
+		if err := cached.client.Close(); err != nil {
+			log.Printf("[Warning] failed to close SSH connections of slurm location %q: %v", location, err)
+		}
+	}
+	clients[location] = locationClient{configHash: configHash, client: client}
+	return client, nil
+}
+
+// checkLocationConfig checks location mandatory configuration parameters
+func checkLocationConfig(locationCfg config.DynamicMap) error {
+	if strings.Trim(locationCfg.GetString("user_name"), "") == "" {
 		return errors.New("slurm infrastructure user_name is not set")
 	}
 
-	if strings.Trim(cfg.Infrastructures[infrastructureName].GetString("password"), "") == "" {
-		return errors.New("slurm infrastructure password is not set")
+	if strings.Trim(locationCfg.GetString("password"), "") == "" && strings.Trim(locationCfg.GetString("private_key"), "") == "" {
+		if _, ok := sshutil.SSHAgentAuth(); !ok {
+			return errors.New("slurm infrastructure password or private_key should be set if no SSH agent is running")
+		}
 	}
 
-	if strings.Trim(cfg.Infrastructures[infrastructureName].GetString("url"), "") == "" {
+	if strings.Trim(locationCfg.GetString("url"), "") == "" {
 		return errors.New("slurm infrastructure url is not set")
 	}
 
-	if strings.Trim(cfg.Infrastructures[infrastructureName].GetString("port"), "") == "" {
+	if strings.Trim(locationCfg.GetString("port"), "") == "" {
 		return errors.New("slurm infrastructure port is not set")
 	}
 
@@ -182,7 +259,7 @@ func parseJobID(str string, regexp *regexp.Regexp) (string, error) {
 	return "", errors.Errorf("Unable to parse std:%q for retrieving jobID", str)
 }
 
-func cancelJobID(jobID string, client *sshutil.SSHClient) error {
+func cancelJobID(jobID string, client sshutil.Client) error {
 	scancelCmd := fmt.Sprintf("scancel %s", jobID)
 	sCancelOutput, err := client.RunCommand(scancelCmd)
 	if err != nil {
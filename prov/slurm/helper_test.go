@@ -17,6 +17,8 @@ package slurm
 import (
 	"errors"
 	"github.com/stretchr/testify/require"
+	"github.com/ystia/yorc/config"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -186,3 +188,77 @@ func TestParseSallocResponseWithExpectedRevokedAllocation(t *testing.T) {
 		require.Fail(t, "No response received")
 	}
 }
+
+func TestGetLocationConfig(t *testing.T) {
+	t.Parallel()
+	cfg := config.Configuration{
+		Infrastructures: map[string]config.DynamicMap{
+			infrastructureName: config.DynamicMap{
+				"user_name":   "root",
+				"private_key": "~/.ssh/id_rsa",
+				"url":         "1.2.3.4",
+				"port":        "22",
+				"locations": map[string]interface{}{
+					"cluster2": map[interface{}]interface{}{
+						"url":               "5.6.7.8",
+						"default_partition": "gpu",
+					},
+				},
+			}}}
+
+	defaultCfg, err := getLocationConfig(cfg, "")
+	require.NoError(t, err)
+	require.Equal(t, "1.2.3.4", defaultCfg.GetString("url"))
+	require.False(t, defaultCfg.IsSet("locations"))
+	require.NoError(t, checkLocationConfig(defaultCfg))
+
+	cluster2Cfg, err := getLocationConfig(cfg, "cluster2")
+	require.NoError(t, err)
+	require.Equal(t, "5.6.7.8", cluster2Cfg.GetString("url"))
+	require.Equal(t, "gpu", cluster2Cfg.GetString("default_partition"))
+	require.Equal(t, "root", cluster2Cfg.GetString("user_name"), "location should inherit default parameters")
+	require.Equal(t, "", defaultCfg.GetString("default_partition"), "default location should not be modified")
+
+	_, err = getLocationConfig(cfg, "unknown")
+	require.Error(t, err)
+
+	_, err = getLocationConfig(config.Configuration{}, "")
+	require.Error(t, err)
+}
+
+func TestCheckLocationConfig(t *testing.T) {
+	// Not parallel as the SSH agent socket environment variable is modified
+	if socket, ok := os.LookupEnv("SSH_AUTH_SOCK"); ok {
+		defer os.Setenv("SSH_AUTH_SOCK", socket)
+	}
+	os.Unsetenv("SSH_AUTH_SOCK")
+	require.NoError(t, checkLocationConfig(config.DynamicMap{"user_name": "root", "password": "pwd", "url": "1.2.3.4", "port": "22"}))
+	require.NoError(t, checkLocationConfig(config.DynamicMap{"user_name": "root", "private_key": "~/.ssh/id_rsa", "url": "1.2.3.4", "port": "22"}))
+	require.Error(t, checkLocationConfig(config.DynamicMap{"user_name": "root", "url": "1.2.3.4", "port": "22"}), "password, private key or SSH agent is required")
+	require.Error(t, checkLocationConfig(config.DynamicMap{"password": "pwd", "url": "1.2.3.4", "port": "22"}), "user name is required")
+
+	os.Setenv("SSH_AUTH_SOCK", "/tmp/ssh-agent.sock")
+	defer os.Unsetenv("SSH_AUTH_SOCK")
+	require.NoError(t, checkLocationConfig(config.DynamicMap{"user_name": "root", "url": "1.2.3.4", "port": "22"}))
+}
+
+func TestGetSSHClientCache(t *testing.T) {
+	t.Parallel()
+	locationCfg := config.DynamicMap{"user_name": "root", "password": "pwd", "url": "1.2.3.4", "port": "22"}
+	client, err := GetSSHClient("testCache", locationCfg)
+	require.NoError(t, err)
+	require.Equal(t, "1.2.3.4", client.Host)
+	require.Equal(t, 22, client.Port)
+
+	sameClient, err := GetSSHClient("testCache", config.DynamicMap{"user_name": "root", "password": "pwd", "url": "1.2.3.4", "port": "22"})
+	require.NoError(t, err)
+	require.True(t, client == sameClient, "client should be reused while the location configuration is unchanged")
+
+	newClient, err := GetSSHClient("testCache", config.DynamicMap{"user_name": "root", "password": "newpwd", "url": "1.2.3.4", "port": "22"})
+	require.NoError(t, err)
+	require.False(t, client == newClient, "client should be replaced when the location credentials changed")
+
+	clientsLock.Lock()
+	defer clientsLock.Unlock()
+	require.NotContains(t, clients["testCache"].configHash, "newpwd", "credentials should not be kept in clear")
+}
@@ -18,5 +18,6 @@ import "github.com/ystia/yorc/registry"
 
 func init() {
 	reg := registry.GetRegistry()
-	reg.RegisterDelegates([]string{`yorc\.nodes\.slurm\..*`}, newExecutor(&slurmGenerator{}), registry.BuiltinOrigin)
+	reg.RegisterDelegates([]string{`yorc\.nodes\.slurm\.Compute`}, newExecutor(&slurmGenerator{}), registry.BuiltinOrigin)
+	reg.RegisterOperationExecutor([]string{batchScriptArtifact}, &jobExecutor{}, registry.BuiltinOrigin)
 }
@@ -0,0 +1,434 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slurm
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/events"
+	"github.com/ystia/yorc/helper/provutil"
+	"github.com/ystia/yorc/helper/sshutil"
+	"github.com/ystia/yorc/helper/stringutil"
+	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/prov"
+	"github.com/ystia/yorc/prov/operations"
+	"github.com/ystia/yorc/tasks"
+)
+
+// batchScriptArtifact is the artifact type of operations implemented by a Slurm batch script
+const batchScriptArtifact = "yorc.artifacts.slurm.BatchScript"
+
+// defaultJobMonitoringTimeInterval is the default interval between two checks of a batch job state
+const defaultJobMonitoringTimeInterval = 5 * time.Second
+
+// maxJobMonitoringFailures is the number of consecutive failures to check a batch job state after which
+// the job is cancelled and its monitoring is given up
+const maxJobMonitoringFailures = 5
+
+// batchScriptDelimiter delimits the batch script content sent to sbatch through a here-document
+const batchScriptDelimiter = "YORC_SLURM_BATCH_SCRIPT_EOF"
+
+var reSbatchJobID = regexp.MustCompile(`^(\d+)(;.*)?$`)
+
+// jobOptions are the sbatch options of a batch job
+type jobOptions struct {
+	jobName          string
+	partition        string
+	nodes            string
+	tasks            string
+	cpusPerTask      string
+	memory           string
+	time             string
+	gres             string
+	workingDirectory string
+}
+
+// jobInfo is the state of a submitted batch job
+type jobInfo struct {
+	jobID      string
+	outputFile string
+	state      string
+	exitCode   string
+}
+
+type jobExecutor struct{}
+
+func (e *jobExecutor) ExecOperation(ctx context.Context, cfg config.Configuration, taskID, deploymentID, nodeName string, operation prov.Operation) error {
+	consulClient, err := cfg.GetConsulClient()
+	if err != nil {
+		return err
+	}
+	kv := consulClient.KV()
+	instances, err := tasks.GetInstances(kv, taskID, deploymentID, nodeName)
+	if err != nil {
+		return err
+	}
+
+	wfName, _ := tasks.GetTaskData(kv, taskID, "workflowName")
+	logOptFields := events.LogOptionalFields{
+		events.NodeID:        nodeName,
+		events.WorkFlowID:    wfName,
+		events.InterfaceName: stringutil.GetAllExceptLastElement(operation.Name, "."),
+		events.OperationName: stringutil.GetLastElement(operation.Name, "."),
+	}
+
+	location, locationCfg, err := getNodeLocationConfig(kv, cfg, deploymentID, nodeName)
+	if err != nil {
+		return err
+	}
+	client, err := GetSSHClient(location, locationCfg)
+	if err != nil {
+		events.WithOptionalFields(logOptFields).NewLogEntry(events.ERROR, deploymentID).RegisterAsString(err.Error())
+		return err
+	}
+
+	script, err := getBatchScript(kv, cfg, deploymentID, operation)
+	if err != nil {
+		return err
+	}
+	opts, err := getJobOptions(kv, locationCfg, deploymentID, nodeName)
+	if err != nil {
+		return err
+	}
+	envInputs, _, err := operations.ResolveInputs(kv, deploymentID, nodeName, taskID, operation)
+	if err != nil {
+		return err
+	}
+	interval := locationCfg.GetDuration("job_monitoring_time_interval")
+	if interval <= 0 {
+		interval = defaultJobMonitoringTimeInterval
+	}
+
+	var g errgroup.Group
+	for _, instance := range instances {
+		instanceName := instance
+		env := make(map[string]string)
+		for _, input := range envInputs {
+			if input.InstanceName == operations.GetInstanceName(nodeName, instanceName) {
+				env[input.Name] = input.Value
+			}
+		}
+		instanceLogOptFields := events.LogOptionalFields{events.InstanceID: instanceName}
+		for k, v := range logOptFields {
+			instanceLogOptFields[k] = v
+		}
+		g.Go(func() error {
+			return runJob(ctx, client, deploymentID, nodeName, instanceName, opts, script, env, interval, instanceLogOptFields)
+		})
+	}
+	if err = g.Wait(); err != nil {
+		err = errors.Wrapf(err, "Slurm batch job failed for node %q", nodeName)
+		events.WithOptionalFields(logOptFields).NewLogEntry(events.ERROR, deploymentID).RegisterAsString(err.Error())
+	}
+	return err
+}
+
+// runJob submits a batch job for a node instance and waits for its completion
+func runJob(ctx context.Context, client sshutil.Client, deploymentID, nodeName, instanceName string, opts jobOptions, script string, env map[string]string, interval time.Duration, logOptFields events.LogOptionalFields) error {
+	job, err := submitJob(client, opts, script, env)
+	if err != nil {
+		return err
+	}
+	events.WithOptionalFields(logOptFields).NewLogEntry(events.INFO, deploymentID).RegisterAsString(fmt.Sprintf("Slurm batch job %q submitted", job.jobID))
+	if err = deployments.SetInstanceAttribute(deploymentID, nodeName, instanceName, "job_id", job.jobID); err != nil {
+		return err
+	}
+
+	logOutput := func(output string) {
+		events.WithOptionalFields(logOptFields).NewLogEntry(events.INFO, deploymentID).RegisterAsString(output)
+	}
+	err = monitorJob(ctx, client, job, interval, logOutput)
+	if job.state != "" {
+		if errAttr := deployments.SetInstanceAttribute(deploymentID, nodeName, instanceName, "job_state", job.state); errAttr != nil {
+			return errAttr
+		}
+	}
+	if job.exitCode != "" {
+		if errAttr := deployments.SetInstanceAttribute(deploymentID, nodeName, instanceName, "exit_code", job.exitCode); errAttr != nil {
+			return errAttr
+		}
+	}
+	if err != nil {
+		return err
+	}
+	events.WithOptionalFields(logOptFields).NewLogEntry(events.INFO, deploymentID).RegisterAsString(fmt.Sprintf("Slurm batch job %q completed with exit code %s", job.jobID, job.exitCode))
+	return nil
+}
+
+// getBatchScript returns the content of the batch script implementing the given operation
+func getBatchScript(kv *api.KV, cfg config.Configuration, deploymentID string, operation prov.Operation) (string, error) {
+	_, primary, err := deployments.GetOperationPathAndPrimaryImplementationForNodeType(kv, deploymentID, operation.ImplementedInType, operation.Name)
+	if err != nil {
+		return "", err
+	}
+	if primary == "" {
+		return "", errors.Errorf("No batch script defined for operation %q of type %q", operation.Name, operation.ImplementedInType)
+	}
+	scriptPath := filepath.Join(cfg.WorkingDirectory, "deployments", deploymentID, "overlay", filepath.FromSlash(strings.TrimSpace(primary)))
+	script, err := ioutil.ReadFile(scriptPath)
+	if err != nil {
+		return "", errors.Wrapf(err, "Failed to read batch script %q", primary)
+	}
+	return string(script), nil
+}
+
+// getJobOptions returns the sbatch options defined by the properties of a yorc.nodes.slurm.Job node and its location
+func getJobOptions(kv *api.KV, locationCfg config.DynamicMap, deploymentID, nodeName string) (jobOptions, error) {
+	opts := jobOptions{}
+	props := []struct {
+		name  string
+		value *string
+	}{
+		{"job_name", &opts.jobName},
+		{"partition", &opts.partition},
+		{"nodes", &opts.nodes},
+		{"tasks", &opts.tasks},
+		{"cpus_per_task", &opts.cpusPerTask},
+		{"memory", &opts.memory},
+		{"time", &opts.time},
+		{"gres", &opts.gres},
+		{"working_directory", &opts.workingDirectory},
+	}
+	for _, prop := range props {
+		_, value, err := deployments.GetNodeProperty(kv, deploymentID, nodeName, prop.name)
+		if err != nil {
+			return opts, err
+		}
+		*prop.value = strings.TrimSpace(value)
+	}
+	if opts.partition == "" {
+		opts.partition = locationCfg.GetString("default_partition")
+	}
+	if opts.jobName == "" {
+		opts.jobName = locationCfg.GetString("default_job_name")
+		if opts.jobName == "" {
+			opts.jobName = deploymentID
+		}
+	}
+	return opts, nil
+}
+
+// sbatchCommand returns the sbatch command submitting the given script
+//
+// The script is sent through a here-document and the given environment variables are defined for
+// the sbatch command in order to be propagated to the job.
+func sbatchCommand(opts jobOptions, script string, env map[string]string) string {
+	var cmd []string
+	for _, name := range sortedKeys(env) {
+		cmd = append(cmd, fmt.Sprintf("%s=%s", provutil.SanitizeForShell(name), quoteForShell(env[name])))
+	}
+	cmd = append(cmd, "sbatch", "--parsable", "-J", quoteForShell(opts.jobName), "-o", quoteForShell(jobOutputPattern(opts)))
+	flags := []struct {
+		flag  string
+		value string
+	}{
+		{"-p", opts.partition},
+		{"-N", opts.nodes},
+		{"-n", opts.tasks},
+		{"-c", opts.cpusPerTask},
+		{"--mem", opts.memory},
+		{"-t", opts.time},
+		{"--gres", opts.gres},
+		{"-D", opts.workingDirectory},
+	}
+	for _, f := range flags {
+		if f.value == "" {
+			continue
+		}
+		if strings.HasPrefix(f.flag, "--") {
+			cmd = append(cmd, fmt.Sprintf("%s=%s", f.flag, quoteForShell(f.value)))
+		} else {
+			cmd = append(cmd, f.flag, quoteForShell(f.value))
+		}
+	}
+	cmd = append(cmd, "<<'"+batchScriptDelimiter+"'")
+	return fmt.Sprintf("%s\n%s\n%s", strings.Join(cmd, " "), strings.TrimRight(script, "\n"), batchScriptDelimiter)
+}
+
+// jobOutputPattern returns the sbatch output file pattern of a job
+func jobOutputPattern(opts jobOptions) string {
+	return "slurm-" + provutil.SanitizeForShell(opts.jobName) + "-%j.out"
+}
+
+// submitJob submits a batch job
+func submitJob(client sshutil.Client, opts jobOptions, script string, env map[string]string) (*jobInfo, error) {
+	output, err := client.RunCommand(sbatchCommand(opts, script, env))
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to submit Slurm batch job: %s", strings.TrimSpace(output))
+	}
+	jobID, err := parseSbatchResponse(output)
+	if err != nil {
+		return nil, err
+	}
+	outputFile := strings.Replace(jobOutputPattern(opts), "%j", jobID, -1)
+	if opts.workingDirectory != "" {
+		outputFile = path.Join(opts.workingDirectory, outputFile)
+	}
+	return &jobInfo{jobID: jobID, outputFile: outputFile}, nil
+}
+
+// parseSbatchResponse parses the output of a "sbatch --parsable" command
+// Below are classic examples:
+// 1881
+// 1881;cluster
+func parseSbatchResponse(output string) (string, error) {
+	for _, line := range strings.Split(output, "\n") {
+		if m := reSbatchJobID.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			return m[1], nil
+		}
+	}
+	return "", errors.Errorf("Unable to parse sbatch output %q for retrieving jobID", strings.TrimSpace(output))
+}
+
+// monitorJob waits for a batch job completion.
+//
+// The job output is streamed using the given logOutput function. The job is cancelled if the given
+// context is cancelled. An error is returned if the job did not complete successfully.
+//
+// Failures to check the job state (SSH connection issues for instance) are retried at the next interval. After
+// maxJobMonitoringFailures consecutive failures the job is cancelled and an error is returned.
+func monitorJob(ctx context.Context, client sshutil.Client, job *jobInfo, interval time.Duration, logOutput func(string)) error {
+	var offset, failures int
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Debugf("Cancellation message has been sent: the Slurm batch job %q has to be cancelled", job.jobID)
+			cancelJob(client, job)
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		running, err := isJobInQueue(client, job.jobID)
+		if err == nil {
+			offset = streamJobOutput(client, job, offset, logOutput)
+			if running {
+				failures = 0
+				continue
+			}
+			job.state, job.exitCode, err = getJobExitStatus(client, job.jobID)
+		}
+		if err != nil {
+			failures++
+			if failures < maxJobMonitoringFailures {
+				log.Printf("[Warning] failed to check Slurm batch job %q state (attempt %d/%d): %v", job.jobID, failures, maxJobMonitoringFailures, err)
+				continue
+			}
+			cancelJob(client, job)
+			return errors.Wrapf(err, "Giving up monitoring Slurm batch job %q after %d consecutive failures, job cancelled", job.jobID, failures)
+		}
+		if job.state != "COMPLETED" {
+			return errors.Errorf("Slurm batch job %q ended with state %q and exit code %s", job.jobID, job.state, job.exitCode)
+		}
+		return nil
+	}
+}
+
+// cancelJob cancels a batch job, a failure to cancel it is only logged
+func cancelJob(client sshutil.Client, job *jobInfo) {
+	if err := cancelJobID(job.jobID, client); err != nil {
+		log.Printf("[Warning] an error occurred during cancelling jobID:%q: %v", job.jobID, err)
+	}
+	job.state = "CANCELLED"
+}
+
+// isJobInQueue checks if a job is still pending or running
+func isJobInQueue(client sshutil.Client, jobID string) (bool, error) {
+	output, err := client.RunCommand(fmt.Sprintf("squeue -j %s --noheader -o %%T", jobID))
+	if err != nil {
+		if strings.Contains(output, "Invalid job id") {
+			// Completed jobs are removed from the queue after a while
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "Failed to retrieve Slurm batch job %q state: %s", jobID, strings.TrimSpace(output))
+	}
+	state := strings.TrimSpace(output)
+	return state != "" && state != "COMPLETED" && state != "COMPLETING" && state != "FAILED" && state != "CANCELLED" && state != "TIMEOUT", nil
+}
+
+// streamJobOutput logs the job output written since the given offset and returns the new offset
+func streamJobOutput(client sshutil.Client, job *jobInfo, offset int, logOutput func(string)) int {
+	output, err := client.RunCommand(fmt.Sprintf("tail -c +%d %s", offset+1, quoteForShell(job.outputFile)))
+	if err != nil {
+		// The output file may not exist yet if the job is pending
+		log.Debugf("Failed to read Slurm batch job %q output: %v", job.jobID, err)
+		return offset
+	}
+	if output != "" {
+		logOutput(output)
+	}
+	return offset + len(output)
+}
+
+// getJobExitStatus returns the final state and exit code of a job using the Slurm accounting
+func getJobExitStatus(client sshutil.Client, jobID string) (string, string, error) {
+	output, err := client.RunCommand(fmt.Sprintf("sacct -j %s -X -n -P -o State,ExitCode", jobID))
+	if err != nil {
+		return "", "", errors.Wrapf(err, "Failed to retrieve Slurm batch job %q exit status: %s", jobID, strings.TrimSpace(output))
+	}
+	return parseSacctResponse(output)
+}
+
+// parseSacctResponse parses the output of a "sacct -X -n -P -o State,ExitCode" command
+// Below are classic examples:
+// COMPLETED|0:0
+// CANCELLED by 1000|0:15
+func parseSacctResponse(output string) (string, string, error) {
+	line := strings.TrimSpace(strings.Split(strings.TrimSpace(output), "\n")[0])
+	fields := strings.Split(line, "|")
+	if len(fields) != 2 {
+		return "", "", errors.Errorf("Unable to parse sacct output %q for retrieving job exit status", line)
+	}
+	state := strings.Fields(fields[0])
+	if len(state) == 0 {
+		return "", "", errors.Errorf("Unable to parse sacct output %q for retrieving job state", line)
+	}
+	// Exit code is given as <exit code>:<signal>
+	exitCode := strings.Split(fields[1], ":")[0]
+	if _, err := strconv.Atoi(exitCode); err != nil {
+		return "", "", errors.Errorf("Unable to parse sacct output %q for retrieving job exit code", line)
+	}
+	return state[0], exitCode, nil
+}
+
+// quoteForShell quotes a string to be used as a single shell word
+func quoteForShell(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
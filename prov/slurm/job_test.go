@@ -0,0 +1,184 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slurm
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSbatchCommand(t *testing.T) {
+	t.Parallel()
+	opts := jobOptions{jobName: "my-job", partition: "debug", tasks: "4", memory: "4G", workingDirectory: "/home/user/jobs"}
+	cmd := sbatchCommand(opts, "#!/bin/bash\necho 'hello'\n", map[string]string{"MSG": "it's me", "my-input": "1"})
+	expected := `MSG='it'\''s me' my_input='1' sbatch --parsable -J 'my-job' -o 'slurm-my_job-%j.out' -p 'debug' -n '4' --mem='4G' -D '/home/user/jobs' <<'YORC_SLURM_BATCH_SCRIPT_EOF'
+#!/bin/bash
+echo 'hello'
+YORC_SLURM_BATCH_SCRIPT_EOF`
+	require.Equal(t, expected, cmd)
+}
+
+func TestParseSbatchResponse(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		output  string
+		want    string
+		wantErr bool
+	}{
+		{"JobID", "1881\n", "1881", false},
+		{"JobIDWithCluster", "1882;cluster\n", "1882", false},
+		{"WithWarning", "sbatch: warning: something\n1883\n", "1883", false},
+		{"Error", "sbatch: error: Batch job submission failed", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobID, err := parseSbatchResponse(tt.output)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, jobID)
+		})
+	}
+}
+
+func TestParseSacctResponse(t *testing.T) {
+	t.Parallel()
+	state, exitCode, err := parseSacctResponse("COMPLETED|0:0\n")
+	require.NoError(t, err)
+	require.Equal(t, "COMPLETED", state)
+	require.Equal(t, "0", exitCode)
+
+	state, exitCode, err = parseSacctResponse("CANCELLED by 1000|0:15\n")
+	require.NoError(t, err)
+	require.Equal(t, "CANCELLED", state)
+	require.Equal(t, "0", exitCode)
+
+	_, _, err = parseSacctResponse("")
+	require.Error(t, err)
+}
+
+// mockJobClient simulates a job running for a given number of squeue calls
+func mockJobClient(runningChecks int, finalStatus string, commands *[]string) *MockSSHClient {
+	outputs := []string{"line1\n", "line2\n"}
+	return &MockSSHClient{
+		MockRunCommand: func(cmd string) (string, error) {
+			*commands = append(*commands, cmd)
+			switch {
+			case strings.HasPrefix(cmd, "squeue"):
+				if runningChecks > 0 {
+					runningChecks--
+					return "RUNNING\n", nil
+				}
+				return "slurm_load_jobs error: Invalid job id specified\n", errors.New("exit status 1")
+			case strings.HasPrefix(cmd, "tail"):
+				if len(outputs) == 0 {
+					return "", nil
+				}
+				out := outputs[0]
+				outputs = outputs[1:]
+				return out, nil
+			case strings.HasPrefix(cmd, "sacct"):
+				return finalStatus, nil
+			}
+			return "", nil
+		},
+	}
+}
+
+func TestMonitorJob(t *testing.T) {
+	t.Parallel()
+	var commands []string
+	client := mockJobClient(1, "COMPLETED|0:0\n", &commands)
+	job := &jobInfo{jobID: "1234", outputFile: "slurm-job-1234.out"}
+	var logs []string
+	err := monitorJob(context.Background(), client, job, time.Millisecond, func(s string) { logs = append(logs, s) })
+	require.NoError(t, err)
+	require.Equal(t, "COMPLETED", job.state)
+	require.Equal(t, "0", job.exitCode)
+	require.Equal(t, []string{"line1\n", "line2\n"}, logs)
+	require.Contains(t, commands, "tail -c +7 'slurm-job-1234.out'")
+}
+
+func TestMonitorJobFailure(t *testing.T) {
+	t.Parallel()
+	var commands []string
+	client := mockJobClient(0, "FAILED|2:0\n", &commands)
+	job := &jobInfo{jobID: "1234", outputFile: "slurm-job-1234.out"}
+	err := monitorJob(context.Background(), client, job, time.Millisecond, func(s string) {})
+	require.Error(t, err)
+	require.Equal(t, "FAILED", job.state)
+	require.Equal(t, "2", job.exitCode)
+}
+
+func TestMonitorJobCancellation(t *testing.T) {
+	t.Parallel()
+	var commands []string
+	client := mockJobClient(0, "COMPLETED|0:0\n", &commands)
+	job := &jobInfo{jobID: "1234", outputFile: "slurm-job-1234.out"}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := monitorJob(ctx, client, job, time.Hour, func(s string) {})
+	require.Error(t, err)
+	require.Equal(t, "CANCELLED", job.state)
+	require.Equal(t, []string{"scancel 1234"}, commands)
+}
+
+func TestMonitorJobTransientFailures(t *testing.T) {
+	t.Parallel()
+	var commands []string
+	client := mockJobClient(1, "COMPLETED|0:0\n", &commands)
+	mockRunCommand := client.MockRunCommand
+	failures := maxJobMonitoringFailures - 1
+	client.MockRunCommand = func(cmd string) (string, error) {
+		if strings.HasPrefix(cmd, "squeue") && failures > 0 {
+			failures--
+			return "", errors.New("connection reset by peer")
+		}
+		return mockRunCommand(cmd)
+	}
+	job := &jobInfo{jobID: "1234", outputFile: "slurm-job-1234.out"}
+	err := monitorJob(context.Background(), client, job, time.Millisecond, func(s string) {})
+	require.NoError(t, err)
+	require.Equal(t, "COMPLETED", job.state)
+	require.NotContains(t, commands, "scancel 1234")
+}
+
+func TestMonitorJobPersistentFailures(t *testing.T) {
+	t.Parallel()
+	var commands []string
+	client := &MockSSHClient{
+		MockRunCommand: func(cmd string) (string, error) {
+			commands = append(commands, cmd)
+			if strings.HasPrefix(cmd, "squeue") {
+				return "", errors.New("connection reset by peer")
+			}
+			return "", nil
+		},
+	}
+	job := &jobInfo{jobID: "1234", outputFile: "slurm-job-1234.out"}
+	err := monitorJob(context.Background(), client, job, time.Millisecond, func(s string) {})
+	require.Error(t, err)
+	require.Equal(t, "CANCELLED", job.state)
+	require.Equal(t, "scancel 1234", commands[len(commands)-1])
+	require.Len(t, commands, maxJobMonitoringFailures+1)
+}
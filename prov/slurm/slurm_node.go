@@ -32,6 +32,10 @@ func (g *slurmGenerator) generateNodeAllocation(ctx context.Context, kv *api.KV,
 	if nodeType != "yorc.nodes.slurm.Compute" {
 		return errors.Errorf("Unsupported node type for %q: %s", nodeName, nodeType)
 	}
+	_, locationCfg, err := getNodeLocationConfig(kv, cfg, deploymentID, nodeName)
+	if err != nil {
+		return err
+	}
 	node := &nodeAllocation{instanceName: instanceName}
 
 	// Set the node CPU and memory property from Tosca Compute 'host' capability property
@@ -59,7 +63,7 @@ func (g *slurmGenerator) generateNodeAllocation(ctx context.Context, kv *api.KV,
 	}
 	if !found || jobName == "" {
 		// Second: with the config
-		jobName = cfg.Infrastructures[infrastructureName].GetString("default_job_name")
+		jobName = locationCfg.GetString("default_job_name")
 		if jobName == "" {
 			// Third: with the deploymentID
 			jobName = deploymentID
@@ -79,6 +83,10 @@ func (g *slurmGenerator) generateNodeAllocation(ctx context.Context, kv *api.KV,
 	if err != nil {
 		return err
 	}
+	if partition == "" {
+		// Use the location default partition if any
+		partition = locationCfg.GetString("default_partition")
+	}
 	node.partition = partition
 	infra.nodes = append(infra.nodes, node)
 	return nil
@@ -73,13 +73,17 @@ func (g *awsGenerator) generateAWSInstance(ctx context.Context, kv *api.KV, cfg
 	}
 	instance.KeyName = keyName
 
-	// security_groups needs to contain a least one occurrence
+	// security_groups needs to contain a least one occurrence unless a security group is generated from endpoints
+	generateSecGroup, err := getBoolNodeProperty(kv, deploymentID, nodeName, "generate_security_group", false)
+	if err != nil {
+		return err
+	}
 	var secGroups string
 	if _, secGroups, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "security_groups"); err != nil {
 		return err
-	} else if secGroups == "" {
+	} else if secGroups == "" && !generateSecGroup {
 		return errors.Errorf("Missing mandatory parameter 'security_groups' node type for %s", nodeName)
-	} else {
+	} else if secGroups != "" {
 		for _, secGroup := range strings.Split(strings.NewReplacer("\"", "", "'", "").Replace(secGroups), ",") {
 			secGroup = strings.TrimSpace(secGroup)
 			instance.SecurityGroups = append(instance.SecurityGroups, secGroup)
@@ -134,12 +138,41 @@ func (g *awsGenerator) generateAWSInstance(ctx context.Context, kv *api.KV, cfg
 	}
 	instance.PlacementGroup = placementGroup
 
+	// Check if the compute should be created in a VPC subnet
+	subnetID, vpcID, err := getSubnet(ctx, kv, deploymentID, nodeName)
+	if err != nil {
+		return err
+	}
+	if subnetID != "" {
+		instance.SubnetID = subnetID
+		// Within a VPC security groups are referenced by ids
+		instance.VPCSecurityGroupIDs = instance.SecurityGroups
+		instance.SecurityGroups = nil
+	}
+
+	if generateSecGroup {
+		secGroupName := instance.Tags.Name + "-sg"
+		if err = generateSecurityGroup(kv, deploymentID, nodeName, secGroupName, vpcID, infrastructure); err != nil {
+			return err
+		}
+		if subnetID != "" {
+			instance.VPCSecurityGroupIDs = append(instance.VPCSecurityGroupIDs, fmt.Sprintf("${aws_security_group.%s.id}", secGroupName))
+		} else {
+			instance.SecurityGroups = append(instance.SecurityGroups, fmt.Sprintf("${aws_security_group.%s.name}", secGroupName))
+		}
+	}
+
 	// Add the AWS instance
 	commons.AddResource(infrastructure, "aws_instance", instance.Tags.Name, &instance)
 
 	// Provide Consul Keys
 	consulKeys := commons.ConsulKeys{Keys: []commons.ConsulKey{}}
 
+	// Attach EBS volumes
+	if err = attachEBSVolumes(ctx, kv, deploymentID, nodeName, instanceName, &instance, infrastructure, &consulKeys); err != nil {
+		return err
+	}
+
 	//Private IP Address
 	consulKeyPrivateAddr := commons.ConsulKey{Path: path.Join(instancesKey, instanceName, "/attributes/private_address"), Value: fmt.Sprintf("${aws_instance.%s.private_ip}", instance.Tags.Name)}
 
@@ -250,7 +283,7 @@ func associateEIP(infrastructure *commons.Infrastructure, instance *ComputeInsta
 		// Add the EIP
 		log.Printf("Adding ElasticIP for instance name:%s", instance.Tags.Name)
 		elasticIPName := "EIP-" + instance.Tags.Name
-		elasticIP := ElasticIP{}
+		elasticIP := ElasticIP{VPC: instance.SubnetID != ""}
 		commons.AddResource(infrastructure, "aws_eip", elasticIPName, &elasticIP)
 
 		eipAssociation.AllocationID = fmt.Sprintf("${aws_eip.%s.id}", elasticIPName)
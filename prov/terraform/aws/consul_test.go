@@ -61,6 +61,24 @@ func TestRunConsulAWSPackageTests(t *testing.T) {
 		t.Run("simpleAWSInstanceWithMalformedEIP", func(t *testing.T) {
 			testSimpleAWSInstanceWithMalformedEIP(t, kv, cfg)
 		})
+		t.Run("simpleAWSInstanceWithEBSVolume", func(t *testing.T) {
+			testSimpleAWSInstanceWithEBSVolume(t, kv, srv, cfg)
+		})
+		t.Run("simpleAWSInstanceInSubnetWithSecurityGroup", func(t *testing.T) {
+			testSimpleAWSInstanceInSubnetWithSecurityGroup(t, kv, srv, cfg)
+		})
+		t.Run("simpleEBSVolume", func(t *testing.T) {
+			testSimpleEBSVolume(t, kv, cfg)
+		})
+		t.Run("ebsVolumeWithVolumeID", func(t *testing.T) {
+			testEBSVolumeWithVolumeID(t, kv, cfg)
+		})
+		t.Run("simpleVPC", func(t *testing.T) {
+			testSimpleVPC(t, kv, cfg)
+		})
+		t.Run("subnetWithVPC", func(t *testing.T) {
+			testSubnetWithVPC(t, kv, srv, cfg)
+		})
 
 	})
 }
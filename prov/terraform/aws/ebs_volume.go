@@ -0,0 +1,200 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/helper/mathutil"
+	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/prov/terraform/commons"
+)
+
+const ebsVolumeType = "yorc.nodes.aws.EBSVolume"
+
+func (g *awsGenerator) generateEBSVolume(kv *api.KV, cfg config.Configuration, deploymentID, nodeName, instanceName string, instanceNb int, infrastructure *commons.Infrastructure) error {
+	nodeType, err := deployments.GetNodeType(kv, deploymentID, nodeName)
+	if err != nil {
+		return err
+	}
+	if nodeType != ebsVolumeType {
+		return errors.Errorf("Unsupported node type for %q: %s", nodeName, nodeType)
+	}
+	instancesKey := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "instances", nodeName)
+	name := cfg.ResourcesPrefix + nodeName + "-" + instanceName
+
+	// Check optional provided existing volumes
+	var volumeIDs string
+	if _, volumeIDs, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "volume_id"); err != nil {
+		return err
+	} else if volumeIDs != "" {
+		ids := strings.Split(strings.NewReplacer("\"", "", "'", "").Replace(volumeIDs), ",")
+		if len(ids) > instanceNb {
+			volumeID := strings.TrimSpace(ids[instanceNb])
+			log.Debugf("Reusing existing volume with id %q for instance %q of node %q", volumeID, instanceName, nodeName)
+			consulKeys := commons.ConsulKeys{Keys: []commons.ConsulKey{{Path: path.Join(instancesKey, instanceName, "/attributes/volume_id"), Value: volumeID}}}
+			commons.AddResource(infrastructure, "consul_keys", name, &consulKeys)
+			return nil
+		}
+	}
+
+	volume := EBSVolume{Tags: Tags{Name: name}}
+
+	// availability_zone is mandatory as a volume could only be attached to instances of the same availability zone
+	if _, volume.AvailabilityZone, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "availability_zone"); err != nil {
+		return err
+	} else if volume.AvailabilityZone == "" {
+		return errors.Errorf("Missing mandatory parameter 'availability_zone' node type for %s", nodeName)
+	}
+
+	if _, volume.SnapshotID, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "snapshot_id"); err != nil {
+		return err
+	}
+	var size string
+	if _, size, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "size"); err != nil {
+		return err
+	} else if size != "" {
+		if volume.Size, err = toGiBSize(size); err != nil {
+			return err
+		}
+	} else if volume.SnapshotID == "" {
+		// Size is inferred from the snapshot otherwise
+		return errors.Errorf("Missing mandatory parameter 'size' node type for %s", nodeName)
+	}
+
+	if _, volume.Type, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "volume_type"); err != nil {
+		return err
+	}
+	if _, s, err := deployments.GetNodeProperty(kv, deploymentID, nodeName, "iops"); err != nil {
+		return err
+	} else if s != "" {
+		if volume.IOPS, err = strconv.Atoi(s); err != nil {
+			return errors.Wrapf(err, "invalid 'iops' for %s", nodeName)
+		}
+	}
+	if _, s, err := deployments.GetNodeProperty(kv, deploymentID, nodeName, "encrypted"); err != nil {
+		return err
+	} else if s != "" {
+		if volume.Encrypted, err = strconv.ParseBool(s); err != nil {
+			return errors.Wrapf(err, "invalid 'encrypted' for %s", nodeName)
+		}
+	}
+	if _, volume.KMSKeyID, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "kms_key_id"); err != nil {
+		return err
+	}
+
+	commons.AddResource(infrastructure, "aws_ebs_volume", name, &volume)
+	consulKeys := commons.ConsulKeys{Keys: []commons.ConsulKey{{Path: path.Join(instancesKey, instanceName, "/attributes/volume_id"), Value: fmt.Sprintf("${aws_ebs_volume.%s.id}", name)}}}
+	commons.AddResource(infrastructure, "consul_keys", name, &consulKeys)
+	return nil
+}
+
+// toGiBSize converts a TOSCA size into a number of GiB rounded up as expected by AWS.
+//
+// Default size unit is MB.
+func toGiBSize(size string) (int, error) {
+	var bSize uint64
+	if mSize, err := strconv.Atoi(size); err == nil {
+		bSize = uint64(mSize) * humanize.MByte
+	} else if bSize, err = humanize.ParseBytes(size); err != nil {
+		return 0, errors.Errorf("Can't convert size to bytes value: %v", err)
+	}
+	gSize := float64(bSize) / humanize.GiByte
+	log.Debugf("Computed size in GiB: %f", gSize)
+	return int(mathutil.Round(gSize, 0, 0)), nil
+}
+
+// attachEBSVolumes attaches volumes targeted by the local_storage requirements of the given Compute instance
+func attachEBSVolumes(ctx context.Context, kv *api.KV, deploymentID, nodeName, instanceName string, instance *ComputeInstance, infrastructure *commons.Infrastructure, consulKeys *commons.ConsulKeys) error {
+	storageKeys, err := deployments.GetRequirementsKeysByTypeForNode(kv, deploymentID, nodeName, "local_storage")
+	if err != nil {
+		return err
+	}
+	instancesPrefix := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "instances")
+	for i, storagePrefix := range storageKeys {
+		requirementIndex := deployments.GetRequirementIndexFromRequirementKey(storagePrefix)
+		volumeNodeName, err := deployments.GetTargetNodeForRequirement(kv, deploymentID, nodeName, requirementIndex)
+		if err != nil {
+			return err
+		} else if volumeNodeName == "" {
+			continue
+		}
+		log.Debugf("Volume attachment required form Volume named %s", volumeNodeName)
+
+		_, device, err := deployments.GetRelationshipPropertyFromRequirement(kv, deploymentID, nodeName, requirementIndex, "device")
+		if err != nil {
+			return err
+		} else if device == "" {
+			// AWS recommends to use devices from /dev/sdf to /dev/sdp for EBS volumes
+			if i > 'p'-'f' {
+				return errors.Errorf("Too many volumes attached to %s, please specify the 'device' property of their relationships", nodeName)
+			}
+			device = "/dev/sd" + string(rune('f'+i))
+		}
+
+		var volumeID string
+		resultChan := make(chan string, 1)
+		go func() {
+			for {
+				// ignore errors and retry
+				found, volID, _ := deployments.GetInstanceAttribute(kv, deploymentID, volumeNodeName, instanceName, "volume_id")
+				// As volumeID is an optional property GetInstanceAttribute then GetProperty
+				// may return an empty volumeID so keep checking as long as we have it
+				if found && volID != "" {
+					resultChan <- volID
+					return
+				}
+				select {
+				case <-time.After(1 * time.Second):
+				case <-ctx.Done():
+					// context cancelled, give up!
+					return
+				}
+			}
+		}()
+		select {
+		case volumeID = <-resultChan:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		volumeAttach := VolumeAttachment{
+			DeviceName: device,
+			VolumeID:   volumeID,
+			InstanceID: fmt.Sprintf("${aws_instance.%s.id}", instance.Tags.Name),
+		}
+		attachName := "Vol" + volumeNodeName + "to" + instance.Tags.Name
+		commons.AddResource(infrastructure, "aws_volume_attachment", attachName, &volumeAttach)
+
+		consulKeys.Keys = append(consulKeys.Keys,
+			commons.ConsulKey{Path: path.Join(instancesPrefix, volumeNodeName, instanceName, "attributes/device"), Value: device},
+			commons.ConsulKey{Path: path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "relationship_instances", nodeName, requirementIndex, instanceName, "attributes/device"), Value: device},
+			commons.ConsulKey{Path: path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "relationship_instances", volumeNodeName, requirementIndex, instanceName, "attributes/device"), Value: device},
+		)
+	}
+	return nil
+}
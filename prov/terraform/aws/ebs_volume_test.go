@@ -0,0 +1,136 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+import (
+	"context"
+	"path"
+	"testing"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/prov/terraform/commons"
+)
+
+func Test_toGiBSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    string
+		want    int
+		wantErr bool
+	}{
+		{"DefaultUnitMB", "1", 1, false},
+		{"MBRoundedUp", "2000", 2, false},
+		{"GiB", "10 GiB", 10, false},
+		{"GB", "10 GB", 10, false},
+		{"TiB", "1TiB", 1024, false},
+		{"Invalid", "1 Kangaroo", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := toGiBSize(tt.size)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func getConsulKeysValues(t *testing.T, infrastructure *commons.Infrastructure, name string) map[string]string {
+	require.Contains(t, infrastructure.Resource, "consul_keys")
+	consulKeysMap := infrastructure.Resource["consul_keys"].(map[string]interface{})
+	require.Contains(t, consulKeysMap, name)
+	consulKeys, ok := consulKeysMap[name].(*commons.ConsulKeys)
+	require.True(t, ok, "%s is not a ConsulKeys", name)
+	values := make(map[string]string)
+	for _, key := range consulKeys.Keys {
+		values[key.Path] = key.Value
+	}
+	return values
+}
+
+func testSimpleEBSVolume(t *testing.T, kv *api.KV, cfg config.Configuration) {
+	t.Parallel()
+	deploymentID := loadTestYaml(t, kv)
+	g := awsGenerator{}
+	infrastructure := commons.Infrastructure{}
+
+	err := g.generateEBSVolume(kv, cfg, deploymentID, "BlockStorage", "0", 0, &infrastructure)
+	require.Nil(t, err)
+
+	require.Len(t, infrastructure.Resource["aws_ebs_volume"], 1)
+	volumes := infrastructure.Resource["aws_ebs_volume"].(map[string]interface{})
+	require.Contains(t, volumes, "BlockStorage-0")
+	volume, ok := volumes["BlockStorage-0"].(*EBSVolume)
+	require.True(t, ok, "BlockStorage-0 is not an EBSVolume")
+	require.Equal(t, "us-east-2c", volume.AvailabilityZone)
+	require.Equal(t, 12, volume.Size)
+	require.Equal(t, "io1", volume.Type)
+	require.Equal(t, 100, volume.IOPS)
+	require.True(t, volume.Encrypted)
+	require.Equal(t, "BlockStorage-0", volume.Tags.Name)
+
+	consulKeys := getConsulKeysValues(t, &infrastructure, "BlockStorage-0")
+	require.Equal(t, "${aws_ebs_volume.BlockStorage-0.id}", consulKeys[path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/instances/BlockStorage/0/attributes/volume_id")])
+}
+
+func testEBSVolumeWithVolumeID(t *testing.T, kv *api.KV, cfg config.Configuration) {
+	t.Parallel()
+	deploymentID := loadTestYaml(t, kv)
+	g := awsGenerator{}
+	infrastructure := commons.Infrastructure{}
+
+	err := g.generateEBSVolume(kv, cfg, deploymentID, "BlockStorage", "0", 0, &infrastructure)
+	require.Nil(t, err)
+	require.NotContains(t, infrastructure.Resource, "aws_ebs_volume")
+	consulKeys := getConsulKeysValues(t, &infrastructure, "BlockStorage-0")
+	require.Equal(t, "vol-0123456789", consulKeys[path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/instances/BlockStorage/0/attributes/volume_id")])
+
+	// Not enough volumes provided and no size to create a new one
+	err = g.generateEBSVolume(kv, cfg, deploymentID, "BlockStorage", "1", 1, &infrastructure)
+	require.Error(t, err, "Expecting missing mandatory parameter 'size' error")
+}
+
+func testSimpleAWSInstanceWithEBSVolume(t *testing.T, kv *api.KV, srv *testutil.TestServer, cfg config.Configuration) {
+	t.Parallel()
+	deploymentID := loadTestYaml(t, kv)
+	srv.PopulateKV(t, map[string][]byte{
+		path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/instances/BlockStorage/0/attributes/volume_id"): []byte("vol-0123456789"),
+	})
+	g := awsGenerator{}
+	infrastructure := commons.Infrastructure{}
+
+	err := g.generateAWSInstance(context.Background(), kv, cfg, deploymentID, "ComputeAWS", "0", &infrastructure, make(map[string]string))
+	require.Nil(t, err)
+
+	require.Len(t, infrastructure.Resource["aws_volume_attachment"], 1)
+	attachments := infrastructure.Resource["aws_volume_attachment"].(map[string]interface{})
+	require.Contains(t, attachments, "VolBlockStoragetoComputeAWS-0")
+	attachment, ok := attachments["VolBlockStoragetoComputeAWS-0"].(*VolumeAttachment)
+	require.True(t, ok, "VolBlockStoragetoComputeAWS-0 is not a VolumeAttachment")
+	require.Equal(t, "vol-0123456789", attachment.VolumeID)
+	require.Equal(t, "${aws_instance.ComputeAWS-0.id}", attachment.InstanceID)
+	require.Equal(t, "/dev/sdf", attachment.DeviceName)
+
+	consulKeys := getConsulKeysValues(t, &infrastructure, "ComputeAWS-0")
+	require.Equal(t, "/dev/sdf", consulKeys[path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/instances/BlockStorage/0/attributes/device")])
+}
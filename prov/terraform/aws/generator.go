@@ -126,6 +126,35 @@ func (g *awsGenerator) GenerateTerraformInfraForNode(ctx context.Context, cfg co
 			}
 		}
 
+	case ebsVolumeType, vpcType, subnetType:
+		instances, err = deployments.GetNodeInstancesIds(kv, deploymentID, nodeName)
+		if err != nil {
+			return false, nil, nil, err
+		}
+
+		for instNb, instanceName := range instances {
+			var instanceState tosca.NodeState
+			instanceState, err = deployments.GetInstanceState(kv, deploymentID, nodeName, instanceName)
+			if err != nil {
+				return false, nil, nil, err
+			}
+			if instanceState == tosca.NodeStateDeleting || instanceState == tosca.NodeStateDeleted {
+				// Do not generate something for this node instance (will be deleted if exists)
+				continue
+			}
+			switch nodeType {
+			case ebsVolumeType:
+				err = g.generateEBSVolume(kv, cfg, deploymentID, nodeName, instanceName, instNb, &infrastructure)
+			case vpcType:
+				err = g.generateVPC(kv, cfg, deploymentID, nodeName, instanceName, &infrastructure)
+			case subnetType:
+				err = g.generateSubnet(ctx, kv, cfg, deploymentID, nodeName, instanceName, &infrastructure)
+			}
+			if err != nil {
+				return false, nil, nil, err
+			}
+		}
+
 	case "yorc.nodes.aws.PublicNetwork":
 		// Nothing to do
 	default:
@@ -0,0 +1,225 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/prov/terraform/commons"
+)
+
+const (
+	vpcType    = "yorc.nodes.aws.VPC"
+	subnetType = "yorc.nodes.aws.Subnet"
+)
+
+func (g *awsGenerator) generateVPC(kv *api.KV, cfg config.Configuration, deploymentID, nodeName, instanceName string, infrastructure *commons.Infrastructure) error {
+	nodeType, err := deployments.GetNodeType(kv, deploymentID, nodeName)
+	if err != nil {
+		return err
+	}
+	if nodeType != vpcType {
+		return errors.Errorf("Unsupported node type for %q: %s", nodeName, nodeType)
+	}
+	instancesKey := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "instances", nodeName)
+	name := cfg.ResourcesPrefix + nodeName + "-" + instanceName
+
+	var vpcID string
+	if _, vpcID, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "network_id"); err != nil {
+		return err
+	} else if vpcID != "" {
+		log.Debugf("Reusing existing VPC with id %q for node %q", vpcID, nodeName)
+		consulKeys := commons.ConsulKeys{Keys: []commons.ConsulKey{{Path: path.Join(instancesKey, instanceName, "/attributes/vpc_id"), Value: vpcID}}}
+		commons.AddResource(infrastructure, "consul_keys", name, &consulKeys)
+		return nil
+	}
+
+	vpc := VPC{Tags: Tags{Name: name}}
+	if _, vpc.CidrBlock, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "cidr"); err != nil {
+		return err
+	} else if vpc.CidrBlock == "" {
+		return errors.Errorf("Missing mandatory parameter 'cidr' node type for %s", nodeName)
+	}
+	if _, vpc.InstanceTenancy, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "instance_tenancy"); err != nil {
+		return err
+	}
+	if vpc.EnableDNSSupport, err = getBoolNodeProperty(kv, deploymentID, nodeName, "enable_dns_support", true); err != nil {
+		return err
+	}
+	if vpc.EnableDNSHostnames, err = getBoolNodeProperty(kv, deploymentID, nodeName, "enable_dns_hostnames", false); err != nil {
+		return err
+	}
+	commons.AddResource(infrastructure, "aws_vpc", name, &vpc)
+
+	internetGateway, err := getBoolNodeProperty(kv, deploymentID, nodeName, "internet_gateway", true)
+	if err != nil {
+		return err
+	}
+	if internetGateway {
+		// Route the Internet traffic of the VPC subnets through an Internet Gateway
+		igw := InternetGateway{VPCID: fmt.Sprintf("${aws_vpc.%s.id}", name), Tags: Tags{Name: name}}
+		commons.AddResource(infrastructure, "aws_internet_gateway", name, &igw)
+		route := Route{
+			RouteTableID:         fmt.Sprintf("${aws_vpc.%s.main_route_table_id}", name),
+			DestinationCidrBlock: "0.0.0.0/0",
+			GatewayID:            fmt.Sprintf("${aws_internet_gateway.%s.id}", name),
+		}
+		commons.AddResource(infrastructure, "aws_route", name, &route)
+	}
+
+	consulKeys := commons.ConsulKeys{Keys: []commons.ConsulKey{{Path: path.Join(instancesKey, instanceName, "/attributes/vpc_id"), Value: fmt.Sprintf("${aws_vpc.%s.id}", name)}}}
+	commons.AddResource(infrastructure, "consul_keys", name, &consulKeys)
+	return nil
+}
+
+func (g *awsGenerator) generateSubnet(ctx context.Context, kv *api.KV, cfg config.Configuration, deploymentID, nodeName, instanceName string, infrastructure *commons.Infrastructure) error {
+	nodeType, err := deployments.GetNodeType(kv, deploymentID, nodeName)
+	if err != nil {
+		return err
+	}
+	if nodeType != subnetType {
+		return errors.Errorf("Unsupported node type for %q: %s", nodeName, nodeType)
+	}
+	instancesKey := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "instances", nodeName)
+	name := cfg.ResourcesPrefix + nodeName + "-" + instanceName
+
+	// The VPC is either provided as an existing one or as a requirement on a VPC node
+	var vpcID string
+	if _, vpcID, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "vpc_id"); err != nil {
+		return err
+	} else if vpcID == "" {
+		var vpcNodeName string
+		vpcNodeName, err = deployments.GetTargetNodeForRequirementByName(kv, deploymentID, nodeName, "vpc")
+		if err != nil {
+			return err
+		} else if vpcNodeName == "" {
+			return errors.Errorf("Either a 'vpc_id' property or a 'vpc' requirement should be provided for %s", nodeName)
+		}
+		log.Debugf("Looking for VPC id of %q", vpcNodeName)
+		if vpcID, err = waitForNodeAttribute(ctx, kv, deploymentID, vpcNodeName, "vpc_id"); err != nil {
+			return err
+		}
+	}
+
+	var subnetID string
+	if _, subnetID, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "network_id"); err != nil {
+		return err
+	} else if subnetID != "" {
+		log.Debugf("Reusing existing subnet with id %q for node %q", subnetID, nodeName)
+		consulKeys := commons.ConsulKeys{Keys: []commons.ConsulKey{
+			{Path: path.Join(instancesKey, instanceName, "/attributes/subnet_id"), Value: subnetID},
+			{Path: path.Join(instancesKey, instanceName, "/attributes/vpc_id"), Value: vpcID},
+		}}
+		commons.AddResource(infrastructure, "consul_keys", name, &consulKeys)
+		return nil
+	}
+
+	subnet := Subnet{VPCID: vpcID, Tags: Tags{Name: name}}
+	if _, subnet.CidrBlock, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "cidr"); err != nil {
+		return err
+	} else if subnet.CidrBlock == "" {
+		return errors.Errorf("Missing mandatory parameter 'cidr' node type for %s", nodeName)
+	}
+	if _, subnet.AvailabilityZone, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "availability_zone"); err != nil {
+		return err
+	}
+	if subnet.MapPublicIPOnLaunch, err = getBoolNodeProperty(kv, deploymentID, nodeName, "map_public_ip_on_launch", false); err != nil {
+		return err
+	}
+	commons.AddResource(infrastructure, "aws_subnet", name, &subnet)
+
+	consulKeys := commons.ConsulKeys{Keys: []commons.ConsulKey{
+		{Path: path.Join(instancesKey, instanceName, "/attributes/subnet_id"), Value: fmt.Sprintf("${aws_subnet.%s.id}", name)},
+		{Path: path.Join(instancesKey, instanceName, "/attributes/vpc_id"), Value: vpcID},
+	}}
+	commons.AddResource(infrastructure, "consul_keys", name, &consulKeys)
+	return nil
+}
+
+// getSubnet returns the ids of the subnet and VPC in which the given Compute should be created if it has a network requirement on a Subnet node
+func getSubnet(ctx context.Context, kv *api.KV, deploymentID, nodeName string) (string, string, error) {
+	networkKeys, err := deployments.GetRequirementsKeysByTypeForNode(kv, deploymentID, nodeName, "network")
+	if err != nil {
+		return "", "", err
+	}
+	for _, networkReqPrefix := range networkKeys {
+		requirementIndex := deployments.GetRequirementIndexFromRequirementKey(networkReqPrefix)
+		networkNodeName, err := deployments.GetTargetNodeForRequirement(kv, deploymentID, nodeName, requirementIndex)
+		if err != nil {
+			return "", "", err
+		}
+		isSubnet, err := deployments.IsNodeDerivedFrom(kv, deploymentID, networkNodeName, subnetType)
+		if err != nil {
+			return "", "", err
+		} else if !isSubnet {
+			continue
+		}
+		log.Debugf("Looking for subnet id of %q", networkNodeName)
+		subnetID, err := waitForNodeAttribute(ctx, kv, deploymentID, networkNodeName, "subnet_id")
+		if err != nil {
+			return "", "", err
+		}
+		vpcID, err := waitForNodeAttribute(ctx, kv, deploymentID, networkNodeName, "vpc_id")
+		return subnetID, vpcID, err
+	}
+	return "", "", nil
+}
+
+// waitForNodeAttribute waits until the given attribute of the first instance of a node is set and returns its value.
+//
+// This is useful to wait for resources generated by another node of the topology like networks which are not scalable.
+func waitForNodeAttribute(ctx context.Context, kv *api.KV, deploymentID, nodeName, attributeName string) (string, error) {
+	for {
+		instances, err := deployments.GetNodeInstancesIds(kv, deploymentID, nodeName)
+		if err != nil {
+			log.Printf("[Warning] bypassing error while waiting for attribute %q of node %q: %v", attributeName, nodeName, err)
+		} else if len(instances) > 0 {
+			found, value, err := deployments.GetInstanceAttribute(kv, deploymentID, nodeName, instances[0], attributeName)
+			if err != nil {
+				log.Printf("[Warning] bypassing error while waiting for attribute %q of node %q: %v", attributeName, nodeName, err)
+			}
+			// As attributes may fallback to optional properties keep checking as long as we have an empty value
+			if found && value != "" {
+				return value, nil
+			}
+		}
+		select {
+		case <-time.After(1 * time.Second):
+		case <-ctx.Done():
+			// context cancelled, give up!
+			return "", ctx.Err()
+		}
+	}
+}
+
+func getBoolNodeProperty(kv *api.KV, deploymentID, nodeName, propertyName string, defaultValue bool) (bool, error) {
+	_, s, err := deployments.GetNodeProperty(kv, deploymentID, nodeName, propertyName)
+	if err != nil || s == "" {
+		return defaultValue, err
+	}
+	b, err := strconv.ParseBool(s)
+	return b, errors.Wrapf(err, "invalid %q property for %s", propertyName, nodeName)
+}
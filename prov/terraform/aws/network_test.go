@@ -0,0 +1,87 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+import (
+	"context"
+	"path"
+	"testing"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/prov/terraform/commons"
+)
+
+func testSimpleVPC(t *testing.T, kv *api.KV, cfg config.Configuration) {
+	t.Parallel()
+	deploymentID := loadTestYaml(t, kv)
+	g := awsGenerator{}
+	infrastructure := commons.Infrastructure{}
+
+	err := g.generateVPC(kv, cfg, deploymentID, "VPC", "0", &infrastructure)
+	require.Nil(t, err)
+
+	require.Len(t, infrastructure.Resource["aws_vpc"], 1)
+	vpcs := infrastructure.Resource["aws_vpc"].(map[string]interface{})
+	require.Contains(t, vpcs, "VPC-0")
+	vpc, ok := vpcs["VPC-0"].(*VPC)
+	require.True(t, ok, "VPC-0 is not a VPC")
+	require.Equal(t, "10.0.0.0/16", vpc.CidrBlock)
+	require.True(t, vpc.EnableDNSSupport)
+	require.True(t, vpc.EnableDNSHostnames)
+
+	require.Len(t, infrastructure.Resource["aws_internet_gateway"], 1)
+	require.Len(t, infrastructure.Resource["aws_route"], 1)
+	routes := infrastructure.Resource["aws_route"].(map[string]interface{})
+	route, ok := routes["VPC-0"].(*Route)
+	require.True(t, ok, "VPC-0 is not a Route")
+	require.Equal(t, "${aws_vpc.VPC-0.main_route_table_id}", route.RouteTableID)
+	require.Equal(t, "${aws_internet_gateway.VPC-0.id}", route.GatewayID)
+
+	consulKeys := getConsulKeysValues(t, &infrastructure, "VPC-0")
+	require.Equal(t, "${aws_vpc.VPC-0.id}", consulKeys[path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/instances/VPC/0/attributes/vpc_id")])
+}
+
+func testSubnetWithVPC(t *testing.T, kv *api.KV, srv *testutil.TestServer, cfg config.Configuration) {
+	t.Parallel()
+	deploymentID := loadTestYaml(t, kv)
+	srv.PopulateKV(t, map[string][]byte{
+		path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/instances/VPC/0/attributes/vpc_id"): []byte("vpc-0123456789"),
+	})
+	g := awsGenerator{}
+	infrastructure := commons.Infrastructure{}
+
+	err := g.generateSubnet(context.Background(), kv, cfg, deploymentID, "Subnet", "0", &infrastructure)
+	require.Nil(t, err)
+
+	require.Len(t, infrastructure.Resource["aws_subnet"], 1)
+	subnets := infrastructure.Resource["aws_subnet"].(map[string]interface{})
+	require.Contains(t, subnets, "Subnet-0")
+	subnet, ok := subnets["Subnet-0"].(*Subnet)
+	require.True(t, ok, "Subnet-0 is not a Subnet")
+	require.Equal(t, "vpc-0123456789", subnet.VPCID)
+	require.Equal(t, "10.0.1.0/24", subnet.CidrBlock)
+	require.Equal(t, "us-east-2c", subnet.AvailabilityZone)
+	require.True(t, subnet.MapPublicIPOnLaunch)
+
+	consulKeys := getConsulKeysValues(t, &infrastructure, "Subnet-0")
+	instanceKey := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/instances/Subnet/0")
+	require.Equal(t, "${aws_subnet.Subnet-0.id}", consulKeys[path.Join(instanceKey, "attributes/subnet_id")])
+	require.Equal(t, "vpc-0123456789", consulKeys[path.Join(instanceKey, "attributes/vpc_id")])
+}
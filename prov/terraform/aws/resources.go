@@ -16,15 +16,18 @@ package aws
 
 // A ComputeInstance represent an AWS compute
 type ComputeInstance struct {
-	ImageID          string      `json:"ami,omitempty"`
-	InstanceType     string      `json:"instance_type,omitempty"`
-	AvailabilityZone string      `json:"availability_zone,omitempty"`
-	PlacementGroup   string      `json:"placement_group,omitempty"`
-	SecurityGroups   []string    `json:"security_groups,omitempty"`
-	KeyName          string      `json:"key_name,omitempty"`
-	Tags             Tags        `json:"tags,omitempty"`
-	ElasticIps       []string    `json:"-"`
-	RootBlockDevice  BlockDevice `json:"root_block_device,omitempty"`
+	ImageID          string   `json:"ami,omitempty"`
+	InstanceType     string   `json:"instance_type,omitempty"`
+	AvailabilityZone string   `json:"availability_zone,omitempty"`
+	PlacementGroup   string   `json:"placement_group,omitempty"`
+	SecurityGroups   []string `json:"security_groups,omitempty"`
+	// VPCSecurityGroupIDs should be used instead of SecurityGroups for instances created in a VPC subnet
+	VPCSecurityGroupIDs []string    `json:"vpc_security_group_ids,omitempty"`
+	SubnetID            string      `json:"subnet_id,omitempty"`
+	KeyName             string      `json:"key_name,omitempty"`
+	Tags                Tags        `json:"tags,omitempty"`
+	ElasticIps          []string    `json:"-"`
+	RootBlockDevice     BlockDevice `json:"root_block_device,omitempty"`
 
 	Provisioners map[string]interface{} `json:"provisioner,omitempty"`
 }
@@ -41,6 +44,8 @@ type Tags struct {
 
 // ElasticIP represents the AWS Elastic IP resource
 type ElasticIP struct {
+	// VPC should be true for Elastic IPs associated to instances created in a VPC subnet
+	VPC bool `json:"vpc,omitempty"`
 }
 
 // ElasticIPAssociation represents the ElasticIP/ComputeInstance association
@@ -50,3 +55,73 @@ type ElasticIPAssociation struct {
 	AllocationID string `json:"allocation_id,omitempty"`
 	PublicIP     string `json:"public_ip,omitempty"`
 }
+
+// An EBSVolume represents an AWS Elastic Block Store volume
+type EBSVolume struct {
+	AvailabilityZone string `json:"availability_zone"`
+	Size             int    `json:"size,omitempty"`
+	Type             string `json:"type,omitempty"`
+	IOPS             int    `json:"iops,omitempty"`
+	Encrypted        bool   `json:"encrypted,omitempty"`
+	KMSKeyID         string `json:"kms_key_id,omitempty"`
+	SnapshotID       string `json:"snapshot_id,omitempty"`
+	Tags             Tags   `json:"tags,omitempty"`
+}
+
+// A VolumeAttachment attaches an EBSVolume to a ComputeInstance
+type VolumeAttachment struct {
+	DeviceName string `json:"device_name"`
+	VolumeID   string `json:"volume_id"`
+	InstanceID string `json:"instance_id"`
+}
+
+// A VPC represents an AWS Virtual Private Cloud
+type VPC struct {
+	CidrBlock          string `json:"cidr_block"`
+	InstanceTenancy    string `json:"instance_tenancy,omitempty"`
+	EnableDNSSupport   bool   `json:"enable_dns_support"`
+	EnableDNSHostnames bool   `json:"enable_dns_hostnames"`
+	Tags               Tags   `json:"tags,omitempty"`
+}
+
+// An InternetGateway allows communication between a VPC and the Internet
+type InternetGateway struct {
+	VPCID string `json:"vpc_id"`
+	Tags  Tags   `json:"tags,omitempty"`
+}
+
+// A Route is an entry of a VPC routing table
+type Route struct {
+	RouteTableID         string `json:"route_table_id"`
+	DestinationCidrBlock string `json:"destination_cidr_block"`
+	GatewayID            string `json:"gateway_id,omitempty"`
+}
+
+// A Subnet represents an AWS VPC subnet
+type Subnet struct {
+	VPCID               string `json:"vpc_id"`
+	CidrBlock           string `json:"cidr_block"`
+	AvailabilityZone    string `json:"availability_zone,omitempty"`
+	MapPublicIPOnLaunch bool   `json:"map_public_ip_on_launch"`
+	Tags                Tags   `json:"tags,omitempty"`
+}
+
+// A SecurityGroup represents an AWS security group
+//
+// Its rules are defined as separated SecurityGroupRule resources.
+type SecurityGroup struct {
+	NamePrefix  string `json:"name_prefix"`
+	Description string `json:"description,omitempty"`
+	VPCID       string `json:"vpc_id,omitempty"`
+	Tags        Tags   `json:"tags,omitempty"`
+}
+
+// A SecurityGroupRule represents an ingress or egress rule of a SecurityGroup
+type SecurityGroupRule struct {
+	Type            string   `json:"type"`
+	FromPort        int      `json:"from_port"`
+	ToPort          int      `json:"to_port"`
+	Protocol        string   `json:"protocol"`
+	CidrBlocks      []string `json:"cidr_blocks"`
+	SecurityGroupID string   `json:"security_group_id"`
+}
@@ -0,0 +1,169 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/prov/terraform/commons"
+)
+
+// privateCidrBlocks are the IPv4 private address ranges (RFC 1918) allowed to access to private endpoints
+var privateCidrBlocks = []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"}
+
+// An endpointRule is an ingress rule computed from a TOSCA endpoint capability
+type endpointRule struct {
+	protocol   string
+	port       int
+	cidrBlocks []string
+}
+
+// generateSecurityGroup generates a security group allowing incoming traffic on the endpoints of the given Compute node
+// and of the nodes hosted on it.
+func generateSecurityGroup(kv *api.KV, deploymentID, nodeName, name, vpcID string, infrastructure *commons.Infrastructure) error {
+	rules, err := getEndpointsRules(kv, deploymentID, nodeName)
+	if err != nil {
+		return err
+	}
+
+	secGroup := SecurityGroup{
+		NamePrefix:  name + "-",
+		Description: fmt.Sprintf("Security group of %s generated from its endpoints", name),
+		VPCID:       vpcID,
+		Tags:        Tags{Name: name},
+	}
+	commons.AddResource(infrastructure, "aws_security_group", name, &secGroup)
+	secGroupID := fmt.Sprintf("${aws_security_group.%s.id}", name)
+
+	for i, rule := range rules {
+		ingress := SecurityGroupRule{
+			Type:            "ingress",
+			FromPort:        rule.port,
+			ToPort:          rule.port,
+			Protocol:        rule.protocol,
+			CidrBlocks:      rule.cidrBlocks,
+			SecurityGroupID: secGroupID,
+		}
+		commons.AddResource(infrastructure, "aws_security_group_rule", fmt.Sprintf("%s-ingress-%d", name, i), &ingress)
+	}
+	// Terraform removes the default egress rule of AWS security groups so allow all outgoing traffic
+	egress := SecurityGroupRule{
+		Type:            "egress",
+		Protocol:        "-1",
+		CidrBlocks:      []string{"0.0.0.0/0"},
+		SecurityGroupID: secGroupID,
+	}
+	commons.AddResource(infrastructure, "aws_security_group_rule", name+"-egress", &egress)
+	return nil
+}
+
+// getEndpointsRules computes ingress rules from the endpoint capabilities of the given Compute node and of the nodes hosted on it.
+//
+// Administration endpoints (used to connect to the Compute) and public endpoints are opened to any source while
+// other endpoints are only opened to private addresses.
+func getEndpointsRules(kv *api.KV, deploymentID, nodeName string) ([]endpointRule, error) {
+	hostedNodes, err := deployments.GetNodesHostedOn(kv, deploymentID, nodeName)
+	if err != nil {
+		return nil, err
+	}
+	// Sort hosted nodes to generate rules in a stable order
+	sort.Strings(hostedNodes)
+	nodes := append([]string{nodeName}, hostedNodes...)
+
+	rules := make([]endpointRule, 0)
+	existingRules := make(map[string]bool)
+	for _, node := range nodes {
+		nodeType, err := deployments.GetNodeType(kv, deploymentID, node)
+		if err != nil {
+			return nil, err
+		}
+		capabilities, err := deployments.GetCapabilitiesOfType(kv, deploymentID, nodeType, "tosca.capabilities.Endpoint")
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(capabilities)
+		for _, capability := range capabilities {
+			rule, ok, err := getEndpointRule(kv, deploymentID, node, capability)
+			if err != nil {
+				return nil, err
+			}
+			ruleKey := fmt.Sprintf("%s/%d/%s", rule.protocol, rule.port, strings.Join(rule.cidrBlocks, ","))
+			if !ok || existingRules[ruleKey] {
+				continue
+			}
+			existingRules[ruleKey] = true
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+func getEndpointRule(kv *api.KV, deploymentID, nodeName, capability string) (endpointRule, bool, error) {
+	rule := endpointRule{}
+	capabilityType, err := deployments.GetNodeCapabilityType(kv, deploymentID, nodeName, capability)
+	if err != nil {
+		return rule, false, err
+	}
+	isAdmin, err := deployments.IsTypeDerivedFrom(kv, deploymentID, capabilityType, "tosca.capabilities.Endpoint.Admin")
+	if err != nil {
+		return rule, false, err
+	}
+
+	_, port, err := deployments.GetCapabilityProperty(kv, deploymentID, nodeName, capability, "port")
+	if err != nil {
+		return rule, false, err
+	}
+	if port == "" {
+		if !isAdmin {
+			// Nothing to open
+			return rule, false, nil
+		}
+		// Yorc connects to Computes using SSH
+		port = "22"
+	}
+	if rule.port, err = strconv.Atoi(port); err != nil {
+		return rule, false, errors.Wrapf(err, "invalid port %q for capability %q of node %q", port, capability, nodeName)
+	}
+
+	_, protocol, err := deployments.GetCapabilityProperty(kv, deploymentID, nodeName, capability, "protocol")
+	if err != nil {
+		return rule, false, err
+	}
+	switch strings.ToLower(protocol) {
+	case "udp", "icmp":
+		rule.protocol = strings.ToLower(protocol)
+	default:
+		// Application protocols like http rely on tcp
+		rule.protocol = "tcp"
+	}
+
+	_, networkName, err := deployments.GetCapabilityProperty(kv, deploymentID, nodeName, capability, "network_name")
+	if err != nil {
+		return rule, false, err
+	}
+	if isAdmin || strings.EqualFold(networkName, "PUBLIC") {
+		rule.cidrBlocks = []string{"0.0.0.0/0"}
+	} else {
+		rule.cidrBlocks = privateCidrBlocks
+	}
+	return rule, true, nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+import (
+	"context"
+	"path"
+	"testing"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/prov/terraform/commons"
+)
+
+func testSimpleAWSInstanceInSubnetWithSecurityGroup(t *testing.T, kv *api.KV, srv *testutil.TestServer, cfg config.Configuration) {
+	t.Parallel()
+	deploymentID := loadTestYaml(t, kv)
+	srv.PopulateKV(t, map[string][]byte{
+		path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/instances/Subnet/0/attributes/subnet_id"): []byte("subnet-0123456789"),
+		path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/instances/Subnet/0/attributes/vpc_id"):    []byte("vpc-0123456789"),
+	})
+	g := awsGenerator{}
+	infrastructure := commons.Infrastructure{}
+
+	err := g.generateAWSInstance(context.Background(), kv, cfg, deploymentID, "ComputeAWS", "0", &infrastructure, make(map[string]string))
+	require.Nil(t, err)
+
+	instancesMap := infrastructure.Resource["aws_instance"].(map[string]interface{})
+	compute, ok := instancesMap["ComputeAWS-0"].(*ComputeInstance)
+	require.True(t, ok, "ComputeAWS-0 is not a ComputeInstance")
+	require.Equal(t, "subnet-0123456789", compute.SubnetID)
+	require.Len(t, compute.SecurityGroups, 0)
+	require.Equal(t, []string{"sg-0123456789", "${aws_security_group.ComputeAWS-0-sg.id}"}, compute.VPCSecurityGroupIDs)
+
+	require.Len(t, infrastructure.Resource["aws_security_group"], 1)
+	secGroups := infrastructure.Resource["aws_security_group"].(map[string]interface{})
+	secGroup, ok := secGroups["ComputeAWS-0-sg"].(*SecurityGroup)
+	require.True(t, ok, "ComputeAWS-0-sg is not a SecurityGroup")
+	require.Equal(t, "vpc-0123456789", secGroup.VPCID)
+
+	rules := infrastructure.Resource["aws_security_group_rule"].(map[string]interface{})
+	require.Len(t, rules, 4)
+	expectedRules := map[string]SecurityGroupRule{
+		"ComputeAWS-0-sg-ingress-0": {Type: "ingress", FromPort: 22, ToPort: 22, Protocol: "tcp", CidrBlocks: []string{"0.0.0.0/0"}},
+		"ComputeAWS-0-sg-ingress-1": {Type: "ingress", FromPort: 80, ToPort: 80, Protocol: "tcp", CidrBlocks: []string{"0.0.0.0/0"}},
+		"ComputeAWS-0-sg-ingress-2": {Type: "ingress", FromPort: 8080, ToPort: 8080, Protocol: "tcp", CidrBlocks: privateCidrBlocks},
+		"ComputeAWS-0-sg-egress":    {Type: "egress", Protocol: "-1", CidrBlocks: []string{"0.0.0.0/0"}},
+	}
+	for name, expected := range expectedRules {
+		require.Contains(t, rules, name)
+		rule, ok := rules[name].(*SecurityGroupRule)
+		require.True(t, ok, "%s is not a SecurityGroupRule", name)
+		expected.SecurityGroupID = "${aws_security_group.ComputeAWS-0-sg.id}"
+		require.Equal(t, expected, *rule)
+	}
+}
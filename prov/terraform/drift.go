@@ -0,0 +1,155 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terraform
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/events"
+	"github.com/ystia/yorc/helper/executil"
+	"github.com/ystia/yorc/prov"
+)
+
+// planExitCodeChanges is the exit code of 'terraform plan -detailed-exitcode' when the plan contains changes
+const planExitCodeChanges = 2
+
+// planActionRegexp matches resource changes in plans generated by terraform prior to 0.12
+// ex: "  ~ openstack_compute_instance_v2.Compute-0" or "-/+ openstack_compute_instance_v2.Compute (new resource required)"
+var planActionRegexp = regexp.MustCompile(`^\s*(-/\+|[-~+])\s+([A-Za-z0-9_\-]+\.[^\s]+)`)
+
+// planCommentRegexp matches resource changes in plans generated by terraform 0.12 and above
+// ex: "  # openstack_compute_instance_v2.Compute will be updated in-place"
+var planCommentRegexp = regexp.MustCompile(`^\s*#\s+(\S+)\s+(will be created|will be updated in-place|will be destroyed|must be replaced)`)
+
+// DetectDrift implements the prov.DriftDetector interface
+//
+// It runs 'terraform plan' on the infrastructure of the node against the terraform state stored in Consul. Any change
+// in the plan means that a resource diverged from its expected state.
+func (e *defaultExecutor) DetectDrift(ctx context.Context, cfg config.Configuration, taskID, deploymentID, nodeName string) ([]prov.ResourceDrift, error) {
+	consulClient, err := cfg.GetConsulClient()
+	if err != nil {
+		return nil, err
+	}
+	kv := consulClient.KV()
+	logOptFields := events.LogOptionalFields{
+		events.NodeID:        nodeName,
+		events.InterfaceName: "delegate",
+		events.OperationName: "drift",
+	}
+
+	infraGenerated, _, env, err := e.generator.GenerateTerraformInfraForNode(ctx, cfg, deploymentID, nodeName)
+	if err != nil || !infraGenerated {
+		return nil, err
+	}
+	// Remote Configuration for Terraform State to read it from the Consul KV store
+	if err = e.remoteConfigInfrastructure(ctx, kv, cfg, deploymentID, nodeName, env, logOptFields); err != nil {
+		return nil, err
+	}
+
+	events.WithOptionalFields(logOptFields).NewLogEntry(events.INFO, deploymentID).RegisterAsString("Checking the infrastructure for drifts")
+	infraPath := filepath.Join(cfg.WorkingDirectory, "deployments", deploymentID, "infra", nodeName)
+	cmd := executil.Command(ctx, "terraform", "plan", "-detailed-exitcode", "-input=false", "-no-color", "-lock=true")
+	cmd.Dir = infraPath
+	cmd.Env = mergeEnvironments(env)
+	out := &bytes.Buffer{}
+	errbuf := &bytes.Buffer{}
+	cmd.Stdout = out
+	cmd.Stderr = errbuf
+
+	err = cmd.Run()
+	if err == nil {
+		return nil, nil
+	}
+	if !isPlanWithChanges(err) {
+		events.WithOptionalFields(logOptFields).NewLogEntry(events.ERROR, deploymentID).RegisterAsString(errbuf.String())
+		return nil, errors.Wrap(err, "Failed to check the infrastructure for drifts via terraform")
+	}
+	return parsePlanDrifts(out.String()), nil
+}
+
+// ReconcileDrift implements the prov.DriftDetector interface
+//
+// It applies again the infrastructure of the node in order to bring back its resources to their expected state.
+func (e *defaultExecutor) ReconcileDrift(ctx context.Context, cfg config.Configuration, taskID, deploymentID, nodeName string) error {
+	consulClient, err := cfg.GetConsulClient()
+	if err != nil {
+		return err
+	}
+	kv := consulClient.KV()
+	logOptFields := events.LogOptionalFields{
+		events.NodeID:        nodeName,
+		events.InterfaceName: "delegate",
+		events.OperationName: "reconcile",
+	}
+
+	infraGenerated, outputs, env, err := e.generator.GenerateTerraformInfraForNode(ctx, cfg, deploymentID, nodeName)
+	if err != nil || !infraGenerated {
+		return err
+	}
+	return e.applyInfrastructure(ctx, kv, cfg, deploymentID, nodeName, outputs, env, logOptFields)
+}
+
+// isPlanWithChanges checks if the error returned by 'terraform plan -detailed-exitcode' means that the plan
+// contains changes rather than a failure
+func isPlanWithChanges(err error) bool {
+	if exiterr, ok := err.(*exec.ExitError); ok {
+		if status, ok := exiterr.Sys().(syscall.WaitStatus); ok {
+			return status.ExitStatus() == planExitCodeChanges
+		}
+	}
+	return false
+}
+
+// parsePlanDrifts extracts the drifted resources from the output of 'terraform plan'
+//
+// A resource that terraform plans to create was deleted outside of Yorc, a resource that it plans to update was
+// modified, a resource that it plans to replace was modified in a way that requires to replace it and a resource that
+// it plans to destroy is no more expected.
+func parsePlanDrifts(plan string) []prov.ResourceDrift {
+	drifts := make([]prov.ResourceDrift, 0)
+	scanner := bufio.NewScanner(strings.NewReader(plan))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := planActionRegexp.FindStringSubmatch(line); m != nil {
+			drifts = append(drifts, prov.ResourceDrift{Resource: m[2], Kind: driftKindFromPlanAction(m[1])})
+		} else if m := planCommentRegexp.FindStringSubmatch(line); m != nil {
+			drifts = append(drifts, prov.ResourceDrift{Resource: m[1], Kind: driftKindFromPlanAction(m[2])})
+		}
+	}
+	return drifts
+}
+
+func driftKindFromPlanAction(action string) prov.DriftKind {
+	switch action {
+	case "+", "will be created":
+		return prov.DriftDeleted
+	case "-", "will be destroyed":
+		return prov.DriftOrphaned
+	case "-/+", "must be replaced":
+		return prov.DriftReplaced
+	default:
+		return prov.DriftModified
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terraform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ystia/yorc/prov"
+)
+
+func TestParsePlanDrifts(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		plan string
+		want []prov.ResourceDrift
+	}{
+		{"NoChanges", `No changes. Infrastructure is up-to-date.`, []prov.ResourceDrift{}},
+		{"Terraform011", `
+Terraform will perform the following actions:
+
+  ~ openstack_compute_instance_v2.Compute-0
+      name:                "Compute-0" => "yorc-Compute-0"
+
+-/+ openstack_compute_floatingip_associate_v2.FIPCompute-0 (new resource required)
+      id:                  "1.2.3.4/abc" => <computed> (forces new resource)
+
+  + openstack_blockstorage_volume_v1.BS-0
+      id:                  <computed>
+
+  - openstack_networking_network_v2.Net
+
+ <= data.consul_keys.keys
+      id:                  <computed>
+
+Plan: 2 to add, 1 to change, 2 to destroy.
+`, []prov.ResourceDrift{
+			{Resource: "openstack_compute_instance_v2.Compute-0", Kind: prov.DriftModified},
+			{Resource: "openstack_compute_floatingip_associate_v2.FIPCompute-0", Kind: prov.DriftReplaced},
+			{Resource: "openstack_blockstorage_volume_v1.BS-0", Kind: prov.DriftDeleted},
+			{Resource: "openstack_networking_network_v2.Net", Kind: prov.DriftOrphaned},
+		}},
+		{"Terraform012", `
+  # aws_instance.Compute-0 will be updated in-place
+  ~ resource "aws_instance" "Compute-0" {
+      ~ instance_type = "t2.large" -> "t2.micro"
+    }
+
+  # aws_eip.EIP-0 must be replaced
+-/+ resource "aws_eip" "EIP-0" {
+    }
+
+  # aws_ebs_volume.Vol-0 will be created
+  + resource "aws_ebs_volume" "Vol-0" {
+    }
+`, []prov.ResourceDrift{
+			{Resource: "aws_instance.Compute-0", Kind: prov.DriftModified},
+			{Resource: "aws_eip.EIP-0", Kind: prov.DriftReplaced},
+			{Resource: "aws_ebs_volume.Vol-0", Kind: prov.DriftDeleted},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parsePlanDrifts(tt.plan))
+		})
+	}
+}
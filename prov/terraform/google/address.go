@@ -0,0 +1,80 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package google
+
+import (
+	"fmt"
+	"net"
+	"path"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/prov/terraform/commons"
+)
+
+func (g *googleGenerator) generateAddress(kv *api.KV, cfg config.Configuration, deploymentID, nodeName, instanceName string, instanceNb int, infrastructure *commons.Infrastructure) error {
+	nodeType, err := deployments.GetNodeType(kv, deploymentID, nodeName)
+	if err != nil {
+		return err
+	}
+	if nodeType != addressType {
+		return errors.Errorf("Unsupported node type for %q: %s", nodeName, nodeType)
+	}
+	instancesKey := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "instances", nodeName)
+	name := toGoogleName(cfg.ResourcesPrefix + nodeName + "-" + instanceName)
+
+	_, addresses, err := deployments.GetNodeProperty(kv, deploymentID, nodeName, "addresses")
+	if err != nil {
+		return err
+	}
+	if ips := splitList(addresses); len(ips) > instanceNb {
+		if net.ParseIP(ips[instanceNb]) == nil {
+			return errors.Errorf("Malformed provided IP address: %s", ips[instanceNb])
+		}
+		log.Debugf("Reusing existing address %q for instance %q of node %q", ips[instanceNb], instanceName, nodeName)
+		consulKeys := commons.ConsulKeys{Keys: []commons.ConsulKey{{Path: path.Join(instancesKey, instanceName, "/attributes/ip_address"), Value: ips[instanceNb]}}}
+		commons.AddResource(infrastructure, "consul_keys", name, &consulKeys)
+		return nil
+	}
+
+	address := Address{Name: name}
+	if _, address.Region, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "region"); err != nil {
+		return err
+	} else if address.Region == "" {
+		address.Region = cfg.Infrastructures[infrastructureName].GetString("region")
+	}
+	if _, address.AddressType, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "address_type"); err != nil {
+		return err
+	}
+	if _, address.NetworkTier, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "network_tier"); err != nil {
+		return err
+	}
+	if _, address.Subnetwork, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "subnetwork"); err != nil {
+		return err
+	}
+	if _, address.Description, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "description"); err != nil {
+		return err
+	}
+
+	commons.AddResource(infrastructure, "google_compute_address", address.Name, &address)
+	consulKeys := commons.ConsulKeys{Keys: []commons.ConsulKey{{Path: path.Join(instancesKey, instanceName, "/attributes/ip_address"), Value: fmt.Sprintf("${google_compute_address.%s.address}", address.Name)}}}
+	commons.AddResource(infrastructure, "consul_keys", address.Name, &consulKeys)
+	return nil
+}
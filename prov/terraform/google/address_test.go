@@ -0,0 +1,54 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package google
+
+import (
+	"path"
+	"testing"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/prov/terraform/commons"
+)
+
+func testAddressWithProvidedIP(t *testing.T, kv *api.KV, cfg config.Configuration) {
+	t.Parallel()
+	deploymentID := loadTestYaml(t, kv)
+	g := googleGenerator{}
+	infrastructure := commons.Infrastructure{}
+	instancesKey := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/instances/Address")
+
+	err := g.generateAddress(kv, cfg, deploymentID, "Address", "0", 0, &infrastructure)
+	require.Nil(t, err)
+	require.NotContains(t, infrastructure.Resource, "google_compute_address")
+	consulKeys := getConsulKeysValues(t, &infrastructure, "address-0")
+	require.Equal(t, "35.0.0.1", consulKeys[path.Join(instancesKey, "0/attributes/ip_address")])
+
+	// A new address is reserved for instances without provided IP
+	err = g.generateAddress(kv, cfg, deploymentID, "Address", "1", 1, &infrastructure)
+	require.Nil(t, err)
+	require.Len(t, infrastructure.Resource["google_compute_address"], 1)
+	addresses := infrastructure.Resource["google_compute_address"].(map[string]interface{})
+	require.Contains(t, addresses, "address-1")
+	address, ok := addresses["address-1"].(*Address)
+	require.True(t, ok, "address-1 is not an Address")
+	require.Equal(t, "europe-west1", address.Region)
+	require.Equal(t, "STANDARD", address.NetworkTier)
+	consulKeys = getConsulKeysValues(t, &infrastructure, "address-1")
+	require.Equal(t, "${google_compute_address.address-1.address}", consulKeys[path.Join(instancesKey, "1/attributes/ip_address")])
+}
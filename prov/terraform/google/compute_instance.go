@@ -0,0 +1,320 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package google
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/prov/terraform/commons"
+)
+
+// A firewallRule is the representation of the yorc.datatypes.google.FirewallRule TOSCA data type
+type firewallRule struct {
+	Protocol     string        `json:"protocol"`
+	Ports        []interface{} `json:"ports"`
+	SourceRanges []string      `json:"source_ranges"`
+}
+
+func (g *googleGenerator) generateComputeInstance(ctx context.Context, kv *api.KV, cfg config.Configuration, deploymentID, nodeName, instanceName string, infrastructure *commons.Infrastructure, outputs map[string]string) error {
+	nodeType, err := deployments.GetNodeType(kv, deploymentID, nodeName)
+	if err != nil {
+		return err
+	}
+	if nodeType != computeType {
+		return errors.Errorf("Unsupported node type for %q: %s", nodeName, nodeType)
+	}
+	instancesPrefix := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "instances")
+	instancesKey := path.Join(instancesPrefix, nodeName)
+
+	instance := ComputeInstance{Name: toGoogleName(cfg.ResourcesPrefix + nodeName + "-" + instanceName)}
+
+	// image, machine_type and zone are mandatory
+	var image string
+	if _, image, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "image"); err != nil {
+		return err
+	} else if image == "" {
+		return errors.Errorf("Missing mandatory parameter 'image' node type for %s", nodeName)
+	}
+	instance.BootDisk.InitializeParams.Image = image
+
+	if _, instance.MachineType, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "machine_type"); err != nil {
+		return err
+	} else if instance.MachineType == "" {
+		return errors.Errorf("Missing mandatory parameter 'machine_type' node type for %s", nodeName)
+	}
+
+	if _, instance.Zone, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "zone"); err != nil {
+		return err
+	} else if instance.Zone == "" {
+		return errors.Errorf("Missing mandatory parameter 'zone' node type for %s", nodeName)
+	}
+
+	if _, instance.Description, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "description"); err != nil {
+		return err
+	}
+
+	// Boot disk options
+	if _, size, err := deployments.GetNodeProperty(kv, deploymentID, nodeName, "boot_disk_size"); err != nil {
+		return err
+	} else if size != "" {
+		if instance.BootDisk.InitializeParams.Size, err = toGBSize(size); err != nil {
+			return errors.Wrapf(err, "invalid 'boot_disk_size' for %s", nodeName)
+		}
+	}
+	if _, instance.BootDisk.InitializeParams.Type, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "boot_disk_type"); err != nil {
+		return err
+	}
+	// Default is deleting the boot disk on compute termination
+	instance.BootDisk.AutoDelete = true
+	if _, s, err := deployments.GetNodeProperty(kv, deploymentID, nodeName, "boot_disk_auto_delete"); err != nil {
+		return err
+	} else if s != "" {
+		if instance.BootDisk.AutoDelete, err = strconv.ParseBool(s); err != nil {
+			return errors.Wrapf(err, "invalid 'boot_disk_auto_delete' for %s", nodeName)
+		}
+	}
+
+	if _, s, err := deployments.GetNodeProperty(kv, deploymentID, nodeName, "preemptible"); err != nil {
+		return err
+	} else if s != "" {
+		preemptible, err := strconv.ParseBool(s)
+		if err != nil {
+			return errors.Wrapf(err, "invalid 'preemptible' for %s", nodeName)
+		}
+		if preemptible {
+			// Preemptible instances can't be automatically restarted nor live migrated
+			instance.Scheduling = &Scheduling{Preemptible: true, AutomaticRestart: false, OnHostMaintenance: "TERMINATE"}
+		}
+	}
+
+	var tags string
+	if _, tags, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "tags"); err != nil {
+		return err
+	}
+	instance.Tags = splitList(tags)
+	hasFirewall, err := hasFirewallRules(kv, deploymentID, nodeName)
+	if err != nil {
+		return err
+	} else if hasFirewall {
+		// This tag is the target of the firewall rules generated for this node
+		instance.Tags = append(instance.Tags, toGoogleName(cfg.ResourcesPrefix+nodeName))
+	}
+
+	if instance.Labels, err = getStringMapNodeProperty(kv, deploymentID, nodeName, "labels"); err != nil {
+		return err
+	}
+	if instance.Metadata, err = getStringMapNodeProperty(kv, deploymentID, nodeName, "metadata"); err != nil {
+		return err
+	}
+
+	networkInterface := NetworkInterface{}
+	if _, networkInterface.Network, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "network"); err != nil {
+		return err
+	}
+	if _, networkInterface.Subnetwork, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "subnetwork"); err != nil {
+		return err
+	}
+	if networkInterface.Network == "" && networkInterface.Subnetwork == "" {
+		networkInterface.Network = "default"
+	}
+
+	var user string
+	if _, user, err = deployments.GetCapabilityProperty(kv, deploymentID, nodeName, "endpoint", "credentials", "user"); err != nil {
+		return err
+	} else if user == "" {
+		return errors.Errorf("Missing mandatory parameter 'user' node type for %s", nodeName)
+	}
+
+	consulKeys := commons.ConsulKeys{Keys: []commons.ConsulKey{}}
+
+	storageKeys, err := deployments.GetRequirementsKeysByTypeForNode(kv, deploymentID, nodeName, "local_storage")
+	if err != nil {
+		return err
+	}
+	for _, storagePrefix := range storageKeys {
+		requirementIndex := deployments.GetRequirementIndexFromRequirementKey(storagePrefix)
+		volumeNodeName, err := deployments.GetTargetNodeForRequirement(kv, deploymentID, nodeName, requirementIndex)
+		if err != nil {
+			return err
+		} else if volumeNodeName == "" {
+			continue
+		}
+		log.Debugf("Volume attachment required form Volume named %s", volumeNodeName)
+		volumeID, err := waitForInstanceAttribute(ctx, kv, deploymentID, volumeNodeName, instanceName, "volume_id")
+		if err != nil {
+			return err
+		}
+		attachedDisk := AttachedDisk{
+			Disk:       volumeID,
+			Instance:   fmt.Sprintf("${google_compute_instance.%s.self_link}", instance.Name),
+			Zone:       instance.Zone,
+			DeviceName: toGoogleName(volumeNodeName + "-" + instanceName),
+		}
+		attachName := toGoogleName("disk-" + volumeNodeName + "-to-" + instance.Name)
+		commons.AddResource(infrastructure, "google_compute_attached_disk", attachName, &attachedDisk)
+
+		// Google exposes attached disks using a stable path based on their device name
+		device := "/dev/disk/by-id/google-" + attachedDisk.DeviceName
+		consulKeys.Keys = append(consulKeys.Keys,
+			commons.ConsulKey{Path: path.Join(instancesPrefix, volumeNodeName, instanceName, "attributes/device"), Value: device},
+			commons.ConsulKey{Path: path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "relationship_instances", nodeName, requirementIndex, instanceName, "attributes/device"), Value: device},
+			commons.ConsulKey{Path: path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "relationship_instances", volumeNodeName, requirementIndex, instanceName, "attributes/device"), Value: device},
+		)
+	}
+
+	networkKeys, err := deployments.GetRequirementsKeysByTypeForNode(kv, deploymentID, nodeName, "network")
+	if err != nil {
+		return err
+	}
+	for _, networkReqPrefix := range networkKeys {
+		requirementIndex := deployments.GetRequirementIndexFromRequirementKey(networkReqPrefix)
+		capability, err := deployments.GetCapabilityForRequirement(kv, deploymentID, nodeName, requirementIndex)
+		if err != nil {
+			return err
+		}
+		if capability == "" {
+			continue
+		}
+		isAddress, err := deployments.IsTypeDerivedFrom(kv, deploymentID, capability, addressCapabilityType)
+		if err != nil {
+			return err
+		} else if !isAddress {
+			continue
+		}
+		if len(networkInterface.AccessConfigs) > 0 {
+			return errors.Errorf("Only one Address could be assigned to the Compute %q", nodeName)
+		}
+		addressNodeName, err := deployments.GetTargetNodeForRequirement(kv, deploymentID, nodeName, requirementIndex)
+		if err != nil {
+			return err
+		}
+		log.Debugf("Looking for Address of %q", addressNodeName)
+		address, err := waitForInstanceAttribute(ctx, kv, deploymentID, addressNodeName, instanceName, "ip_address")
+		if err != nil {
+			return err
+		}
+		networkInterface.AccessConfigs = append(networkInterface.AccessConfigs, AccessConfig{NatIP: address})
+	}
+
+	noAddress := false
+	if _, s, err := deployments.GetNodeProperty(kv, deploymentID, nodeName, "no_address"); err != nil {
+		return err
+	} else if s != "" {
+		if noAddress, err = strconv.ParseBool(s); err != nil {
+			return errors.Wrapf(err, "invalid 'no_address' for %s", nodeName)
+		}
+	}
+	if len(networkInterface.AccessConfigs) == 0 && !noAddress {
+		// An empty access config means an ephemeral external IP
+		networkInterface.AccessConfigs = append(networkInterface.AccessConfigs, AccessConfig{})
+	}
+	instance.NetworkInterfaces = []NetworkInterface{networkInterface}
+
+	commons.AddResource(infrastructure, "google_compute_instance", instance.Name, &instance)
+
+	privateIP := fmt.Sprintf("${google_compute_instance.%s.network_interface.0.network_ip}", instance.Name)
+	accessIP := privateIP
+	consulKeys.Keys = append(consulKeys.Keys,
+		commons.ConsulKey{Path: path.Join(instancesKey, instanceName, "/attributes/ip_address"), Value: privateIP},
+		commons.ConsulKey{Path: path.Join(instancesKey, instanceName, "/attributes/private_address"), Value: privateIP},
+	)
+	if len(networkInterface.AccessConfigs) > 0 {
+		publicIP := fmt.Sprintf("${google_compute_instance.%s.network_interface.0.access_config.0.nat_ip}", instance.Name)
+		// Use the external IP for provisioning
+		accessIP = publicIP
+		consulKeys.Keys = append(consulKeys.Keys,
+			commons.ConsulKey{Path: path.Join(instancesKey, instanceName, "/attributes/public_address"), Value: publicIP},
+			// In order to be backward compatible to components developed for Alien (only the above is standard)
+			commons.ConsulKey{Path: path.Join(instancesKey, instanceName, "/attributes/public_ip_address"), Value: publicIP},
+		)
+	}
+	consulKeys.Keys = append(consulKeys.Keys, commons.ConsulKey{Path: path.Join(instancesKey, instanceName, "/capabilities/endpoint/attributes/ip_address"), Value: accessIP})
+
+	commons.AddResource(infrastructure, "consul_keys", instance.Name, &consulKeys)
+
+	// Check the connection in order to be sure that ansible will be able to log on the instance
+	nullResource := commons.Resource{}
+	// TODO private key should not be hard-coded
+	re := commons.RemoteExec{Inline: []string{`echo "connected"`}, Connection: &commons.Connection{User: user, Host: accessIP, PrivateKey: `${file("~/.ssh/yorc.pem")}`}}
+	nullResource.Provisioners = make([]map[string]interface{}, 0)
+	provMap := make(map[string]interface{})
+	provMap["remote-exec"] = re
+	nullResource.Provisioners = append(nullResource.Provisioners, provMap)
+
+	commons.AddResource(infrastructure, "null_resource", instance.Name+"-ConnectionCheck", &nullResource)
+
+	return nil
+}
+
+func getFirewallRules(kv *api.KV, deploymentID, nodeName string) ([]firewallRule, error) {
+	_, value, err := deployments.GetNodeProperty(kv, deploymentID, nodeName, "firewall_rules")
+	if err != nil || value == "" {
+		return nil, err
+	}
+	var rules []firewallRule
+	if err = json.Unmarshal([]byte(value), &rules); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse property 'firewall_rules' of node %q", nodeName)
+	}
+	return rules, nil
+}
+
+func hasFirewallRules(kv *api.KV, deploymentID, nodeName string) (bool, error) {
+	rules, err := getFirewallRules(kv, deploymentID, nodeName)
+	return len(rules) > 0, err
+}
+
+// generateFirewalls generates a firewall rule targeting all instances of the given Compute node for each of its 'firewall_rules'
+func (g *googleGenerator) generateFirewalls(kv *api.KV, cfg config.Configuration, deploymentID, nodeName string, infrastructure *commons.Infrastructure) error {
+	rules, err := getFirewallRules(kv, deploymentID, nodeName)
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+	_, network, err := deployments.GetNodeProperty(kv, deploymentID, nodeName, "network")
+	if err != nil {
+		return err
+	} else if network == "" {
+		network = "default"
+	}
+	targetTag := toGoogleName(cfg.ResourcesPrefix + nodeName)
+	for i, rule := range rules {
+		allow := AllowRule{Protocol: rule.Protocol}
+		if allow.Protocol == "" {
+			allow.Protocol = "tcp"
+		}
+		for _, port := range rule.Ports {
+			allow.Ports = append(allow.Ports, fmt.Sprint(port))
+		}
+		firewall := Firewall{
+			Name:         toGoogleName(fmt.Sprintf("%s-fw-%d", targetTag, i)),
+			Network:      network,
+			Allow:        []AllowRule{allow},
+			SourceRanges: rule.SourceRanges,
+			TargetTags:   []string{targetTag},
+		}
+		commons.AddResource(infrastructure, "google_compute_firewall", firewall.Name, &firewall)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package google
+
+import (
+	"context"
+	"path"
+	"testing"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/prov/terraform/commons"
+)
+
+func loadTestYaml(t *testing.T, kv *api.KV) string {
+	deploymentID := path.Base(t.Name())
+	yamlName := "testdata/" + deploymentID + ".yaml"
+	err := deployments.StoreDeploymentDefinition(context.Background(), kv, deploymentID, yamlName)
+	require.Nil(t, err, "Failed to parse "+yamlName+" definition")
+	return deploymentID
+}
+
+func getConsulKeysValues(t *testing.T, infrastructure *commons.Infrastructure, name string) map[string]string {
+	require.Contains(t, infrastructure.Resource, "consul_keys")
+	consulKeysMap := infrastructure.Resource["consul_keys"].(map[string]interface{})
+	require.Contains(t, consulKeysMap, name)
+	consulKeys, ok := consulKeysMap[name].(*commons.ConsulKeys)
+	require.True(t, ok, "%s is not a ConsulKeys", name)
+	values := make(map[string]string)
+	for _, key := range consulKeys.Keys {
+		values[key.Path] = key.Value
+	}
+	return values
+}
+
+func testSimpleComputeInstance(t *testing.T, kv *api.KV, cfg config.Configuration) {
+	t.Parallel()
+	deploymentID := loadTestYaml(t, kv)
+	g := googleGenerator{}
+	infrastructure := commons.Infrastructure{}
+
+	err := g.generateComputeInstance(context.Background(), kv, cfg, deploymentID, "Compute", "0", &infrastructure, make(map[string]string))
+	require.Nil(t, err)
+
+	require.Len(t, infrastructure.Resource["google_compute_instance"], 1)
+	instancesMap := infrastructure.Resource["google_compute_instance"].(map[string]interface{})
+	require.Contains(t, instancesMap, "compute-0")
+
+	compute, ok := instancesMap["compute-0"].(*ComputeInstance)
+	require.True(t, ok, "compute-0 is not a ComputeInstance")
+	require.Equal(t, "compute-0", compute.Name)
+	require.Equal(t, "n1-standard-1", compute.MachineType)
+	require.Equal(t, "europe-west1-b", compute.Zone)
+	require.Equal(t, "centos-cloud/centos-7", compute.BootDisk.InitializeParams.Image)
+	require.Equal(t, 20, compute.BootDisk.InitializeParams.Size)
+	require.False(t, compute.BootDisk.AutoDelete)
+	require.Equal(t, []string{"tag1", "tag2", "compute"}, compute.Tags)
+	require.Equal(t, map[string]string{"env": "test"}, compute.Labels)
+	require.Equal(t, map[string]string{"ssh-keys": "centos:ssh-rsa AAAA centos"}, compute.Metadata)
+	require.NotNil(t, compute.Scheduling)
+	require.True(t, compute.Scheduling.Preemptible)
+	require.False(t, compute.Scheduling.AutomaticRestart)
+
+	require.Len(t, compute.NetworkInterfaces, 1)
+	require.Equal(t, "default", compute.NetworkInterfaces[0].Network)
+	require.Equal(t, []AccessConfig{{}}, compute.NetworkInterfaces[0].AccessConfigs)
+
+	consulKeys := getConsulKeysValues(t, &infrastructure, "compute-0")
+	instancesKey := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "instances", "Compute", "0")
+	require.Equal(t, "${google_compute_instance.compute-0.network_interface.0.network_ip}", consulKeys[path.Join(instancesKey, "attributes/private_address")])
+	require.Equal(t, "${google_compute_instance.compute-0.network_interface.0.access_config.0.nat_ip}", consulKeys[path.Join(instancesKey, "attributes/public_address")])
+	require.Equal(t, "${google_compute_instance.compute-0.network_interface.0.access_config.0.nat_ip}", consulKeys[path.Join(instancesKey, "capabilities/endpoint/attributes/ip_address")])
+
+	require.Contains(t, infrastructure.Resource, "null_resource")
+	nullResources := infrastructure.Resource["null_resource"].(map[string]interface{})
+	require.Contains(t, nullResources, "compute-0-ConnectionCheck")
+	nullRes, ok := nullResources["compute-0-ConnectionCheck"].(*commons.Resource)
+	require.True(t, ok)
+	require.Len(t, nullRes.Provisioners, 1)
+	rex, ok := nullRes.Provisioners[0]["remote-exec"].(commons.RemoteExec)
+	require.True(t, ok)
+	require.Equal(t, "centos", rex.Connection.User)
+	require.Equal(t, "${google_compute_instance.compute-0.network_interface.0.access_config.0.nat_ip}", rex.Connection.Host)
+
+	// Firewall rules are generated once for all instances
+	err = g.generateFirewalls(kv, cfg, deploymentID, "Compute", &infrastructure)
+	require.Nil(t, err)
+	require.Len(t, infrastructure.Resource["google_compute_firewall"], 2)
+	firewalls := infrastructure.Resource["google_compute_firewall"].(map[string]interface{})
+	require.Contains(t, firewalls, "compute-fw-0")
+	fw, ok := firewalls["compute-fw-0"].(*Firewall)
+	require.True(t, ok)
+	require.Equal(t, "default", fw.Network)
+	require.Equal(t, []AllowRule{{Protocol: "tcp", Ports: []string{"80", "8080-8090"}}}, fw.Allow)
+	require.Equal(t, []string{"0.0.0.0/0"}, fw.SourceRanges)
+	require.Equal(t, []string{"compute"}, fw.TargetTags)
+	require.Contains(t, firewalls, "compute-fw-1")
+	fw, ok = firewalls["compute-fw-1"].(*Firewall)
+	require.True(t, ok)
+	require.Equal(t, []AllowRule{{Protocol: "icmp"}}, fw.Allow)
+}
+
+func testSimpleComputeInstanceMissingParameter(t *testing.T, kv *api.KV, cfg config.Configuration) {
+	t.Parallel()
+	deploymentID := loadTestYaml(t, kv)
+	g := googleGenerator{}
+	infrastructure := commons.Infrastructure{}
+
+	err := g.generateComputeInstance(context.Background(), kv, cfg, deploymentID, "Compute", "0", &infrastructure, make(map[string]string))
+	require.Error(t, err, "Expecting missing mandatory parameter 'machine_type' error")
+}
+
+func testComputeInstanceWithAddress(t *testing.T, kv *api.KV, srv *testutil.TestServer, cfg config.Configuration) {
+	t.Parallel()
+	deploymentID := loadTestYaml(t, kv)
+	srv.PopulateKV(t, map[string][]byte{
+		path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/instances/Address/0/attributes/ip_address"): []byte("35.0.0.1"),
+	})
+	g := googleGenerator{}
+	infrastructure := commons.Infrastructure{}
+
+	err := g.generateComputeInstance(context.Background(), kv, cfg, deploymentID, "Compute", "0", &infrastructure, make(map[string]string))
+	require.Nil(t, err)
+
+	instancesMap := infrastructure.Resource["google_compute_instance"].(map[string]interface{})
+	compute, ok := instancesMap["compute-0"].(*ComputeInstance)
+	require.True(t, ok, "compute-0 is not a ComputeInstance")
+	require.Len(t, compute.NetworkInterfaces, 1)
+	require.Equal(t, "", compute.NetworkInterfaces[0].Network)
+	require.Equal(t, "my-subnet", compute.NetworkInterfaces[0].Subnetwork)
+	require.Equal(t, []AccessConfig{{NatIP: "35.0.0.1"}}, compute.NetworkInterfaces[0].AccessConfigs)
+	require.NotContains(t, infrastructure.Resource, "google_compute_firewall")
+}
+
+func testComputeInstanceWithDisk(t *testing.T, kv *api.KV, srv *testutil.TestServer, cfg config.Configuration) {
+	t.Parallel()
+	deploymentID := loadTestYaml(t, kv)
+	srv.PopulateKV(t, map[string][]byte{
+		path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/instances/Disk/0/attributes/volume_id"): []byte("my-disk"),
+	})
+	g := googleGenerator{}
+	infrastructure := commons.Infrastructure{}
+
+	err := g.generateComputeInstance(context.Background(), kv, cfg, deploymentID, "Compute", "0", &infrastructure, make(map[string]string))
+	require.Nil(t, err)
+
+	instancesMap := infrastructure.Resource["google_compute_instance"].(map[string]interface{})
+	compute, ok := instancesMap["compute-0"].(*ComputeInstance)
+	require.True(t, ok, "compute-0 is not a ComputeInstance")
+	require.Len(t, compute.NetworkInterfaces, 1)
+	require.Len(t, compute.NetworkInterfaces[0].AccessConfigs, 0)
+
+	require.Len(t, infrastructure.Resource["google_compute_attached_disk"], 1)
+	attachedDisks := infrastructure.Resource["google_compute_attached_disk"].(map[string]interface{})
+	require.Contains(t, attachedDisks, "disk-disk-to-compute-0")
+	attachedDisk, ok := attachedDisks["disk-disk-to-compute-0"].(*AttachedDisk)
+	require.True(t, ok)
+	require.Equal(t, "my-disk", attachedDisk.Disk)
+	require.Equal(t, "${google_compute_instance.compute-0.self_link}", attachedDisk.Instance)
+	require.Equal(t, "europe-west1-b", attachedDisk.Zone)
+	require.Equal(t, "disk-0", attachedDisk.DeviceName)
+
+	consulKeys := getConsulKeysValues(t, &infrastructure, "compute-0")
+	instancesKey := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "instances")
+	require.Equal(t, "/dev/disk/by-id/google-disk-0", consulKeys[path.Join(instancesKey, "Disk", "0", "attributes/device")])
+	require.NotContains(t, consulKeys, path.Join(instancesKey, "Compute", "0", "attributes/public_address"))
+	require.Equal(t, "${google_compute_instance.compute-0.network_interface.0.network_ip}", consulKeys[path.Join(instancesKey, "Compute", "0", "capabilities/endpoint/attributes/ip_address")])
+}
@@ -0,0 +1,61 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package google
+
+import (
+	"testing"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/testutil"
+)
+
+// The aim of this function is to run all package tests with consul server dependency with only one consul server start
+func TestRunConsulGooglePackageTests(t *testing.T) {
+	srv, client := testutil.NewTestConsulInstance(t)
+	kv := client.KV()
+	defer srv.Stop()
+
+	// Google infrastructure config
+	cfg := config.Configuration{
+		Infrastructures: map[string]config.DynamicMap{
+			infrastructureName: config.DynamicMap{
+				"project": "my-project",
+				"region":  "europe-west1",
+			}}}
+
+	t.Run("groupGoogle", func(t *testing.T) {
+		t.Run("simpleComputeInstance", func(t *testing.T) {
+			testSimpleComputeInstance(t, kv, cfg)
+		})
+		t.Run("simpleComputeInstanceMissingParameter", func(t *testing.T) {
+			testSimpleComputeInstanceMissingParameter(t, kv, cfg)
+		})
+		t.Run("computeInstanceWithAddress", func(t *testing.T) {
+			testComputeInstanceWithAddress(t, kv, srv, cfg)
+		})
+		t.Run("computeInstanceWithDisk", func(t *testing.T) {
+			testComputeInstanceWithDisk(t, kv, srv, cfg)
+		})
+		t.Run("simplePersistentDisk", func(t *testing.T) {
+			testSimplePersistentDisk(t, kv, cfg)
+		})
+		t.Run("persistentDiskWithVolumeID", func(t *testing.T) {
+			testPersistentDiskWithVolumeID(t, kv, cfg)
+		})
+		t.Run("addressWithProvidedIP", func(t *testing.T) {
+			testAddressWithProvidedIP(t, kv, cfg)
+		})
+	})
+}
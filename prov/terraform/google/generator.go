@@ -0,0 +1,251 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package google
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/helper/mathutil"
+	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/prov/terraform/commons"
+	"github.com/ystia/yorc/tosca"
+)
+
+const (
+	infrastructureName = "google"
+	computeType        = "yorc.nodes.google.Compute"
+	persistentDiskType = "yorc.nodes.google.PersistentDisk"
+	addressType        = "yorc.nodes.google.Address"
+	// addressCapabilityType is the type of the capability to target from a Compute network requirement to assign it an Address
+	addressCapabilityType = "yorc.capabilities.google.AddressConnectivity"
+)
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+type googleGenerator struct {
+}
+
+func (g *googleGenerator) GenerateTerraformInfraForNode(ctx context.Context, cfg config.Configuration, deploymentID, nodeName string) (bool, map[string]string, []string, error) {
+	log.Debugf("Generating infrastructure for deployment with id %s", deploymentID)
+	cClient, err := cfg.GetConsulClient()
+	if err != nil {
+		return false, nil, nil, err
+	}
+	kv := cClient.KV()
+	nodeKey := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "nodes", nodeName)
+	terraformStateKey := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "terraform-state", nodeName)
+
+	infrastructure := commons.Infrastructure{}
+
+	consulAddress := "127.0.0.1:8500"
+	if cfg.Consul.Address != "" {
+		consulAddress = cfg.Consul.Address
+	}
+	consulScheme := "http"
+	if cfg.Consul.SSL {
+		consulScheme = "https"
+	}
+
+	// Remote Configuration for Terraform State to store it in the Consul KV store
+	infrastructure.Terraform = map[string]interface{}{
+		"backend": map[string]interface{}{
+			"consul": map[string]interface{}{
+				"path":    terraformStateKey,
+				"address": consulAddress,
+				"scheme":  consulScheme,
+			},
+		},
+	}
+
+	// Credentials are secrets so they are provided through the environment rather than in tf files
+	cmdEnv := make([]string, 0)
+	if credentials := cfg.Infrastructures[infrastructureName].GetString("credentials"); credentials != "" {
+		cmdEnv = append(cmdEnv, fmt.Sprintf("GOOGLE_CREDENTIALS=%s", credentials))
+	}
+	if appCredentials := cfg.Infrastructures[infrastructureName].GetString("application_credentials"); appCredentials != "" {
+		cmdEnv = append(cmdEnv, fmt.Sprintf("GOOGLE_APPLICATION_CREDENTIALS=%s", appCredentials))
+	}
+
+	// Management of variables for Terraform
+	infrastructure.Provider = map[string]interface{}{
+		"google": map[string]interface{}{
+			"project": cfg.Infrastructures[infrastructureName].GetString("project"),
+			"region":  cfg.Infrastructures[infrastructureName].GetString("region"),
+		},
+		"consul": map[string]interface{}{
+			"address":   consulAddress,
+			"scheme":    consulScheme,
+			"ca_file":   cfg.Consul.CA,
+			"cert_file": cfg.Consul.Cert,
+			"key_file":  cfg.Consul.Key,
+		},
+	}
+
+	log.Debugf("inspecting node %s", nodeKey)
+	nodeType, err := deployments.GetNodeType(kv, deploymentID, nodeName)
+	if err != nil {
+		return false, nil, nil, err
+	}
+	outputs := make(map[string]string)
+
+	switch nodeType {
+	case computeType:
+		// Firewall rules are shared by all instances of a Compute node
+		if err = g.generateFirewalls(kv, cfg, deploymentID, nodeName, &infrastructure); err != nil {
+			return false, nil, nil, err
+		}
+	case persistentDiskType, addressType:
+	default:
+		return false, nil, nil, errors.Errorf("Unsupported node type '%s' for node '%s' in deployment '%s'", nodeType, nodeName, deploymentID)
+	}
+
+	instances, err := deployments.GetNodeInstancesIds(kv, deploymentID, nodeName)
+	if err != nil {
+		return false, nil, nil, err
+	}
+
+	for instNb, instanceName := range instances {
+		instanceState, err := deployments.GetInstanceState(kv, deploymentID, nodeName, instanceName)
+		if err != nil {
+			return false, nil, nil, err
+		}
+		if instanceState == tosca.NodeStateDeleting || instanceState == tosca.NodeStateDeleted {
+			// Do not generate something for this node instance (will be deleted if exists)
+			continue
+		}
+
+		switch nodeType {
+		case computeType:
+			err = g.generateComputeInstance(ctx, kv, cfg, deploymentID, nodeName, instanceName, &infrastructure, outputs)
+		case persistentDiskType:
+			err = g.generatePersistentDisk(kv, cfg, deploymentID, nodeName, instanceName, instNb, &infrastructure)
+		case addressType:
+			err = g.generateAddress(kv, cfg, deploymentID, nodeName, instanceName, instNb, &infrastructure)
+		}
+		if err != nil {
+			return false, nil, nil, err
+		}
+	}
+
+	jsonInfra, err := json.MarshalIndent(infrastructure, "", "  ")
+	if err != nil {
+		return false, nil, nil, errors.Wrap(err, "Failed to generate JSON of terraform Infrastructure description")
+	}
+	infraPath := filepath.Join(cfg.WorkingDirectory, "deployments", deploymentID, "infra", nodeName)
+	if err = os.MkdirAll(infraPath, 0775); err != nil {
+		return false, nil, nil, errors.Wrapf(err, "Failed to create infrastructure working directory %q", infraPath)
+	}
+
+	if err = ioutil.WriteFile(filepath.Join(infraPath, "infra.tf.json"), jsonInfra, 0664); err != nil {
+		return false, nil, nil, errors.Wrapf(err, "Failed to write file %q", filepath.Join(infraPath, "infra.tf.json"))
+	}
+
+	log.Debugf("Infrastructure generated for deployment with id %s", deploymentID)
+	return true, outputs, cmdEnv, nil
+}
+
+// toGoogleName converts the given string into a valid Google Compute Engine resource name.
+//
+// Such names are made of at most 63 lowercase letters, digits or dashes and should start with a letter.
+func toGoogleName(s string) string {
+	name := invalidNameChars.ReplaceAllString(strings.ToLower(s), "-")
+	if name == "" || name[0] < 'a' || name[0] > 'z' {
+		name = "yorc-" + name
+	}
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return strings.TrimRight(name, "-")
+}
+
+// toGBSize converts a TOSCA size into a number of GB rounded up as expected by Google Compute Engine.
+//
+// Default size unit is MB.
+func toGBSize(size string) (int, error) {
+	var gSize float64
+	if mSize, err := strconv.Atoi(size); err == nil {
+		gSize = float64(mSize) / 1000
+	} else {
+		bSize, err := humanize.ParseBytes(size)
+		if err != nil {
+			return 0, errors.Errorf("Can't convert size to bytes value: %v", err)
+		}
+		gSize = float64(bSize) / humanize.GByte
+	}
+	log.Debugf("Computed size in GB: %f", gSize)
+	return int(mathutil.Round(gSize, 0, 0)), nil
+}
+
+// splitList splits a coma separated list of values
+func splitList(s string) []string {
+	values := make([]string, 0)
+	for _, value := range strings.Split(strings.NewReplacer("\"", "", "'", "").Replace(s), ",") {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func getStringMapNodeProperty(kv *api.KV, deploymentID, nodeName, propertyName string) (map[string]string, error) {
+	_, value, err := deployments.GetNodeProperty(kv, deploymentID, nodeName, propertyName)
+	if err != nil || value == "" {
+		return nil, err
+	}
+	result := make(map[string]string)
+	if err = json.Unmarshal([]byte(value), &result); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse property %q of node %q as a map of strings", propertyName, nodeName)
+	}
+	return result, nil
+}
+
+// waitForInstanceAttribute waits until the given attribute of a node instance is set and returns its value.
+//
+// This is useful to wait for resources generated by another node of the topology.
+func waitForInstanceAttribute(ctx context.Context, kv *api.KV, deploymentID, nodeName, instanceName, attributeName string) (string, error) {
+	for {
+		found, value, err := deployments.GetInstanceAttribute(kv, deploymentID, nodeName, instanceName, attributeName)
+		if err != nil {
+			log.Printf("[Warning] bypassing error while waiting for attribute %q of node %q: %v", attributeName, nodeName, err)
+		}
+		// As attributes may fallback to optional properties keep checking as long as we have an empty value
+		if found && value != "" {
+			return value, nil
+		}
+		select {
+		case <-time.After(1 * time.Second):
+		case <-ctx.Done():
+			// context cancelled, give up!
+			return "", ctx.Err()
+		}
+	}
+}
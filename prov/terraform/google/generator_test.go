@@ -0,0 +1,73 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package google
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_toGoogleName(t *testing.T) {
+	tests := []struct {
+		name string
+		arg  string
+		want string
+	}{
+		{"AlreadyValid", "compute-0", "compute-0"},
+		{"UpperCase", "ComputeGCP-0", "computegcp-0"},
+		{"InvalidChars", "my_Compute.node-0", "my-compute-node-0"},
+		{"StartsWithDigit", "0compute", "yorc-0compute"},
+		{"TrailingDash", "compute_", "compute"},
+		{"TooLong", "a123456789012345678901234567890123456789012345678901234567890123456789", "a12345678901234567890123456789012345678901234567890123456789012"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, toGoogleName(tt.arg))
+		})
+	}
+}
+
+func Test_toGBSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    string
+		want    int
+		wantErr bool
+	}{
+		{"DefaultUnitMB", "1", 1, false},
+		{"MBRoundedUp", "2500", 3, false},
+		{"GB", "10 GB", 10, false},
+		{"GiB", "1 GiB", 2, false},
+		{"TB", "1TB", 1000, false},
+		{"Invalid", "1 Kangaroo", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := toGBSize(tt.size)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_splitList(t *testing.T) {
+	require.Equal(t, []string{}, splitList(""))
+	require.Equal(t, []string{"a", "b", "c"}, splitList(`"a", 'b',c,`))
+}
@@ -0,0 +1,92 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package google
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/prov/terraform/commons"
+)
+
+func (g *googleGenerator) generatePersistentDisk(kv *api.KV, cfg config.Configuration, deploymentID, nodeName, instanceName string, instanceNb int, infrastructure *commons.Infrastructure) error {
+	nodeType, err := deployments.GetNodeType(kv, deploymentID, nodeName)
+	if err != nil {
+		return err
+	}
+	if nodeType != persistentDiskType {
+		return errors.Errorf("Unsupported node type for %q: %s", nodeName, nodeType)
+	}
+	instancesKey := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "instances", nodeName)
+	name := toGoogleName(cfg.ResourcesPrefix + nodeName + "-" + instanceName)
+
+	_, volumeIDs, err := deployments.GetNodeProperty(kv, deploymentID, nodeName, "volume_id")
+	if err != nil {
+		return err
+	}
+	if diskIDs := splitList(volumeIDs); len(diskIDs) > instanceNb {
+		log.Debugf("Reusing existing disk %q for instance %q of node %q", diskIDs[instanceNb], instanceName, nodeName)
+		consulKeys := commons.ConsulKeys{Keys: []commons.ConsulKey{{Path: path.Join(instancesKey, instanceName, "/attributes/volume_id"), Value: diskIDs[instanceNb]}}}
+		commons.AddResource(infrastructure, "consul_keys", name, &consulKeys)
+		return nil
+	}
+
+	disk := PersistentDisk{Name: name}
+	if _, disk.Zone, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "zone"); err != nil {
+		return err
+	} else if disk.Zone == "" {
+		return errors.Errorf("Missing mandatory property 'zone' for %s", nodeName)
+	}
+	if _, disk.Type, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "type"); err != nil {
+		return err
+	}
+	if _, disk.Image, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "image"); err != nil {
+		return err
+	}
+	if _, disk.Snapshot, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "snapshot_id"); err != nil {
+		return err
+	}
+	if _, disk.Description, err = deployments.GetNodeProperty(kv, deploymentID, nodeName, "description"); err != nil {
+		return err
+	}
+	if disk.Labels, err = getStringMapNodeProperty(kv, deploymentID, nodeName, "labels"); err != nil {
+		return err
+	}
+
+	_, size, err := deployments.GetNodeProperty(kv, deploymentID, nodeName, "size")
+	if err != nil {
+		return err
+	}
+	if size != "" {
+		if disk.Size, err = toGBSize(size); err != nil {
+			return err
+		}
+	} else if disk.Image == "" && disk.Snapshot == "" {
+		// The size of the disk is inferred from the image or snapshot if any
+		return errors.Errorf("Missing mandatory property 'size' for %s", nodeName)
+	}
+
+	commons.AddResource(infrastructure, "google_compute_disk", disk.Name, &disk)
+	consulKeys := commons.ConsulKeys{Keys: []commons.ConsulKey{{Path: path.Join(instancesKey, instanceName, "/attributes/volume_id"), Value: fmt.Sprintf("${google_compute_disk.%s.name}", disk.Name)}}}
+	commons.AddResource(infrastructure, "consul_keys", disk.Name, &consulKeys)
+	return nil
+}
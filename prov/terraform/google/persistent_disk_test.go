@@ -0,0 +1,69 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package google
+
+import (
+	"path"
+	"testing"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/prov/terraform/commons"
+)
+
+func testSimplePersistentDisk(t *testing.T, kv *api.KV, cfg config.Configuration) {
+	t.Parallel()
+	deploymentID := loadTestYaml(t, kv)
+	g := googleGenerator{}
+	infrastructure := commons.Infrastructure{}
+
+	err := g.generatePersistentDisk(kv, cfg, deploymentID, "Disk", "0", 0, &infrastructure)
+	require.Nil(t, err)
+
+	require.Len(t, infrastructure.Resource["google_compute_disk"], 1)
+	disks := infrastructure.Resource["google_compute_disk"].(map[string]interface{})
+	require.Contains(t, disks, "disk-0")
+	disk, ok := disks["disk-0"].(*PersistentDisk)
+	require.True(t, ok, "disk-0 is not a PersistentDisk")
+	require.Equal(t, "disk-0", disk.Name)
+	require.Equal(t, "europe-west1-b", disk.Zone)
+	require.Equal(t, 12, disk.Size)
+	require.Equal(t, "pd-ssd", disk.Type)
+	require.Equal(t, "my disk", disk.Description)
+	require.Equal(t, map[string]string{"env": "test"}, disk.Labels)
+
+	consulKeys := getConsulKeysValues(t, &infrastructure, "disk-0")
+	require.Equal(t, "${google_compute_disk.disk-0.name}", consulKeys[path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/instances/Disk/0/attributes/volume_id")])
+}
+
+func testPersistentDiskWithVolumeID(t *testing.T, kv *api.KV, cfg config.Configuration) {
+	t.Parallel()
+	deploymentID := loadTestYaml(t, kv)
+	g := googleGenerator{}
+	infrastructure := commons.Infrastructure{}
+
+	err := g.generatePersistentDisk(kv, cfg, deploymentID, "Disk", "0", 0, &infrastructure)
+	require.Nil(t, err)
+	require.NotContains(t, infrastructure.Resource, "google_compute_disk")
+	consulKeys := getConsulKeysValues(t, &infrastructure, "disk-0")
+	require.Equal(t, "disk-0", consulKeys[path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/instances/Disk/0/attributes/volume_id")])
+
+	// Not enough disks provided and no size to create a new one
+	err = g.generatePersistentDisk(kv, cfg, deploymentID, "Disk", "1", 1, &infrastructure)
+	require.Error(t, err, "Expecting missing mandatory property 'size' error")
+}
@@ -0,0 +1,109 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package google
+
+// A ComputeInstance represents a Google Compute Engine virtual machine
+type ComputeInstance struct {
+	Name              string             `json:"name"`
+	MachineType       string             `json:"machine_type"`
+	Zone              string             `json:"zone"`
+	Description       string             `json:"description,omitempty"`
+	Tags              []string           `json:"tags,omitempty"`
+	Labels            map[string]string  `json:"labels,omitempty"`
+	Metadata          map[string]string  `json:"metadata,omitempty"`
+	BootDisk          BootDisk           `json:"boot_disk"`
+	NetworkInterfaces []NetworkInterface `json:"network_interface"`
+	Scheduling        *Scheduling        `json:"scheduling,omitempty"`
+}
+
+// A BootDisk represents the boot disk of a ComputeInstance
+type BootDisk struct {
+	AutoDelete       bool                 `json:"auto_delete"`
+	InitializeParams InitializeParameters `json:"initialize_params"`
+}
+
+// InitializeParameters are the parameters used to create the boot disk of a ComputeInstance
+type InitializeParameters struct {
+	Image string `json:"image"`
+	Size  int    `json:"size,omitempty"`
+	Type  string `json:"type,omitempty"`
+}
+
+// A NetworkInterface attaches a ComputeInstance to a network
+type NetworkInterface struct {
+	Network    string `json:"network,omitempty"`
+	Subnetwork string `json:"subnetwork,omitempty"`
+	// AccessConfigs should contain at most one element, an empty AccessConfig means an ephemeral external IP
+	AccessConfigs []AccessConfig `json:"access_config,omitempty"`
+}
+
+// An AccessConfig allows to give an external IP to a NetworkInterface
+type AccessConfig struct {
+	NatIP       string `json:"nat_ip,omitempty"`
+	NetworkTier string `json:"network_tier,omitempty"`
+}
+
+// Scheduling defines the scheduling strategy of a ComputeInstance
+type Scheduling struct {
+	Preemptible       bool   `json:"preemptible"`
+	AutomaticRestart  bool   `json:"automatic_restart"`
+	OnHostMaintenance string `json:"on_host_maintenance,omitempty"`
+}
+
+// A PersistentDisk represents a Google Compute Engine persistent disk
+type PersistentDisk struct {
+	Name        string            `json:"name"`
+	Zone        string            `json:"zone"`
+	Size        int               `json:"size,omitempty"`
+	Type        string            `json:"type,omitempty"`
+	Image       string            `json:"image,omitempty"`
+	Snapshot    string            `json:"snapshot,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+// An AttachedDisk attaches a PersistentDisk to a ComputeInstance
+type AttachedDisk struct {
+	Disk       string `json:"disk"`
+	Instance   string `json:"instance"`
+	Zone       string `json:"zone,omitempty"`
+	DeviceName string `json:"device_name,omitempty"`
+	Mode       string `json:"mode,omitempty"`
+}
+
+// An Address represents a Google Compute Engine static IP address
+type Address struct {
+	Name        string `json:"name"`
+	Region      string `json:"region,omitempty"`
+	AddressType string `json:"address_type,omitempty"`
+	NetworkTier string `json:"network_tier,omitempty"`
+	Subnetwork  string `json:"subnetwork,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// A Firewall represents a Google Compute Engine firewall rule
+type Firewall struct {
+	Name         string      `json:"name"`
+	Network      string      `json:"network"`
+	Allow        []AllowRule `json:"allow"`
+	SourceRanges []string    `json:"source_ranges,omitempty"`
+	TargetTags   []string    `json:"target_tags,omitempty"`
+}
+
+// An AllowRule is a protocol and ports combination allowed by a Firewall
+type AllowRule struct {
+	Protocol string   `json:"protocol"`
+	Ports    []string `json:"ports,omitempty"`
+}
@@ -61,7 +61,11 @@ func (s *Server) newCustomCommandHandler(w http.ResponseWriter, r *http.Request)
 		log.Panic(err)
 	}
 
-	data := make(map[string]string)
+	data, restErr := newTaskData(r)
+	if restErr != nil {
+		writeError(w, r, restErr)
+		return
+	}
 
 	// For now custom commands are for all instances
 	instances, err := deployments.GetNodeInstancesIds(s.consulClient.KV(), id, inputMap.NodeName)
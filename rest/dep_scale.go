@@ -49,17 +49,9 @@ func (s *Server) scaleHandler(w http.ResponseWriter, r *http.Request) {
 		log.Panic("You must provide a nodename")
 	}
 
-	var instancesDelta int
-	if value, ok := r.URL.Query()["delta"]; ok {
-		if instancesDelta, err = strconv.Atoi(value[0]); err != nil {
-			writeError(w, r, newBadRequestError(err))
-			return
-		} else if instancesDelta == 0 {
-			writeError(w, r, newBadRequestError(errors.New("You need to provide a non zero value as 'delta' parameter")))
-			return
-		}
-	} else {
-		writeError(w, r, newBadRequestError(errors.New("You need to provide a 'delta' parameter")))
+	instancesDelta, restErr := getScalingDelta(r)
+	if restErr != nil {
+		writeError(w, r, restErr)
 		return
 	}
 
@@ -80,11 +72,89 @@ func (s *Server) scaleHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Debugf("Scaling %d instances of node %q", instancesDelta, nodeName)
+	s.submitScaling(w, r, id, []string{nodeName}, instancesDelta)
+}
+
+func (s *Server) scaleGroupHandler(w http.ResponseWriter, r *http.Request) {
+	var params httprouter.Params
+	ctx := r.Context()
+	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	id := params.ByName("id")
+	groupName := params.ByName("groupName")
+
+	kv := s.consulClient.KV()
+
+	if !s.checkDeploymentExists(w, r, id) {
+		return
+	}
+
+	instancesDelta, restErr := getScalingDelta(r)
+	if restErr != nil {
+		writeError(w, r, restErr)
+		return
+	}
+
+	exists, err := deployments.DoesGroupExist(kv, id, groupName)
+	if err != nil {
+		log.Panic(err)
+	}
+	if !exists {
+		writeError(w, r, errNotFound)
+		return
+	}
+	members, err := deployments.GetGroupMembers(kv, id, groupName)
+	if err != nil {
+		log.Panic(err)
+	}
+	if len(members) == 0 {
+		writeError(w, r, newBadRequestParameter("group", errors.Errorf("Group %q has no members", groupName)))
+		return
+	}
+	for _, member := range members {
+		ok, err := deployments.HasScalableCapability(kv, id, member)
+		if err != nil {
+			log.Panic(err)
+		}
+		if !ok {
+			writeError(w, r, newBadRequestParameter("group", errors.Errorf("Node %q member of group %q must be scalable", member, groupName)))
+			return
+		}
+	}
+
+	log.Debugf("Scaling %d instances of nodes %v members of group %q", instancesDelta, members, groupName)
+	s.submitScaling(w, r, id, members, instancesDelta)
+}
+
+// getScalingDelta returns the non-zero 'delta' query parameter of a scaling request
+func getScalingDelta(r *http.Request) (int, *Error) {
+	value, ok := r.URL.Query()["delta"]
+	if !ok {
+		return 0, newBadRequestError(errors.New("You need to provide a 'delta' parameter"))
+	}
+	instancesDelta, err := strconv.Atoi(value[0])
+	if err != nil {
+		return 0, newBadRequestError(err)
+	}
+	if instancesDelta == 0 {
+		return 0, newBadRequestError(errors.New("You need to provide a non zero value as 'delta' parameter"))
+	}
+	return instancesDelta, nil
+}
+
+// submitScaling registers a scaling task applying the same instances delta to all the given nodes
+func (s *Server) submitScaling(w http.ResponseWriter, r *http.Request, id string, nodeNames []string, instancesDelta int) {
+	data, restErr := newTaskData(r)
+	if restErr != nil {
+		writeError(w, r, restErr)
+		return
+	}
+
 	var taskID string
+	var err error
 	if instancesDelta > 0 {
-		taskID, err = s.scaleOut(id, nodeName, uint32(instancesDelta))
+		taskID, err = s.scaleOut(id, nodeNames, uint32(instancesDelta), data)
 	} else {
-		taskID, err = s.scaleIn(id, nodeName, uint32(-instancesDelta))
+		taskID, err = s.scaleIn(id, nodeNames, uint32(-instancesDelta), data)
 	}
 	if err != nil {
 		if ok, _ := tasks.IsAnotherLivingTaskAlreadyExistsError(err); ok {
@@ -101,67 +171,88 @@ func (s *Server) scaleHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusAccepted)
 }
 
-func (s *Server) scaleOut(id, nodeName string, instancesDelta uint32) (string, error) {
+// scaleOut adds instances to the given nodes.
+//
+// The delta is lowered if needed so that the same number of instances is added to each node
+// without exceeding any node maximum number of instances.
+func (s *Server) scaleOut(id string, nodeNames []string, instancesDelta uint32, data map[string]string) (string, error) {
 	kv := s.consulClient.KV()
-	maxInstances, err := deployments.GetMaxNbInstancesForNode(kv, id, nodeName)
-	if err != nil {
-		return "", err
-	}
-	currentNbInstance, err := deployments.GetNbInstancesForNode(kv, id, nodeName)
-	if err != nil {
-		return "", err
-	}
+	for _, nodeName := range nodeNames {
+		maxInstances, err := deployments.GetMaxNbInstancesForNode(kv, id, nodeName)
+		if err != nil {
+			return "", err
+		}
+		currentNbInstance, err := deployments.GetNbInstancesForNode(kv, id, nodeName)
+		if err != nil {
+			return "", err
+		}
 
-	if currentNbInstance+instancesDelta > maxInstances {
-		log.Debug("The delta is too high, the max instances number is chosen")
-		instancesDelta = maxInstances - currentNbInstance
-		if instancesDelta == 0 {
-			return "", newBadRequestMessage("Maximum number of instances reached")
+		if currentNbInstance+instancesDelta > maxInstances {
+			log.Debug("The delta is too high, the max instances number is chosen")
+			instancesDelta = maxInstances - currentNbInstance
+			if instancesDelta == 0 {
+				return "", newBadRequestMessage(scalingLimitMessage("Maximum", nodeName, len(nodeNames)))
+			}
 		}
 	}
 
-	instancesByNodes, err := deployments.CreateNewNodeStackInstances(kv, id, nodeName, int(instancesDelta))
-	if err != nil {
-		return "", err
-	}
+	for _, nodeName := range nodeNames {
+		instancesByNodes, err := deployments.CreateNewNodeStackInstances(kv, id, nodeName, int(instancesDelta))
+		if err != nil {
+			return "", err
+		}
 
-	data := make(map[string]string)
-	for scalableNode, nodeInstances := range instancesByNodes {
-		data[path.Join("nodes", scalableNode)] = nodeInstances
+		for scalableNode, nodeInstances := range instancesByNodes {
+			data[path.Join("nodes", scalableNode)] = nodeInstances
+		}
 	}
 	return s.tasksCollector.RegisterTaskWithData(id, tasks.ScaleOut, data)
 }
 
-func (s *Server) scaleIn(id, nodeName string, instancesDelta uint32) (string, error) {
+// scaleIn removes instances from the given nodes.
+//
+// The delta is lowered if needed so that the same number of instances is removed from each node
+// without going below any node minimum number of instances.
+func (s *Server) scaleIn(id string, nodeNames []string, instancesDelta uint32, data map[string]string) (string, error) {
 	kv := s.consulClient.KV()
 
-	minInstances, err := deployments.GetMinNbInstancesForNode(kv, id, nodeName)
-	if err != nil {
-		return "", err
-	}
-	currentNbInstance, err := deployments.GetNbInstancesForNode(kv, id, nodeName)
-	if err != nil {
-		return "", err
-	}
+	for _, nodeName := range nodeNames {
+		minInstances, err := deployments.GetMinNbInstancesForNode(kv, id, nodeName)
+		if err != nil {
+			return "", err
+		}
+		currentNbInstance, err := deployments.GetNbInstancesForNode(kv, id, nodeName)
+		if err != nil {
+			return "", err
+		}
 
-	if currentNbInstance-instancesDelta < minInstances {
-		log.Debug("The delta is too low, the min instances number is chosen")
-		instancesDelta = currentNbInstance - minInstances
-		if instancesDelta == 0 {
-			return "", newBadRequestMessage("Minimum number of instances reached")
+		if currentNbInstance-instancesDelta < minInstances {
+			log.Debug("The delta is too low, the min instances number is chosen")
+			instancesDelta = currentNbInstance - minInstances
+			if instancesDelta == 0 {
+				return "", newBadRequestMessage(scalingLimitMessage("Minimum", nodeName, len(nodeNames)))
+			}
 		}
 	}
 
-	instancesByNodes, err := deployments.SelectNodeStackInstances(kv, id, nodeName, int(instancesDelta))
-	if err != nil {
-		return "", err
-	}
+	for _, nodeName := range nodeNames {
+		instancesByNodes, err := deployments.SelectNodeStackInstances(kv, id, nodeName, int(instancesDelta))
+		if err != nil {
+			return "", err
+		}
 
-	data := make(map[string]string)
-	for scalableNode, nodeInstances := range instancesByNodes {
-		data[path.Join("nodes", scalableNode)] = nodeInstances
+		for scalableNode, nodeInstances := range instancesByNodes {
+			data[path.Join("nodes", scalableNode)] = nodeInstances
+		}
 	}
 
 	return s.tasksCollector.RegisterTaskWithData(id, tasks.ScaleIn, data)
 
 }
+
+func scalingLimitMessage(limit, nodeName string, nbNodes int) string {
+	if nbNodes > 1 {
+		return fmt.Sprintf("%s number of instances reached for node %q", limit, nodeName)
+	}
+	return fmt.Sprintf("%s number of instances reached", limit)
+}
@@ -0,0 +1,224 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/tasks"
+	"github.com/ystia/yorc/tasks/scheduling"
+)
+
+func (s *Server) newScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	var params httprouter.Params
+	ctx := r.Context()
+	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	deploymentID := params.ByName("id")
+
+	if !s.checkDeploymentExists(w, r, deploymentID) {
+		return
+	}
+	schedule, restErr := readScheduleRequest(r, deploymentID)
+	if restErr != nil {
+		writeError(w, r, restErr)
+		return
+	}
+
+	scheduleID, err := scheduling.CreateSchedule(s.consulClient.KV(), schedule)
+	if err != nil {
+		if scheduling.IsBadRequestError(err) {
+			writeError(w, r, newBadRequestError(err))
+			return
+		}
+		log.Panic(err)
+	}
+	w.Header().Set("Location", fmt.Sprintf("/deployments/%s/schedules/%s", deploymentID, scheduleID))
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) updateScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	var params httprouter.Params
+	ctx := r.Context()
+	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	deploymentID := params.ByName("id")
+	scheduleID := params.ByName("scheduleId")
+
+	if !s.checkDeploymentExists(w, r, deploymentID) {
+		return
+	}
+	schedule, restErr := readScheduleRequest(r, deploymentID)
+	if restErr != nil {
+		writeError(w, r, restErr)
+		return
+	}
+	schedule.ID = scheduleID
+
+	if err := scheduling.UpdateSchedule(s.consulClient.KV(), schedule); err != nil {
+		if scheduling.IsScheduleNotFoundError(err) {
+			writeError(w, r, errNotFound)
+			return
+		}
+		if scheduling.IsBadRequestError(err) {
+			writeError(w, r, newBadRequestError(err))
+			return
+		}
+		log.Panic(err)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) deleteScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	var params httprouter.Params
+	ctx := r.Context()
+	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	deploymentID := params.ByName("id")
+	scheduleID := params.ByName("scheduleId")
+
+	if !s.checkDeploymentExists(w, r, deploymentID) {
+		return
+	}
+	if err := scheduling.DeleteSchedule(s.consulClient.KV(), deploymentID, scheduleID); err != nil {
+		if scheduling.IsScheduleNotFoundError(err) {
+			writeError(w, r, errNotFound)
+			return
+		}
+		log.Panic(err)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) getScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	var params httprouter.Params
+	ctx := r.Context()
+	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	deploymentID := params.ByName("id")
+	scheduleID := params.ByName("scheduleId")
+	kv := s.consulClient.KV()
+
+	if !s.checkDeploymentExists(w, r, deploymentID) {
+		return
+	}
+	schedule, err := scheduling.GetSchedule(kv, deploymentID, scheduleID)
+	if err != nil {
+		if scheduling.IsScheduleNotFoundError(err) {
+			writeError(w, r, errNotFound)
+			return
+		}
+		log.Panic(err)
+	}
+	history, err := scheduling.GetScheduleHistory(kv, deploymentID, scheduleID)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	restSchedule := Schedule{
+		ID: schedule.ID,
+		ScheduleRequest: ScheduleRequest{
+			Cron:              schedule.Cron,
+			TaskType:          schedule.TaskType.String(),
+			WorkflowName:      schedule.WorkflowName,
+			ContinueOnError:   schedule.ContinueOnError,
+			NodeName:          schedule.NodeName,
+			CustomCommandName: schedule.CommandName,
+			Inputs:            schedule.Inputs,
+		},
+		NextRun: schedule.NextRun,
+		History: make([]ScheduleHistoryEntry, len(history)),
+		Links: []AtomLink{
+			newAtomLink(LinkRelSelf, path.Join("/deployments", deploymentID, "schedules", scheduleID)),
+			newAtomLink(LinkRelDeployment, path.Join("/deployments", deploymentID)),
+		},
+	}
+	for i, entry := range history {
+		restSchedule.History[i] = ScheduleHistoryEntry{Date: entry.Date, TaskID: entry.TaskID, Error: entry.Error}
+	}
+	encodeJSONResponse(w, r, restSchedule)
+}
+
+func (s *Server) listSchedulesHandler(w http.ResponseWriter, r *http.Request) {
+	var params httprouter.Params
+	ctx := r.Context()
+	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	deploymentID := params.ByName("id")
+
+	if !s.checkDeploymentExists(w, r, deploymentID) {
+		return
+	}
+	schedules, err := scheduling.GetSchedules(s.consulClient.KV(), deploymentID)
+	if err != nil {
+		log.Panic(err)
+	}
+	if len(schedules) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	schedulesCol := SchedulesCollection{Schedules: make([]AtomLink, len(schedules))}
+	for i, schedule := range schedules {
+		schedulesCol.Schedules[i] = newAtomLink(LinkRelSchedule, path.Join("/deployments", deploymentID, "schedules", schedule.ID))
+	}
+	encodeJSONResponse(w, r, schedulesCol)
+}
+
+func (s *Server) checkDeploymentExists(w http.ResponseWriter, r *http.Request, deploymentID string) bool {
+	dExits, err := deployments.DoesDeploymentExists(s.consulClient.KV(), deploymentID)
+	if err != nil {
+		log.Panicf("%v", err)
+	}
+	if !dExits {
+		writeError(w, r, errNotFound)
+		return false
+	}
+	return true
+}
+
+func readScheduleRequest(r *http.Request, deploymentID string) (*scheduling.Schedule, *Error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Panic(err)
+	}
+	var request ScheduleRequest
+	if err = json.Unmarshal(body, &request); err != nil {
+		return nil, newBadRequestError(err)
+	}
+
+	schedule := &scheduling.Schedule{
+		DeploymentID:    deploymentID,
+		Cron:            request.Cron,
+		WorkflowName:    request.WorkflowName,
+		ContinueOnError: request.ContinueOnError,
+		NodeName:        request.NodeName,
+		CommandName:     request.CustomCommandName,
+		Inputs:          request.Inputs,
+	}
+	switch {
+	case strings.EqualFold(request.TaskType, tasks.CustomWorkflow.String()):
+		schedule.TaskType = tasks.CustomWorkflow
+	case strings.EqualFold(request.TaskType, tasks.CustomCommand.String()):
+		schedule.TaskType = tasks.CustomCommand
+	default:
+		return nil, newBadRequestParameter("task_type", errors.Errorf("expecting %q or %q, got %q", tasks.CustomWorkflow, tasks.CustomCommand, request.TaskType))
+	}
+	return schedule, nil
+}
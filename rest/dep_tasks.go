@@ -17,6 +17,7 @@ package rest
 import (
 	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/pkg/errors"
@@ -27,6 +28,19 @@ import (
 	"github.com/ystia/yorc/tasks"
 )
 
+// newTaskData returns the data of a task to be registered, it contains the task priority if given as
+// a "priority" query parameter
+func newTaskData(r *http.Request) (map[string]string, *Error) {
+	data := make(map[string]string)
+	if value, ok := r.URL.Query()["priority"]; ok {
+		if _, err := strconv.Atoi(value[0]); err != nil {
+			return nil, newBadRequestParameter("priority", err)
+		}
+		data[tasks.PriorityDataName] = value[0]
+	}
+	return data, nil
+}
+
 func (s *Server) tasksPreChecks(w http.ResponseWriter, r *http.Request, id, taskID string) bool {
 	kv := s.consulClient.KV()
 
@@ -198,6 +212,14 @@ func (s *Server) resumeTaskHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Steps already done are not run again, this would be wrong if their resources were removed, even partially, by a rollback
+	if rollbackAttempted, err := tasks.IsTaskRollbackAttempted(kv, taskID); err != nil {
+		log.Panic(err)
+	} else if rollbackAttempted {
+		writeError(w, r, newBadRequestError(errors.Errorf("Cannot resume task %q as the resources it created were rolled back.", taskID)))
+		return
+	}
+
 	if err := tasks.ResumeTask(kv, taskID); err != nil {
 		log.Panic(err)
 	}
@@ -27,6 +27,7 @@ import (
 	"github.com/ystia/yorc/helper/collections"
 	"github.com/ystia/yorc/log"
 	"github.com/ystia/yorc/tasks"
+	"github.com/ystia/yorc/tasks/workflow"
 )
 
 func (s *Server) newWorkflowHandler(w http.ResponseWriter, r *http.Request) {
@@ -55,7 +56,11 @@ func (s *Server) newWorkflowHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	data := make(map[string]string)
+	data, restErr := newTaskData(r)
+	if restErr != nil {
+		writeError(w, r, restErr)
+		return
+	}
 	data["workflowName"] = workflowName
 	if _, ok := r.URL.Query()["continueOnError"]; ok {
 		data["continueOnError"] = strconv.FormatBool(true)
@@ -137,3 +142,57 @@ func (s *Server) getWorkflowHandler(w http.ResponseWriter, r *http.Request) {
 	wf := Workflow{Name: workflowName, Workflow: wfSteps}
 	encodeJSONResponse(w, r, wf)
 }
+
+func (s *Server) getWorkflowPlanHandler(w http.ResponseWriter, r *http.Request) {
+	var params httprouter.Params
+	ctx := r.Context()
+	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	deploymentID := params.ByName("id")
+	workflowName := params.ByName("workflowName")
+	kv := s.consulClient.KV()
+
+	dExits, err := deployments.DoesDeploymentExists(kv, deploymentID)
+	if err != nil {
+		log.Panicf("%v", err)
+	}
+	if !dExits {
+		writeError(w, r, errNotFound)
+		return
+	}
+
+	workflows, err := deployments.GetWorkflows(kv, deploymentID)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	if !collections.ContainsString(workflows, workflowName) {
+		writeError(w, r, errNotFound)
+		return
+	}
+
+	taskID := r.URL.Query().Get("taskID")
+	if taskID != "" {
+		tExists, err := tasks.TaskExists(kv, taskID)
+		if err != nil {
+			log.Panic(err)
+		}
+		if !tExists {
+			writeError(w, r, errNotFound)
+			return
+		}
+		targetID, err := tasks.GetTaskTarget(kv, taskID)
+		if err != nil {
+			log.Panic(err)
+		}
+		if targetID != deploymentID {
+			writeError(w, r, newBadRequestMessage(fmt.Sprintf("Task %q is not related to deployment %q", taskID, deploymentID)))
+			return
+		}
+	}
+
+	plan, err := workflow.BuildWorkflowPlan(kv, deploymentID, workflowName, taskID)
+	if err != nil {
+		log.Panic(err)
+	}
+	encodeJSONResponse(w, r, plan)
+}
@@ -37,10 +37,10 @@ import (
 	"github.com/ystia/yorc/tasks"
 )
 
-func extractFile(f *zip.File, path string) {
+func extractFile(f *zip.File, path string) error {
 	fileReader, err := f.Open()
 	if err != nil {
-		log.Panic(err)
+		return errors.Wrapf(err, "failed to read file %q from archive", f.Name)
 	}
 	defer fileReader.Close()
 
@@ -53,52 +53,17 @@ func extractFile(f *zip.File, path string) {
 	if _, err := io.Copy(targetFile, fileReader); err != nil {
 		log.Panic(err)
 	}
+	return nil
 }
 
-func (s *Server) newDeploymentHandler(w http.ResponseWriter, r *http.Request) {
-
-	var uid string
-	if r.Method == http.MethodPut {
-		var params httprouter.Params
-		ctx := r.Context()
-		params = ctx.Value(paramsLookupKey).(httprouter.Params)
-		id := params.ByName("id")
-		id, err := url.QueryUnescape(id)
-		if err != nil {
-			log.Panicf("%v", errors.Wrapf(err, "Failed to unescape given deployment id %q", id))
-		}
-		matched, err := regexp.MatchString(YorcDeploymentIDPattern, id)
-		if err != nil {
-			log.Panicf("%v", errors.Wrapf(err, "Failed to parse given deployment id %q", id))
-		}
-		if !matched {
-			writeError(w, r, newBadRequestError(errors.Errorf("Deployment id should respect the following format: %q", YorcDeploymentIDPattern)))
-			return
-		}
-		// Do not impose a max id length as it doesn't have a concrete impact for now
-		// if len(id) > YorcDeploymentIDMaxLength {
-		// 	writeError(w, r, newBadRequestError(errors.Errorf("Deployment id should be less than %d characters (actual size %d)", YorcDeploymentIDMaxLength, len(id))))
-		// 	return
-		// }
-		dExits, err := deployments.DoesDeploymentExists(s.consulClient.KV(), id)
-		if err != nil {
-			log.Panicf("%v", err)
-		}
-		if dExits {
-			mess := fmt.Sprintf("Deployment with id %q already exists", id)
-			log.Debugf("[ERROR]: %s", mess)
-			writeError(w, r, newConflictRequest(mess))
-			return
-		}
-		uid = id
-	} else {
-		uid = fmt.Sprint(uuid.NewV4())
-	}
-	log.Printf("Analyzing deployment %s\n", uid)
-
+// extractCSAR stores the CSAR archive sent in the request body under uploadPath and extracts it into
+// an "overlay" sub-directory.
+//
+// It returns the path of the root YAML definition file of the archive. A non-nil error is returned
+// only if the archive itself is invalid, in which case the request should be rejected.
+func extractCSAR(r *http.Request, uploadPath string) (string, error) {
 	var err error
 	var file *os.File
-	uploadPath := filepath.Join(s.config.WorkingDirectory, "deployments", uid)
 	if err = os.MkdirAll(uploadPath, 0775); err != nil {
 		log.Panicf("%+v", err)
 	}
@@ -108,6 +73,7 @@ func (s *Server) newDeploymentHandler(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		log.Panicf("%+v", err)
 	}
+	defer file.Close()
 
 	_, err = io.Copy(file, r.Body)
 	if err != nil {
@@ -119,7 +85,7 @@ func (s *Server) newDeploymentHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	zipReader, err := zip.OpenReader(file.Name())
 	if err != nil {
-		log.Panicf("%+v", err)
+		return "", errors.Wrap(err, "invalid CSAR archive")
 	}
 	defer zipReader.Close()
 
@@ -135,7 +101,9 @@ func (s *Server) newDeploymentHandler(w http.ResponseWriter, r *http.Request) {
 			}
 			continue
 		}
-		extractFile(f, fPath)
+		if err = extractFile(f, fPath); err != nil {
+			return "", err
+		}
 	}
 
 	patterns := []struct {
@@ -153,14 +121,81 @@ func (s *Server) newDeploymentHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	if len(yamlList) != 1 {
-		log.Panic("One and only one YAML (.yml or .yaml) file should be present at the root of deployment archive")
+		return "", errors.New("One and only one YAML (.yml or .yaml) file should be present at the root of deployment archive")
 	}
+	return yamlList[0], nil
+}
 
-	if err := deployments.StoreDeploymentDefinition(r.Context(), s.consulClient.KV(), uid, yamlList[0]); err != nil {
+func (s *Server) newDeploymentHandler(w http.ResponseWriter, r *http.Request) {
+
+	data, restErr := newTaskData(r)
+	if restErr != nil {
+		writeError(w, r, restErr)
+		return
+	}
+
+	var uid string
+	var isUpdate bool
+	if r.Method == http.MethodPut {
+		var params httprouter.Params
+		ctx := r.Context()
+		params = ctx.Value(paramsLookupKey).(httprouter.Params)
+		id := params.ByName("id")
+		id, err := url.QueryUnescape(id)
+		if err != nil {
+			log.Panicf("%v", errors.Wrapf(err, "Failed to unescape given deployment id %q", id))
+		}
+		matched, err := regexp.MatchString(YorcDeploymentIDPattern, id)
+		if err != nil {
+			log.Panicf("%v", errors.Wrapf(err, "Failed to parse given deployment id %q", id))
+		}
+		if !matched {
+			writeError(w, r, newBadRequestError(errors.Errorf("Deployment id should respect the following format: %q", YorcDeploymentIDPattern)))
+			return
+		}
+		// Do not impose a max id length as it doesn't have a concrete impact for now
+		// if len(id) > YorcDeploymentIDMaxLength {
+		// 	writeError(w, r, newBadRequestError(errors.Errorf("Deployment id should be less than %d characters (actual size %d)", YorcDeploymentIDMaxLength, len(id))))
+		// 	return
+		// }
+		dExits, err := deployments.DoesDeploymentExists(s.consulClient.KV(), id)
+		if err != nil {
+			log.Panicf("%v", err)
+		}
+		if dExits {
+			if !s.checkDeploymentCanBeUpdated(w, r, id) {
+				return
+			}
+			isUpdate = true
+		}
+		uid = id
+	} else {
+		uid = fmt.Sprint(uuid.NewV4())
+	}
+	log.Printf("Analyzing deployment %s\n", uid)
+
+	if isUpdate {
+		s.updateDeployment(w, r, uid, data)
+		return
+	}
+
+	defPath, err := extractCSAR(r, filepath.Join(s.config.WorkingDirectory, "deployments", uid))
+	if err != nil {
+		writeError(w, r, newBadRequestError(err))
+		return
+	}
+
+	if err := deployments.StoreDeploymentDefinition(r.Context(), s.consulClient.KV(), uid, defPath); err != nil {
 		log.Debugf("ERROR: %+v", err)
 		log.Panic(err)
 	}
-	taskID, err := s.tasksCollector.RegisterTask(uid, tasks.Deploy)
+	if _, ok := r.URL.Query()["rollbackOnFailure"]; ok {
+		log.Debugf("Rollback on failure requested for deployment %s", uid)
+		if err := deployments.SetRollbackOnFailure(s.consulClient.KV(), uid, true); err != nil {
+			log.Panic(err)
+		}
+	}
+	taskID, err := s.tasksCollector.RegisterTaskWithData(uid, tasks.Deploy, data)
 	if err != nil {
 		if ok, _ := tasks.IsAnotherLivingTaskAlreadyExistsError(err); ok {
 			writeError(w, r, newBadRequestError(err))
@@ -173,6 +208,96 @@ func (s *Server) newDeploymentHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusCreated)
 }
 
+// checkDeploymentCanBeUpdated checks that an existing deployment is deployed and not currently processed by a task.
+//
+// If it is not the case an error is written in the response and false is returned.
+func (s *Server) checkDeploymentCanBeUpdated(w http.ResponseWriter, r *http.Request, id string) bool {
+	kv := s.consulClient.KV()
+	status, err := deployments.GetDeploymentStatus(kv, id)
+	if err != nil {
+		log.Panicf("%v", err)
+	}
+	if status != deployments.DEPLOYED && status != deployments.UPDATE_FAILED {
+		mess := fmt.Sprintf("Deployment with id %q already exists and can't be updated while its status is %q", id, status.String())
+		log.Debugf("[ERROR]: %s", mess)
+		writeError(w, r, newConflictRequest(mess))
+		return false
+	}
+	hasLivingTask, livingTaskID, livingTaskStatus, err := tasks.TargetHasLivingTasks(kv, id)
+	if err != nil {
+		log.Panicf("%v", err)
+	}
+	if hasLivingTask {
+		writeError(w, r, newBadRequestError(errors.Errorf("Deployment with id %q can't be updated as task %q is %s", id, livingTaskID, strings.ToLower(livingTaskStatus))))
+		return false
+	}
+	return true
+}
+
+// updateDeployment replaces the definition of an existing deployment and submits a task applying the changes
+//
+// The update task is registered first so no other task could be submitted for this deployment meanwhile, and the new
+// definition is applied while the task is not yet dispatched. If the definition could not be applied the task is deleted.
+// The new archive is extracted into a dedicated directory that replaces the deployment overlay only once the
+// new definition is stored. Files of the previous overlay are kept until the update task uninstalled removed nodes.
+func (s *Server) updateDeployment(w http.ResponseWriter, r *http.Request, id string, data map[string]string) {
+	log.Printf("Updating deployment %s\n", id)
+	deploymentPath := filepath.Join(s.config.WorkingDirectory, "deployments", id)
+	updatePath := filepath.Join(deploymentPath, "update")
+	var topologyUpdate *deployments.TopologyUpdate
+	var csarErr error
+	taskID, err := s.tasksCollector.RegisterTaskWithPreparation(id, tasks.Update, data, func(string) (map[string]string, error) {
+		if err := os.RemoveAll(updatePath); err != nil {
+			return nil, errors.Wrapf(err, "failed to clean update directory of deployment %q", id)
+		}
+		defer func() {
+			if err := os.RemoveAll(updatePath); err != nil {
+				log.Printf("Failed to remove update directory %q: %v", updatePath, err)
+			}
+		}()
+		defPath, err := extractCSAR(r, updatePath)
+		if err != nil {
+			csarErr = err
+			return nil, err
+		}
+
+		topologyUpdate, err = deployments.UpdateDeploymentDefinition(r.Context(), s.consulClient.KV(), id, defPath)
+		if err != nil {
+			return nil, err
+		}
+		err = deployments.ReplaceOverlay(s.config.WorkingDirectory, id, filepath.Join(updatePath, "overlay"))
+		if err != nil {
+			return nil, err
+		}
+		err = os.Rename(filepath.Join(updatePath, "deployment.zip"), filepath.Join(deploymentPath, "deployment.zip"))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to replace archive of deployment %q", id)
+		}
+		return map[string]string{
+			tasks.UpdateAddedNodesDataName:    strings.Join(topologyUpdate.AddedNodes, ","),
+			tasks.UpdateRemovedNodesDataName:  strings.Join(topologyUpdate.RemovedNodes, ","),
+			tasks.UpdateModifiedNodesDataName: strings.Join(topologyUpdate.ModifiedNodes, ","),
+		}, nil
+	})
+	if err != nil {
+		if ok, _ := tasks.IsAnotherLivingTaskAlreadyExistsError(err); ok {
+			writeError(w, r, newBadRequestError(err))
+			return
+		}
+		if csarErr != nil {
+			writeError(w, r, newBadRequestError(csarErr))
+			return
+		}
+		log.Debugf("ERROR: %+v", err)
+		log.Panic(err)
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/deployments/%s/tasks/%s", id, taskID))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	encodeJSONResponse(w, r, DeploymentUpdate{TopologyUpdate: *topologyUpdate})
+}
+
 func (s *Server) deleteDeploymentHandler(w http.ResponseWriter, r *http.Request) {
 	var params httprouter.Params
 	ctx := r.Context()
@@ -188,6 +313,12 @@ func (s *Server) deleteDeploymentHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	data, restErr := newTaskData(r)
+	if restErr != nil {
+		writeError(w, r, restErr)
+		return
+	}
+
 	var taskType tasks.TaskType
 	if _, ok := r.URL.Query()["purge"]; ok {
 		log.Debugf("A purge task on deployment:%s has been requested", id)
@@ -207,7 +338,7 @@ func (s *Server) deleteDeploymentHandler(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	if taskID, err := s.tasksCollector.RegisterTask(id, taskType); err != nil {
+	if taskID, err := s.tasksCollector.RegisterTaskWithData(id, taskType, data); err != nil {
 		log.Debugln("register task err" + err.Error())
 		if ok, _ := tasks.IsAnotherLivingTaskAlreadyExistsError(err); ok {
 			log.Debugln("another task is living")
@@ -256,6 +387,7 @@ func (s *Server) getDeploymentHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	links = append(links, s.listOutputsLinks(id)...)
+	links = append(links, s.listPoliciesLinks(id)...)
 
 	deployment.Links = links
 	encodeJSONResponse(w, r, deployment)
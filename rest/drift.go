@@ -0,0 +1,118 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/tasks"
+)
+
+// checkDeploymentForDrift checks that drifts could be detected or reconciled on a deployment
+func (s *Server) checkDeploymentForDrift(w http.ResponseWriter, r *http.Request, deploymentID string) bool {
+	status, err := deployments.GetDeploymentStatus(s.consulClient.KV(), deploymentID)
+	if err != nil {
+		if deployments.IsDeploymentNotFoundError(err) {
+			writeError(w, r, errNotFound)
+			return false
+		}
+		log.Panic(err)
+	}
+	if status != deployments.DEPLOYED {
+		writeError(w, r, newBadRequestError(errors.Errorf("Drifts can only be checked on a deployed application, current deployment status is %q", status.String())))
+		return false
+	}
+	return true
+}
+
+func (s *Server) postDriftHandler(w http.ResponseWriter, r *http.Request) {
+	var params httprouter.Params
+	ctx := r.Context()
+	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	deploymentID := params.ByName("id")
+	log.Debugf("Posting query for detecting drifts of deployment %q", deploymentID)
+
+	if !s.checkDeploymentForDrift(w, r, deploymentID) {
+		return
+	}
+	// Drift queries are not registered on the deployment itself so they are not serialized with deployment tasks,
+	// refuse to detect drifts while the deployment is changed by another task
+	hasLivingTask, livingTaskID, livingTaskStatus, err := tasks.TargetHasLivingTasks(s.consulClient.KV(), deploymentID)
+	if err != nil {
+		log.Panic(err)
+	}
+	if hasLivingTask {
+		writeError(w, r, newBadRequestError(errors.Errorf("Drifts of deployment with id %q can't be detected as task %q is %s", deploymentID, livingTaskID, strings.ToLower(livingTaskStatus))))
+		return
+	}
+
+	data, restErr := newTaskData(r)
+	if restErr != nil {
+		writeError(w, r, restErr)
+		return
+	}
+
+	// Build a task targetID to describe query
+	targetID := fmt.Sprintf("drift:%s", deploymentID)
+	taskID, err := s.tasksCollector.RegisterTaskWithData(targetID, tasks.Query, data)
+	if err != nil {
+		// If any identical query is running : we provide the related task ID
+		if ok, currTaskID := tasks.IsAnotherLivingTaskAlreadyExistsError(err); ok {
+			w.Header().Set("Location", fmt.Sprintf("/drift/%s/tasks/%s", deploymentID, currTaskID))
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		log.Panic(err)
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/drift/%s/tasks/%s", deploymentID, taskID))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) newReconcileHandler(w http.ResponseWriter, r *http.Request) {
+	var params httprouter.Params
+	ctx := r.Context()
+	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	deploymentID := params.ByName("id")
+
+	if !s.checkDeploymentForDrift(w, r, deploymentID) {
+		return
+	}
+
+	data, restErr := newTaskData(r)
+	if restErr != nil {
+		writeError(w, r, restErr)
+		return
+	}
+
+	taskID, err := s.tasksCollector.RegisterTaskWithData(deploymentID, tasks.Reconcile, data)
+	if err != nil {
+		if ok, _ := tasks.IsAnotherLivingTaskAlreadyExistsError(err); ok {
+			writeError(w, r, newBadRequestError(err))
+			return
+		}
+		log.Panic(err)
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/deployments/%s/tasks/%s", deploymentID, taskID))
+	w.WriteHeader(http.StatusCreated)
+}
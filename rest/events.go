@@ -16,7 +16,9 @@ package rest
 
 import (
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"encoding/json"
@@ -27,6 +29,10 @@ import (
 	"github.com/ystia/yorc/log"
 )
 
+// streamWaitTime is the default duration of the blocking queries used to stream events and logs.
+// A keep-alive is sent to the client each time it expires without new entries.
+const streamWaitTime = 30 * time.Second
+
 func (s *Server) pollEvents(w http.ResponseWriter, r *http.Request) {
 	var params httprouter.Params
 	ctx := r.Context()
@@ -42,25 +48,33 @@ func (s *Server) pollEvents(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	values := r.URL.Query()
-	var err error
-	var waitIndex uint64 = 1
-	timeout := 5 * time.Minute
-	if idx := values.Get("index"); idx != "" {
-		if waitIndex, err = strconv.ParseUint(idx, 10, 64); err != nil {
-			writeError(w, r, newBadRequestParameter("index", err))
-			return
-		}
+	waitIndex, timeout, ok := parseWaitParameters(w, r)
+	if !ok {
+		return
 	}
+	filter := parseEventsFilter(r.URL.Query())
 
-	if dur := values.Get("wait"); dur != "" {
-		if timeout, err = time.ParseDuration(dur); err != nil {
-			writeError(w, r, newBadRequestParameter("index", err))
-			return
-		}
-		if timeout > 10*time.Minute {
-			timeout = 10 * time.Minute
-		}
+	if isEventStreamRequest(r) {
+		// If id parameter not set (id == ""), StatusEvents returns events for all the deployments
+		s.streamEntries(w, r, "status", waitIndex, func(waitIndex uint64) ([]json.RawMessage, uint64, error) {
+			evts, lastIdx, err := events.StatusEvents(kv, id, waitIndex, timeout)
+			if err != nil {
+				return nil, lastIdx, err
+			}
+			entries := make([]json.RawMessage, 0, len(evts))
+			for _, evt := range evts {
+				if !filter.MatchStatusUpdate(evt) {
+					continue
+				}
+				b, err := json.Marshal(evt)
+				if err != nil {
+					return nil, lastIdx, err
+				}
+				entries = append(entries, b)
+			}
+			return entries, lastIdx, nil
+		})
+		return
 	}
 
 	// If id parameter not set (id == ""), StatusEvents returns events for all the deployments
@@ -68,6 +82,15 @@ func (s *Server) pollEvents(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		log.Panicf("Can't retrieve events: %v", err)
 	}
+	if !filter.IsEmpty() {
+		filtered := make([]events.StatusUpdate, 0, len(evts))
+		for _, evt := range evts {
+			if filter.MatchStatusUpdate(evt) {
+				filtered = append(filtered, evt)
+			}
+		}
+		evts = filtered
+	}
 
 	eventsCollection := EventsCollection{Events: evts, LastIndex: lastIdx}
 	w.Header().Add(YorcIndexHeader, strconv.FormatUint(lastIdx, 10))
@@ -88,40 +111,165 @@ func (s *Server) pollLogs(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
+
+	waitIndex, timeout, ok := parseWaitParameters(w, r)
+	if !ok {
+		return
+	}
+	filter := parseEventsFilter(r.URL.Query())
+
+	if isEventStreamRequest(r) {
+		// If id parameter not set (id == ""), LogsEvents returns logs for all the deployments
+		s.streamEntries(w, r, "log", waitIndex, func(waitIndex uint64) ([]json.RawMessage, uint64, error) {
+			logs, lastIdx, err := events.LogsEvents(kv, id, waitIndex, timeout)
+			if err != nil {
+				return nil, lastIdx, err
+			}
+			logs, err = filterLogs(filter, logs)
+			return logs, lastIdx, err
+		})
+		return
+	}
+
+	// If id parameter not set (id == ""), LogsEvents returns logs for all the deployments
+	logs, lastIdx, err := events.LogsEvents(kv, id, waitIndex, timeout)
+	if err != nil {
+		log.Panicf("Can't retrieve events: %v", err)
+	}
+	logs, err = filterLogs(filter, logs)
+	if err != nil {
+		log.Panicf("Can't filter logs: %v", err)
+	}
+
+	logCollection := LogsCollection{Logs: logs, LastIndex: lastIdx}
+	w.Header().Add(YorcIndexHeader, strconv.FormatUint(lastIdx, 10))
+	encodeJSONResponse(w, r, logCollection)
+}
+
+// parseWaitParameters parses the index and wait query parameters common to events and logs endpoints.
+//
+// When streaming, the Last-Event-ID header sent by clients on reconnection takes precedence over the index parameter.
+// If parameters are invalid an error is written to the response and ok is false.
+func parseWaitParameters(w http.ResponseWriter, r *http.Request) (waitIndex uint64, timeout time.Duration, ok bool) {
 	values := r.URL.Query()
 	var err error
-	var waitIndex uint64 = 1
-	timeout := 5 * time.Minute
-	if idx := values.Get("index"); idx != "" {
+	waitIndex = 1
+	timeout = 5 * time.Minute
+	stream := isEventStreamRequest(r)
+	if stream {
+		timeout = streamWaitTime
+	}
+	idx := values.Get("index")
+	if lastEventID := r.Header.Get("Last-Event-ID"); stream && lastEventID != "" {
+		idx = lastEventID
+	}
+	if idx != "" {
 		if waitIndex, err = strconv.ParseUint(idx, 10, 64); err != nil {
 			writeError(w, r, newBadRequestParameter("index", err))
-			return
+			return 0, 0, false
 		}
 	}
 
 	if dur := values.Get("wait"); dur != "" {
 		if timeout, err = time.ParseDuration(dur); err != nil {
-			writeError(w, r, newBadRequestParameter("index", err))
-			return
+			writeError(w, r, newBadRequestParameter("wait", err))
+			return 0, 0, false
 		}
 		if timeout > 10*time.Minute {
 			timeout = 10 * time.Minute
 		}
 	}
+	return waitIndex, timeout, true
+}
 
-	var logs []json.RawMessage
-	var lastIdx uint64
+// parseEventsFilter builds a filter from the node, task, workflow and level query parameters.
+//
+// Each parameter may be repeated or contain a comma-separated list of values.
+func parseEventsFilter(values url.Values) events.Filter {
+	getValues := func(param string) []string {
+		var res []string
+		for _, v := range values[param] {
+			for _, s := range strings.Split(v, ",") {
+				if s = strings.TrimSpace(s); s != "" {
+					res = append(res, s)
+				}
+			}
+		}
+		return res
+	}
+	return events.Filter{
+		Nodes:     getValues("node"),
+		Tasks:     getValues("task"),
+		Workflows: getValues("workflow"),
+		Levels:    getValues("level"),
+	}
+}
 
-	// If id parameter not set (id == ""), LogsEvents returns logs for all the deployments
-	logs, idx, err := events.LogsEvents(kv, id, waitIndex, timeout)
-	if err != nil {
-		log.Panicf("Can't retrieve events: %v", err)
+func filterLogs(filter events.Filter, logs []json.RawMessage) ([]json.RawMessage, error) {
+	if filter.IsEmpty() {
+		return logs, nil
 	}
-	lastIdx = idx
+	filtered := make([]json.RawMessage, 0, len(logs))
+	for _, l := range logs {
+		match, err := filter.MatchLog(l)
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			filtered = append(filtered, l)
+		}
+	}
+	return filtered, nil
+}
 
-	logCollection := LogsCollection{Logs: logs, LastIndex: lastIdx}
-	w.Header().Add(YorcIndexHeader, strconv.FormatUint(lastIdx, 10))
-	encodeJSONResponse(w, r, logCollection)
+// streamEntries pushes entries returned by successive calls to fetch as Server-Sent Events
+// until the client closes the connection.
+//
+// fetch is expected to block until new entries are available after the given index or its wait time expires.
+// The id of the last event of each batch is set to the Consul index the batch was retrieved at,
+// so clients may resume the stream using the Last-Event-ID header.
+func (s *Server) streamEntries(w http.ResponseWriter, r *http.Request, eventName string, waitIndex uint64, fetch func(waitIndex uint64) ([]json.RawMessage, uint64, error)) {
+	stream, err := newEventStream(w)
+	if err != nil {
+		writeError(w, r, newInternalServerError(err))
+		return
+	}
+	ctx := r.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		entries, lastIdx, err := fetch(waitIndex)
+		if err != nil {
+			// Headers are already sent, all we can do is to close the stream
+			log.Printf("Failed to retrieve %s entries to stream: %v", eventName, err)
+			return
+		}
+		if lastIdx < waitIndex {
+			// Consul index went backward, resetting it as recommended by Consul
+			lastIdx = 0
+		}
+		if len(entries) == 0 {
+			err = stream.keepAlive()
+		}
+		for i, entry := range entries {
+			var id string
+			if i == len(entries)-1 {
+				id = strconv.FormatUint(lastIdx, 10)
+			}
+			if err = stream.send(id, eventName, entry); err != nil {
+				break
+			}
+		}
+		if err != nil {
+			log.Debugf("Closing %s stream: %v", eventName, err)
+			return
+		}
+		stream.flush()
+		waitIndex = lastIdx
+	}
 }
 
 func (s *Server) headEventsIndex(w http.ResponseWriter, r *http.Request) {
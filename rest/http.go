@@ -139,33 +139,44 @@ func (s *Server) registerHandlers() {
 	commonHandlers := alice.New(telemetryHandler, loggingHandler, recoverHandler)
 	s.router.Post("/deployments", commonHandlers.Append(contentTypeHandler("application/zip")).ThenFunc(s.newDeploymentHandler))
 	s.router.Put("/deployments/:id", commonHandlers.Append(contentTypeHandler("application/zip")).ThenFunc(s.newDeploymentHandler))
+	s.router.Post("/validations", commonHandlers.Append(contentTypeHandler("application/zip"), acceptHandler("application/json")).ThenFunc(s.newValidationHandler))
 	s.router.Delete("/deployments/:id", commonHandlers.ThenFunc(s.deleteDeploymentHandler))
 	s.router.Get("/deployments/:id", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getDeploymentHandler))
 	s.router.Get("/deployments", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listDeploymentsHandler))
-	s.router.Get("/deployments/:id/events", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.pollEvents))
-	s.router.Get("/events", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.pollEvents))
+	s.router.Get("/deployments/:id/events", commonHandlers.Append(acceptHandler("application/json", "text/event-stream")).ThenFunc(s.pollEvents))
+	s.router.Get("/events", commonHandlers.Append(acceptHandler("application/json", "text/event-stream")).ThenFunc(s.pollEvents))
 	s.router.Head("/deployments/:id/events", commonHandlers.ThenFunc(s.headEventsIndex))
 	s.router.Head("/events", commonHandlers.ThenFunc(s.headEventsIndex))
-	s.router.Get("/deployments/:id/logs", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.pollLogs))
-	s.router.Get("/logs", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.pollLogs))
+	s.router.Get("/deployments/:id/logs", commonHandlers.Append(acceptHandler("application/json", "text/event-stream")).ThenFunc(s.pollLogs))
+	s.router.Get("/logs", commonHandlers.Append(acceptHandler("application/json", "text/event-stream")).ThenFunc(s.pollLogs))
 	s.router.Head("/deployments/:id/logs", commonHandlers.ThenFunc(s.headLogsEventsIndex))
 	s.router.Head("/logs", commonHandlers.ThenFunc(s.headLogsEventsIndex))
 	s.router.Get("/deployments/:id/nodes/:nodeName", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getNodeHandler))
 	s.router.Get("/deployments/:id/nodes/:nodeName/instances/:instanceId", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getNodeInstanceHandler))
 	s.router.Get("/deployments/:id/outputs", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listOutputsHandler))
 	s.router.Get("/deployments/:id/outputs/:opt", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getOutputHandler))
+	s.router.Get("/deployments/:id/policies", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listPoliciesHandler))
+	s.router.Get("/deployments/:id/policies/:policyName", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getPolicyHandler))
 	s.router.Get("/deployments/:id/tasks/:taskId", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getTaskHandler))
 	s.router.Get("/deployments/:id/tasks/:taskId/steps", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getTaskStepsHandler))
 	s.router.Delete("/deployments/:id/tasks/:taskId", commonHandlers.ThenFunc(s.cancelTaskHandler))
 	s.router.Put("/deployments/:id/tasks/:taskId", commonHandlers.ThenFunc(s.resumeTaskHandler))
 	s.router.Put("/deployments/:id/tasks/:taskId/steps/:stepId", commonHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.updateTaskStepStatusHandler))
 	s.router.Post("/deployments/:id/scale/:nodeName", commonHandlers.ThenFunc(s.scaleHandler))
+	s.router.Post("/deployments/:id/groups/:groupName/scale", commonHandlers.ThenFunc(s.scaleGroupHandler))
 	s.router.Get("/deployments/:id/nodes/:nodeName/instances/:instanceId/attributes", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getNodeInstanceAttributesListHandler))
 	s.router.Get("/deployments/:id/nodes/:nodeName/instances/:instanceId/attributes/:attributeName", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getNodeInstanceAttributeHandler))
 	s.router.Post("/deployments/:id/custom", commonHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.newCustomCommandHandler))
 	s.router.Post("/deployments/:id/workflows/:workflowName", commonHandlers.ThenFunc(s.newWorkflowHandler))
 	s.router.Get("/deployments/:id/workflows/:workflowName", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getWorkflowHandler))
+	s.router.Get("/deployments/:id/workflows/:workflowName/plan", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getWorkflowPlanHandler))
 	s.router.Get("/deployments/:id/workflows", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listWorkflowsHandler))
+	s.router.Post("/deployments/:id/schedules", commonHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.newScheduleHandler))
+	s.router.Get("/deployments/:id/schedules", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listSchedulesHandler))
+	s.router.Get("/deployments/:id/schedules/:scheduleId", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getScheduleHandler))
+	s.router.Put("/deployments/:id/schedules/:scheduleId", commonHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.updateScheduleHandler))
+	s.router.Delete("/deployments/:id/schedules/:scheduleId", commonHandlers.ThenFunc(s.deleteScheduleHandler))
+	s.router.Post("/deployments/:id/reconcile", commonHandlers.ThenFunc(s.newReconcileHandler))
 
 	s.router.Get("/registry/delegates", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listRegistryDelegatesHandler))
 	s.router.Get("/registry/implementations", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listRegistryImplementationsHandler))
@@ -178,6 +189,11 @@ func (s *Server) registerHandlers() {
 	s.router.Delete("/infra_usage/:infraName/tasks/:taskId", commonHandlers.ThenFunc(s.deleteTaskQueryHandler))
 	s.router.Get("/infra_usage", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listTaskQueryHandler))
 
+	s.router.Post("/drift/:id", commonHandlers.ThenFunc(s.postDriftHandler))
+	s.router.Get("/drift/:id/tasks/:taskId", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getTaskQueryHandler))
+	s.router.Delete("/drift/:id/tasks/:taskId", commonHandlers.ThenFunc(s.deleteTaskQueryHandler))
+	s.router.Get("/drift", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listTaskQueryHandler))
+
 	s.router.Put("/hosts_pool/:host", commonHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.newHostInPool))
 	s.router.Patch("/hosts_pool/:host", commonHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.updateHostInPool))
 	s.router.Delete("/hosts_pool/:host", commonHandlers.ThenFunc(s.deleteHostInPool))
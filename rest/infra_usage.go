@@ -39,9 +39,15 @@ func (s *Server) postInfraUsageHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	data, restErr := newTaskData(r)
+	if restErr != nil {
+		writeError(w, r, restErr)
+		return
+	}
+
 	// Build a task targetID to describe query
 	targetID := fmt.Sprintf("infra_usage:%s", infraName)
-	taskID, err := s.tasksCollector.RegisterTask(targetID, tasks.Query)
+	taskID, err := s.tasksCollector.RegisterTaskWithData(targetID, tasks.Query, data)
 	if err != nil {
 		// If any identical query is running : we provide the related task ID
 		if ok, currTaskID := tasks.IsAnotherLivingTaskAlreadyExistsError(err); ok {
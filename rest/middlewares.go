@@ -17,6 +17,7 @@ package rest
 import (
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/armon/go-metrics"
@@ -50,11 +51,19 @@ func loggingHandler(next http.Handler) http.Handler {
 	return http.HandlerFunc(fn)
 }
 
-func acceptHandler(cType string) func(http.Handler) http.Handler {
+func acceptHandler(cTypes ...string) func(http.Handler) http.Handler {
 	m := func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
-			if r.Header.Get("Accept") != cType {
-				writeError(w, r, newNotAcceptableError(cType))
+			accept := r.Header.Get("Accept")
+			var accepted bool
+			for _, cType := range cTypes {
+				if accept == cType {
+					accepted = true
+					break
+				}
+			}
+			if !accepted {
+				writeError(w, r, newNotAcceptableError(strings.Join(cTypes, "' or '")))
 				return
 			}
 
@@ -0,0 +1,80 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// eventStreamContentType is the media type of Server-Sent Events streams
+const eventStreamContentType = "text/event-stream"
+
+func isEventStreamRequest(r *http.Request) bool {
+	return r.Header.Get("Accept") == eventStreamContentType
+}
+
+// eventStream writes Server-Sent Events to an http response
+type eventStream struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// newEventStream sends the response headers of a Server-Sent Events stream
+func newEventStream(w http.ResponseWriter) (*eventStream, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, errors.New("streaming is not supported by the underlying http response writer")
+	}
+	w.Header().Set("Content-Type", eventStreamContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	return &eventStream{w: w, flusher: flusher}, nil
+}
+
+// send writes a single event to the stream.
+//
+// An empty id means that the event does not update the client last event ID.
+// Data is expected to be a single line as produced by json.Marshal.
+func (s *eventStream) send(id, event string, data []byte) error {
+	var msg string
+	if id != "" {
+		msg += "id: " + id + "\n"
+	}
+	if event != "" {
+		msg += "event: " + event + "\n"
+	}
+	// Just in case data contains line breaks
+	for _, line := range strings.Split(string(data), "\n") {
+		msg += "data: " + line + "\n"
+	}
+	_, err := fmt.Fprint(s.w, msg+"\n")
+	return errors.Wrap(err, "failed to write event to stream")
+}
+
+// keepAlive writes a comment line allowing to detect closed connections and to prevent proxies to time out
+func (s *eventStream) keepAlive() error {
+	_, err := fmt.Fprint(s.w, ": keep-alive\n\n")
+	return errors.Wrap(err, "failed to write keep-alive to stream")
+}
+
+func (s *eventStream) flush() {
+	s.flusher.Flush()
+}
@@ -19,8 +19,11 @@ package rest
 import (
 	"bytes"
 	"encoding/json"
+	"time"
 
+	"github.com/ystia/yorc/deployments"
 	"github.com/ystia/yorc/events"
+	"github.com/ystia/yorc/prov"
 	"github.com/ystia/yorc/prov/hostspool"
 	"github.com/ystia/yorc/registry"
 	"github.com/ystia/yorc/tosca"
@@ -45,6 +48,10 @@ const (
 	LinkRelWorkflow string = "workflow"
 	// LinkRelHost defines the AtomLink Rel attribute for relationships of the "host" (for hostspool)
 	LinkRelHost string = "host"
+	// LinkRelSchedule defines the AtomLink Rel attribute for relationships of the "schedule"
+	LinkRelSchedule string = "schedule"
+	// LinkRelPolicy defines the AtomLink Rel attribute for relationships of the "policy"
+	LinkRelPolicy string = "policy"
 )
 
 const (
@@ -75,13 +82,28 @@ func newAtomLink(rel, href string) AtomLink {
 
 // Deployment is the representation of a Yorc deployment
 //
-// Deployment's links may be of type LinkRelSelf, LinkRelNode, LinkRelTask, LinkRelOutput.
+// Deployment's links may be of type LinkRelSelf, LinkRelNode, LinkRelTask, LinkRelOutput, LinkRelPolicy.
 type Deployment struct {
 	ID     string     `json:"id"`
 	Status string     `json:"status"`
 	Links  []AtomLink `json:"links"`
 }
 
+// DeploymentUpdate is the representation of the changes applied by a deployment update
+type DeploymentUpdate struct {
+	deployments.TopologyUpdate
+}
+
+// ValidationReport is the representation of the result of a CSAR validation
+type ValidationReport struct {
+	deployments.ValidationReport
+}
+
+// DriftReport is the representation of the result of a drift detection, it is the result set of a drift query task
+type DriftReport struct {
+	prov.DriftReport
+}
+
 // Output is the representation of a deployment output
 type Output struct {
 	Name  string `json:"name"`
@@ -129,6 +151,22 @@ type OutputsCollection struct {
 	Outputs []AtomLink `json:"outputs,omitempty"`
 }
 
+// Policy is the representation of a TOSCA policy of a deployment
+type Policy struct {
+	Name        string            `json:"name"`
+	Type        string            `json:"type"`
+	Description string            `json:"description,omitempty"`
+	Targets     []string          `json:"targets,omitempty"`
+	Properties  map[string]string `json:"properties,omitempty"`
+}
+
+// PoliciesCollection is a collection of deployment's policies links
+//
+// Links are all of type LinkRelPolicy.
+type PoliciesCollection struct {
+	Policies []AtomLink `json:"policies,omitempty"`
+}
+
 // Task is the representation of a Yorc' task
 type Task struct {
 	ID        string          `json:"id"`
@@ -262,3 +300,42 @@ type RegistryVaultsCollection struct {
 type RegistryInfraUsageCollectorsCollection struct {
 	InfraUsageCollectors []registry.InfraUsageCollector `json:"infrastructure_usage_collectors"`
 }
+
+// ScheduleRequest is the representation of a request to create or update a schedule
+//
+// TaskType is either "CustomWorkflow" or "CustomCommand". WorkflowName and ContinueOnError are used by custom
+// workflows while NodeName, CustomCommandName and Inputs are used by custom commands.
+type ScheduleRequest struct {
+	Cron              string            `json:"cron"`
+	TaskType          string            `json:"task_type"`
+	WorkflowName      string            `json:"workflow_name,omitempty"`
+	ContinueOnError   bool              `json:"continue_on_error,omitempty"`
+	NodeName          string            `json:"node,omitempty"`
+	CustomCommandName string            `json:"name,omitempty"`
+	Inputs            map[string]string `json:"inputs,omitempty"`
+}
+
+// SchedulesCollection is a collection of schedules links
+//
+// Links are all of type LinkRelSchedule.
+type SchedulesCollection struct {
+	Schedules []AtomLink `json:"schedules"`
+}
+
+// Schedule is the representation of a deployment schedule
+//
+// Links are of type LinkRelSelf and LinkRelDeployment.
+type Schedule struct {
+	ID string `json:"id"`
+	ScheduleRequest
+	NextRun time.Time              `json:"next_run"`
+	History []ScheduleHistoryEntry `json:"history"`
+	Links   []AtomLink             `json:"links"`
+}
+
+// ScheduleHistoryEntry is the representation of a task registration attempt by a schedule
+type ScheduleHistoryEntry struct {
+	Date   time.Time `json:"date"`
+	TaskID string    `json:"task_id,omitempty"`
+	Error  string    `json:"error,omitempty"`
+}
@@ -0,0 +1,48 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/log"
+)
+
+func (s *Server) newValidationHandler(w http.ResponseWriter, r *http.Request) {
+	uploadPath := filepath.Join(s.config.WorkingDirectory, "validations", fmt.Sprint(uuid.NewV4()))
+	defer func() {
+		if err := os.RemoveAll(uploadPath); err != nil {
+			log.Printf("Failed to remove validation directory %q: %v", uploadPath, err)
+		}
+	}()
+	defPath, err := extractCSAR(r, uploadPath)
+	if err != nil {
+		// An invalid archive is a validation error of the submitted definition
+		report := deployments.ValidationReport{Errors: []deployments.ValidationMessage{{Message: err.Error()}}}
+		encodeJSONResponse(w, r, ValidationReport{report})
+		return
+	}
+
+	report, err := deployments.ValidateDeploymentDefinition(r.Context(), s.consulClient.KV(), defPath)
+	if err != nil {
+		log.Panic(err)
+	}
+	encodeJSONResponse(w, r, ValidationReport{*report})
+}
@@ -27,7 +27,9 @@ import (
 	"github.com/ystia/yorc/config"
 	"github.com/ystia/yorc/helper/consulutil"
 	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/prov/hostspool"
 	"github.com/ystia/yorc/rest"
+	"github.com/ystia/yorc/tasks/scheduling"
 	"github.com/ystia/yorc/tasks/workflow"
 )
 
@@ -63,6 +65,8 @@ func RunServer(configuration config.Configuration, shutdownCh chan struct{}) err
 
 	dispatcher := workflow.NewDispatcher(configuration, shutdownCh, client, &wg)
 	go dispatcher.Run()
+	scheduling.NewScheduler(client, shutdownCh, &wg).Start()
+	hostspool.StartLeasesReconciliation(client, shutdownCh, &wg)
 	var httpServer *rest.Server
 	pm := newPluginManager()
 	defer pm.cleanup()
@@ -52,6 +52,41 @@ func (c *Collector) RegisterTaskWithData(targetID string, taskType TaskType, dat
 	return taskID, nil
 }
 
+// RegisterTaskWithPreparation registers a new Task of a given type with some data and runs the given prepare function
+// before the task could be processed.
+//
+// The task is registered before calling prepare so no other task could be registered for the same target meanwhile,
+// but it is not dispatched before prepare returns. Data returned by prepare are added to the task data.
+// If prepare fails the task is deleted and the error returned by prepare is returned.
+func (c *Collector) RegisterTaskWithPreparation(targetID string, taskType TaskType, data map[string]string, prepare func(taskID string) (map[string]string, error)) (string, error) {
+	destroy, lock, taskID, err := c.registerTaskWithoutDestroyLock(targetID, taskType, data)
+	if err != nil {
+		if destroy != nil {
+			destroy(lock, taskID, targetID)
+		}
+		return "", err
+	}
+	kv := c.consulClient.KV()
+	prepData, err := prepare(taskID)
+	for keyM, valM := range prepData {
+		if err != nil {
+			break
+		}
+		_, err = kv.Put(&api.KVPair{Key: path.Join(consulutil.TasksPrefix, taskID, keyM), Value: []byte(valM)}, nil)
+		err = errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if err != nil {
+		// Delete the task before releasing the create lock so it is never dispatched
+		if errDel := DeleteTask(kv, taskID); errDel != nil {
+			log.Printf("Failed to delete task %q (target id %q) after its preparation failure: %+v", taskID, targetID, errDel)
+		}
+		destroy(lock, taskID, targetID)
+		return "", err
+	}
+	destroy(lock, taskID, targetID)
+	return taskID, nil
+}
+
 // RegisterTask register a new Task of a given type.
 //
 // The task id is returned.
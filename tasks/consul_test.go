@@ -78,6 +78,9 @@ func TestRunConsulTasksPackageTests(t *testing.T) {
 		t.Run("testResumeTask", func(t *testing.T) {
 			testResumeTask(t, kv)
 		})
+		t.Run("testTaskRollbackAttempted", func(t *testing.T) {
+			testTaskRollbackAttempted(t, kv)
+		})
 		t.Run("testGetTaskResultSet", func(t *testing.T) {
 			testGetTaskResultSet(t, kv)
 		})
@@ -0,0 +1,63 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tasks
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+)
+
+// PriorityDataName is the name of the task data used to store an explicit task priority
+const PriorityDataName = "priority"
+
+// DefaultTaskPriority returns the priority of a task of the given type when no explicit priority is set
+//
+// Tasks with higher priorities are dispatched first. Short-lived tasks are preferred over long-lived ones.
+func DefaultTaskPriority(taskType TaskType) int {
+	switch taskType {
+	case Query, CustomCommand:
+		return 30
+	case CustomWorkflow, ScaleOut, ScaleIn:
+		return 20
+	case Deploy, Update:
+		return 10
+	}
+	return 0
+}
+
+// GetTaskPriority returns the priority of a task
+//
+// An explicit priority set at task submission takes precedence over the priority configured for this type of task
+// (priorities keys are lower-cased task type names), which itself takes precedence over the default priority.
+func GetTaskPriority(kv *api.KV, taskID string, taskType TaskType, priorities map[string]int) (int, error) {
+	p, err := GetTaskData(kv, taskID, PriorityDataName)
+	if err != nil && !IsTaskDataNotFoundError(err) {
+		return 0, err
+	}
+	if err == nil {
+		priority, err := strconv.Atoi(p)
+		if err != nil {
+			return 0, errors.Wrapf(err, "invalid priority %q for task %q", p, taskID)
+		}
+		return priority, nil
+	}
+	if priority, ok := priorities[strings.ToLower(taskType.String())]; ok {
+		return priority, nil
+	}
+	return DefaultTaskPriority(taskType), nil
+}
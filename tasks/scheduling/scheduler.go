@@ -0,0 +1,209 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduling
+
+import (
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/events"
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/helper/cronutil"
+	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/tasks"
+)
+
+// checkPeriod is the period at which the scheduler leader looks for schedules to run
+const checkPeriod = 5 * time.Second
+
+// A Scheduler registers tasks of deployments schedules when they are due
+//
+// All Yorc servers of a cluster run a Scheduler but only the one elected as leader registers tasks.
+type Scheduler struct {
+	client     *api.Client
+	collector  *tasks.Collector
+	shutdownCh chan struct{}
+	wg         *sync.WaitGroup
+}
+
+// NewScheduler creates a new Scheduler
+func NewScheduler(client *api.Client, shutdownCh chan struct{}, wg *sync.WaitGroup) *Scheduler {
+	return &Scheduler{client: client, collector: tasks.NewCollector(client), shutdownCh: shutdownCh, wg: wg}
+}
+
+// Start runs the scheduler leader election and the schedules checks in background
+func (s *Scheduler) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		nodeName, err := s.client.Agent().NodeName()
+		if err != nil {
+			log.Printf("Failed to start tasks scheduler, can't connect to Consul: %+v", err)
+			return
+		}
+		for {
+			select {
+			case <-s.shutdownCh:
+				return
+			default:
+			}
+			lock, err := s.client.LockOpts(&api.LockOptions{
+				Key:   path.Join(consulutil.SchedulingPrefix, "leader"),
+				Value: []byte(nodeName),
+			})
+			if err != nil {
+				log.Printf("Failed to create tasks scheduler leader lock: %+v", err)
+				time.Sleep(checkPeriod)
+				continue
+			}
+			// Blocks until we are the leader or shutdown
+			leaderCh, err := lock.Lock(s.shutdownCh)
+			if err != nil {
+				log.Printf("Failed to acquire tasks scheduler leader lock: %+v", err)
+				time.Sleep(checkPeriod)
+				continue
+			}
+			if leaderCh == nil {
+				// Shutdown
+				return
+			}
+			log.Printf("This Yorc server is now the tasks scheduler leader")
+			s.runAsLeader(leaderCh)
+			lock.Unlock()
+			lock.Destroy()
+		}
+	}()
+}
+
+func (s *Scheduler) runAsLeader(leaderCh <-chan struct{}) {
+	ticker := time.NewTicker(checkPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.shutdownCh:
+			return
+		case <-leaderCh:
+			log.Printf("This Yorc server lost the tasks scheduler leadership")
+			return
+		case <-ticker.C:
+			if err := s.checkSchedules(time.Now()); err != nil {
+				log.Printf("Failed to check schedules: %+v", err)
+			}
+		}
+	}
+}
+
+// checkSchedules registers the tasks of all the schedules which next run is before the given time
+//
+// Missed occurrences (for instance when no Yorc server was running) result in a single task registration.
+func (s *Scheduler) checkSchedules(now time.Time) error {
+	kv := s.client.KV()
+	depPaths, _, err := kv.Keys(consulutil.DeploymentKVPrefix+"/", "/", nil)
+	if err != nil {
+		return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	for _, depPath := range depPaths {
+		deploymentID := path.Base(depPath)
+		schedulesKeys, _, err := kv.Keys(getSchedulesPath(deploymentID)+"/", "/", nil)
+		if err != nil {
+			return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+		}
+		for _, scheduleKey := range schedulesKeys {
+			if err = s.checkSchedule(kv, deploymentID, path.Base(scheduleKey), now); err != nil {
+				log.Printf("Deployment id: %q, failed to check schedule %q: %+v", deploymentID, path.Base(scheduleKey), err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Scheduler) checkSchedule(kv *api.KV, deploymentID, scheduleID string, now time.Time) error {
+	kvp, _, err := kv.Get(path.Join(getSchedulePath(deploymentID, scheduleID), "next_run"), nil)
+	if err != nil {
+		return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp == nil {
+		// Schedule being created or deleted
+		return nil
+	}
+	var nextRun time.Time
+	if err = nextRun.UnmarshalText(kvp.Value); err != nil {
+		return errors.Wrap(err, "invalid schedule next run")
+	}
+	if nextRun.IsZero() || nextRun.After(now) {
+		return nil
+	}
+	schedule, err := GetSchedule(kv, deploymentID, scheduleID)
+	if err != nil {
+		return err
+	}
+	cronSchedule, err := cronutil.Parse(schedule.Cron)
+	if err != nil {
+		return err
+	}
+	next, err := cronSchedule.Next(now).MarshalText()
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal schedule next run")
+	}
+	// Check and set ensures that an occurrence is fired only once even if the leadership changes meanwhile
+	kvp.Value = next
+	ok, _, err := kv.CAS(kvp, nil)
+	if err != nil {
+		return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if !ok {
+		return nil
+	}
+
+	entry := HistoryEntry{Date: now}
+	entry.TaskID, err = s.registerTask(kv, schedule)
+	if err != nil {
+		entry.Error = err.Error()
+		events.SimpleLogEntry(events.WARN, deploymentID).Registerf("Failed to register %s task of schedule %q: %v", schedule.TaskType, scheduleID, err)
+	} else {
+		events.SimpleLogEntry(events.INFO, deploymentID).Registerf("Registered %s task %q of schedule %q", schedule.TaskType, entry.TaskID, scheduleID)
+	}
+	return addHistoryEntry(kv, deploymentID, scheduleID, entry)
+}
+
+// registerTask registers a task using the same data as the REST API would do
+func (s *Scheduler) registerTask(kv *api.KV, schedule *Schedule) (string, error) {
+	data := make(map[string]string)
+	switch schedule.TaskType {
+	case tasks.CustomWorkflow:
+		data["workflowName"] = schedule.WorkflowName
+		data["continueOnError"] = strconv.FormatBool(schedule.ContinueOnError)
+	case tasks.CustomCommand:
+		instances, err := deployments.GetNodeInstancesIds(kv, schedule.DeploymentID, schedule.NodeName)
+		if err != nil {
+			return "", err
+		}
+		data[path.Join("nodes", schedule.NodeName)] = strings.Join(instances, ",")
+		data["commandName"] = schedule.CommandName
+		for name, value := range schedule.Inputs {
+			data[path.Join("inputs", name)] = value
+		}
+	default:
+		return "", errors.Errorf("unsupported task type %q for schedule %q", schedule.TaskType, schedule.ID)
+	}
+	return s.collector.RegisterTaskWithData(schedule.DeploymentID, schedule.TaskType, data)
+}
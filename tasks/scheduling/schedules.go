@@ -0,0 +1,286 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduling
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+	"github.com/satori/go.uuid"
+
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/helper/collections"
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/helper/cronutil"
+	"github.com/ystia/yorc/tasks"
+)
+
+// maxHistoryEntries is the number of history entries kept for a schedule
+const maxHistoryEntries = 50
+
+func getSchedulesPath(deploymentID string) string {
+	return path.Join(consulutil.DeploymentKVPrefix, deploymentID, "schedules")
+}
+
+func getSchedulePath(deploymentID, scheduleID string) string {
+	return path.Join(getSchedulesPath(deploymentID), scheduleID)
+}
+
+// CreateSchedule validates and stores a new schedule for a deployment
+//
+// The schedule ID is generated and its next run is computed from the current time. The schedule ID is returned.
+func CreateSchedule(kv *api.KV, s *Schedule) (string, error) {
+	s.ID = fmt.Sprint(uuid.NewV4())
+	return s.ID, storeSchedule(kv, s)
+}
+
+// UpdateSchedule replaces the definition of an existing schedule
+//
+// The next run is computed again from the current time, the schedule history is kept.
+func UpdateSchedule(kv *api.KV, s *Schedule) error {
+	if _, err := GetSchedule(kv, s.DeploymentID, s.ID); err != nil {
+		return err
+	}
+	return storeSchedule(kv, s)
+}
+
+func storeSchedule(kv *api.KV, s *Schedule) error {
+	cronSchedule, err := validateSchedule(kv, s)
+	if err != nil {
+		return err
+	}
+	s.NextRun = cronSchedule.Next(time.Now())
+	nextRun, err := s.NextRun.MarshalText()
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal schedule next run")
+	}
+
+	schedulePath := getSchedulePath(s.DeploymentID, s.ID)
+	// Remove a previous definition but keep the history
+	for _, key := range []string{"cron", "task_type", "workflow_name", "continue_on_error", "node_name", "command_name", "next_run"} {
+		if _, err := kv.Delete(path.Join(schedulePath, key), nil); err != nil {
+			return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+		}
+	}
+	if _, err := kv.DeleteTree(path.Join(schedulePath, "inputs"), nil); err != nil {
+		return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+
+	kvps := api.KVPairs{
+		&api.KVPair{Key: path.Join(schedulePath, "cron"), Value: []byte(s.Cron)},
+		&api.KVPair{Key: path.Join(schedulePath, "task_type"), Value: []byte(strconv.Itoa(int(s.TaskType)))},
+		&api.KVPair{Key: path.Join(schedulePath, "next_run"), Value: nextRun},
+	}
+	switch s.TaskType {
+	case tasks.CustomWorkflow:
+		kvps = append(kvps,
+			&api.KVPair{Key: path.Join(schedulePath, "workflow_name"), Value: []byte(s.WorkflowName)},
+			&api.KVPair{Key: path.Join(schedulePath, "continue_on_error"), Value: []byte(strconv.FormatBool(s.ContinueOnError))},
+		)
+	case tasks.CustomCommand:
+		kvps = append(kvps,
+			&api.KVPair{Key: path.Join(schedulePath, "node_name"), Value: []byte(s.NodeName)},
+			&api.KVPair{Key: path.Join(schedulePath, "command_name"), Value: []byte(s.CommandName)},
+		)
+		for name, value := range s.Inputs {
+			kvps = append(kvps, &api.KVPair{Key: path.Join(schedulePath, "inputs", name), Value: []byte(value)})
+		}
+	}
+	for _, kvp := range kvps {
+		if _, err := kv.Put(kvp, nil); err != nil {
+			return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+		}
+	}
+	return nil
+}
+
+func validateSchedule(kv *api.KV, s *Schedule) (*cronutil.Schedule, error) {
+	cronSchedule, err := cronutil.Parse(s.Cron)
+	if err != nil {
+		return nil, badRequestError{err.Error()}
+	}
+	if cronSchedule.Next(time.Now()).IsZero() {
+		return nil, badRequestError{fmt.Sprintf("cron expression %q will never be activated", s.Cron)}
+	}
+	switch s.TaskType {
+	case tasks.CustomWorkflow:
+		workflows, err := deployments.GetWorkflows(kv, s.DeploymentID)
+		if err != nil {
+			return nil, err
+		}
+		if !collections.ContainsString(workflows, s.WorkflowName) {
+			return nil, badRequestError{fmt.Sprintf("unknown workflow %q", s.WorkflowName)}
+		}
+	case tasks.CustomCommand:
+		if s.CommandName == "" {
+			return nil, badRequestError{"a command name is required to schedule a custom command"}
+		}
+		exists, err := deployments.DoesNodeExist(kv, s.DeploymentID, s.NodeName)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, badRequestError{fmt.Sprintf("unknown node %q", s.NodeName)}
+		}
+	default:
+		return nil, badRequestError{fmt.Sprintf("unsupported task type %q, only %q and %q tasks could be scheduled", s.TaskType, tasks.CustomWorkflow, tasks.CustomCommand)}
+	}
+	return cronSchedule, nil
+}
+
+// GetSchedule returns a schedule of a deployment
+func GetSchedule(kv *api.KV, deploymentID, scheduleID string) (*Schedule, error) {
+	schedulePath := getSchedulePath(deploymentID, scheduleID)
+	kvp, _, err := kv.Get(path.Join(schedulePath, "cron"), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp == nil {
+		return nil, errors.WithStack(scheduleNotFoundError{})
+	}
+	s := &Schedule{ID: scheduleID, DeploymentID: deploymentID, Cron: string(kvp.Value), Inputs: make(map[string]string)}
+
+	kvps, _, err := kv.List(schedulePath+"/", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	for _, kvp := range kvps {
+		key := kvp.Key[len(schedulePath)+1:]
+		switch {
+		case key == "task_type":
+			taskType, err := strconv.Atoi(string(kvp.Value))
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid task type for schedule %q", scheduleID)
+			}
+			s.TaskType = tasks.TaskType(taskType)
+		case key == "workflow_name":
+			s.WorkflowName = string(kvp.Value)
+		case key == "continue_on_error":
+			s.ContinueOnError, err = strconv.ParseBool(string(kvp.Value))
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid continue_on_error value for schedule %q", scheduleID)
+			}
+		case key == "node_name":
+			s.NodeName = string(kvp.Value)
+		case key == "command_name":
+			s.CommandName = string(kvp.Value)
+		case key == "next_run":
+			if err = s.NextRun.UnmarshalText(kvp.Value); err != nil {
+				return nil, errors.Wrapf(err, "invalid next run for schedule %q", scheduleID)
+			}
+		case path.Dir(key) == "inputs":
+			s.Inputs[path.Base(key)] = string(kvp.Value)
+		}
+	}
+	return s, nil
+}
+
+// GetSchedules returns the schedules of a deployment
+func GetSchedules(kv *api.KV, deploymentID string) ([]*Schedule, error) {
+	keys, _, err := kv.Keys(getSchedulesPath(deploymentID)+"/", "/", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	schedules := make([]*Schedule, 0, len(keys))
+	for _, key := range keys {
+		s, err := GetSchedule(kv, deploymentID, path.Base(key))
+		if err != nil {
+			if IsScheduleNotFoundError(err) {
+				// Deleted in the meantime
+				continue
+			}
+			return nil, err
+		}
+		schedules = append(schedules, s)
+	}
+	return schedules, nil
+}
+
+// DeleteSchedule removes a schedule and its history
+func DeleteSchedule(kv *api.KV, deploymentID, scheduleID string) error {
+	if _, err := GetSchedule(kv, deploymentID, scheduleID); err != nil {
+		return err
+	}
+	_, err := kv.DeleteTree(getSchedulePath(deploymentID, scheduleID)+"/", nil)
+	return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+}
+
+// GetScheduleHistory returns the task registration attempts of a schedule ordered by date
+func GetScheduleHistory(kv *api.KV, deploymentID, scheduleID string) ([]HistoryEntry, error) {
+	historyPath := path.Join(getSchedulePath(deploymentID, scheduleID), "history")
+	keys, _, err := kv.Keys(historyPath+"/", "/", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	history := make([]HistoryEntry, 0, len(keys))
+	for _, key := range keys {
+		nanos, err := strconv.ParseInt(path.Base(key), 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid history entry %q for schedule %q", key, scheduleID)
+		}
+		entry := HistoryEntry{Date: time.Unix(0, nanos)}
+		kvp, _, err := kv.Get(path.Join(key, "task_id"), nil)
+		if err != nil {
+			return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+		}
+		if kvp != nil {
+			entry.TaskID = string(kvp.Value)
+		}
+		kvp, _, err = kv.Get(path.Join(key, "error"), nil)
+		if err != nil {
+			return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+		}
+		if kvp != nil {
+			entry.Error = string(kvp.Value)
+		}
+		history = append(history, entry)
+	}
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].Date.Before(history[j].Date)
+	})
+	return history, nil
+}
+
+// addHistoryEntry records a task registration attempt and removes the oldest entries above maxHistoryEntries
+func addHistoryEntry(kv *api.KV, deploymentID, scheduleID string, entry HistoryEntry) error {
+	historyPath := path.Join(getSchedulePath(deploymentID, scheduleID), "history")
+	entryPath := path.Join(historyPath, strconv.FormatInt(entry.Date.UnixNano(), 10))
+	if entry.TaskID != "" {
+		if _, err := kv.Put(&api.KVPair{Key: path.Join(entryPath, "task_id"), Value: []byte(entry.TaskID)}, nil); err != nil {
+			return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+		}
+	}
+	if entry.Error != "" {
+		if _, err := kv.Put(&api.KVPair{Key: path.Join(entryPath, "error"), Value: []byte(entry.Error)}, nil); err != nil {
+			return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+		}
+	}
+	history, err := GetScheduleHistory(kv, deploymentID, scheduleID)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(history)-maxHistoryEntries; i++ {
+		_, err = kv.DeleteTree(path.Join(historyPath, strconv.FormatInt(history[i].Date.UnixNano(), 10))+"/", nil)
+		if err != nil {
+			return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+		}
+	}
+	return nil
+}
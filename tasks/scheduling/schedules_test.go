@@ -0,0 +1,129 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduling
+
+import (
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/tasks"
+)
+
+func populateKV(t *testing.T, srv *testutil.TestServer) {
+	srv.PopulateKV(t, map[string][]byte{
+		consulutil.DeploymentKVPrefix + "/sched1/workflows/backup/steps/step1/target":          []byte("Server"),
+		consulutil.DeploymentKVPrefix + "/sched1/topology/nodes/Server/name":                   []byte("Server"),
+		consulutil.DeploymentKVPrefix + "/sched1/topology/instances/Server/0/attributes/state": []byte("started"),
+		consulutil.DeploymentKVPrefix + "/sched1/topology/instances/Server/1/attributes/state": []byte("started"),
+
+		consulutil.DeploymentKVPrefix + "/sched2/workflows/backup/steps/step1/target": []byte("Server"),
+	})
+}
+
+func testSchedulesCRUD(t *testing.T, kv *api.KV) {
+	t.Parallel()
+	s := &Schedule{DeploymentID: "sched1", Cron: "@daily", TaskType: tasks.CustomWorkflow, WorkflowName: "backup", ContinueOnError: true}
+	scheduleID, err := CreateSchedule(kv, s)
+	require.NoError(t, err)
+	require.NotEmpty(t, scheduleID)
+
+	got, err := GetSchedule(kv, "sched1", scheduleID)
+	require.NoError(t, err)
+	assert.Equal(t, "@daily", got.Cron)
+	assert.Equal(t, tasks.CustomWorkflow, got.TaskType)
+	assert.Equal(t, "backup", got.WorkflowName)
+	assert.True(t, got.ContinueOnError)
+	assert.True(t, got.NextRun.After(time.Now()))
+
+	got.TaskType = tasks.CustomCommand
+	got.Cron = "0 3 * * MON"
+	got.NodeName = "Server"
+	got.CommandName = "dump"
+	got.Inputs = map[string]string{"target": "/backup"}
+	require.NoError(t, UpdateSchedule(kv, got))
+	got, err = GetSchedule(kv, "sched1", scheduleID)
+	require.NoError(t, err)
+	assert.Equal(t, tasks.CustomCommand, got.TaskType)
+	assert.Equal(t, "", got.WorkflowName, "previous definition should be removed")
+	assert.Equal(t, map[string]string{"target": "/backup"}, got.Inputs)
+	assert.Equal(t, time.Monday, got.NextRun.Weekday())
+
+	schedules, err := GetSchedules(kv, "sched1")
+	require.NoError(t, err)
+	assert.Len(t, schedules, 1)
+
+	_, err = CreateSchedule(kv, &Schedule{DeploymentID: "sched1", Cron: "* * *", TaskType: tasks.CustomWorkflow, WorkflowName: "backup"})
+	assert.True(t, IsBadRequestError(err), "invalid cron expression")
+	_, err = CreateSchedule(kv, &Schedule{DeploymentID: "sched1", Cron: "@daily", TaskType: tasks.CustomWorkflow, WorkflowName: "unknown"})
+	assert.True(t, IsBadRequestError(err), "unknown workflow")
+	_, err = CreateSchedule(kv, &Schedule{DeploymentID: "sched1", Cron: "@daily", TaskType: tasks.Deploy})
+	assert.True(t, IsBadRequestError(err), "unsupported task type")
+	err = UpdateSchedule(kv, &Schedule{ID: "unknown", DeploymentID: "sched1", Cron: "@daily", TaskType: tasks.CustomWorkflow, WorkflowName: "backup"})
+	assert.True(t, IsScheduleNotFoundError(err))
+
+	require.NoError(t, DeleteSchedule(kv, "sched1", scheduleID))
+	_, err = GetSchedule(kv, "sched1", scheduleID)
+	assert.True(t, IsScheduleNotFoundError(err))
+	assert.True(t, IsScheduleNotFoundError(DeleteSchedule(kv, "sched1", scheduleID)))
+}
+
+func testCheckSchedule(t *testing.T, client *api.Client) {
+	t.Parallel()
+	kv := client.KV()
+	scheduler := NewScheduler(client, make(chan struct{}), &sync.WaitGroup{})
+	scheduleID, err := CreateSchedule(kv, &Schedule{DeploymentID: "sched2", Cron: "@hourly", TaskType: tasks.CustomWorkflow, WorkflowName: "backup"})
+	require.NoError(t, err)
+
+	// Not due yet
+	now := time.Now()
+	require.NoError(t, scheduler.checkSchedule(kv, "sched2", scheduleID, now))
+	history, err := GetScheduleHistory(kv, "sched2", scheduleID)
+	require.NoError(t, err)
+	assert.Len(t, history, 0)
+
+	// Due: a task is registered and the next run is moved forward
+	due := now.Add(time.Hour)
+	require.NoError(t, scheduler.checkSchedule(kv, "sched2", scheduleID, due))
+	history, err = GetScheduleHistory(kv, "sched2", scheduleID)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	require.NotEmpty(t, history[0].TaskID)
+	assert.Empty(t, history[0].Error)
+	wfName, err := tasks.GetTaskData(kv, history[0].TaskID, "workflowName")
+	require.NoError(t, err)
+	assert.Equal(t, "backup", wfName)
+	schedule, err := GetSchedule(kv, "sched2", scheduleID)
+	require.NoError(t, err)
+	assert.True(t, schedule.NextRun.After(due))
+
+	// Next occurrence while the previous task is still living is recorded as an error
+	require.NoError(t, scheduler.checkSchedule(kv, "sched2", scheduleID, schedule.NextRun))
+	history, err = GetScheduleHistory(kv, "sched2", scheduleID)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Empty(t, history[1].TaskID)
+	assert.NotEmpty(t, history[1].Error)
+
+	_, err = kv.DeleteTree(path.Join(consulutil.TasksPrefix, history[0].TaskID), nil)
+	require.NoError(t, err)
+}
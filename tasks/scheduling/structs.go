@@ -0,0 +1,48 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduling
+
+import (
+	"time"
+
+	"github.com/ystia/yorc/tasks"
+)
+
+// A Schedule periodically registers a custom workflow or a custom command task on a deployment
+type Schedule struct {
+	ID           string
+	DeploymentID string
+	// Cron is a cron expression as supported by the cronutil package
+	Cron string
+	// TaskType is either tasks.CustomWorkflow or tasks.CustomCommand
+	TaskType tasks.TaskType
+	// WorkflowName and ContinueOnError are only relevant for custom workflows
+	WorkflowName    string
+	ContinueOnError bool
+	// NodeName, CommandName and Inputs are only relevant for custom commands
+	NodeName    string
+	CommandName string
+	Inputs      map[string]string
+	// NextRun is the next time the task will be registered
+	NextRun time.Time
+}
+
+// A HistoryEntry records a task registration attempt for a Schedule
+type HistoryEntry struct {
+	Date   time.Time
+	TaskID string
+	// Error is set if the task could not be registered, for instance if another task is running on the deployment
+	Error string
+}
@@ -41,6 +41,10 @@ const (
 	CustomWorkflow
 	// Query defines a Task of type "Query"
 	Query
+	// Update defines a Task of type "Update"
+	Update
+	// Reconcile defines a Task of type "Reconcile"
+	Reconcile
 	// NOTE: if a new task type should be added then change validity check on GetTaskType
 )
 
@@ -52,7 +56,8 @@ type TaskStatus int
 // RUNNING,
 // DONE,
 // ERROR,
-// CANCELED
+// CANCELED,
+// SKIPPED
 // )
 type TaskStepStatus int
 
@@ -72,8 +77,11 @@ const (
 
 // TaskStep represents a step related to the task
 type TaskStep struct {
-	Name   string `json:"name"`
-	Status string `json:"status"`
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Attempts int    `json:"attempts,omitempty"`
+	// SkippedOnResume is true if the step was not run again when the task was resumed as it was already done
+	SkippedOnResume bool `json:"skipped_on_resume,omitempty"`
 }
 
 type anotherLivingTaskAlreadyExistsError struct {
@@ -95,3 +103,40 @@ func IsAnotherLivingTaskAlreadyExistsError(err error) (bool, string) {
 	}
 	return ok, ""
 }
+
+// WorkflowPlan represents the execution plan of a workflow, it describes what a workflow would do if it was run
+type WorkflowPlan struct {
+	Name string `json:"name"`
+	// Levels contains the steps of the workflow grouped by execution level.
+	// Steps of a level only depend on steps of previous levels.
+	Levels       []WorkflowPlanLevel `json:"levels"`
+	SkippedSteps []string            `json:"skipped_steps"`
+}
+
+// WorkflowPlanLevel represents a set of steps that could be run concurrently in a WorkflowPlan
+type WorkflowPlanLevel struct {
+	Steps []WorkflowPlanStep `json:"steps"`
+}
+
+// WorkflowPlanStep represents a step of a WorkflowPlan
+type WorkflowPlanStep struct {
+	Name       string                 `json:"name"`
+	Target     string                 `json:"target,omitempty"`
+	Skipped    bool                   `json:"skipped"`
+	SkipReason string                 `json:"skip_reason,omitempty"`
+	Activities []WorkflowPlanActivity `json:"activities"`
+}
+
+// WorkflowPlanActivity represents an activity of a WorkflowPlanStep with its resolved executor if any
+type WorkflowPlanActivity struct {
+	Type                   string `json:"type"`
+	Value                  string `json:"value"`
+	NodeType               string `json:"node_type,omitempty"`
+	ImplementationArtifact string `json:"implementation_artifact,omitempty"`
+	// ExecutorOrigin is the origin of the executor (builtin or a plugin name)
+	ExecutorOrigin string `json:"executor_origin,omitempty"`
+	// ExecutorMatch is the node type pattern or the implementation artifact that matched the executor
+	ExecutorMatch string `json:"executor_match,omitempty"`
+	// Error is set if the activity can't be resolved (for instance if there is no executor for it)
+	Error string `json:"error,omitempty"`
+}
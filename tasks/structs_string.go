@@ -29,9 +29,9 @@ func (i TaskStatus) String() string {
 	return _TaskStatus_name[_TaskStatus_index[i]:_TaskStatus_index[i+1]]
 }
 
-const _TaskType_name = "DeployUnDeployScaleOutScaleInPurgeCustomCommandCustomWorkflowQuery"
+const _TaskType_name = "DeployUnDeployScaleOutScaleInPurgeCustomCommandCustomWorkflowQueryUpdateReconcile"
 
-var _TaskType_index = [...]uint8{0, 6, 14, 22, 29, 34, 47, 61, 66}
+var _TaskType_index = [...]uint8{0, 6, 14, 22, 29, 34, 47, 61, 66, 72, 81}
 
 func (i TaskType) String() string {
 	if i < 0 || i >= TaskType(len(_TaskType_index)-1) {
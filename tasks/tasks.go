@@ -112,7 +112,7 @@ func GetTaskType(kv *api.KV, taskID string) (TaskType, error) {
 	if err != nil {
 		return Deploy, errors.Wrapf(err, "Invalid task type:")
 	}
-	if typeInt < 0 || typeInt > int(Query) {
+	if typeInt < 0 || typeInt > int(Reconcile) {
 		return Deploy, errors.Errorf("Invalid type for task with id %q: %q", taskID, string(kvp.Value))
 	}
 	return TaskType(typeInt), nil
@@ -167,6 +167,8 @@ func CancelTask(kv *api.KV, taskID string) error {
 }
 
 // ResumeTask marks a task as Initial to allow it being resumed
+//
+// Steps already done are not run again, only failed, canceled and not yet run steps are.
 func ResumeTask(kv *api.KV, taskID string) error {
 	kvp := &api.KVPair{Key: path.Join(consulutil.TasksPrefix, taskID, "status"), Value: []byte(strconv.Itoa(int(INITIAL)))}
 	_, err := kv.Put(kvp, nil)
@@ -176,6 +178,25 @@ func ResumeTask(kv *api.KV, taskID string) error {
 	return nil
 }
 
+// SetTaskRollbackAttempted records that a rollback of the resources created by a task was attempted
+//
+// It is recorded before the rollback starts, even a failed or partial rollback means that steps already done by
+// the task may not reflect the state of the resources any more.
+func SetTaskRollbackAttempted(kv *api.KV, taskID string) error {
+	kvp := &api.KVPair{Key: path.Join(consulutil.TasksPrefix, taskID, "rollback", "attempted"), Value: []byte("true")}
+	_, err := kv.Put(kvp, nil)
+	return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+}
+
+// IsTaskRollbackAttempted checks if a rollback of the resources created by a task was attempted
+func IsTaskRollbackAttempted(kv *api.KV, taskID string) (bool, error) {
+	kvp, _, err := kv.Get(path.Join(consulutil.TasksPrefix, taskID, "rollback", "attempted"), nil)
+	if err != nil {
+		return false, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	return kvp != nil && string(kvp.Value) == "true", nil
+}
+
 // DeleteTask allows to delete a stored task
 func DeleteTask(kv *api.KV, taskID string) error {
 	_, err := kv.DeleteTree(path.Join(consulutil.TasksPrefix, taskID), nil)
@@ -292,12 +313,57 @@ func GetTaskRelatedSteps(kv *api.KV, taskID string) ([]TaskStep, error) {
 		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
 	}
 
+	attempts, skippedOnResume, err := getTaskStepsRunData(kv, taskID)
+	if err != nil {
+		return nil, err
+	}
+
 	for _, kvp := range kvps {
-		steps = append(steps, TaskStep{Name: path.Base(kvp.Key), Status: string(kvp.Value)})
+		stepName := path.Base(kvp.Key)
+		steps = append(steps, TaskStep{Name: stepName, Status: string(kvp.Value), Attempts: attempts[stepName], SkippedOnResume: skippedOnResume[stepName]})
 	}
 	return steps, nil
 }
 
+// SetTaskStepAttempts stores the number of attempts of the latest operation executed by a task step
+func SetTaskStepAttempts(kv *api.KV, taskID, stepName string, attempts int) error {
+	kvp := &api.KVPair{Key: path.Join(consulutil.TasksPrefix, taskID, "steps", stepName, "attempts"), Value: []byte(strconv.Itoa(attempts))}
+	_, err := kv.Put(kvp, nil)
+	return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+}
+
+// SetTaskStepSkippedOnResume records that a step was not run again when resuming a task as it was already done
+func SetTaskStepSkippedOnResume(kv *api.KV, taskID, stepName string) error {
+	kvp := &api.KVPair{Key: path.Join(consulutil.TasksPrefix, taskID, "steps", stepName, "skipped_on_resume"), Value: []byte("true")}
+	_, err := kv.Put(kvp, nil)
+	return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+}
+
+// getTaskStepsRunData returns the number of attempts stored for each step of a task and the steps skipped on resume
+func getTaskStepsRunData(kv *api.KV, taskID string) (map[string]int, map[string]bool, error) {
+	stepsPrefix := path.Join(consulutil.TasksPrefix, taskID, "steps")
+	kvps, _, err := kv.List(stepsPrefix+"/", nil)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	attempts := make(map[string]int, len(kvps))
+	skippedOnResume := make(map[string]bool)
+	for _, kvp := range kvps {
+		stepName := path.Base(path.Dir(kvp.Key))
+		switch path.Base(kvp.Key) {
+		case "attempts":
+			nb, err := strconv.Atoi(string(kvp.Value))
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "Invalid attempts number %q for task %q", string(kvp.Value), taskID)
+			}
+			attempts[stepName] = nb
+		case "skipped_on_resume":
+			skippedOnResume[stepName] = string(kvp.Value) == "true"
+		}
+	}
+	return attempts, skippedOnResume, nil
+}
+
 // TaskStepExists checks if a task step exists with a stepID and related to a given taskID and returns it
 func TaskStepExists(kv *api.KV, taskID, stepID string) (bool, *TaskStep, error) {
 	kvp, _, err := kv.Get(path.Join(consulutil.WorkflowsPrefix, taskID, stepID), nil)
@@ -73,6 +73,9 @@ func populateKV(t *testing.T, srv *testutil.TestServer) {
 		consulutil.WorkflowsPrefix + "/t10/step1": []byte("status1"),
 		consulutil.WorkflowsPrefix + "/t11/step1": []byte("status1"),
 
+		consulutil.TasksPrefix + "/t8/steps/step1/skipped_on_resume": []byte("true"),
+		consulutil.TasksPrefix + "/t8/steps/step2/attempts":          []byte("2"),
+
 		consulutil.TasksPrefix + "/t12/status":    []byte("3"),
 		consulutil.TasksPrefix + "/t12/type":      []byte("5"),
 		consulutil.TasksPrefix + "/t12/targetId":  []byte("id"),
@@ -478,7 +481,7 @@ func testGetTaskRelatedWFSteps(t *testing.T, kv *api.KV) {
 		want    []TaskStep
 		wantErr bool
 	}{
-		{"TaskWith3Steps", args{kv, "t8"}, []TaskStep{{Name: "step1", Status: "status1"}, {Name: "step2", Status: "status2"}, {Name: "step3", Status: "status3"}}, false},
+		{"TaskWith3Steps", args{kv, "t8"}, []TaskStep{{Name: "step1", Status: "status1", SkippedOnResume: true}, {Name: "step2", Status: "status2", Attempts: 2}, {Name: "step3", Status: "status3"}}, false},
 		{"TaskWithoutStep", args{kv, "t9"}, []TaskStep{}, false},
 		{"TaskDoesntExist", args{kv, "fake"}, []TaskStep{}, false},
 	}
@@ -630,6 +633,30 @@ func testResumeTask(t *testing.T, kv *api.KV) {
 	}
 }
 
+func testTaskRollbackAttempted(t *testing.T, kv *api.KV) {
+	attempted, err := IsTaskRollbackAttempted(kv, "t12")
+	if err != nil {
+		t.Errorf("IsTaskRollbackAttempted() error = %v", err)
+		return
+	}
+	if attempted {
+		t.Error("IsTaskRollbackAttempted() = true before any rollback")
+		return
+	}
+	if err = SetTaskRollbackAttempted(kv, "t12"); err != nil {
+		t.Errorf("SetTaskRollbackAttempted() error = %v", err)
+		return
+	}
+	attempted, err = IsTaskRollbackAttempted(kv, "t12")
+	if err != nil {
+		t.Errorf("IsTaskRollbackAttempted() error = %v", err)
+		return
+	}
+	if !attempted {
+		t.Error("IsTaskRollbackAttempted() = false after a rollback")
+	}
+}
+
 func testGetTaskResultSet(t *testing.T, kv *api.KV) {
 	type args struct {
 		kv     *api.KV
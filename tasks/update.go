@@ -0,0 +1,47 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tasks
+
+import (
+	"strings"
+
+	"github.com/hashicorp/consul/api"
+)
+
+// UpdateAddedNodesDataName is the name of the task data listing the nodes added by a deployment update
+const UpdateAddedNodesDataName = "addedNodes"
+
+// UpdateRemovedNodesDataName is the name of the task data listing the nodes removed by a deployment update
+const UpdateRemovedNodesDataName = "removedNodes"
+
+// UpdateModifiedNodesDataName is the name of the task data listing the nodes modified by a deployment update
+const UpdateModifiedNodesDataName = "modifiedNodes"
+
+// GetTaskDataList retrieves a comma-separated list stored in task data
+//
+// An empty list is returned if the data is not defined for this task.
+func GetTaskDataList(kv *api.KV, taskID, dataName string) ([]string, error) {
+	value, err := GetTaskData(kv, taskID, dataName)
+	if err != nil {
+		if IsTaskDataNotFoundError(err) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	if value == "" {
+		return []string{}, nil
+	}
+	return strings.Split(value, ","), nil
+}
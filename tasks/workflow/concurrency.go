@@ -0,0 +1,149 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/tasks"
+)
+
+// infrastructureTypeRegexp extracts the infrastructure name from a Yorc node type like yorc.nodes.openstack.Compute
+var infrastructureTypeRegexp = regexp.MustCompile(`^yorc\.nodes\.([^.]+)\.`)
+
+// concurrencySlots are the Consul semaphores held by a task to enforce the dispatcher concurrency limits
+type concurrencySlots []*api.Semaphore
+
+func (cs concurrencySlots) release() {
+	for _, sem := range cs {
+		if err := sem.Release(); err != nil && err != api.ErrSemaphoreNotHeld {
+			log.Debugf("Failed to release task concurrency slot: %+v", err)
+		}
+		// Destroy fails if the semaphore is still used by other tasks, this is expected
+		sem.Destroy()
+	}
+}
+
+// acquireConcurrencySlots tries to acquire a slot for the deployment and for each infrastructure used by a task
+//
+// Slots are Consul semaphores so limits are enforced across all the Yorc servers of a cluster.
+// If a limit is reached, already acquired slots are released and false is returned.
+func (d *Dispatcher) acquireConcurrencySlots(kv *api.KV, taskID, targetID string, taskType tasks.TaskType, nodeName string) (concurrencySlots, bool, error) {
+	// Query tasks do not target a deployment
+	if taskType == tasks.Query {
+		return nil, true, nil
+	}
+	prefixes := make(map[string]int)
+	if limit := d.cfg.TasksDispatcher.MaxRunningTasksPerDeployment; limit > 0 {
+		prefixes[path.Join(consulutil.TasksLocksPrefix, "semaphores", "deployments", targetID)] = limit
+	}
+	if limit := d.cfg.TasksDispatcher.MaxRunningTasksPerInfrastructure; limit > 0 {
+		infrastructures, err := getDeploymentInfrastructures(kv, targetID)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, infra := range infrastructures {
+			prefixes[path.Join(consulutil.TasksLocksPrefix, "semaphores", "infrastructures", infra)] = limit
+		}
+	}
+
+	slots := make(concurrencySlots, 0, len(prefixes))
+	for prefix, limit := range prefixes {
+		sem, err := d.client.SemaphoreOpts(&api.SemaphoreOptions{
+			Prefix:            prefix,
+			Limit:             limit,
+			Value:             []byte(nodeName + ":" + taskID),
+			SemaphoreTryOnce:  true,
+			SemaphoreWaitTime: 10 * time.Millisecond,
+		})
+		if err != nil {
+			slots.release()
+			return nil, false, errors.Wrapf(err, "failed to create concurrency slot %q for task %q", prefix, taskID)
+		}
+		lostCh, err := sem.Acquire(nil)
+		if err != nil {
+			slots.release()
+			return nil, false, errors.Wrapf(err, "failed to acquire concurrency slot %q for task %q", prefix, taskID)
+		}
+		if lostCh == nil {
+			log.Debugf("Concurrency limit %d reached for %q, task %q is deferred", limit, prefix, taskID)
+			slots.release()
+			return nil, false, nil
+		}
+		slots = append(slots, sem)
+	}
+	return slots, true, nil
+}
+
+// getDeploymentInfrastructures returns the sorted names of the infrastructures used by the nodes of a deployment
+//
+// Infrastructures are identified by node types deriving from yorc.nodes.<infrastructure>.* types.
+func getDeploymentInfrastructures(kv *api.KV, deploymentID string) ([]string, error) {
+	nodes, err := deployments.GetNodes(kv, deploymentID)
+	if err != nil {
+		return nil, err
+	}
+	infraSet := make(map[string]struct{})
+	for _, node := range nodes {
+		nodeType, err := deployments.GetNodeType(kv, deploymentID, node)
+		if err != nil {
+			return nil, err
+		}
+		for nodeType != "" {
+			if m := infrastructureTypeRegexp.FindStringSubmatch(nodeType); m != nil {
+				infraSet[m[1]] = struct{}{}
+				break
+			}
+			nodeType, err = deployments.GetParentType(kv, deploymentID, nodeType)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	infrastructures := make([]string, 0, len(infraSet))
+	for infra := range infraSet {
+		infrastructures = append(infrastructures, infra)
+	}
+	sort.Strings(infrastructures)
+	return infrastructures, nil
+}
+
+// dispatchCandidate is a task waiting to be dispatched
+type dispatchCandidate struct {
+	taskKey      string
+	taskID       string
+	taskType     tasks.TaskType
+	priority     int
+	creationDate time.Time
+}
+
+// sortDispatchCandidates sorts tasks by decreasing priority then by creation date
+func sortDispatchCandidates(candidates []dispatchCandidate) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].priority != candidates[j].priority {
+			return candidates[i].priority > candidates[j].priority
+		}
+		return candidates[i].creationDate.Before(candidates[j].creationDate)
+	})
+}
@@ -0,0 +1,63 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortDispatchCandidates(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	candidates := []dispatchCandidate{
+		{taskID: "deployOld", priority: 10, creationDate: now.Add(-time.Minute)},
+		{taskID: "undeploy", priority: 0, creationDate: now.Add(-time.Hour)},
+		{taskID: "customCommand", priority: 30, creationDate: now},
+		{taskID: "deployNew", priority: 10, creationDate: now},
+		{taskID: "urgent", priority: 100, creationDate: now.Add(time.Minute)},
+	}
+	sortDispatchCandidates(candidates)
+	ids := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		ids = append(ids, c.taskID)
+	}
+	assert.Equal(t, []string{"urgent", "customCommand", "deployOld", "deployNew", "undeploy"}, ids)
+}
+
+func TestInfrastructureTypeRegexp(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		nodeType string
+		want     string
+	}{
+		{"yorc.nodes.openstack.Compute", "openstack"},
+		{"yorc.nodes.aws.PublicIP", "aws"},
+		{"yorc.nodes.hostspool.Compute", "hostspool"},
+		{"yorc.nodes.Compute", ""},
+		{"tosca.nodes.Compute", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nodeType, func(t *testing.T) {
+			var got string
+			if m := infrastructureTypeRegexp.FindStringSubmatch(tt.nodeType); m != nil {
+				got = m[1]
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
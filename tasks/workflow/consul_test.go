@@ -39,5 +39,17 @@ func TestRunConsulWorkflowPackageTests(t *testing.T) {
 		t.Run("testReadStepFromConsulFailing", func(t *testing.T) {
 			testReadStepFromConsulFailing(t, srv, kv)
 		})
+		t.Run("testReadStepWithRetry", func(t *testing.T) {
+			testReadStepWithRetry(t, srv, kv)
+		})
+		t.Run("testReadStepWithFilterAndOnFailure", func(t *testing.T) {
+			testReadStepWithFilterAndOnFailure(t, srv, kv)
+		})
+		t.Run("testGetNodesToRollback", func(t *testing.T) {
+			testGetNodesToRollback(t, srv, kv)
+		})
+		t.Run("testResumeTaskSteps", func(t *testing.T) {
+			testResumeTaskSteps(t, srv, kv)
+		})
 	})
 }
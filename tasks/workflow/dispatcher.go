@@ -15,7 +15,6 @@
 package workflow
 
 import (
-	"strconv"
 	"time"
 
 	"sync"
@@ -43,6 +42,9 @@ type Dispatcher struct {
 	wg         *sync.WaitGroup
 }
 
+// deferredTasksCheckPeriod is the maximum duration between two attempts to dispatch tasks deferred due to concurrency limits
+const deferredTasksCheckPeriod = 5 * time.Second
+
 // NewDispatcher create a new Dispatcher with a given number of workers
 func NewDispatcher(cfg config.Configuration, shutdownCh chan struct{}, client *api.Client, wg *sync.WaitGroup) *Dispatcher {
 	pool := make(chan chan *task, cfg.WorkersNumber)
@@ -107,6 +109,9 @@ func (d *Dispatcher) emitTasksMetrics() {
 }
 
 // Run creates workers and waits for new tasks
+//
+// Tasks are dispatched by decreasing priority then by creation date. A task is deferred if it would exceed the
+// maximum number of tasks running concurrently for its deployment or its infrastructures.
 func (d *Dispatcher) Run() {
 
 	for i := 0; i < d.maxWorkers; i++ {
@@ -115,6 +120,7 @@ func (d *Dispatcher) Run() {
 	}
 	log.Printf("%d worker started", d.maxWorkers)
 	var waitIndex uint64
+	var hasDeferredTasks bool
 	kv := d.client.KV()
 	nodeName, err := d.client.Agent().NodeName()
 	if err != nil {
@@ -129,6 +135,10 @@ func (d *Dispatcher) Run() {
 		default:
 		}
 		q := &api.QueryOptions{WaitIndex: waitIndex}
+		if hasDeferredTasks {
+			// Deferred tasks may be dispatched as soon as a running task ends
+			q.WaitTime = deferredTasksCheckPeriod
+		}
 		log.Debugf("Long polling task list")
 		tasksKeys, rMeta, err := kv.Keys(consulutil.TasksPrefix+"/", "/", q)
 		if err != nil {
@@ -137,37 +147,17 @@ func (d *Dispatcher) Run() {
 			log.Debugf("%+v", err)
 			continue
 		}
-		if waitIndex == rMeta.LastIndex {
+		if waitIndex == rMeta.LastIndex && !hasDeferredTasks {
 			// long pool ended due to a timeout
 			// there is no new items go back to the pooling
 			continue
 		}
 		waitIndex = rMeta.LastIndex
 		log.Debugf("Got response new wait index is %d", waitIndex)
-		for _, taskKey := range tasksKeys {
-			taskID := path.Base(taskKey)
-			log.Debugf("Check if createLock exists for task %s", taskKey)
-			for {
-				if createLock, _, _ := kv.Get(taskKey+".createLock", nil); createLock != nil {
-					// Locked in creation let's it finish
-					log.Debugf("CreateLock exists for task %s wait for few ms", taskKey)
-					time.Sleep(100 * time.Millisecond)
-				} else {
-					break
-				}
-			}
-			status, err := tasks.GetTaskStatus(kv, taskID)
-
-			if err != nil {
-				log.Print(err)
-				log.Debugf("%+v", err)
-				continue
-			}
-
-			if status != tasks.INITIAL && status != tasks.RUNNING {
-				log.Debugf("Skipping task with status %q", status)
-				continue
-			}
+		hasDeferredTasks = false
+		for _, candidate := range d.getDispatchCandidates(kv, tasksKeys) {
+			taskKey := candidate.taskKey
+			taskID := candidate.taskID
 
 			log.Debugf("Try to acquire processing lock for task %s", taskKey)
 			opts := &api.LockOptions{
@@ -191,7 +181,7 @@ func (d *Dispatcher) Run() {
 				continue
 			}
 
-			status, err = tasks.GetTaskStatus(kv, taskID)
+			status, err := tasks.GetTaskStatus(kv, taskID)
 
 			if err != nil {
 				log.Print(err)
@@ -221,33 +211,15 @@ func (d *Dispatcher) Run() {
 			}
 			targetID := string(kvPairContent.Value)
 
-			kvPairContent, _, err = kv.Get(taskKey+"type", nil)
-			if err != nil {
-				log.Printf("Failed to get task type for key %s: %+v", taskKey, err)
-				continue
-			}
-			if kvPairContent == nil {
-				log.Printf("Failed to get task type for key %s: nil value", taskKey)
-				continue
-			}
-			taskType, err := strconv.Atoi(string(kvPairContent.Value))
-			if err != nil {
-				log.Printf("Failed to get task type for key %s: %+v", taskKey, err)
-				continue
-			}
-			kvPairContent, _, err = kv.Get(taskKey+"creationDate", nil)
-			if err != nil {
-				log.Printf("Failed to get task creationDate for key %s: %+v", taskKey, err)
-				continue
-			}
-			if kvPairContent == nil {
-				log.Printf("Failed to get task creationDate for key %s: nil value", taskKey)
-				continue
-			}
-			creationDate := time.Time{}
-			err = creationDate.UnmarshalBinary(kvPairContent.Value)
-			if err != nil {
-				log.Printf("Failed to get task creationDate for key %s: %+v", taskKey, err)
+			slots, acquired, err := d.acquireConcurrencySlots(kv, taskID, targetID, candidate.taskType, nodeName)
+			if err != nil || !acquired {
+				if err != nil {
+					log.Printf("Failed to check concurrency limits for task %q: %+v", taskID, err)
+				}
+				// Let this task for later and let other instances a chance to consume it
+				hasDeferredTasks = true
+				lock.Unlock()
+				lock.Destroy()
 				continue
 			}
 
@@ -257,9 +229,10 @@ func (d *Dispatcher) Run() {
 				status:       status,
 				TargetID:     targetID,
 				taskLock:     lock,
+				slots:        slots,
 				kv:           kv,
-				creationDate: creationDate,
-				TaskType:     tasks.TaskType(taskType),
+				creationDate: candidate.creationDate,
+				TaskType:     candidate.taskType,
 			}
 			log.Debugf("New task created %+v: pushing it to a work channel", t)
 			// try to obtain a worker task channel that is available.
@@ -269,17 +242,16 @@ func (d *Dispatcher) Run() {
 				taskChannel <- t
 			case <-leaderChan:
 				// lock lost
+				slots.release()
 				continue
 			case <-d.shutdownCh:
-				lock.Unlock()
-				lock.Destroy()
+				t.releaseLock()
 				log.Printf("Dispatcher received shutdown signal. Exiting...")
 				return
 			case <-time.After(5 * time.Second):
 				// Timeout let another instance a chance to consume this
 				// task
-				lock.Unlock()
-				lock.Destroy()
+				t.releaseLock()
 				time.Sleep(100 * time.Millisecond)
 				break
 			}
@@ -288,3 +260,52 @@ func (d *Dispatcher) Run() {
 	}
 
 }
+
+// getDispatchCandidates returns tasks that are waiting to be processed sorted by dispatching order
+func (d *Dispatcher) getDispatchCandidates(kv *api.KV, tasksKeys []string) []dispatchCandidate {
+	candidates := make([]dispatchCandidate, 0, len(tasksKeys))
+	for _, taskKey := range tasksKeys {
+		taskID := path.Base(taskKey)
+		log.Debugf("Check if createLock exists for task %s", taskKey)
+		for {
+			if createLock, _, _ := kv.Get(taskKey+".createLock", nil); createLock != nil {
+				// Locked in creation let's it finish
+				log.Debugf("CreateLock exists for task %s wait for few ms", taskKey)
+				time.Sleep(100 * time.Millisecond)
+			} else {
+				break
+			}
+		}
+		status, err := tasks.GetTaskStatus(kv, taskID)
+
+		if err != nil {
+			log.Print(err)
+			log.Debugf("%+v", err)
+			continue
+		}
+
+		if status != tasks.INITIAL && status != tasks.RUNNING {
+			log.Debugf("Skipping task with status %q", status)
+			continue
+		}
+
+		taskType, err := tasks.GetTaskType(kv, taskID)
+		if err != nil {
+			log.Printf("Failed to get task type for key %s: %+v", taskKey, err)
+			continue
+		}
+		creationDate, err := tasks.GetTaskCreationDate(kv, taskID)
+		if err != nil {
+			log.Printf("Failed to get task creationDate for key %s: %+v", taskKey, err)
+			continue
+		}
+		priority, err := tasks.GetTaskPriority(kv, taskID, taskType, d.cfg.TasksDispatcher.Priorities)
+		if err != nil {
+			log.Printf("Failed to get task priority for key %s: %+v", taskKey, err)
+			continue
+		}
+		candidates = append(candidates, dispatchCandidate{taskKey: taskKey, taskID: taskID, taskType: taskType, priority: priority, creationDate: creationDate})
+	}
+	sortDispatchCandidates(candidates)
+	return candidates
+}
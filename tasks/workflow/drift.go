@@ -0,0 +1,106 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/events"
+	"github.com/ystia/yorc/prov"
+	"github.com/ystia/yorc/registry"
+)
+
+// detectDeploymentDrift checks the infrastructure resources of the nodes of a deployment for drifts
+//
+// Only nodes handled by a delegate executor implementing prov.DriftDetector are checked. As their expected state is
+// stored in Consul this could be done by any Yorc server, not only by the one that deployed them.
+func (w worker) detectDeploymentDrift(ctx context.Context, taskID, deploymentID string) (*prov.DriftReport, error) {
+	kv := w.consulClient.KV()
+	nodes, err := deployments.GetNodes(kv, deploymentID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(nodes)
+	report := &prov.DriftReport{DeploymentID: deploymentID}
+	for _, nodeName := range nodes {
+		detector, err := getDriftDetector(kv, deploymentID, nodeName)
+		if err != nil {
+			return nil, err
+		}
+		if detector == nil {
+			continue
+		}
+		drifts, err := detector.DetectDrift(ctx, w.cfg, taskID, deploymentID, nodeName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to detect drifts of node %q", nodeName)
+		}
+		for _, d := range drifts {
+			events.WithOptionalFields(events.LogOptionalFields{events.NodeID: nodeName}).NewLogEntry(events.WARN, deploymentID).RegisterAsString(fmt.Sprintf("Resource %q of node %q has drifted (%s)", d.Resource, nodeName, d.Kind))
+		}
+		report.Drifted = report.Drifted || len(drifts) > 0
+		report.Nodes = append(report.Nodes, prov.NodeDrift{NodeName: nodeName, Resources: drifts})
+	}
+	return report, nil
+}
+
+// runDriftReconcile brings back the drifted infrastructure resources of a deployment to their expected state
+func (w worker) runDriftReconcile(ctx context.Context, t *task) error {
+	kv := w.consulClient.KV()
+	report, err := w.detectDeploymentDrift(ctx, t.ID, t.TargetID)
+	if err != nil {
+		return err
+	}
+	if !report.Drifted {
+		events.SimpleLogEntry(events.INFO, t.TargetID).RegisterAsString("No drift detected, nothing to reconcile")
+		return nil
+	}
+	for _, node := range report.Nodes {
+		if len(node.Resources) == 0 {
+			continue
+		}
+		detector, err := getDriftDetector(kv, t.TargetID, node.NodeName)
+		if err != nil {
+			return err
+		}
+		events.WithOptionalFields(events.LogOptionalFields{events.NodeID: node.NodeName}).NewLogEntry(events.INFO, t.TargetID).RegisterAsString(fmt.Sprintf("Reconciling drifted node %q", node.NodeName))
+		err = detector.ReconcileDrift(ctx, w.cfg, t.ID, t.TargetID, node.NodeName)
+		if err != nil {
+			return errors.Wrapf(err, "failed to reconcile drifts of node %q", node.NodeName)
+		}
+	}
+	return nil
+}
+
+// getDriftDetector returns the drift detector of a node or nil if the node is not managed by a delegate executor
+// able to detect drifts
+func getDriftDetector(kv *api.KV, deploymentID, nodeName string) (prov.DriftDetector, error) {
+	nodeType, err := deployments.GetNodeType(kv, deploymentID, nodeName)
+	if err != nil {
+		return nil, err
+	}
+	executor, err := registry.GetRegistry().GetDelegateExecutor(nodeType)
+	if err != nil {
+		// Not a delegate node
+		return nil, nil
+	}
+	detector, _ := executor.(prov.DriftDetector)
+	return detector, nil
+}
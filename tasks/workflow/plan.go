@@ -0,0 +1,226 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"path"
+	"regexp"
+	"sort"
+
+	"github.com/hashicorp/consul/api"
+
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/prov/operations"
+	"github.com/ystia/yorc/registry"
+	"github.com/ystia/yorc/tasks"
+)
+
+// BuildWorkflowPlan computes the execution plan of a workflow without running it
+//
+// Steps are evaluated against the current state of the deployment. If taskID is not empty, the steps statuses and
+// the nodes related to this task are taken into account, this allows to preview what resuming a task would do.
+func BuildWorkflowPlan(kv *api.KV, deploymentID, workflowName, taskID string) (*tasks.WorkflowPlan, error) {
+	t := &task{TargetID: deploymentID, TaskType: tasks.CustomWorkflow, kv: kv}
+	if taskID != "" {
+		taskType, err := tasks.GetTaskType(kv, taskID)
+		if err != nil {
+			return nil, err
+		}
+		t.ID = taskID
+		t.TaskType = taskType
+	}
+
+	wf, err := readWorkFlowFromConsul(kv, path.Join(consulutil.DeploymentKVPrefix, deploymentID, "workflows", workflowName))
+	if err != nil {
+		return nil, err
+	}
+	// Sort steps to get a stable plan
+	sort.Slice(wf, func(i, j int) bool {
+		return wf[i].Name < wf[j].Name
+	})
+
+	levels := make(map[*step]int, len(wf))
+	maxLevel := -1
+	for _, s := range wf {
+		s.SetTaskID(t)
+		l := stepLevel(s, levels)
+		if l > maxLevel {
+			maxLevel = l
+		}
+	}
+
+	plan := &tasks.WorkflowPlan{Name: workflowName, Levels: make([]tasks.WorkflowPlanLevel, maxLevel+1), SkippedSteps: make([]string, 0)}
+	for i := range plan.Levels {
+		plan.Levels[i].Steps = make([]tasks.WorkflowPlanStep, 0)
+	}
+	// Steps on a branch that is not taken (on failure steps) are computed level by level
+	notOnPath := make(map[*step]bool)
+	for level := 0; level <= maxLevel; level++ {
+		for _, s := range wf {
+			if levels[s] != level {
+				continue
+			}
+			onPath := isStepOnExecutionPath(s, notOnPath)
+			if !onPath {
+				notOnPath[s] = true
+			}
+			planStep, err := s.plan(deploymentID, onPath)
+			if err != nil {
+				return nil, err
+			}
+			if planStep.Skipped {
+				plan.SkippedSteps = append(plan.SkippedSteps, s.Name)
+			}
+			plan.Levels[level].Steps = append(plan.Levels[level].Steps, planStep)
+		}
+	}
+	return plan, nil
+}
+
+// stepLevel returns the execution level of a step: 0 for steps without previous steps, otherwise the highest level
+// of its previous steps plus one
+func stepLevel(s *step, levels map[*step]int) int {
+	if l, ok := levels[s]; ok {
+		return l
+	}
+	l := 0
+	for _, prev := range s.Previous {
+		if pl := stepLevel(prev, levels) + 1; pl > l {
+			l = pl
+		}
+	}
+	levels[s] = l
+	return l
+}
+
+// isStepOnExecutionPath checks if a step would be reached assuming that all steps succeed
+//
+// A step is not on the execution path if all its previous steps are either not on the execution path or only link
+// to it as an on_failure step.
+func isStepOnExecutionPath(s *step, notOnPath map[*step]bool) bool {
+	if len(s.Previous) == 0 {
+		return true
+	}
+	for _, prev := range s.Previous {
+		if notOnPath[prev] {
+			continue
+		}
+		for _, next := range prev.Next {
+			if next == s {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// plan describes what this step would do if it was run
+func (s *step) plan(deploymentID string, onExecutionPath bool) (tasks.WorkflowPlanStep, error) {
+	planStep := tasks.WorkflowPlanStep{Name: s.Name, Target: s.Target, Activities: make([]tasks.WorkflowPlanActivity, 0)}
+	reason, err := s.notRunnableReason()
+	if err != nil {
+		return planStep, err
+	}
+	if reason == "" && !onExecutionPath {
+		reason = "step only runs on failure of a previous step"
+	}
+	if reason == "" {
+		satisfied, err := s.isFilterSatisfied(deploymentID)
+		if err != nil {
+			return planStep, err
+		}
+		if !satisfied {
+			reason = "step filter is not satisfied"
+		}
+	}
+	if reason != "" {
+		planStep.Skipped = true
+		planStep.SkipReason = reason
+	}
+
+	for _, activity := range s.Activities {
+		planStep.Activities = append(planStep.Activities, s.planActivity(deploymentID, activity))
+	}
+	return planStep, nil
+}
+
+// planActivity resolves the executor of an activity
+//
+// Resolution errors are reported into the returned activity as they would only fail the step at runtime.
+func (s *step) planActivity(deploymentID string, activity activity) tasks.WorkflowPlanActivity {
+	planActivity := tasks.WorkflowPlanActivity{Type: activity.ActivityType(), Value: activity.ActivityValue()}
+	switch activity.ActivityType() {
+	case wfDelegateActivity:
+		nodeType, err := deployments.GetNodeType(s.kv, deploymentID, s.Target)
+		if err != nil {
+			planActivity.Error = err.Error()
+			return planActivity
+		}
+		planActivity.NodeType = nodeType
+		if _, err = registry.GetRegistry().GetDelegateExecutor(nodeType); err != nil {
+			planActivity.Error = err.Error()
+			return planActivity
+		}
+		for _, m := range registry.GetRegistry().ListDelegateExecutors() {
+			if ok, _ := regexp.MatchString(m.Match, nodeType); ok {
+				planActivity.ExecutorOrigin = m.Origin
+				planActivity.ExecutorMatch = m.Match
+				break
+			}
+		}
+	case wfCallOpActivity:
+		nodeType, err := deployments.GetNodeType(s.kv, deploymentID, s.Target)
+		if err != nil {
+			planActivity.Error = err.Error()
+			return planActivity
+		}
+		planActivity.NodeType = nodeType
+		op, err := operations.GetOperation(s.kv, deploymentID, s.Target, activity.ActivityValue(), s.TargetRelationship, s.OperationHost)
+		if err != nil {
+			if deployments.IsOperationNotImplemented(err) {
+				// Operation not implemented it will be skipped at runtime
+				return planActivity
+			}
+			planActivity.Error = err.Error()
+			return planActivity
+		}
+		planActivity.ImplementationArtifact = op.ImplementationArtifact
+		if _, err = getOperationExecutor(s.kv, deploymentID, op.ImplementationArtifact); err != nil {
+			planActivity.Error = err.Error()
+			return planActivity
+		}
+		planActivity.ExecutorOrigin, planActivity.ExecutorMatch = getOperationExecutorOrigin(s.kv, deploymentID, op.ImplementationArtifact)
+	}
+	return planActivity
+}
+
+// getOperationExecutorOrigin returns the origin and the matching artifact of the operation executor of the given
+// implementation artifact using the same resolution than getOperationExecutor
+func getOperationExecutorOrigin(kv *api.KV, deploymentID, artifact string) (string, string) {
+	for artifact != "" {
+		for _, m := range registry.GetRegistry().ListOperationExecutors() {
+			if m.Artifact == artifact {
+				return m.Origin, m.Artifact
+			}
+		}
+		parentArt, err := deployments.GetParentType(kv, deploymentID, artifact)
+		if err != nil {
+			return "", ""
+		}
+		artifact = parentArt
+	}
+	return "", ""
+}
@@ -0,0 +1,58 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func linkSteps(from *step, next []*step, onFailure []*step) {
+	from.Next = append(from.Next, next...)
+	from.OnFailure = append(from.OnFailure, onFailure...)
+	for _, s := range append(next, onFailure...) {
+		s.Previous = append(s.Previous, from)
+	}
+}
+
+func TestStepLevelAndExecutionPath(t *testing.T) {
+	t.Parallel()
+	// a -> b -> d
+	// a -> c -> d
+	// c -(on_failure)-> cleanup -> notify
+	// d -> e
+	a, b, c, d, e := &step{Name: "a"}, &step{Name: "b"}, &step{Name: "c"}, &step{Name: "d"}, &step{Name: "e"}
+	cleanup, notify := &step{Name: "cleanup"}, &step{Name: "notify"}
+	linkSteps(a, []*step{b, c}, nil)
+	linkSteps(b, []*step{d}, nil)
+	linkSteps(c, []*step{d}, []*step{cleanup})
+	linkSteps(d, []*step{e}, nil)
+	linkSteps(cleanup, []*step{notify}, nil)
+
+	levels := make(map[*step]int)
+	expectedLevels := map[*step]int{a: 0, b: 1, c: 1, d: 2, e: 3, cleanup: 2, notify: 3}
+	for s, expected := range expectedLevels {
+		assert.Equal(t, expected, stepLevel(s, levels), "unexpected level for step %q", s.Name)
+	}
+
+	notOnPath := make(map[*step]bool)
+	for _, s := range []*step{a, b, c, d, cleanup, e, notify} {
+		if !isStepOnExecutionPath(s, notOnPath) {
+			notOnPath[s] = true
+		}
+	}
+	assert.Equal(t, map[*step]bool{cleanup: true, notify: true}, notOnPath)
+}
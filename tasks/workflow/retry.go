@@ -0,0 +1,199 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/tosca"
+)
+
+const (
+	retryBackoffFixed       = "fixed"
+	retryBackoffExponential = "exponential"
+)
+
+const (
+	retryableErrorsAll     = "all"
+	retryableErrorsTimeout = "timeout"
+	retryableErrorsNetwork = "network"
+)
+
+var timeoutErrorsMessages = []string{"timeout", "timed out", "deadline exceeded"}
+var networkErrorsMessages = []string{"connection refused", "connection reset", "no such host", "broken pipe", "network is unreachable"}
+
+// retryPolicy defines how operations called by a workflow step are retried on failure
+type retryPolicy struct {
+	maxAttempts     int
+	delay           time.Duration
+	maxDelay        time.Duration
+	backoff         string
+	retryableErrors []string
+}
+
+// newRetryPolicy builds a retry policy from the Yorc server defaults overridden by the given step retry definition (if any)
+func newRetryPolicy(defaults config.WfStepRetry, stepRetry *tosca.Retry) (retryPolicy, error) {
+	rp := retryPolicy{
+		maxAttempts:     defaults.MaxAttempts,
+		delay:           defaults.Delay,
+		maxDelay:        defaults.MaxDelay,
+		backoff:         strings.ToLower(defaults.Backoff),
+		retryableErrors: defaults.RetryableErrors,
+	}
+	if stepRetry != nil {
+		var err error
+		if stepRetry.MaxAttempts != 0 {
+			rp.maxAttempts = stepRetry.MaxAttempts
+		}
+		if stepRetry.Delay != "" {
+			rp.delay, err = time.ParseDuration(stepRetry.Delay)
+			if err != nil {
+				return rp, errors.Wrapf(err, "invalid retry delay %q", stepRetry.Delay)
+			}
+		}
+		if stepRetry.MaxDelay != "" {
+			rp.maxDelay, err = time.ParseDuration(stepRetry.MaxDelay)
+			if err != nil {
+				return rp, errors.Wrapf(err, "invalid retry max delay %q", stepRetry.MaxDelay)
+			}
+		}
+		if stepRetry.Backoff != "" {
+			rp.backoff = strings.ToLower(stepRetry.Backoff)
+		}
+		if len(stepRetry.RetryableErrors) > 0 {
+			rp.retryableErrors = stepRetry.RetryableErrors
+		}
+	}
+	if rp.maxAttempts < 1 {
+		rp.maxAttempts = 1
+	}
+	if rp.backoff == "" {
+		rp.backoff = retryBackoffFixed
+	}
+	if rp.backoff != retryBackoffFixed && rp.backoff != retryBackoffExponential {
+		return rp, errors.Errorf("invalid retry backoff %q: only %q or %q values are accepted", rp.backoff, retryBackoffFixed, retryBackoffExponential)
+	}
+	for _, retryableError := range rp.retryableErrors {
+		switch strings.ToLower(retryableError) {
+		case retryableErrorsAll, retryableErrorsTimeout, retryableErrorsNetwork:
+		default:
+			if _, err := regexp.Compile(retryableError); err != nil {
+				return rp, errors.Wrapf(err, "invalid retryable error pattern %q", retryableError)
+			}
+		}
+	}
+	return rp, nil
+}
+
+// delayBeforeAttempt returns the delay to wait before running the given attempt (attempts start at 1)
+func (rp retryPolicy) delayBeforeAttempt(attempt int) time.Duration {
+	if attempt <= 1 {
+		return 0
+	}
+	if rp.backoff != retryBackoffExponential {
+		return rp.delay
+	}
+	delay := rp.delay
+	for i := 2; i < attempt; i++ {
+		delay *= 2
+		if rp.maxDelay > 0 && delay >= rp.maxDelay {
+			return rp.maxDelay
+		}
+	}
+	if rp.maxDelay > 0 && delay > rp.maxDelay {
+		return rp.maxDelay
+	}
+	return delay
+}
+
+// isRetryable checks if the given error matches one of the retryable errors classes of this policy.
+//
+// An empty list of classes means that all errors are retryable. Errors due to a context cancellation are never retryable.
+func (rp retryPolicy) isRetryable(err error) bool {
+	if err == nil || errors.Cause(err) == context.Canceled {
+		return false
+	}
+	if len(rp.retryableErrors) == 0 {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, retryableError := range rp.retryableErrors {
+		switch strings.ToLower(retryableError) {
+		case retryableErrorsAll:
+			return true
+		case retryableErrorsTimeout:
+			if isTimeoutError(err, msg) {
+				return true
+			}
+		case retryableErrorsNetwork:
+			if isNetworkError(err, msg) {
+				return true
+			}
+		default:
+			matched, regErr := regexp.MatchString(retryableError, err.Error())
+			if regErr != nil {
+				log.Printf("[WARNING] invalid retryable error pattern %q: %v", retryableError, regErr)
+				continue
+			}
+			if matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isTimeoutError(err error, msg string) bool {
+	if isOperationTimeoutError(err) {
+		return true
+	}
+	cause := errors.Cause(err)
+	if cause == context.DeadlineExceeded {
+		return true
+	}
+	if netErr, ok := cause.(net.Error); ok && netErr.Timeout() {
+		return true
+	}
+	return containsOneOf(msg, timeoutErrorsMessages)
+}
+
+func isNetworkError(err error, msg string) bool {
+	cause := errors.Cause(err)
+	if cause == io.EOF || cause == io.ErrUnexpectedEOF {
+		return true
+	}
+	if _, ok := cause.(net.Error); ok {
+		return true
+	}
+	return containsOneOf(msg, networkErrorsMessages)
+}
+
+func containsOneOf(s string, substrings []string) bool {
+	for _, sub := range substrings {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
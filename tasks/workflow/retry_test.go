@@ -0,0 +1,114 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/tosca"
+)
+
+func TestNewRetryPolicy(t *testing.T) {
+	t.Parallel()
+	defaults := config.WfStepRetry{MaxAttempts: 1, Delay: 10 * time.Second, MaxDelay: time.Minute, Backoff: "fixed"}
+	tests := []struct {
+		name      string
+		stepRetry *tosca.Retry
+		want      retryPolicy
+		wantErr   bool
+	}{
+		{"DefaultsOnly", nil, retryPolicy{maxAttempts: 1, delay: 10 * time.Second, maxDelay: time.Minute, backoff: "fixed"}, false},
+		{"StepOverride", &tosca.Retry{MaxAttempts: 3, Delay: "1s", Backoff: "Exponential", RetryableErrors: []string{"timeout"}}, retryPolicy{maxAttempts: 3, delay: time.Second, maxDelay: time.Minute, backoff: "exponential", retryableErrors: []string{"timeout"}}, false},
+		{"NegativeAttempts", &tosca.Retry{MaxAttempts: -2}, retryPolicy{maxAttempts: 1, delay: 10 * time.Second, maxDelay: time.Minute, backoff: "fixed"}, false},
+		{"InvalidDelay", &tosca.Retry{Delay: "ten seconds"}, retryPolicy{}, true},
+		{"InvalidBackoff", &tosca.Retry{Backoff: "linear"}, retryPolicy{}, true},
+		{"InvalidPattern", &tosca.Retry{RetryableErrors: []string{"(unclosed"}}, retryPolicy{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newRetryPolicy(defaults, tt.stepRetry)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRetryPolicyDelayBeforeAttempt(t *testing.T) {
+	t.Parallel()
+	fixed := retryPolicy{maxAttempts: 5, delay: time.Second, backoff: retryBackoffFixed}
+	exponential := retryPolicy{maxAttempts: 5, delay: time.Second, maxDelay: 5 * time.Second, backoff: retryBackoffExponential}
+	tests := []struct {
+		name    string
+		policy  retryPolicy
+		attempt int
+		want    time.Duration
+	}{
+		{"FixedFirstAttempt", fixed, 1, 0},
+		{"FixedSecondAttempt", fixed, 2, time.Second},
+		{"FixedFifthAttempt", fixed, 5, time.Second},
+		{"ExponentialSecondAttempt", exponential, 2, time.Second},
+		{"ExponentialThirdAttempt", exponential, 3, 2 * time.Second},
+		{"ExponentialFourthAttempt", exponential, 4, 4 * time.Second},
+		{"ExponentialCappedAttempt", exponential, 5, 5 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.policy.delayBeforeAttempt(tt.attempt))
+		})
+	}
+}
+
+func TestRetryPolicyIsRetryable(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name            string
+		retryableErrors []string
+		err             error
+		want            bool
+	}{
+		{"NoErrorClasses", nil, errors.New("something went wrong"), true},
+		{"Canceled", nil, errors.Wrap(context.Canceled, "operation aborted"), false},
+		{"All", []string{"all"}, errors.New("something went wrong"), true},
+		{"TimeoutMatch", []string{"timeout"}, errors.Wrap(context.DeadlineExceeded, "failed"), true},
+		{"TimeoutMessageMatch", []string{"timeout"}, errors.New("Request Timed Out"), true},
+		{"TimeoutNoMatch", []string{"timeout"}, errors.New("invalid flavor"), false},
+		{"NetworkMatch", []string{"network"}, errors.New("dial tcp 10.0.0.1:443: connect: connection refused"), true},
+		{"NetworkEOF", []string{"network"}, errors.Wrap(io.EOF, "failed to read response"), true},
+		{"NetworkUnexpectedEOF", []string{"network"}, errors.Wrap(io.ErrUnexpectedEOF, "failed to read response"), true},
+		{"NetworkOpError", []string{"network"}, errors.Wrap(&net.OpError{Op: "read", Net: "tcp", Err: errors.New("reset")}, "failed"), true},
+		{"NetworkNoMatch", []string{"network"}, errors.New("unknown geofence zone"), false},
+		{"PatternMatch", []string{"network", "HTTP 50[0-9]"}, errors.New("Expected HTTP response code [202] but got HTTP 503"), true},
+		{"PatternNoMatch", []string{"HTTP 50[0-9]"}, errors.New("Expected HTTP response code [202] but got HTTP 404"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rp := retryPolicy{maxAttempts: 3, retryableErrors: tt.retryableErrors}
+			assert.Equal(t, tt.want, rp.isRetryable(tt.err))
+		})
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/consul/api"
+
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/events"
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/tasks"
+	"github.com/ystia/yorc/tosca"
+)
+
+// rollbackDeployment removes the resources created by a failed install workflow if it was requested for this deployment.
+//
+// The uninstall workflow is run only for nodes that were at least partially created during the install workflow.
+// Errors are not bypassed: the rollback stops at the first failing operation so leaked resources are not hidden.
+// The deployment status is set to ROLLED_BACK if the rollback succeeded, otherwise it is set to ROLLBACK_FAILED.
+//
+// Steps of the uninstall workflow are run in their own namespace so they don't mix with the install steps of the task.
+// As resources of install steps already done may have been removed, the task is flagged as rolled back before running
+// the uninstall workflow and can't be resumed anymore.
+func (w worker) rollbackDeployment(ctx context.Context, t *task) {
+	kv := w.consulClient.KV()
+	rollbackOnFailure, err := deployments.IsRollbackOnFailureEnabled(kv, t.TargetID)
+	if err != nil {
+		log.Printf("Deployment id: %q, Task id: %q, Failed to check if rollback on failure is enabled: %+v", t.TargetID, t.ID, err)
+		return
+	}
+	if !rollbackOnFailure || t.Status() == tasks.CANCELED || ctx.Err() != nil {
+		return
+	}
+
+	nodes, err := getNodesToRollback(kv, t)
+	if err != nil {
+		log.Printf("Deployment id: %q, Task id: %q, Failed to compute nodes to rollback: %+v", t.TargetID, t.ID, err)
+		events.SimpleLogEntry(events.ERROR, t.TargetID).Registerf("Failed to rollback deployment: %v", err)
+		return
+	}
+	nodesNames := make([]string, 0, len(nodes))
+	for nodeName := range nodes {
+		nodesNames = append(nodesNames, nodeName)
+	}
+	sort.Strings(nodesNames)
+	events.SimpleLogEntry(events.INFO, t.TargetID).Registerf("Rolling back deployment, removing resources of nodes: %s", strings.Join(nodesNames, ", "))
+
+	if len(nodes) > 0 {
+		if err = tasks.SetTaskRollbackAttempted(kv, t.ID); err != nil {
+			log.Printf("Deployment id: %q, Task id: %q, Failed to rollback deployment: %+v", t.TargetID, t.ID, err)
+			events.SimpleLogEntry(events.ERROR, t.TargetID).Registerf("Failed to rollback deployment: %v", err)
+			return
+		}
+		t.rollingBack = true
+		err = w.runWorkflow(ctx, t, "uninstall", false, nodes)
+		t.rollingBack = false
+		if err != nil {
+			log.Printf("Deployment id: %q, Task id: %q, Failed to rollback deployment: %+v", t.TargetID, t.ID, err)
+			events.SimpleLogEntry(events.ERROR, t.TargetID).Registerf("Failed to rollback deployment: %v", err)
+			w.setDeploymentStatus(t.TargetID, deployments.ROLLBACK_FAILED)
+			return
+		}
+	}
+	w.setDeploymentStatus(t.TargetID, deployments.ROLLED_BACK)
+}
+
+// getNodesToRollback returns the nodes targeted by steps of the install workflow that were executed by the given task
+// and which have at least one instance that may hold resources: instances in a state between creating and started
+// or in error as a failing step may have partially created them.
+func getNodesToRollback(kv *api.KV, t *task) (map[string]bool, error) {
+	wf, err := readWorkFlowFromConsul(kv, path.Join(consulutil.DeploymentKVPrefix, t.TargetID, "workflows", "install"))
+	if err != nil {
+		return nil, err
+	}
+	taskSteps, err := tasks.GetTaskRelatedSteps(kv, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	stepsStatus := make(map[string]string, len(taskSteps))
+	for _, taskStep := range taskSteps {
+		stepsStatus[taskStep.Name] = taskStep.Status
+	}
+
+	nodes := make(map[string]bool)
+	checkedNodes := make(map[string]bool)
+	for _, s := range wf {
+		if s.Target == "" || checkedNodes[s.Target] || stepsStatus[s.Name] == "" {
+			continue
+		}
+		stepStatus, err := tasks.ParseTaskStepStatus(stepsStatus[s.Name])
+		if err != nil {
+			return nil, err
+		}
+		if stepStatus != tasks.TaskStepStatusDONE && stepStatus != tasks.TaskStepStatusERROR {
+			continue
+		}
+		checkedNodes[s.Target] = true
+		instances, err := tasks.GetInstances(kv, t.ID, t.TargetID, s.Target)
+		if err != nil {
+			return nil, err
+		}
+		for _, instance := range instances {
+			state, err := deployments.GetInstanceState(kv, t.TargetID, s.Target, instance)
+			if err != nil {
+				return nil, err
+			}
+			if state >= tosca.NodeStateCreating && state <= tosca.NodeStateStarted || state == tosca.NodeStateError {
+				nodes[s.Target] = true
+				break
+			}
+		}
+	}
+	return nodes, nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"path"
+	"testing"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/helper/consulutil"
+)
+
+func testGetNodesToRollback(t *testing.T, srv1 *testutil.TestServer, kv *api.KV) {
+	t.Parallel()
+
+	deploymentID := "dep_" + path.Base(t.Name())
+	taskID := "task_" + path.Base(t.Name())
+	wfPrefix := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "workflows", "install", "steps")
+	instancesPrefix := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "instances")
+	data := make(map[string][]byte)
+
+	// Compute is started
+	data[wfPrefix+"/Compute_create/activities/0/delegate"] = []byte("install")
+	data[wfPrefix+"/Compute_create/target"] = []byte("Compute")
+	data[wfPrefix+"/Compute_create/next/Soft_create"] = []byte("")
+	data[path.Join(consulutil.WorkflowsPrefix, taskID, "Compute_create")] = []byte("done")
+	data[instancesPrefix+"/Compute/0/attributes/state"] = []byte("started")
+
+	// Soft creation failed
+	data[wfPrefix+"/Soft_create/activities/0/call-operation"] = []byte("Standard.create")
+	data[wfPrefix+"/Soft_create/target"] = []byte("Soft")
+	data[wfPrefix+"/Soft_create/next/Other_create"] = []byte("")
+	data[path.Join(consulutil.WorkflowsPrefix, taskID, "Soft_create")] = []byte("error")
+	data[instancesPrefix+"/Soft/0/attributes/state"] = []byte("error")
+
+	// Failed is in error but its steps were not run by this task
+	data[wfPrefix+"/Failed_create/activities/0/call-operation"] = []byte("Standard.create")
+	data[wfPrefix+"/Failed_create/target"] = []byte("Failed")
+	data[instancesPrefix+"/Failed/0/attributes/state"] = []byte("error")
+
+	// Other was never processed
+	data[wfPrefix+"/Other_create/activities/0/call-operation"] = []byte("Standard.create")
+	data[wfPrefix+"/Other_create/target"] = []byte("Other")
+	data[path.Join(consulutil.WorkflowsPrefix, taskID, "Other_create")] = []byte("initial")
+	data[instancesPrefix+"/Other/0/attributes/state"] = []byte("initial")
+
+	srv1.PopulateKV(t, data)
+
+	nodes, err := getNodesToRollback(kv, &task{ID: taskID, TargetID: deploymentID})
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{"Compute": true, "Soft": true}, nodes)
+}
@@ -33,14 +33,30 @@ type task struct {
 	TaskType     tasks.TaskType
 	creationDate time.Time
 	taskLock     *api.Lock
+	slots        concurrencySlots
 	kv           *api.KV
+	// rollingBack is true while the task rolls back the resources it created, steps run
+	// for the rollback have their own status namespace
+	rollingBack bool
 }
 
 func (t *task) releaseLock() {
+	t.slots.release()
 	t.taskLock.Unlock()
 	t.taskLock.Destroy()
 }
 
+// stepsStatusPrefix returns the prefix of the Consul keys storing the status of the workflow steps run by this task
+//
+// Steps run to roll back the task are stored apart from the steps of the task workflow, so a rollback never
+// overrides the status of the task steps and is not seen as done steps when listing them.
+func (t *task) stepsStatusPrefix() string {
+	if t.rollingBack {
+		return path.Join(consulutil.TasksPrefix, t.ID, "rollback", "steps")
+	}
+	return path.Join(consulutil.WorkflowsPrefix, t.ID)
+}
+
 func (t *task) Status() tasks.TaskStatus {
 	return t.status
 }
@@ -0,0 +1,97 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/prov"
+)
+
+// operationTimeoutError is returned when an operation did not complete before its timeout
+type operationTimeoutError struct {
+	opDesc  string
+	timeout time.Duration
+}
+
+func (e operationTimeoutError) Error() string {
+	return fmt.Sprintf("%s timed out after %s", e.opDesc, e.timeout)
+}
+
+func isOperationTimeoutError(err error) bool {
+	_, ok := errors.Cause(err).(operationTimeoutError)
+	return ok
+}
+
+// getOperationTimeout returns the timeout of an operation
+//
+// The timeout defined in the operation implementation takes precedence over the given default one.
+func getOperationTimeout(kv *api.KV, deploymentID string, op prov.Operation, defaultTimeout time.Duration) (time.Duration, error) {
+	timeout, err := deployments.GetOperationImplementationTimeout(kv, deploymentID, op.ImplementedInType, op.Name)
+	if err != nil {
+		return 0, err
+	}
+	if timeout > 0 {
+		return timeout, nil
+	}
+	return defaultTimeout, nil
+}
+
+// runWithTimeout runs the given operation function with a context canceled when the given timeout expires
+//
+// A zero or negative timeout means no timeout. Canceling the context kills the processes started by the operation
+// using helper/executil. The operation is then given up to gracePeriod to return before being abandoned.
+//
+// An abandoned operation can't be stopped: its goroutine keeps running until opFn returns, and so does any resource
+// it holds. Operation executors should therefore return promptly once their context is canceled, the grace period
+// only prevents a misbehaving executor from blocking the workflow forever.
+func runWithTimeout(ctx context.Context, timeout, gracePeriod time.Duration, opDesc string, opFn func(ctx context.Context) error) error {
+	if timeout <= 0 {
+		return opFn(ctx)
+	}
+	opCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- opFn(opCtx)
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil && ctx.Err() == nil && opCtx.Err() == context.DeadlineExceeded {
+			return operationTimeoutError{opDesc: opDesc, timeout: timeout}
+		}
+		return err
+	case <-opCtx.Done():
+		if ctx.Err() != nil {
+			// Canceled by the caller, not a timeout
+			return <-errCh
+		}
+	}
+
+	select {
+	case <-errCh:
+	case <-time.After(gracePeriod):
+		log.Printf("[WARNING] %s did not return %s after its timeout expiration, giving up on it while it may still be running", opDesc, gracePeriod)
+	}
+	return operationTimeoutError{opDesc: opDesc, timeout: timeout}
+}
@@ -0,0 +1,63 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunWithTimeout(t *testing.T) {
+	t.Parallel()
+	blockingOp := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	err := runWithTimeout(context.Background(), 0, time.Second, "operation", func(ctx context.Context) error {
+		return nil
+	})
+	assert.NoError(t, err, "no timeout")
+
+	err = runWithTimeout(context.Background(), time.Second, time.Second, "operation", func(ctx context.Context) error {
+		return errors.New("failed")
+	})
+	assert.EqualError(t, err, "failed", "operation error before timeout")
+
+	err = runWithTimeout(context.Background(), 10*time.Millisecond, time.Second, "operation \"start\"", blockingOp)
+	assert.True(t, isOperationTimeoutError(err), "expecting a timeout error, got %v", err)
+	assert.EqualError(t, err, "operation \"start\" timed out after 10ms")
+
+	err = runWithTimeout(context.Background(), 10*time.Millisecond, 10*time.Millisecond, "operation", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+	assert.True(t, isOperationTimeoutError(err), "expecting a timeout error when the operation does not return, got %v", err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = runWithTimeout(ctx, time.Minute, time.Second, "operation", blockingOp)
+	assert.Equal(t, context.Canceled, err, "caller cancellation should not be reported as a timeout")
+}
+
+func TestTimeoutErrorIsRetryable(t *testing.T) {
+	t.Parallel()
+	rp := retryPolicy{maxAttempts: 2, retryableErrors: []string{retryableErrorsTimeout}}
+	assert.True(t, rp.isRetryable(operationTimeoutError{opDesc: "operation", timeout: time.Second}))
+}
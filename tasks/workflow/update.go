@@ -0,0 +1,87 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/events"
+	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/tasks"
+)
+
+// runDeploymentUpdate applies a topology update computed by deployments.UpdateDeploymentDefinition.
+//
+// Removed nodes are uninstalled first and, only if they were successfully uninstalled, their definitions are deleted
+// along with files of the previous deployment archive kept for them, then added nodes are installed using the install workflow and finally
+// modified nodes are configured again.
+func (w worker) runDeploymentUpdate(ctx context.Context, t *task) error {
+	kv := w.consulClient.KV()
+	addedNodes, err := tasks.GetTaskDataList(kv, t.ID, tasks.UpdateAddedNodesDataName)
+	if err != nil {
+		return err
+	}
+	removedNodes, err := tasks.GetTaskDataList(kv, t.ID, tasks.UpdateRemovedNodesDataName)
+	if err != nil {
+		return err
+	}
+	modifiedNodes, err := tasks.GetTaskDataList(kv, t.ID, tasks.UpdateModifiedNodesDataName)
+	if err != nil {
+		return err
+	}
+	events.SimpleLogEntry(events.INFO, t.TargetID).RegisterAsString(fmt.Sprintf("Updating deployment, added nodes: [%s], removed nodes: [%s], modified nodes: [%s]",
+		strings.Join(addedNodes, ", "), strings.Join(removedNodes, ", "), strings.Join(modifiedNodes, ", ")))
+
+	if len(removedNodes) > 0 {
+		err = w.runWorkflow(ctx, t, deployments.UpdateUninstallWorkflowName, false, nil)
+		if err != nil {
+			return err
+		}
+		for _, nodeName := range removedNodes {
+			err = deployments.DeleteNode(kv, t.TargetID, nodeName)
+			if err != nil {
+				log.Printf("Deployment id: %q, Task id: %q, Failed to delete removed node %q: %+v", t.TargetID, t.ID, nodeName, err)
+				if t.Status() == tasks.RUNNING {
+					t.WithStatus(tasks.FAILED)
+				}
+				return err
+			}
+		}
+	}
+	// Files of the previous archive are not referenced anymore once removed nodes are uninstalled
+	err = deployments.PruneOverlay(w.cfg.WorkingDirectory, t.TargetID)
+	if err != nil {
+		log.Printf("Deployment id: %q, Task id: %q, Failed to remove files of the previous archive: %+v", t.TargetID, t.ID, err)
+	}
+
+	if len(addedNodes) > 0 {
+		nodes := make(map[string]bool, len(addedNodes))
+		for _, nodeName := range addedNodes {
+			nodes[nodeName] = true
+		}
+		err = w.runWorkflow(ctx, t, "install", false, nodes)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(modifiedNodes) > 0 {
+		return w.runWorkflow(ctx, t, deployments.UpdateConfigureWorkflowName, false, nil)
+	}
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -138,7 +139,8 @@ func (w worker) handleTask(t *task) {
 	// Fill log optional fields for log registration
 	wfName, _ := tasks.GetTaskData(kv, t.ID, "workflowName")
 	logOptFields := events.LogOptionalFields{
-		events.WorkFlowID: wfName,
+		events.WorkFlowID:  wfName,
+		events.ExecutionID: t.ID,
 	}
 	bgCtx := context.Background()
 	ctx, cancelFunc := context.WithCancel(bgCtx)
@@ -155,6 +157,7 @@ func (w worker) handleTask(t *task) {
 		err := w.runWorkflows(ctx, t, []string{"install"}, false)
 		if err != nil {
 			w.setDeploymentStatus(t.TargetID, deployments.DEPLOYMENT_FAILED)
+			w.rollbackDeployment(ctx, t)
 			return
 		}
 		w.setDeploymentStatus(t.TargetID, deployments.DEPLOYED)
@@ -181,6 +184,7 @@ func (w worker) handleTask(t *task) {
 				t.WithStatus(tasks.FAILED)
 				return
 			}
+			deployments.ClearAttributeConstraintsCache(t.TargetID)
 			tasksList, err := tasks.GetTasksIdsForTarget(kv, t.TargetID)
 			if err != nil {
 				log.Printf("Deployment id: %q, Task id: %q, Failed to purge tasks related to deployment: %+v", t.TargetID, t.ID, err)
@@ -288,10 +292,20 @@ func (w worker) handleTask(t *task) {
 			t.WithStatus(tasks.FAILED)
 			return
 		}
+		timeout, err := getOperationTimeout(kv, t.TargetID, op, w.cfg.WfStepOperationTimeout)
+		if err != nil {
+			log.Printf("Deployment id: %q, Task id: %q, Failed to retrieve timeout of operation %q, using the default one: %+v", t.TargetID, t.ID, op.Name, err)
+			timeout = w.cfg.WfStepOperationTimeout
+		}
 		err = func() error {
 			defer metrics.MeasureSince(metricsutil.CleanupMetricKey([]string{"executor", "operation", t.TargetID, nodeType, op.Name}), time.Now())
-			return exec.ExecOperation(ctx, w.cfg, t.ID, t.TargetID, nodeName, op)
+			return runWithTimeout(ctx, timeout, w.cfg.WfStepGracefulTerminationTimeout, fmt.Sprintf("custom command %q", commandName), func(opCtx context.Context) error {
+				return exec.ExecOperation(opCtx, w.cfg, t.ID, t.TargetID, nodeName, op)
+			})
 		}()
+		if isOperationTimeoutError(err) {
+			events.WithOptionalFields(events.LogOptionalFields{events.NodeID: nodeName}).NewLogEntry(events.ERROR, t.TargetID).RegisterAsString(fmt.Sprintf("%v, the command has been canceled", err))
+		}
 		if err != nil {
 			metrics.IncrCounter(metricsutil.CleanupMetricKey([]string{"executor", "operation", t.TargetID, nodeType, op.Name, "failures"}), 1)
 			log.Printf("Deployment id: %q, Task id: %q, Command execution failed for node %q: %+v", t.TargetID, t.ID, nodeName, err)
@@ -331,6 +345,28 @@ func (w worker) handleTask(t *task) {
 			return
 		}
 		w.setDeploymentStatus(t.TargetID, deployments.DEPLOYED)
+	case tasks.Update:
+		w.setDeploymentStatus(t.TargetID, deployments.UPDATE_IN_PROGRESS)
+		err := w.runDeploymentUpdate(ctx, t)
+		if err != nil {
+			log.Printf("Deployment id: %q, Task id: %q, Failed to update deployment: %+v", t.TargetID, t.ID, err)
+			if t.Status() == tasks.RUNNING {
+				t.WithStatus(tasks.FAILED)
+			}
+			w.setDeploymentStatus(t.TargetID, deployments.UPDATE_FAILED)
+			return
+		}
+		w.setDeploymentStatus(t.TargetID, deployments.DEPLOYED)
+	case tasks.Reconcile:
+		err := w.runDriftReconcile(ctx, t)
+		if err != nil {
+			log.Printf("Deployment id: %q, Task id: %q, Failed to reconcile deployment drifts: %+v", t.TargetID, t.ID, err)
+			events.WithOptionalFields(logOptFields).NewLogEntry(events.ERROR, t.TargetID).RegisterAsString(fmt.Sprintf("Failed to reconcile deployment drifts: %v", err))
+			if t.Status() == tasks.RUNNING {
+				t.WithStatus(tasks.FAILED)
+			}
+			return
+		}
 	case tasks.CustomWorkflow:
 		wfName, err := tasks.GetTaskData(kv, t.ID, "workflowName")
 		if err != nil {
@@ -403,6 +439,28 @@ func (w worker) handleTask(t *task) {
 					return
 				}
 			}
+		case "drift":
+			report, err := w.detectDeploymentDrift(ctx, t.ID, target)
+			if err != nil {
+				log.Printf("Query Task id: %q Failed to detect drifts of deployment %q: %v", t.ID, target, err)
+				log.Debugf("%+v", err)
+				t.WithStatus(tasks.FAILED)
+				return
+			}
+			jsonRes, err := json.Marshal(report)
+			if err != nil {
+				log.Printf("Failed to marshal drift report [%+v]: due to error:%+v", report, err)
+				log.Debugf("%+v", err)
+				t.WithStatus(tasks.FAILED)
+				return
+			}
+			kvPair := &api.KVPair{Key: path.Join(consulutil.TasksPrefix, t.ID, "resultSet"), Value: jsonRes}
+			if _, err := kv.Put(kvPair, nil); err != nil {
+				log.Printf("Query Task id: %q Failed to store result: %v", t.ID, errors.Wrap(err, consulutil.ConsulGenericErrMsg))
+				log.Debugf("%+v", err)
+				t.WithStatus(tasks.FAILED)
+				return
+			}
 		default:
 			mess := fmt.Sprintf("Unknown query: %q for Task with id %q", query, t.ID)
 			events.WithOptionalFields(logOptFields).NewLogEntry(events.ERROR, t.TargetID).RegisterAsString(mess)
@@ -508,39 +566,60 @@ func (w worker) monitorTaskForCancellation(ctx context.Context, cancelFunc conte
 }
 
 func (w worker) runWorkflows(ctx context.Context, t *task, workflows []string, bypassErrors bool) error {
-	kv := w.consulClient.KV()
 	for _, workflow := range workflows {
-		wf, err := readWorkFlowFromConsul(kv, path.Join(consulutil.DeploymentKVPrefix, t.TargetID, path.Join("workflows", workflow)))
-		if err != nil {
-			if t.Status() == tasks.RUNNING {
-				t.WithStatus(tasks.FAILED)
-			}
-			log.Printf("%v. Aborting", err)
+		if err := w.runWorkflow(ctx, t, workflow, bypassErrors, nil); err != nil {
 			return err
+		}
+	}
+	return nil
+}
 
+// runWorkflow runs a single workflow
+//
+// If targetNodes is not nil, steps targeting nodes that are not part of it are skipped.
+func (w worker) runWorkflow(ctx context.Context, t *task, workflow string, bypassErrors bool, targetNodes map[string]bool) error {
+	kv := w.consulClient.KV()
+	wf, err := readWorkFlowFromConsul(kv, path.Join(consulutil.DeploymentKVPrefix, t.TargetID, path.Join("workflows", workflow)))
+	if err != nil {
+		if t.Status() == tasks.RUNNING {
+			t.WithStatus(tasks.FAILED)
 		}
-		for _, step := range wf {
-			step.SetTaskID(t)
-			kvp, _, err := kv.Get(path.Join(consulutil.WorkflowsPrefix, t.ID, step.Name), nil)
-			if err != nil {
-				if t.Status() == tasks.RUNNING {
-					t.WithStatus(tasks.FAILED)
-				}
-				log.Printf("%v. Aborting", err)
-				return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
-			}
-			// Only create step key if step doesn't already exists to allow resuming task
-			if kvp == nil {
-				consulutil.StoreConsulKeyAsString(path.Join(consulutil.WorkflowsPrefix, t.ID, step.Name), "")
-			}
+		log.Printf("%v. Aborting", err)
+		return err
+
+	}
+	doneSteps := make([]string, 0)
+	for _, step := range wf {
+		step.SetTaskID(t)
+		if targetNodes != nil && step.Target != "" && !targetNodes[step.Target] {
+			step.excluded = true
 		}
-		if err = w.processWorkflow(ctx, workflow, wf, t.TargetID, bypassErrors); err != nil {
+		kvp, _, err := kv.Get(path.Join(t.stepsStatusPrefix(), step.Name), nil)
+		if err != nil {
 			if t.Status() == tasks.RUNNING {
 				t.WithStatus(tasks.FAILED)
 			}
 			log.Printf("%v. Aborting", err)
-			return err
+			return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
 		}
+		// Only create step key if step doesn't already exists to allow resuming task
+		if kvp == nil {
+			consulutil.StoreConsulKeyAsString(path.Join(t.stepsStatusPrefix(), step.Name), "")
+		} else if strings.EqualFold(string(kvp.Value), tasks.TaskStepStatusDONE.String()) {
+			doneSteps = append(doneSteps, step.Name)
+		}
+	}
+	if len(doneSteps) > 0 {
+		sort.Strings(doneSteps)
+		events.WithOptionalFields(events.LogOptionalFields{events.WorkFlowID: workflow}).NewLogEntry(events.INFO, t.TargetID).RegisterAsString(
+			fmt.Sprintf("Resuming workflow %q, steps already done will not be run again: %s", workflow, strings.Join(doneSteps, ", ")))
+	}
+	if err = w.processWorkflow(ctx, workflow, wf, t.TargetID, bypassErrors); err != nil {
+		if t.Status() == tasks.RUNNING {
+			t.WithStatus(tasks.FAILED)
+		}
+		log.Printf("%v. Aborting", err)
+		return err
 	}
 	return nil
 }
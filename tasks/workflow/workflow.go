@@ -47,13 +47,18 @@ type step struct {
 	Target             string
 	TargetRelationship string
 	OperationHost      string
+	Retry              *tosca.Retry
+	Filter             []tosca.ConditionClause
 	Activities         []activity
 	Next               []*step
+	OnFailure          []*step
 	Previous           []*step
-	NotifyChan         chan struct{}
+	NotifyChan         chan stepNotification
 	kv                 *api.KV
 	stepPrefix         string
 	t                  *task
+	// excluded steps are skipped without running their activities
+	excluded bool
 }
 
 type activity interface {
@@ -106,20 +111,46 @@ func (i inlineActivity) ActivityValue() string {
 }
 
 func (s *step) IsTerminal() bool {
-	return len(s.Next) == 0
+	return len(s.Next) == 0 && len(s.OnFailure) == 0
 }
 
 func (s *step) SetTaskID(taskID *task) {
 	s.t = taskID
 }
 
-func (s *step) notifyNext() {
-	for _, next := range s.Next {
-		log.Debugf("Step %q, notifying step %q", s.Name, next.Name)
-		next.NotifyChan <- struct{}{}
+// stepNotification is sent by a step to its following steps when it ends
+//
+// skipped is true if the notified step is not on the workflow execution path
+// (e.g. an on_failure step of a step that succeeded)
+type stepNotification struct {
+	skipped bool
+}
+
+func notifySteps(from string, steps []*step, skipped bool) {
+	for _, next := range steps {
+		log.Debugf("Step %q, notifying step %q (skipped: %t)", from, next.Name, skipped)
+		next.NotifyChan <- stepNotification{skipped: skipped}
 	}
 }
 
+// notifyNext notifies on success steps that they should run and on failure steps that they are skipped
+func (s *step) notifyNext() {
+	notifySteps(s.Name, s.Next, false)
+	notifySteps(s.Name, s.OnFailure, true)
+}
+
+// notifyOnFailure notifies on failure steps that they should run and on success steps that they are skipped
+func (s *step) notifyOnFailure() {
+	notifySteps(s.Name, s.Next, true)
+	notifySteps(s.Name, s.OnFailure, false)
+}
+
+// notifyAll notifies all following steps
+func (s *step) notifyAll(skipped bool) {
+	notifySteps(s.Name, s.Next, skipped)
+	notifySteps(s.Name, s.OnFailure, skipped)
+}
+
 type visitStep struct {
 	refCount int
 	s        *step
@@ -132,7 +163,7 @@ func (s *step) setStatus(status tasks.TaskStepStatus) error {
 	if err != nil {
 		return err
 	}
-	kvp = &api.KVPair{Key: path.Join(consulutil.WorkflowsPrefix, s.t.ID, s.Name), Value: []byte(statusStr)}
+	kvp = &api.KVPair{Key: path.Join(s.t.stepsStatusPrefix(), s.Name), Value: []byte(statusStr)}
 	_, err = s.kv.Put(kvp, nil)
 	return err
 }
@@ -153,43 +184,49 @@ func (s *step) isRelationshipTargetNodeRelated() (bool, error) {
 	return false, nil
 }
 
-// isRunnable Checks if a step should be run or bypassed
+// stepAlreadyDoneReason is the reason why a step is bypassed when it was already done by a previous run of the task
+const stepAlreadyDoneReason = "step already done"
+
+// notRunnableReason returns the reason why a step should be bypassed or an empty string if it should be run
 //
-// It first checks if the step is not already done in this workflow instance
+// It first checks if the step is not already done in this workflow instance, this guarantees that resuming a task
+// never runs again steps that succeeded.
 // And for ScaleOut and ScaleDown it checks if the node or the target node in case of an operation running on the target node is part of the operation
-func (s *step) isRunnable() (bool, error) {
-	kvp, _, err := s.kv.Get(path.Join(consulutil.WorkflowsPrefix, s.t.ID, s.Name), nil)
+func (s *step) notRunnableReason() (string, error) {
+	kvp, _, err := s.kv.Get(path.Join(s.t.stepsStatusPrefix(), s.Name), nil)
 	if err != nil {
-		return false, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+		return "", errors.Wrap(err, consulutil.ConsulGenericErrMsg)
 	}
 
 	// Check if step is already done
-	status := string(kvp.Value)
-	if status != "" {
-		stepStatus, err := tasks.ParseTaskStepStatus(status)
+	if kvp != nil && len(kvp.Value) > 0 {
+		stepStatus, err := tasks.ParseTaskStepStatus(string(kvp.Value))
 		if err != nil {
-			return false, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+			return "", errors.Wrap(err, consulutil.ConsulGenericErrMsg)
 		}
 
-		if kvp != nil && stepStatus == tasks.TaskStepStatusDONE {
-			return false, nil
+		if stepStatus == tasks.TaskStepStatusDONE {
+			return stepAlreadyDoneReason, nil
 		}
 	}
 
 	if s.t.TaskType == tasks.ScaleOut || s.t.TaskType == tasks.ScaleIn {
 		isNodeTargetTask, err := tasks.IsTaskRelatedNode(s.kv, s.t.ID, s.Target)
 		if err != nil {
-			return false, err
+			return "", err
 		}
 		if !isNodeTargetTask {
 			isTargetNodeRelated, err := s.isRelationshipTargetNodeRelated()
-			if err != nil || !isTargetNodeRelated {
-				return false, err
+			if err != nil {
+				return "", err
+			}
+			if !isTargetNodeRelated {
+				return "step target is not related to this task", nil
 			}
 		}
 	}
 
-	return true, nil
+	return "", nil
 }
 
 func setNodeStatus(kv *api.KV, taskID, deploymentID, nodeName, status string) error {
@@ -223,25 +260,34 @@ func (s *step) run(ctx context.Context, deploymentID string, kv *api.KV, ignored
 	}
 
 	// First: we check if step is runnable
-	if runnable, err := s.isRunnable(); err != nil {
+	if reason, err := s.notRunnableReason(); err != nil {
 		return err
-	} else if !runnable {
-		log.Debugf("Deployment %q: Skipping Step %q", deploymentID, s.Name)
-		events.WithOptionalFields(logOptFields).NewLogEntry(events.INFO, deploymentID).RegisterAsString(fmt.Sprintf("Skipping Step %q", s.Name))
+	} else if reason != "" {
+		log.Debugf("Deployment %q: Skipping Step %q: %s", deploymentID, s.Name, reason)
+		events.WithOptionalFields(logOptFields).NewLogEntry(events.INFO, deploymentID).RegisterAsString(fmt.Sprintf("Skipping Step %q: %s", s.Name, reason))
+		if reason == stepAlreadyDoneReason {
+			// Report it as skipped by this resumed run
+			if err = tasks.SetTaskStepSkippedOnResume(kv, s.t.ID, s.Name); err != nil {
+				return err
+			}
+		}
 		s.setStatus(tasks.TaskStepStatusDONE)
 		s.notifyNext()
 		return nil
 	}
 
 	s.setStatus(tasks.TaskStepStatusINITIAL)
-	haveErr := false
+	skippedBranches := 0
 	for i := 0; i < len(s.Previous); i++ {
 		// Wait for previous be done
 		log.Debugf("Step %q waiting for %d previous steps", s.Name, len(s.Previous)-i)
 		for {
 			select {
-			case <-s.NotifyChan:
+			case n := <-s.NotifyChan:
 				log.Debugf("Step %q caught a notification", s.Name)
+				if n.skipped {
+					skippedBranches++
+				}
 				goto BR
 			case <-shutdownChan:
 				log.Printf("Step %q canceled", s.Name)
@@ -257,8 +303,45 @@ func (s *step) run(ctx context.Context, deploymentID string, kv *api.KV, ignored
 		}
 	BR:
 	}
+
+	if len(s.Previous) > 0 && skippedBranches == len(s.Previous) {
+		log.Debugf("Deployment %q: Skipping Step %q as it is not on the workflow execution path", deploymentID, s.Name)
+		events.WithOptionalFields(logOptFields).NewLogEntry(events.INFO, deploymentID).RegisterAsString(fmt.Sprintf("Skipping Step %q as it is not on the workflow execution path", s.Name))
+		s.setStatus(tasks.TaskStepStatusSKIPPED)
+		s.notifyAll(true)
+		return nil
+	}
+
+	// Excluded steps are skipped only once previous steps are done so ordering of the workflow is preserved
+	if s.excluded {
+		log.Debugf("Deployment %q: Skipping Step %q as its target is excluded from this workflow run", deploymentID, s.Name)
+		events.WithOptionalFields(logOptFields).NewLogEntry(events.INFO, deploymentID).RegisterAsString(fmt.Sprintf("Skipping Step %q as its target is excluded from this workflow run", s.Name))
+		s.setStatus(tasks.TaskStepStatusSKIPPED)
+		s.notifyNext()
+		return nil
+	}
+
+	if satisfied, err := s.isFilterSatisfied(deploymentID); err != nil {
+		err = errors.Wrapf(err, "failed to evaluate filter of step %q", s.Name)
+		log.Printf("Deployment %q, Step %q: %v", deploymentID, s.Name, err)
+		return s.handleFailure(ctx, deploymentID, logOptFields, err)
+	} else if !satisfied {
+		log.Debugf("Deployment %q: Skipping Step %q as its filter is not satisfied", deploymentID, s.Name)
+		events.WithOptionalFields(logOptFields).NewLogEntry(events.INFO, deploymentID).RegisterAsString(fmt.Sprintf("Skipping Step %q as its filter is not satisfied", s.Name))
+		s.setStatus(tasks.TaskStepStatusSKIPPED)
+		s.notifyNext()
+		return nil
+	}
+
 	s.setStatus(tasks.TaskStepStatusRUNNING)
 
+	retry, err := newRetryPolicy(cfg.WfStepRetry, s.Retry)
+	if err != nil {
+		err = errors.Wrapf(err, "invalid retry policy for step %q", s.Name)
+		log.Printf("Deployment %q, Step %q: %v", deploymentID, s.Name, err)
+		return s.handleFailure(ctx, deploymentID, logOptFields, err)
+	}
+
 	// Create a new context to handle gracefully current step termination when an error occurred during another step
 	wfCtx, cancelWf := context.WithCancel(context.Background())
 	waitDoneCh := make(chan struct{})
@@ -290,6 +373,34 @@ func (s *step) run(ctx context.Context, deploymentID string, kv *api.KV, ignored
 	}()
 	defer cancelWf()
 
+	haveErr, err := s.runActivities(ctx, wfCtx, deploymentID, kv, ignoredErrsChan, cfg, retry, bypassErrors, logOptFields, w)
+	if err != nil {
+		return s.handleFailure(ctx, deploymentID, logOptFields, err)
+	}
+
+	if haveErr {
+		log.Debug("Step %s generate an error but workflow continue", s.Name)
+		s.setStatus(tasks.TaskStepStatusERROR)
+		if len(s.OnFailure) > 0 {
+			s.notifyOnFailure()
+		} else {
+			s.notifyNext()
+		}
+		// Return nil otherwise the rest of the workflow will be canceled
+		return nil
+	}
+	s.notifyNext()
+	log.Debugf("Step %s done without error.", s.Name)
+	s.setStatus(tasks.TaskStepStatusDONE)
+	return nil
+}
+
+// runActivities runs the activities of this step
+//
+// It returns an error that should stop the step. If errors are bypassed then it returns true as first return parameter
+// if at least one error occurred.
+func (s *step) runActivities(ctx, wfCtx context.Context, deploymentID string, kv *api.KV, ignoredErrsChan chan error, cfg config.Configuration, retry retryPolicy, bypassErrors bool, logOptFields events.LogOptionalFields, w worker) (bool, error) {
+	haveErr := false
 	log.Debugf("Processing step %q", s.Name)
 	for _, activity := range s.Activities {
 		actType := activity.ActivityType()
@@ -305,7 +416,7 @@ func (s *step) run(ctx context.Context, deploymentID string, kv *api.KV, ignored
 					ignoredErrsChan <- err
 					haveErr = true
 				} else {
-					return err
+					return haveErr, err
 				}
 			}
 			provisioner, err := registry.GetRegistry().GetDelegateExecutor(nodeType)
@@ -318,14 +429,17 @@ func (s *step) run(ctx context.Context, deploymentID string, kv *api.KV, ignored
 					ignoredErrsChan <- err
 					haveErr = true
 				} else {
-					return err
+					return haveErr, err
 				}
 			} else {
 				delegateOp := activity.ActivityValue()
-				err := func() error {
+				opDesc := fmt.Sprintf("delegate operation %q", delegateOp)
+				err := s.runWithRetry(wfCtx, retry, deploymentID, logOptFields, opDesc, func() error {
 					defer metrics.MeasureSince(metricsutil.CleanupMetricKey([]string{"executor", "delegate", deploymentID, nodeType, delegateOp}), time.Now())
-					return provisioner.ExecDelegate(wfCtx, cfg, s.t.ID, deploymentID, s.Target, delegateOp)
-				}()
+					return s.runWithTimeout(wfCtx, cfg, cfg.WfStepOperationTimeout, deploymentID, logOptFields, opDesc, func(opCtx context.Context) error {
+						return provisioner.ExecDelegate(opCtx, cfg, s.t.ID, deploymentID, s.Target, delegateOp)
+					})
+				})
 
 				if err != nil {
 					metrics.IncrCounter(metricsutil.CleanupMetricKey([]string{"executor", "delegate", deploymentID, nodeType, delegateOp, "failures"}), 1)
@@ -337,7 +451,7 @@ func (s *step) run(ctx context.Context, deploymentID string, kv *api.KV, ignored
 						ignoredErrsChan <- err
 						haveErr = true
 					} else {
-						return err
+						return haveErr, err
 					}
 				} else {
 					metrics.IncrCounter(metricsutil.CleanupMetricKey([]string{"executor", "delegate", deploymentID, nodeType, delegateOp, "successes"}), 1)
@@ -362,7 +476,7 @@ func (s *step) run(ctx context.Context, deploymentID string, kv *api.KV, ignored
 					haveErr = true
 				} else {
 					s.setStatus(tasks.TaskStepStatusERROR)
-					return err
+					return haveErr, err
 				}
 			}
 
@@ -376,7 +490,7 @@ func (s *step) run(ctx context.Context, deploymentID string, kv *api.KV, ignored
 					haveErr = true
 				} else {
 					s.setStatus(tasks.TaskStepStatusERROR)
-					return err
+					return haveErr, err
 				}
 			} else {
 				nodeType, err := deployments.GetNodeType(kv, deploymentID, s.Target)
@@ -389,13 +503,21 @@ func (s *step) run(ctx context.Context, deploymentID string, kv *api.KV, ignored
 						ignoredErrsChan <- err
 						haveErr = true
 					} else {
-						return err
+						return haveErr, err
 					}
 				}
-				err = func() error {
+				timeout, err := getOperationTimeout(kv, deploymentID, op, cfg.WfStepOperationTimeout)
+				if err != nil {
+					log.Printf("Deployment %q, Step %q: failed to retrieve timeout of operation %q, using the default one: %v", deploymentID, s.Name, op.Name, err)
+					timeout = cfg.WfStepOperationTimeout
+				}
+				opDesc := fmt.Sprintf("operation %q", op.Name)
+				err = s.runWithRetry(wfCtx, retry, deploymentID, logOptFields, opDesc, func() error {
 					defer metrics.MeasureSince(metricsutil.CleanupMetricKey([]string{"executor", "operation", deploymentID, nodeType, op.Name}), time.Now())
-					return exec.ExecOperation(wfCtx, cfg, s.t.ID, deploymentID, s.Target, op)
-				}()
+					return s.runWithTimeout(wfCtx, cfg, timeout, deploymentID, logOptFields, opDesc, func(opCtx context.Context) error {
+						return exec.ExecOperation(opCtx, cfg, s.t.ID, deploymentID, s.Target, op)
+					})
+				})
 				if err != nil {
 					metrics.IncrCounter(metricsutil.CleanupMetricKey([]string{"executor", "operation", deploymentID, nodeType, op.Name, "failures"}), 1)
 					setNodeStatus(kv, s.t.ID, deploymentID, s.Target, tosca.NodeStateError.String())
@@ -406,7 +528,7 @@ func (s *step) run(ctx context.Context, deploymentID string, kv *api.KV, ignored
 						haveErr = true
 					} else {
 						s.setStatus(tasks.TaskStepStatusERROR)
-						return err
+						return haveErr, err
 					}
 				} else {
 					metrics.IncrCounter(metricsutil.CleanupMetricKey([]string{"executor", "operation", deploymentID, nodeType, op.Name, "successes"}), 1)
@@ -414,24 +536,92 @@ func (s *step) run(ctx context.Context, deploymentID string, kv *api.KV, ignored
 			}
 		case wfInlineActivity:
 			if err := w.runWorkflows(ctx, s.t, []string{activity.ActivityValue()}, bypassErrors); err != nil {
-				return err
+				return haveErr, err
 			}
 		}
 	}
 
-	s.notifyNext()
+	return haveErr, nil
+}
 
-	if haveErr {
-		log.Debug("Step %s generate an error but workflow continue", s.Name)
-		s.setStatus(tasks.TaskStepStatusERROR)
-		// Return nil otherwise the rest of the workflow will be canceled
-		return nil
+// handleFailure handles an error that occurred while running this step
+//
+// If this step defines steps to run on failure and the workflow is not canceled, then the error is logged,
+// the on failure steps are notified and nil is returned to allow the workflow to continue.
+// Otherwise the given error is returned.
+func (s *step) handleFailure(ctx context.Context, deploymentID string, logOptFields events.LogOptionalFields, err error) error {
+	s.setStatus(tasks.TaskStepStatusERROR)
+	if len(s.OnFailure) == 0 || ctx.Err() != nil {
+		return err
 	}
-	log.Debugf("Step %s done without error.", s.Name)
-	s.setStatus(tasks.TaskStepStatusDONE)
+	events.WithOptionalFields(logOptFields).NewLogEntry(events.ERROR, deploymentID).RegisterAsString(fmt.Sprintf("Step %q failed: %v. Running its on_failure steps.", s.Name, err))
+	log.Printf("Deployment %q, Step %q failed: %v. Running its on_failure steps.", deploymentID, s.Name, err)
+	s.notifyOnFailure()
 	return nil
 }
 
+// isFilterSatisfied checks if the step filter is satisfied by at least one of the target instances
+//
+// A step without filter is always satisfied.
+func (s *step) isFilterSatisfied(deploymentID string) (bool, error) {
+	if len(s.Filter) == 0 {
+		return true, nil
+	}
+	instances, err := tasks.GetInstances(s.kv, s.t.ID, deploymentID, s.Target)
+	if err != nil {
+		return false, err
+	}
+	for _, instance := range instances {
+		satisfied, err := tosca.EvaluateConditionClauses(s.Filter, func(attributeName string) (string, error) {
+			_, value, err := deployments.GetInstanceAttribute(s.kv, deploymentID, s.Target, instance, attributeName)
+			return value, err
+		})
+		if err != nil || satisfied {
+			return satisfied, err
+		}
+	}
+	return false, nil
+}
+
+// runWithRetry runs the given operation function according to the given retry policy.
+//
+// Each attempt is recorded in the task step and failed attempts are logged. The error of the last attempt is returned.
+func (s *step) runWithRetry(ctx context.Context, retry retryPolicy, deploymentID string, logOptFields events.LogOptionalFields, opDesc string, opFn func() error) error {
+	for attempt := 1; ; attempt++ {
+		if err := tasks.SetTaskStepAttempts(s.kv, s.t.ID, s.Name, attempt); err != nil {
+			log.Printf("Deployment %q, Step %q: failed to store attempts number: %v", deploymentID, s.Name, err)
+		}
+		err := opFn()
+		if err == nil {
+			return nil
+		}
+		if attempt >= retry.maxAttempts || ctx.Err() != nil || !retry.isRetryable(err) {
+			if attempt > 1 {
+				events.WithOptionalFields(logOptFields).NewLogEntry(events.ERROR, deploymentID).RegisterAsString(fmt.Sprintf("Step %q: %s failed after %d attempts", s.Name, opDesc, attempt))
+			}
+			return err
+		}
+		delay := retry.delayBeforeAttempt(attempt + 1)
+		metrics.IncrCounter(metricsutil.CleanupMetricKey([]string{"workflow", "steps", deploymentID, s.Name, "retries"}), 1)
+		events.WithOptionalFields(logOptFields).NewLogEntry(events.WARN, deploymentID).RegisterAsString(fmt.Sprintf("Step %q: %s failed (attempt %d/%d): %v. Retrying in %s", s.Name, opDesc, attempt, retry.maxAttempts, err, delay))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// runWithTimeout runs an operation of this step with the given timeout and logs an event if it expires
+func (s *step) runWithTimeout(ctx context.Context, cfg config.Configuration, timeout time.Duration, deploymentID string, logOptFields events.LogOptionalFields, opDesc string, opFn func(ctx context.Context) error) error {
+	err := runWithTimeout(ctx, timeout, cfg.WfStepGracefulTerminationTimeout, opDesc, opFn)
+	if isOperationTimeoutError(err) {
+		metrics.IncrCounter(metricsutil.CleanupMetricKey([]string{"workflow", "steps", deploymentID, s.Name, "timeouts"}), 1)
+		events.WithOptionalFields(logOptFields).NewLogEntry(events.ERROR, deploymentID).RegisterAsString(fmt.Sprintf("Step %q: %v, the operation has been canceled", s.Name, err))
+	}
+	return err
+}
+
 func readStep(kv *api.KV, stepsPrefix, stepName string, visitedMap map[string]*visitStep) (*step, error) {
 	stepPrefix := stepsPrefix + stepName
 	s := &step{Name: stepName, kv: kv, stepPrefix: stepPrefix}
@@ -459,6 +649,11 @@ func readStep(kv *api.KV, stepsPrefix, stepName string, visitedMap map[string]*v
 		return nil, errors.Errorf("Invalid value %q for operation host with step %s : only SELF or HOST values are accepted", s.OperationHost, stepName)
 	}
 
+	s.Retry, err = deployments.ReadWorkflowStepRetry(kv, stepPrefix)
+	if err != nil {
+		return nil, err
+	}
+
 	kvKeys, _, err := kv.List(stepPrefix+"/activities/", nil)
 	if err != nil {
 		return nil, err
@@ -501,15 +696,40 @@ func readStep(kv *api.KV, stepsPrefix, stepName string, visitedMap map[string]*v
 		s.Target = string(kvPair.Value)
 	}
 
-	kvPairs, _, err := kv.List(stepPrefix+"/next", nil)
+	s.Filter, err = deployments.ReadWorkflowStepFilter(kv, stepPrefix)
 	if err != nil {
 		return nil, err
 	}
-	s.Next = make([]*step, 0)
+	if len(s.Filter) > 0 && s.Target == "" {
+		return nil, errors.Errorf("Missing target attribute for step %s, it is mandatory when a filter is defined", stepName)
+	}
+
 	s.Previous = make([]*step, 0)
+	s.Next, err = s.readLinkedSteps(kv, stepsPrefix, "next", visitedMap)
+	if err != nil {
+		return nil, err
+	}
+	s.OnFailure, err = s.readLinkedSteps(kv, stepsPrefix, "on_failure", visitedMap)
+	if err != nil {
+		return nil, err
+	}
+	visitedMap[stepName] = &visitStep{refCount: 0, s: s}
+	return s, nil
+
+}
+
+// readLinkedSteps reads the steps linked to this step with the given link type (next or on_failure)
+// and registers this step as a previous step of them
+func (s *step) readLinkedSteps(kv *api.KV, stepsPrefix, linkType string, visitedMap map[string]*visitStep) ([]*step, error) {
+	linkPrefix := path.Join(s.stepPrefix, linkType)
+	kvPairs, _, err := kv.List(linkPrefix, nil)
+	if err != nil {
+		return nil, err
+	}
+	linkedSteps := make([]*step, 0)
 	for _, nextKV := range kvPairs {
 		var nextStep *step
-		nextStepName := strings.TrimPrefix(nextKV.Key, stepPrefix+"/next/")
+		nextStepName := strings.TrimPrefix(nextKV.Key, linkPrefix+"/")
 		if visitStep, ok := visitedMap[nextStepName]; ok {
 			log.Debugf("Found existing step %s", nextStepName)
 			nextStep = visitStep.s
@@ -521,14 +741,12 @@ func readStep(kv *api.KV, stepsPrefix, stepName string, visitedMap map[string]*v
 			}
 		}
 
-		s.Next = append(s.Next, nextStep)
+		linkedSteps = append(linkedSteps, nextStep)
 		nextStep.Previous = append(nextStep.Previous, s)
 		visitedMap[nextStepName].refCount++
 		log.Debugf("RefCount for step %s set to %d", nextStepName, visitedMap[nextStepName].refCount)
 	}
-	visitedMap[stepName] = &visitStep{refCount: 0, s: s}
-	return s, nil
-
+	return linkedSteps, nil
 }
 
 // Creates a workflow tree from values stored in Consul at the given prefix.
@@ -557,7 +775,7 @@ func readWorkFlowFromConsul(kv *api.KV, wfPrefix string) ([]*step, error) {
 
 	// build buffered NotifyChan with a size related to the previous steps nb
 	for _, s := range steps {
-		s.NotifyChan = make(chan struct{}, len(s.Previous))
+		s.NotifyChan = make(chan stepNotification, len(s.Previous))
 	}
 	return steps, nil
 }
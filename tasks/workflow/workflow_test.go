@@ -22,7 +22,9 @@ import (
 	"github.com/hashicorp/consul/api"
 	"github.com/hashicorp/consul/testutil"
 	"github.com/stretchr/testify/require"
+	"github.com/ystia/yorc/helper/consulutil"
 	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/tasks"
 )
 
 func testReadStepFromConsulFailing(t *testing.T, srv1 *testutil.TestServer, kv *api.KV) {
@@ -144,3 +146,111 @@ func testReadWorkFlowFromConsul(t *testing.T, srv1 *testutil.TestServer, kv *api
 	require.Nil(t, err, "oups")
 	require.Len(t, steps, 6)
 }
+
+func testReadStepWithRetry(t *testing.T, srv1 *testutil.TestServer, kv *api.KV) {
+	t.Parallel()
+
+	wfName := "wf_" + path.Base(t.Name())
+	data := make(map[string][]byte)
+	data[wfName+"/steps/stepName/activities/0/delegate"] = []byte("install")
+	data[wfName+"/steps/stepName/target"] = []byte("nodeName")
+	data[wfName+"/steps/stepName/retry/max_attempts"] = []byte("3")
+	data[wfName+"/steps/stepName/retry/delay"] = []byte("5s")
+	data[wfName+"/steps/stepName/retry/backoff"] = []byte("exponential")
+	data[wfName+"/steps/stepName/retry/retryable_errors/0"] = []byte("timeout")
+	data[wfName+"/steps/stepName/retry/retryable_errors/1"] = []byte("HTTP 50[0-9]")
+
+	data[wfName+"/steps/noRetry/activities/0/delegate"] = []byte("install")
+	data[wfName+"/steps/noRetry/target"] = []byte("nodeName")
+
+	srv1.PopulateKV(t, data)
+
+	visitedMap := make(map[string]*visitStep)
+	step, err := readStep(kv, wfName+"/steps/", "stepName", visitedMap)
+	require.Nil(t, err)
+	require.NotNil(t, step.Retry)
+	require.Equal(t, 3, step.Retry.MaxAttempts)
+	require.Equal(t, "5s", step.Retry.Delay)
+	require.Equal(t, "", step.Retry.MaxDelay)
+	require.Equal(t, "exponential", step.Retry.Backoff)
+	require.Equal(t, []string{"timeout", "HTTP 50[0-9]"}, step.Retry.RetryableErrors)
+
+	step, err = readStep(kv, wfName+"/steps/", "noRetry", visitedMap)
+	require.Nil(t, err)
+	require.Nil(t, step.Retry)
+}
+
+func testReadStepWithFilterAndOnFailure(t *testing.T, srv1 *testutil.TestServer, kv *api.KV) {
+	t.Parallel()
+
+	wfName := "wf_" + path.Base(t.Name())
+	data := make(map[string][]byte)
+	data[wfName+"/steps/stepName/activities/0/call-operation"] = []byte("Standard.start")
+	data[wfName+"/steps/stepName/target"] = []byte("nodeName")
+	data[wfName+"/steps/stepName/filter"] = []byte("- state:\n  - equal: configured\n")
+	data[wfName+"/steps/stepName/next/done"] = []byte("")
+	data[wfName+"/steps/stepName/on_failure/cleanup"] = []byte("")
+
+	data[wfName+"/steps/done/activities/0/set-state"] = []byte("started")
+	data[wfName+"/steps/done/target"] = []byte("nodeName")
+
+	data[wfName+"/steps/cleanup/activities/0/delegate"] = []byte("uninstall")
+	data[wfName+"/steps/cleanup/target"] = []byte("nodeName")
+
+	data[wfName+"/steps/noTarget/activities/0/inline"] = []byte("other_wf")
+	data[wfName+"/steps/noTarget/filter"] = []byte("- state:\n  - equal: configured\n")
+
+	srv1.PopulateKV(t, data)
+
+	visitedMap := make(map[string]*visitStep)
+	step, err := readStep(kv, wfName+"/steps/", "stepName", visitedMap)
+	require.Nil(t, err)
+	require.Len(t, step.Filter, 1)
+	require.Len(t, step.Filter[0].Assert["state"], 1)
+	require.Equal(t, "configured", step.Filter[0].Assert["state"][0].Value)
+	require.Len(t, step.Next, 1)
+	require.Equal(t, "done", step.Next[0].Name)
+	require.Len(t, step.OnFailure, 1)
+	require.Equal(t, "cleanup", step.OnFailure[0].Name)
+	require.Len(t, step.OnFailure[0].Previous, 1)
+	require.Equal(t, "stepName", step.OnFailure[0].Previous[0].Name)
+	require.False(t, step.IsTerminal())
+	require.True(t, step.OnFailure[0].IsTerminal())
+
+	_, err = readStep(kv, wfName+"/steps/", "noTarget", visitedMap)
+	require.Error(t, err, "Expecting an error for a step with a filter but no target")
+}
+
+func testResumeTaskSteps(t *testing.T, srv1 *testutil.TestServer, kv *api.KV) {
+	t.Parallel()
+
+	taskID := "task_" + path.Base(t.Name())
+	srv1.PopulateKV(t, map[string][]byte{
+		path.Join(consulutil.WorkflowsPrefix, taskID, "Compute_create"): []byte("done"),
+		path.Join(consulutil.WorkflowsPrefix, taskID, "Soft_create"):    []byte("error"),
+		path.Join(consulutil.WorkflowsPrefix, taskID, "Soft_start"):     []byte("initial"),
+	})
+
+	resumedTask := &task{ID: taskID, TargetID: "dep_" + path.Base(t.Name()), TaskType: tasks.Deploy, kv: kv}
+	reasons := make(map[string]string)
+	for _, stepName := range []string{"Compute_create", "Soft_create", "Soft_start", "Other_create"} {
+		s := &step{Name: stepName, Target: "Node", kv: kv, t: resumedTask}
+		reason, err := s.notRunnableReason()
+		require.NoError(t, err)
+		reasons[stepName] = reason
+	}
+	// Only steps already done are not run again
+	require.Equal(t, map[string]string{
+		"Compute_create": stepAlreadyDoneReason,
+		"Soft_create":    "",
+		"Soft_start":     "",
+		"Other_create":   "",
+	}, reasons)
+
+	// Steps of a rollback don't share the status of the task steps
+	resumedTask.rollingBack = true
+	s := &step{Name: "Compute_create", Target: "Node", kv: kv, t: resumedTask}
+	reason, err := s.notRunnableReason()
+	require.NoError(t, err)
+	require.Equal(t, "", reason)
+}
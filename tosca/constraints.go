@@ -0,0 +1,264 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tosca
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Constraint operators as defined in the TOSCA specification
+const (
+	ConstraintEqual          = "equal"
+	ConstraintGreaterThan    = "greater_than"
+	ConstraintGreaterOrEqual = "greater_or_equal"
+	ConstraintLessThan       = "less_than"
+	ConstraintLessOrEqual    = "less_or_equal"
+	ConstraintInRange        = "in_range"
+	ConstraintValidValues    = "valid_values"
+	ConstraintLength         = "length"
+	ConstraintMinLength      = "min_length"
+	ConstraintMaxLength      = "max_length"
+	ConstraintPattern        = "pattern"
+)
+
+// An ConstraintClause is the representation of a TOSCA Constraint Clause
+//
+// See http://docs.oasis-open.org/tosca/TOSCA-Simple-Profile-YAML/v1.2/TOSCA-Simple-Profile-YAML-v1.2.html#DEFN_ELEMENT_CONSTRAINTS_CLAUSE for more details
+type ConstraintClause struct {
+	Operator string
+	Value    interface{}
+}
+
+// UnmarshalYAML unmarshals a yaml into a ConstraintClause
+func (c *ConstraintClause) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var m map[string]interface{}
+	if err := unmarshal(&m); err != nil {
+		return err
+	}
+	if len(m) != 1 {
+		return errors.Errorf("a constraint clause should have exactly one operator, got %d", len(m))
+	}
+	for op, value := range m {
+		c.Operator = op
+		c.Value = value
+	}
+	return c.validate()
+}
+
+// MarshalYAML marshals a ConstraintClause into yaml
+func (c ConstraintClause) MarshalYAML() (interface{}, error) {
+	return map[string]interface{}{c.Operator: c.Value}, nil
+}
+
+// MarshalJSON marshals a ConstraintClause into json
+func (c ConstraintClause) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{c.Operator: c.Value})
+}
+
+// String returns the textual representation of a ConstraintClause
+func (c ConstraintClause) String() string {
+	return fmt.Sprintf("%s: %v", c.Operator, c.Value)
+}
+
+func (c ConstraintClause) validate() error {
+	switch c.Operator {
+	case ConstraintEqual, ConstraintGreaterThan, ConstraintGreaterOrEqual, ConstraintLessThan, ConstraintLessOrEqual:
+		if _, ok := c.Value.([]interface{}); ok {
+			return errors.Errorf("constraint operator %q expects a scalar value", c.Operator)
+		}
+	case ConstraintInRange:
+		if l, ok := c.Value.([]interface{}); !ok || len(l) != 2 {
+			return errors.Errorf("constraint operator %q expects a list of two values", c.Operator)
+		}
+	case ConstraintValidValues:
+		if _, ok := c.Value.([]interface{}); !ok {
+			return errors.Errorf("constraint operator %q expects a list of values", c.Operator)
+		}
+	case ConstraintLength, ConstraintMinLength, ConstraintMaxLength:
+		if _, err := strconv.Atoi(fmt.Sprint(c.Value)); err != nil {
+			return errors.Errorf("constraint operator %q expects an integer value", c.Operator)
+		}
+	case ConstraintPattern:
+		if _, err := regexp.Compile(fmt.Sprint(c.Value)); err != nil {
+			return errors.Wrapf(err, "invalid pattern for constraint operator %q", c.Operator)
+		}
+	default:
+		return errors.Errorf("unsupported constraint operator %q", c.Operator)
+	}
+	return nil
+}
+
+// Evaluate checks if the given value satisfies this constraint
+//
+// Values are compared as numbers if both of them could be parsed as numbers otherwise they are compared as strings.
+func (c ConstraintClause) Evaluate(value string) (bool, error) {
+	switch c.Operator {
+	case ConstraintEqual:
+		return compareValues(value, c.Value) == 0, nil
+	case ConstraintGreaterThan:
+		return compareValues(value, c.Value) > 0, nil
+	case ConstraintGreaterOrEqual:
+		return compareValues(value, c.Value) >= 0, nil
+	case ConstraintLessThan:
+		return compareValues(value, c.Value) < 0, nil
+	case ConstraintLessOrEqual:
+		return compareValues(value, c.Value) <= 0, nil
+	case ConstraintInRange:
+		bounds, ok := c.Value.([]interface{})
+		if !ok || len(bounds) != 2 {
+			return false, errors.Errorf("constraint operator %q expects a list of two values", c.Operator)
+		}
+		return compareValues(value, bounds[0]) >= 0 && (fmt.Sprint(bounds[1]) == "UNBOUNDED" || compareValues(value, bounds[1]) <= 0), nil
+	case ConstraintValidValues:
+		validValues, ok := c.Value.([]interface{})
+		if !ok {
+			return false, errors.Errorf("constraint operator %q expects a list of values", c.Operator)
+		}
+		for _, v := range validValues {
+			if compareValues(value, v) == 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+	case ConstraintLength, ConstraintMinLength, ConstraintMaxLength:
+		return c.EvaluateLength(len([]rune(value)))
+	case ConstraintPattern:
+		// TOSCA patterns should match the whole value
+		return regexp.MatchString("^(?:"+fmt.Sprint(c.Value)+")$", value)
+	}
+	return false, errors.Errorf("unsupported constraint operator %q", c.Operator)
+}
+
+// IsLengthConstraint returns true if this constraint applies on the length of a value
+func (c ConstraintClause) IsLengthConstraint() bool {
+	return c.Operator == ConstraintLength || c.Operator == ConstraintMinLength || c.Operator == ConstraintMaxLength
+}
+
+// EvaluateLength checks if the given length satisfies this length, min_length or max_length constraint
+//
+// This allows to check the number of entries of lists and maps.
+func (c ConstraintClause) EvaluateLength(length int) (bool, error) {
+	if !c.IsLengthConstraint() {
+		return false, errors.Errorf("constraint operator %q does not apply on a length", c.Operator)
+	}
+	expected, err := strconv.Atoi(fmt.Sprint(c.Value))
+	if err != nil {
+		return false, errors.Errorf("constraint operator %q expects an integer value", c.Operator)
+	}
+	switch c.Operator {
+	case ConstraintMinLength:
+		return length >= expected, nil
+	case ConstraintMaxLength:
+		return length <= expected, nil
+	}
+	return length == expected, nil
+}
+
+// compareValues compares a value with a constraint value and returns an integer that is
+// 0 if they are equal, negative if value is lower than constraintValue and positive otherwise.
+//
+// Scalar-unit values of the same kind (like 512 MB and 1 GiB) are compared after conversion to their base unit.
+func compareValues(value string, constraintValue interface{}) int {
+	cv := fmt.Sprint(constraintValue)
+	f1, err1 := strconv.ParseFloat(value, 64)
+	f2, err2 := strconv.ParseFloat(cv, 64)
+	if err1 == nil && err2 == nil {
+		return compareFloats(f1, f2)
+	}
+	s1, kind1, ok1 := parseScalarUnit(value)
+	s2, kind2, ok2 := parseScalarUnit(cv)
+	if ok1 && ok2 && kind1 == kind2 {
+		return compareFloats(s1, s2)
+	}
+	switch {
+	case value < cv:
+		return -1
+	case value > cv:
+		return 1
+	}
+	return 0
+}
+
+func compareFloats(f1, f2 float64) int {
+	switch {
+	case f1 < f2:
+		return -1
+	case f1 > f2:
+		return 1
+	}
+	return 0
+}
+
+// Kinds of TOSCA scalar-unit values
+const (
+	scalarUnitSize      = "scalar-unit.size"
+	scalarUnitTime      = "scalar-unit.time"
+	scalarUnitFrequency = "scalar-unit.frequency"
+)
+
+type scalarUnit struct {
+	kind       string
+	multiplier float64
+}
+
+// scalarUnits are indexed by their lower case representation as TOSCA units are case-insensitive
+var scalarUnits = map[string]scalarUnit{
+	"b":   {scalarUnitSize, 1},
+	"kb":  {scalarUnitSize, 1e3},
+	"kib": {scalarUnitSize, 1 << 10},
+	"mb":  {scalarUnitSize, 1e6},
+	"mib": {scalarUnitSize, 1 << 20},
+	"gb":  {scalarUnitSize, 1e9},
+	"gib": {scalarUnitSize, 1 << 30},
+	"tb":  {scalarUnitSize, 1e12},
+	"tib": {scalarUnitSize, 1 << 40},
+	"d":   {scalarUnitTime, 86400},
+	"h":   {scalarUnitTime, 3600},
+	"m":   {scalarUnitTime, 60},
+	"s":   {scalarUnitTime, 1},
+	"ms":  {scalarUnitTime, 1e-3},
+	"us":  {scalarUnitTime, 1e-6},
+	"ns":  {scalarUnitTime, 1e-9},
+	"hz":  {scalarUnitFrequency, 1},
+	"khz": {scalarUnitFrequency, 1e3},
+	"mhz": {scalarUnitFrequency, 1e6},
+	"ghz": {scalarUnitFrequency, 1e9},
+}
+
+var scalarUnitRegexp = regexp.MustCompile(`^\s*([-+]?[0-9]*\.?[0-9]+(?:[eE][-+]?[0-9]+)?)\s*([a-zA-Z]+)\s*$`)
+
+// parseScalarUnit parses a TOSCA scalar-unit value and returns its value converted in the base unit
+// of its kind (bytes, seconds or hertz) and its kind.
+func parseScalarUnit(value string) (float64, string, bool) {
+	m := scalarUnitRegexp.FindStringSubmatch(value)
+	if m == nil {
+		return 0, "", false
+	}
+	unit, ok := scalarUnits[strings.ToLower(m[2])]
+	if !ok {
+		return 0, "", false
+	}
+	f, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, "", false
+	}
+	return f * unit.multiplier, unit.kind, true
+}
@@ -0,0 +1,85 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tosca
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestGroupedGroupsParallel(t *testing.T) {
+	t.Run("groupGroups", func(t *testing.T) {
+		t.Run("TestGroups", groups)
+		t.Run("TestGroupTypes", groupTypes)
+	})
+}
+
+func groups(t *testing.T) {
+	t.Parallel()
+	var data = `
+topology_template:
+  node_templates:
+    DB1:
+      type: tosca.nodes.Database
+    DB2:
+      type: tosca.nodes.Database
+  groups:
+    db_replicas:
+      type: yorc.groups.Replicas
+      description: All database replicas
+      metadata:
+        owner: dba
+      properties:
+        quorum: 2
+      members: [ DB1, DB2 ]
+`
+	topo := Topology{}
+	err := yaml.Unmarshal([]byte(data), &topo)
+	require.NoError(t, err)
+	require.Contains(t, topo.TopologyTemplate.Groups, "db_replicas")
+	g := topo.TopologyTemplate.Groups["db_replicas"]
+	assert.Equal(t, "yorc.groups.Replicas", g.Type)
+	assert.Equal(t, "All database replicas", g.Description)
+	assert.Equal(t, map[string]string{"owner": "dba"}, g.Metadata)
+	assert.Equal(t, []string{"DB1", "DB2"}, g.Members)
+	require.Contains(t, g.Properties, "quorum")
+	assert.Equal(t, "2", g.Properties["quorum"].String())
+}
+
+func groupTypes(t *testing.T) {
+	t.Parallel()
+	var data = `
+group_types:
+  yorc.groups.Replicas:
+    derived_from: tosca.groups.Root
+    properties:
+      quorum:
+        type: integer
+        default: 1
+    members: [ tosca.nodes.Database ]
+`
+	topo := Topology{}
+	err := yaml.Unmarshal([]byte(data), &topo)
+	require.NoError(t, err)
+	require.Contains(t, topo.GroupTypes, "yorc.groups.Replicas")
+	gt := topo.GroupTypes["yorc.groups.Replicas"]
+	assert.Equal(t, "tosca.groups.Root", gt.DerivedFrom)
+	assert.Equal(t, []string{"tosca.nodes.Database"}, gt.Members)
+	require.Contains(t, gt.Properties, "quorum")
+	assert.Equal(t, "integer", gt.Properties["quorum"].Type)
+}
@@ -51,7 +51,8 @@ func implementationComplexGrammar(t *testing.T) {
 	var inputYaml = `
 implementation:
   primary: scripts/start_server.sh
-  operation_host: HOST`
+  operation_host: HOST
+  timeout: 300`
 	implem := implementationTestType{}
 
 	err := yaml.Unmarshal([]byte(inputYaml), &implem)
@@ -59,6 +60,7 @@ implementation:
 	assert.Equal(t, "scripts/start_server.sh", implem.Implementation.Primary)
 	assert.Len(t, implem.Implementation.Dependencies, 0, "Expecting no dependencies but found %d", len(implem.Implementation.Dependencies))
 	assert.Equal(t, "HOST", implem.Implementation.OperationHost)
+	assert.Equal(t, 300, implem.Implementation.Timeout)
 }
 
 func implementationArtifact(t *testing.T) {
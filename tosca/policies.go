@@ -0,0 +1,75 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tosca
+
+import (
+	"github.com/pkg/errors"
+)
+
+// A PolicyType is the representation of a TOSCA Policy Type
+//
+// See http://docs.oasis-open.org/tosca/TOSCA-Simple-Profile-YAML/v1.2/TOSCA-Simple-Profile-YAML-v1.2.html#DEFN_ENTITY_POLICY_TYPE
+// for more details
+type PolicyType struct {
+	Type       `yaml:",inline"`
+	Properties map[string]PropertyDefinition `yaml:"properties,omitempty"`
+	Targets    []string                      `yaml:"targets,omitempty,flow"`
+	// Triggers are not supported in Yorc so we don't parse them
+}
+
+// A Policy is the representation of a TOSCA Policy Definition
+//
+// See http://docs.oasis-open.org/tosca/TOSCA-Simple-Profile-YAML/v1.2/TOSCA-Simple-Profile-YAML-v1.2.html#DEFN_ELEMENT_POLICY_DEF
+// for more details
+type Policy struct {
+	Type        string                      `yaml:"type"`
+	Description string                      `yaml:"description,omitempty"`
+	Metadata    map[string]string           `yaml:"metadata,omitempty"`
+	Properties  map[string]*ValueAssignment `yaml:"properties,omitempty"`
+	Targets     []string                    `yaml:"targets,omitempty,flow"`
+	// Triggers are not supported in Yorc so we don't parse them
+}
+
+// PolicyMap is a map of Policy indexed by policy name
+type PolicyMap map[string]Policy
+
+// UnmarshalYAML unmarshals a yaml into a PolicyMap
+//
+// The TOSCA grammar defines policies as a list of single-entry maps but some tools generate a map,
+// so both forms are accepted.
+func (pm *PolicyMap) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	*pm = make(PolicyMap)
+	var m map[string]Policy
+	if err := unmarshal(&m); err == nil {
+		for k, v := range m {
+			(*pm)[k] = v
+		}
+		return nil
+	}
+
+	var l []map[string]Policy
+	if err := unmarshal(&l); err != nil {
+		return errors.Wrap(err, "policies should be either a list of policy definitions or a map of policy definitions")
+	}
+	for _, m := range l {
+		for k, v := range m {
+			if _, ok := (*pm)[k]; ok {
+				return errors.Errorf("policy %q is defined several times", k)
+			}
+			(*pm)[k] = v
+		}
+	}
+	return nil
+}
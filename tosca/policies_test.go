@@ -0,0 +1,117 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tosca
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestGroupedPoliciesParallel(t *testing.T) {
+	t.Run("groupPolicies", func(t *testing.T) {
+		t.Run("TestPoliciesAsList", policiesAsList)
+		t.Run("TestPoliciesAsMap", policiesAsMap)
+		t.Run("TestPoliciesDuplicated", policiesDuplicated)
+		t.Run("TestPolicyTypes", policyTypes)
+	})
+}
+
+func policiesAsList(t *testing.T) {
+	t.Parallel()
+	var data = `
+topology_template:
+  node_templates:
+    Compute:
+      type: tosca.nodes.Compute
+  policies:
+    - my_placement:
+        type: tosca.policies.Placement
+        description: Place compute in zone A
+        metadata:
+          owner: ops
+        properties:
+          zone: { get_input: zone }
+        targets: [ Compute ]
+    - my_scaling:
+        type: tosca.policies.Scaling
+`
+	topo := Topology{}
+	err := yaml.Unmarshal([]byte(data), &topo)
+	require.NoError(t, err)
+	require.Len(t, topo.TopologyTemplate.Policies, 2)
+	require.Contains(t, topo.TopologyTemplate.Policies, "my_placement")
+	require.Contains(t, topo.TopologyTemplate.Policies, "my_scaling")
+	p := topo.TopologyTemplate.Policies["my_placement"]
+	assert.Equal(t, "tosca.policies.Placement", p.Type)
+	assert.Equal(t, "Place compute in zone A", p.Description)
+	assert.Equal(t, map[string]string{"owner": "ops"}, p.Metadata)
+	assert.Equal(t, []string{"Compute"}, p.Targets)
+	require.Contains(t, p.Properties, "zone")
+	assert.Equal(t, ValueAssignmentFunction, p.Properties["zone"].Type)
+	assert.Equal(t, "tosca.policies.Scaling", topo.TopologyTemplate.Policies["my_scaling"].Type)
+}
+
+func policiesAsMap(t *testing.T) {
+	t.Parallel()
+	var data = `
+my_placement:
+  type: tosca.policies.Placement
+  targets: [ Compute, Network ]
+`
+	var policies PolicyMap
+	err := yaml.Unmarshal([]byte(data), &policies)
+	require.NoError(t, err)
+	require.Contains(t, policies, "my_placement")
+	assert.Equal(t, []string{"Compute", "Network"}, policies["my_placement"].Targets)
+}
+
+func policiesDuplicated(t *testing.T) {
+	t.Parallel()
+	var data = `
+- my_placement:
+    type: tosca.policies.Placement
+- my_placement:
+    type: tosca.policies.Scaling
+`
+	var policies PolicyMap
+	err := yaml.Unmarshal([]byte(data), &policies)
+	assert.Error(t, err)
+}
+
+func policyTypes(t *testing.T) {
+	t.Parallel()
+	var data = `
+policy_types:
+  yorc.policies.Parallelism:
+    derived_from: tosca.policies.Root
+    properties:
+      max:
+        type: integer
+        default: 1
+    targets: [ tosca.nodes.Root ]
+`
+	topo := Topology{}
+	err := yaml.Unmarshal([]byte(data), &topo)
+	require.NoError(t, err)
+	require.Contains(t, topo.PolicyTypes, "yorc.policies.Parallelism")
+	pt := topo.PolicyTypes["yorc.policies.Parallelism"]
+	assert.Equal(t, "tosca.policies.Root", pt.DerivedFrom)
+	assert.Equal(t, []string{"tosca.nodes.Root"}, pt.Targets)
+	require.Contains(t, pt.Properties, "max")
+	assert.Equal(t, "integer", pt.Properties["max"].Type)
+}
@@ -0,0 +1,32 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tosca
+
+// A SubstitutionMapping is the representation of TOSCA Substitution Mappings
+//
+// It allows a topology template to be used as the implementation of a node template of another topology.
+// Properties are mapped to inputs of the substituting topology ([ <input_name> ]) or to properties of its
+// node templates ([ <node_template_name>, <property_name> ]). Capabilities, requirements and attributes
+// are mapped to the ones of its node templates ([ <node_template_name>, <name> ]).
+//
+// See http://docs.oasis-open.org/tosca/TOSCA-Simple-Profile-YAML/v1.2/TOSCA-Simple-Profile-YAML-v1.2.html#DEFN_ELEMENT_SUBSTITUTION_MAPPING
+// for more details
+type SubstitutionMapping struct {
+	NodeType     string              `yaml:"node_type"`
+	Properties   map[string][]string `yaml:"properties,omitempty"`
+	Capabilities map[string][]string `yaml:"capabilities,omitempty"`
+	Requirements map[string][]string `yaml:"requirements,omitempty"`
+	Attributes   map[string][]string `yaml:"attributes,omitempty"`
+}
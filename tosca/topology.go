@@ -33,8 +33,8 @@ type Topology struct {
 	NodeTypes         map[string]NodeType         `yaml:"node_types,omitempty"`
 	CapabilityTypes   map[string]CapabilityType   `yaml:"capability_types,omitempty"`
 	RelationshipTypes map[string]RelationshipType `yaml:"relationship_types,omitempty"`
-	// TODO Group Types
-	// TODO Policy Types
+	PolicyTypes       map[string]PolicyType       `yaml:"policy_types,omitempty"`
+	GroupTypes        map[string]GroupType        `yaml:"group_types,omitempty"`
 
 	TopologyTemplate TopologyTemplate `yaml:"topology_template"`
 }
@@ -47,11 +47,11 @@ type TopologyTemplate struct {
 	Inputs        map[string]ParameterDefinition `yaml:"inputs,omitempty"`
 	NodeTemplates map[string]NodeTemplate        `yaml:"node_templates"`
 	//RelationshipTemplates []RelationshipTemplate `yaml:"relationship_templates,omitempty"`
-	//Groups                []Group `yaml:",omitempty"`
-	//Policies              []Policy                 `yaml:",omitempty"`
-	Outputs map[string]ParameterDefinition `yaml:"outputs,omitempty"`
-	//substitution_mappings
-	Workflows map[string]Workflow
+	Groups               map[string]Group               `yaml:"groups,omitempty"`
+	Policies             PolicyMap                      `yaml:"policies,omitempty"`
+	Outputs              map[string]ParameterDefinition `yaml:"outputs,omitempty"`
+	SubstitutionMappings *SubstitutionMapping           `yaml:"substitution_mappings,omitempty"`
+	Workflows            map[string]Workflow
 }
 
 // An NodeTemplate is the representation of a TOSCA Node Template
@@ -68,9 +68,9 @@ type NodeTemplate struct {
 	Artifacts    ArtifactDefMap                  `yaml:"artifacts,omitempty"`
 }
 
-//A Repository is representation of TOSCA Repository
+// A Repository is representation of TOSCA Repository
 //
-//See http://docs.oasis-open.org/tosca/TOSCA-Simple-Profile-YAML/v1.0/csprd01/TOSCA-Simple-Profile-YAML-v1.0-csprd01.html#_Toc430015673 for more details
+// See http://docs.oasis-open.org/tosca/TOSCA-Simple-Profile-YAML/v1.0/csprd01/TOSCA-Simple-Profile-YAML-v1.0-csprd01.html#_Toc430015673 for more details
 type Repository struct {
 	URL         string     `yaml:"url,omitempty"`
 	Type        string     `yaml:"type,omitempty"`
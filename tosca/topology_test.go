@@ -55,3 +55,47 @@ topology_template:
 	require.Equal(t, false, *input.Required)
 	require.Equal(t, `http://10.197.132.16/sla`, input.Value.GetLiteral())
 }
+
+func TestTopologyTemplate_SubstitutionMappings(t *testing.T) {
+	data := `
+topology_template:
+  inputs:
+    port:
+      type: integer
+  substitution_mappings:
+    node_type: org.ystia.nodes.WebApp
+    properties:
+      port: [ port ]
+      context_root: [ Server, context_root ]
+    capabilities:
+      app_endpoint: [ Server, endpoint ]
+    requirements:
+      database: [ Server, database ]
+    attributes:
+      url: [ Server, url ]
+  node_templates:
+    Server:
+      type: org.ystia.nodes.AppServer
+`
+	topo := Topology{}
+
+	err := yaml.Unmarshal([]byte(data), &topo)
+	require.NoError(t, err)
+	sm := topo.TopologyTemplate.SubstitutionMappings
+	require.NotNil(t, sm)
+	require.Equal(t, "org.ystia.nodes.WebApp", sm.NodeType)
+	require.Equal(t, map[string][]string{"port": {"port"}, "context_root": {"Server", "context_root"}}, sm.Properties)
+	require.Equal(t, map[string][]string{"app_endpoint": {"Server", "endpoint"}}, sm.Capabilities)
+	require.Equal(t, map[string][]string{"database": {"Server", "database"}}, sm.Requirements)
+	require.Equal(t, map[string][]string{"url": {"Server", "url"}}, sm.Attributes)
+
+	topo = Topology{}
+	err = yaml.Unmarshal([]byte(`
+topology_template:
+  node_templates:
+    Server:
+      type: org.ystia.nodes.AppServer
+`), &topo)
+	require.NoError(t, err)
+	require.Nil(t, topo.TopologyTemplate.SubstitutionMappings)
+}
@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/ystia/yorc/log"
@@ -37,9 +38,14 @@ const (
 	GetOperationOutputOperator Operator = "get_operation_output"
 	// ConcatOperator is the Operator of the concat function
 	ConcatOperator Operator = "concat"
-	// TokenOperator     Operator = "token"
-	// GetNodesOfTypeOperator     Operator = "get_nodes_of_type"
-	// GetArtifactOperator        Operator = "get_artifact"
+	// JoinOperator is the Operator of the join function
+	JoinOperator Operator = "join"
+	// TokenOperator is the Operator of the token function
+	TokenOperator Operator = "token"
+	// GetNodesOfTypeOperator is the Operator of the get_nodes_of_type function
+	GetNodesOfTypeOperator Operator = "get_nodes_of_type"
+	// GetArtifactOperator is the Operator of the get_artifact function
+	GetArtifactOperator Operator = "get_artifact"
 )
 
 // IsOperator checks if a given token is a known TOSCA function keyword
@@ -48,7 +54,11 @@ func IsOperator(op string) bool {
 		op == string(GetAttributeOperator) ||
 		op == string(GetInputOperator) ||
 		op == string(GetOperationOutputOperator) ||
-		op == string(ConcatOperator)
+		op == string(ConcatOperator) ||
+		op == string(JoinOperator) ||
+		op == string(TokenOperator) ||
+		op == string(GetNodesOfTypeOperator) ||
+		op == string(GetArtifactOperator)
 }
 
 func parseOperator(op string) (Operator, error) {
@@ -63,13 +73,21 @@ func parseOperator(op string) (Operator, error) {
 		return GetOperationOutputOperator, nil
 	case op == string(ConcatOperator):
 		return ConcatOperator, nil
+	case op == string(JoinOperator):
+		return JoinOperator, nil
+	case op == string(TokenOperator):
+		return TokenOperator, nil
+	case op == string(GetNodesOfTypeOperator):
+		return GetNodesOfTypeOperator, nil
+	case op == string(GetArtifactOperator):
+		return GetArtifactOperator, nil
 	default:
 		return GetPropertyOperator, errors.Errorf("%q is not a known or supported TOSCA operator", op)
 
 	}
 }
 
-// Operand represents the parameters part of a TOSCA function it could be a LiteralOperand or a Function
+// Operand represents the parameters part of a TOSCA function it could be a LiteralOperand, a ListOperand or a Function
 type Operand interface {
 	fmt.Stringer
 	// IsLiteral allows to know if an Operand is a LiteralOperand (true) or a TOSCA Function (false)
@@ -86,13 +104,34 @@ func (l LiteralOperand) IsLiteral() bool {
 
 func (l LiteralOperand) String() string {
 	s := string(l)
-	if shouldQuoteYamlString(s) {
+	if s == "" || strings.TrimSpace(s) != s || shouldQuoteYamlString(s) {
 		// Quote String if it contains YAML special chars
 		s = strconv.Quote(s)
 	}
 	return s
 }
 
+// ListOperand represents a list of operands in a TOSCA function like the list of values of a join function
+type ListOperand []Operand
+
+// IsLiteral allows to know if an Operand is a LiteralOperand (true) or a TOSCA Function (false)
+func (l ListOperand) IsLiteral() bool {
+	return false
+}
+
+func (l ListOperand) String() string {
+	var b bytes.Buffer
+	b.WriteString("[")
+	for i := range l {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(l[i].String())
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
 // Function models a TOSCA Function
 //
 // A Function is composed by an Operator and a list of Operand
@@ -111,9 +150,11 @@ func (f Function) String() string {
 	b.WriteString(string(f.Operator))
 	b.WriteString(": ")
 	if len(f.Operands) == 1 {
-		// Shortcut
-		b.WriteString(f.Operands[0].String())
-		return b.String()
+		if _, isList := f.Operands[0].(ListOperand); !isList {
+			// Shortcut
+			b.WriteString(f.Operands[0].String())
+			return b.String()
+		}
 	}
 	b.WriteString("[")
 	for i := range f.Operands {
@@ -135,13 +176,25 @@ func (f *Function) GetFunctionsByOperator(o Operator) []*Function {
 		result = append(result, f)
 	}
 	for _, op := range f.Operands {
-		if !op.IsLiteral() {
-			result = append(result, op.(*Function).GetFunctionsByOperator(o)...)
-		}
+		result = append(result, getOperandFunctionsByOperator(op, o)...)
 	}
 	return result
 }
 
+func getOperandFunctionsByOperator(op Operand, o Operator) []*Function {
+	switch v := op.(type) {
+	case *Function:
+		return v.GetFunctionsByOperator(o)
+	case ListOperand:
+		result := make([]*Function, 0)
+		for _, subOp := range v {
+			result = append(result, getOperandFunctionsByOperator(subOp, o)...)
+		}
+		return result
+	}
+	return nil
+}
+
 // UnmarshalYAML unmarshal a yaml into a Function
 func (f *Function) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	var m map[interface{}]interface{}
@@ -172,7 +225,12 @@ func parseFunctionOperands(value interface{}) ([]Operand, error) {
 			if err != nil {
 				return nil, err
 			}
-			ops[i] = o[0]
+			if _, isList := op.([]interface{}); isList {
+				// Nested list like the values of a join function
+				ops[i] = ListOperand(o)
+			} else {
+				ops[i] = o[0]
+			}
 		}
 	case map[interface{}]interface{}:
 		log.Debugf("Found map value %v %T", v, v)
@@ -201,8 +259,63 @@ func parseFunction(f map[interface{}]interface{}) (*Function, error) {
 		if err != nil {
 			return nil, err
 		}
-
-		return &Function{Operator: operator, Operands: ops}, nil
+		fn := &Function{Operator: operator, Operands: ops}
+		return fn, fn.checkOperands()
 	}
 	return nil, errors.Errorf("Not a TOSCA function")
 }
+
+// checkOperands checks the number and the kind of operands of the functions which have a fixed signature
+func (f *Function) checkOperands() error {
+	switch f.Operator {
+	case JoinOperator:
+		if len(f.Operands) < 1 || len(f.Operands) > 2 {
+			return errors.Errorf("%q: expecting a list of values and an optional delimiter as parameters of a join function", f)
+		}
+		if f.Operands[0].IsLiteral() {
+			return errors.Errorf("%q: expecting a list or a function returning a list as first parameter of a join function", f)
+		}
+		if len(f.Operands) == 2 && !f.Operands[1].IsLiteral() {
+			return errors.Errorf("%q: expecting a literal delimiter as second parameter of a join function", f)
+		}
+	case TokenOperator:
+		if len(f.Operands) != 3 {
+			return errors.Errorf("%q: expecting exactly three parameters for a token function", f)
+		}
+		if err := checkNoListOperand(f); err != nil {
+			return err
+		}
+		if f.Operands[2].IsLiteral() {
+			if i, err := strconv.Atoi(string(f.Operands[2].(LiteralOperand))); err != nil || i < 0 {
+				return errors.Errorf("%q: expecting a positive integer as substring index of a token function", f)
+			}
+		}
+	case GetNodesOfTypeOperator:
+		if len(f.Operands) != 1 {
+			return errors.Errorf("%q: expecting exactly one parameter for a get_nodes_of_type function", f)
+		}
+		return checkNoListOperand(f)
+	case GetArtifactOperator:
+		if len(f.Operands) < 2 || len(f.Operands) > 4 {
+			return errors.Errorf("%q: expecting between two and four parameters for a get_artifact function", f)
+		}
+		if err := checkNoListOperand(f); err != nil {
+			return err
+		}
+		if len(f.Operands) == 4 && f.Operands[3].IsLiteral() {
+			if _, err := strconv.ParseBool(string(f.Operands[3].(LiteralOperand))); err != nil {
+				return errors.Errorf("%q: expecting a boolean as remove parameter of a get_artifact function", f)
+			}
+		}
+	}
+	return nil
+}
+
+func checkNoListOperand(f *Function) error {
+	for _, op := range f.Operands {
+		if _, isList := op.(ListOperand); isList {
+			return errors.Errorf("%q: unexpected list parameter for a %s function", f, f.Operator)
+		}
+	}
+	return nil
+}
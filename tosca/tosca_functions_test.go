@@ -40,6 +40,19 @@ func TestFunctionParsing(t *testing.T) {
 		{"TestConcatFunction", inputs{yml: "concat: [get_property: [SELF, ip_address], get_attribute: [SELF, port]]"}, false},
 		{"TestGetInputFunction", inputs{yml: "get_input: ip_address"}, false},
 		{"TestConcatFunctionQuoting", inputs{yml: `concat: ["http://", get_property: [SELF, ip_address], get_attribute: [SELF, port], "\"ff\""]`}, false},
+		{"TestJoinFunction", inputs{yml: `join: [[get_input: user, "@", get_attribute: [SELF, public_address]], ":"]`}, false},
+		{"TestJoinFunctionNoDelimiter", inputs{yml: "join: [[a, b]]"}, false},
+		{"TestJoinFunctionSpaceDelimiter", inputs{yml: `join: [[a, b], " "]`}, false},
+		{"TestJoinFunctionOfFunction", inputs{yml: `join: [get_nodes_of_type: tosca.nodes.Compute, ","]`}, false},
+		{"TestJoinFunctionLiteral", inputs{yml: `join: [a, ","]`}, true},
+		{"TestTokenFunction", inputs{yml: `token: [get_attribute: [SELF, ip_cidr], /, 0]`}, false},
+		{"TestTokenFunctionInvalidIndex", inputs{yml: `token: [get_attribute: [SELF, ip_cidr], /, first]`}, true},
+		{"TestTokenFunctionMissingIndex", inputs{yml: `token: [get_attribute: [SELF, ip_cidr], /]`}, true},
+		{"TestGetNodesOfTypeFunction", inputs{yml: "get_nodes_of_type: tosca.nodes.Compute"}, false},
+		{"TestGetNodesOfTypeFunctionTooManyParams", inputs{yml: "get_nodes_of_type: [tosca.nodes.Compute, tosca.nodes.Root]"}, true},
+		{"TestGetArtifactFunction", inputs{yml: "get_artifact: [SELF, config, LOCAL_FILE, true]"}, false},
+		{"TestGetArtifactFunctionInvalidRemove", inputs{yml: "get_artifact: [SELF, config, LOCAL_FILE, maybe]"}, true},
+		{"TestGetArtifactFunctionMissingName", inputs{yml: "get_artifact: SELF"}, true},
 	}
 
 	for _, tt := range tests {
@@ -64,6 +77,7 @@ func TestOperandsImplementation(t *testing.T) {
 	// Checks that LiteralOperand and Function implement the Operand interface
 	var _ Operand = (*LiteralOperand)(nil)
 	var _ Operand = (*Function)(nil)
+	var _ Operand = (ListOperand)(nil)
 }
 
 func generateFunctionFromYaml(t testing.TB, yml string) *Function {
@@ -90,6 +104,7 @@ func TestFunction_GetFunctionsByOperator(t *testing.T) {
 		{"nestedLevel", generateFunctionFromYaml(t, `{concat: [get_property: [SELF, port]]}`), args{GetPropertyOperator}, []*Function{generateFunctionFromYaml(t, `{get_property: [SELF, port]}`)}},
 		{"severalNestedLevel", generateFunctionFromYaml(t, `{concat: [get_property: [SELF, port], concat: [get_input: "i", get_property: [SELF, test]]]}`), args{GetPropertyOperator}, []*Function{generateFunctionFromYaml(t, `{get_property: [SELF, port]}`), generateFunctionFromYaml(t, `{get_property: [SELF, test]}`)}},
 		{"notFound", generateFunctionFromYaml(t, `{concat: [get_property: [SELF, port], concat: [get_input: "i", get_property: [SELF, test]]]}`), args{GetAttributeOperator}, []*Function{}},
+		{"inJoinList", generateFunctionFromYaml(t, `{join: [[a, get_attribute: [SELF, port]], ":"]}`), args{GetAttributeOperator}, []*Function{generateFunctionFromYaml(t, `{get_attribute: [SELF, port]}`)}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -103,8 +103,7 @@ type ArtifactType struct {
 // See http://docs.oasis-open.org/tosca/TOSCA-Simple-Profile-YAML/v1.2/TOSCA-Simple-Profile-YAML-v1.2.html#DEFN_ENTITY_DATA_TYPE
 // for more details
 type DataType struct {
-	Type       `yaml:",inline"`
-	Properties map[string]PropertyDefinition `yaml:"properties,omitempty"`
-	// Constraints not enforced in Yorc so we don't parse them
-	// Constraints []ConstraintClause
+	Type        `yaml:",inline"`
+	Properties  map[string]PropertyDefinition `yaml:"properties,omitempty"`
+	Constraints []ConstraintClause            `yaml:"constraints,omitempty"`
 }
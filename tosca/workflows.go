@@ -14,6 +14,10 @@
 
 package tosca
 
+import (
+	"github.com/pkg/errors"
+)
+
 // An Workflow is the representation of a TOSCA Workflow
 //
 // Currently Workflows are not part of the TOSCA specification
@@ -25,11 +29,25 @@ type Workflow struct {
 //
 // Currently Workflows are not part of the TOSCA specification
 type Step struct {
-	Target             string     `yaml:"target,omitempty" json:"target,omitempty"`
-	TargetRelationShip string     `yaml:"target_relationship,omitempty" json:"target_relationship,omitempty"`
-	Activities         []Activity `yaml:"activities" json:"activities"`
-	OnSuccess          []string   `yaml:"on_success,omitempty" json:"on_success,omitempty"`
-	OperationHost      string     `yaml:"operation_host,omitempty" json:"operation_host,omitempty"`
+	Target             string            `yaml:"target,omitempty" json:"target,omitempty"`
+	TargetRelationShip string            `yaml:"target_relationship,omitempty" json:"target_relationship,omitempty"`
+	Filter             []ConditionClause `yaml:"filter,omitempty" json:"filter,omitempty"`
+	Activities         []Activity        `yaml:"activities" json:"activities"`
+	OnSuccess          []string          `yaml:"on_success,omitempty" json:"on_success,omitempty"`
+	OnFailure          []string          `yaml:"on_failure,omitempty" json:"on_failure,omitempty"`
+	OperationHost      string            `yaml:"operation_host,omitempty" json:"operation_host,omitempty"`
+	Retry              *Retry            `yaml:"retry,omitempty" json:"retry,omitempty"`
+}
+
+// A Retry is the representation of a retry policy applied to the operations called by a Workflow Step
+//
+// Retry policies are a Yorc extension of TOSCA workflows. Unset values fallback to the Yorc server defaults.
+type Retry struct {
+	MaxAttempts     int      `yaml:"max_attempts,omitempty" json:"max_attempts,omitempty"`
+	Delay           string   `yaml:"delay,omitempty" json:"delay,omitempty"`
+	MaxDelay        string   `yaml:"max_delay,omitempty" json:"max_delay,omitempty"`
+	Backoff         string   `yaml:"backoff,omitempty" json:"backoff,omitempty"`
+	RetryableErrors []string `yaml:"retryable_errors,omitempty" json:"retryable_errors,omitempty"`
 }
 
 // An Activity is the representation of a TOSCA Workflow Step Activity
@@ -41,3 +59,124 @@ type Activity struct {
 	CallOperation string `yaml:"call_operation,omitempty" json:"call_operation,omitempty"`
 	Inline        string `yaml:"inline,omitempty" json:"inline,omitempty"`
 }
+
+// Logical operators of condition clauses
+const (
+	ConditionAnd    = "and"
+	ConditionOr     = "or"
+	ConditionNot    = "not"
+	ConditionAssert = "assert"
+)
+
+// A ConditionClause is the representation of a TOSCA Condition Clause used to filter workflows steps
+//
+// A condition clause is either a logical operator (and, or, not) applied on other condition clauses
+// or an assertion of a list of constraints on attributes.
+// See http://docs.oasis-open.org/tosca/TOSCA-Simple-Profile-YAML/v1.2/TOSCA-Simple-Profile-YAML-v1.2.html#DEFN_ELEMENT_CONDITION_CLAUSE_DEFN for more details
+type ConditionClause struct {
+	And    []ConditionClause             `json:"and,omitempty"`
+	Or     []ConditionClause             `json:"or,omitempty"`
+	Not    []ConditionClause             `json:"not,omitempty"`
+	Assert map[string][]ConstraintClause `json:"assert,omitempty"`
+}
+
+// UnmarshalYAML unmarshals a yaml into a ConditionClause
+func (c *ConditionClause) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var keys map[string]interface{}
+	if err := unmarshal(&keys); err != nil {
+		return err
+	}
+	var logicalOps, assertions int
+	for k := range keys {
+		switch k {
+		case ConditionAnd, ConditionOr, ConditionNot, ConditionAssert:
+			logicalOps++
+		default:
+			assertions++
+		}
+	}
+	if logicalOps > 1 || (logicalOps == 1 && assertions > 0) {
+		return errors.New("a condition clause should contain either a single logical operator or attributes assertions")
+	}
+	if assertions > 0 {
+		return unmarshal(&c.Assert)
+	}
+
+	var ops struct {
+		And    []ConditionClause               `yaml:"and,omitempty"`
+		Or     []ConditionClause               `yaml:"or,omitempty"`
+		Not    []ConditionClause               `yaml:"not,omitempty"`
+		Assert []map[string][]ConstraintClause `yaml:"assert,omitempty"`
+	}
+	if err := unmarshal(&ops); err != nil {
+		return err
+	}
+	c.And, c.Or, c.Not = ops.And, ops.Or, ops.Not
+	if ops.Assert != nil {
+		c.Assert = make(map[string][]ConstraintClause)
+		for _, assertion := range ops.Assert {
+			for attr, constraints := range assertion {
+				c.Assert[attr] = append(c.Assert[attr], constraints...)
+			}
+		}
+	}
+	return nil
+}
+
+// MarshalYAML marshals a ConditionClause into yaml
+func (c ConditionClause) MarshalYAML() (interface{}, error) {
+	switch {
+	case c.And != nil:
+		return map[string]interface{}{ConditionAnd: c.And}, nil
+	case c.Or != nil:
+		return map[string]interface{}{ConditionOr: c.Or}, nil
+	case c.Not != nil:
+		return map[string]interface{}{ConditionNot: c.Not}, nil
+	}
+	return c.Assert, nil
+}
+
+// Evaluate checks if this condition clause is satisfied.
+//
+// getAttribute is used to retrieve the value of attributes referenced by assertions.
+func (c ConditionClause) Evaluate(getAttribute func(attributeName string) (string, error)) (bool, error) {
+	switch {
+	case c.And != nil:
+		return EvaluateConditionClauses(c.And, getAttribute)
+	case c.Or != nil:
+		for _, cc := range c.Or {
+			ok, err := cc.Evaluate(getAttribute)
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+	case c.Not != nil:
+		ok, err := EvaluateConditionClauses(c.Not, getAttribute)
+		return !ok, err
+	}
+	for attr, constraints := range c.Assert {
+		value, err := getAttribute(attr)
+		if err != nil {
+			return false, err
+		}
+		for _, constraint := range constraints {
+			ok, err := constraint.Evaluate(value)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+	}
+	return true, nil
+}
+
+// EvaluateConditionClauses checks if all the given condition clauses are satisfied
+func EvaluateConditionClauses(clauses []ConditionClause, getAttribute func(attributeName string) (string, error)) (bool, error) {
+	for _, cc := range clauses {
+		ok, err := cc.Evaluate(getAttribute)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
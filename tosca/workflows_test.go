@@ -0,0 +1,153 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tosca
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestGroupedWorkflowsParallel(t *testing.T) {
+	t.Run("groupWorkflows", func(t *testing.T) {
+		t.Run("TestStepFilterAndOnFailure", stepFilterAndOnFailure)
+		t.Run("TestConditionClauseEvaluate", conditionClauseEvaluate)
+		t.Run("TestConditionClauseFailing", conditionClauseFailing)
+		t.Run("TestConstraintClauseEvaluate", constraintClauseEvaluate)
+	})
+}
+
+func stepFilterAndOnFailure(t *testing.T) {
+	t.Parallel()
+	var inputYaml = `
+target: Compute
+filter:
+  - state: [{equal: started}]
+  - or:
+    - cpus: [{greater_or_equal: 4}]
+    - assert:
+      - os: [{valid_values: [linux, windows]}]
+activities:
+  - call_operation: Standard.start
+on_success:
+  - done
+on_failure:
+  - cleanup
+  - notify
+`
+	step := Step{}
+	err := yaml.Unmarshal([]byte(inputYaml), &step)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"done"}, step.OnSuccess)
+	assert.Equal(t, []string{"cleanup", "notify"}, step.OnFailure)
+	require.Len(t, step.Filter, 2)
+	require.Len(t, step.Filter[0].Assert["state"], 1)
+	assert.Equal(t, ConstraintClause{Operator: ConstraintEqual, Value: "started"}, step.Filter[0].Assert["state"][0])
+	require.Len(t, step.Filter[1].Or, 2)
+	assert.Len(t, step.Filter[1].Or[0].Assert["cpus"], 1)
+	assert.Len(t, step.Filter[1].Or[1].Assert["os"], 1)
+
+	// Check that a filter survives a marshal / unmarshal round trip as it is how it is stored
+	b, err := yaml.Marshal(step.Filter)
+	require.NoError(t, err)
+	var filter []ConditionClause
+	require.NoError(t, yaml.Unmarshal(b, &filter))
+	assert.Equal(t, step.Filter, filter)
+}
+
+func conditionClauseEvaluate(t *testing.T) {
+	t.Parallel()
+	attributes := map[string]string{"state": "started", "cpus": "2", "os": "linux"}
+	getAttribute := func(attr string) (string, error) {
+		return attributes[attr], nil
+	}
+	tests := []struct {
+		name   string
+		filter string
+		want   bool
+	}{
+		{"SimpleAssert", `[{state: [{equal: started}]}]`, true},
+		{"SimpleAssertFalse", `[{state: [{equal: stopped}]}]`, false},
+		{"SeveralConstraints", `[{cpus: [{greater_than: 1}, {less_than: 4}]}]`, true},
+		{"ImplicitAnd", `[{state: [{equal: started}]}, {cpus: [{greater_than: 2}]}]`, false},
+		{"Or", `[{or: [{state: [{equal: stopped}]}, {os: [{valid_values: [linux, windows]}]}]}]`, true},
+		{"Not", `[{not: [{state: [{equal: stopped}]}]}]`, true},
+		{"And", `[{and: [{state: [{equal: started}]}, {os: [{pattern: "lin.*"}]}]}]`, true},
+		{"Assert", `[{assert: [{state: [{equal: started}]}, {cpus: [{in_range: [4, UNBOUNDED]}]}]}]`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var filter []ConditionClause
+			require.NoError(t, yaml.Unmarshal([]byte(tt.filter), &filter))
+			got, err := EvaluateConditionClauses(filter, getAttribute)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := EvaluateConditionClauses([]ConditionClause{{Assert: map[string][]ConstraintClause{"state": {{Operator: ConstraintEqual, Value: "started"}}}}}, func(attr string) (string, error) {
+		return "", errors.New("failed to get attribute")
+	})
+	assert.Error(t, err)
+}
+
+func conditionClauseFailing(t *testing.T) {
+	t.Parallel()
+	var filter []ConditionClause
+	err := yaml.Unmarshal([]byte(`[{state: [{unknown_operator: started}]}]`), &filter)
+	assert.Error(t, err, "Expecting an error for an unsupported constraint operator")
+	err = yaml.Unmarshal([]byte(`[{state: [{equal: started, less_than: 5}]}]`), &filter)
+	assert.Error(t, err, "Expecting an error for a constraint with several operators")
+	err = yaml.Unmarshal([]byte(`[{cpus: [{in_range: [1]}]}]`), &filter)
+	assert.Error(t, err, "Expecting an error for an in_range constraint without two bounds")
+}
+
+func constraintClauseEvaluate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		constraint ConstraintClause
+		value      string
+		want       bool
+	}{
+		{"EqualString", ConstraintClause{ConstraintEqual, "started"}, "started", true},
+		{"EqualNumbers", ConstraintClause{ConstraintEqual, 2}, "2.0", true},
+		{"GreaterThanNumbers", ConstraintClause{ConstraintGreaterThan, 9}, "10", true},
+		{"LessOrEqual", ConstraintClause{ConstraintLessOrEqual, 9}, "10", false},
+		{"InRange", ConstraintClause{ConstraintInRange, []interface{}{1, 4}}, "4", true},
+		{"InRangeOut", ConstraintClause{ConstraintInRange, []interface{}{1, 4}}, "5", false},
+		{"InRangeUnbounded", ConstraintClause{ConstraintInRange, []interface{}{1, "UNBOUNDED"}}, "500", true},
+		{"ValidValues", ConstraintClause{ConstraintValidValues, []interface{}{"a", "b"}}, "c", false},
+		{"Length", ConstraintClause{ConstraintLength, 3}, "abc", true},
+		{"MinLength", ConstraintClause{ConstraintMinLength, 4}, "abc", false},
+		{"MaxLength", ConstraintClause{ConstraintMaxLength, 4}, "abc", true},
+		{"Pattern", ConstraintClause{ConstraintPattern, "[a-z]+"}, "abc", true},
+		{"PatternFullMatch", ConstraintClause{ConstraintPattern, "[a-z]+"}, "abc1", false},
+		{"ScalarUnitSize", ConstraintClause{ConstraintGreaterOrEqual, "512 MB"}, "1 GiB", true},
+		{"ScalarUnitSizeLower", ConstraintClause{ConstraintGreaterOrEqual, "1 GB"}, "512 MB", false},
+		{"ScalarUnitFrequency", ConstraintClause{ConstraintGreaterOrEqual, "0.1 GHz"}, "800 MHz", true},
+		{"ScalarUnitTimeRange", ConstraintClause{ConstraintInRange, []interface{}{"1 m", "1 h"}}, "90 s", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.constraint.Evaluate(tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}